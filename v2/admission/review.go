@@ -0,0 +1,86 @@
+package admission
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// APIVersion is the version of the AdmissionReview API that is supported.
+const APIVersion = "admission.k8s.io/v1"
+
+// Review is an `admission.k8s.io/v1` AdmissionReview, which the Kubernetes API
+// server sends to admission webhooks with a Request, and expects to receive
+// back with a Response.
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// Kind identifies the type of an object.
+type Kind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// Request describes the object being admitted.
+type Request struct {
+	UID         string          `json:"uid"`
+	Kind        Kind            `json:"kind"`
+	SubResource string          `json:"subResource,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	Namespace   string          `json:"namespace,omitempty"`
+	Operation   string          `json:"operation"`
+	Object      json.RawMessage `json:"object,omitempty"`
+	DryRun      *bool           `json:"dryRun,omitempty"`
+}
+
+// Response is the decision to admit an object or not.
+type Response struct {
+	UID              string            `json:"uid"`
+	Allowed          bool              `json:"allowed"`
+	Result           *Status           `json:"status,omitempty"`
+	Warnings         []string          `json:"warnings,omitempty"`
+	AuditAnnotations map[string]string `json:"auditAnnotations,omitempty"`
+}
+
+// Status describes why an object was not admitted.
+type Status struct {
+	Code    int32  `json:"code"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewReview creates a Review holding the passed Response.
+func NewReview(response Response) Review {
+	return Review{
+		APIVersion: APIVersion,
+		Kind:       "AdmissionReview",
+		Response:   &response,
+	}
+}
+
+// Allow creates a Response which admits the object in the Request with the
+// passed UID.
+func Allow(uid string) Response {
+	return Response{
+		UID:     uid,
+		Allowed: true,
+	}
+}
+
+// Deny creates a Response which rejects the object in the Request with the
+// passed UID, for the passed reason.
+func Deny(uid string, message string) Response {
+	return Response{
+		UID:     uid,
+		Allowed: false,
+		Result: &Status{
+			Code:    http.StatusForbidden,
+			Reason:  "Forbidden",
+			Message: message,
+		},
+	}
+}
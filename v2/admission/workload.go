@@ -0,0 +1,123 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Workload is the part of a Pod, or of an object with a Pod template, that is
+// needed to decide whether to admit it.
+type Workload struct {
+	// Annotations are the annotations of the object and of its Pod template
+	Annotations map[string]string
+	// Images are the images of every container, init container and ephemeral
+	// container, without duplicates
+	Images []string
+}
+
+type objectMeta struct {
+	Annotations map[string]string `json:"annotations"`
+}
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type podSpec struct {
+	Containers          []container `json:"containers"`
+	InitContainers      []container `json:"initContainers"`
+	EphemeralContainers []container `json:"ephemeralContainers"`
+}
+
+// podTemplate is a Pod template, or a Pod, which has the same fields.
+type podTemplate struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     podSpec    `json:"spec"`
+}
+
+type workload struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Template podTemplate `json:"template"`
+	} `json:"spec"`
+}
+
+type podTemplateObject struct {
+	Metadata objectMeta  `json:"metadata"`
+	Template podTemplate `json:"template"`
+}
+
+type cronJob struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		JobTemplate struct {
+			Metadata objectMeta `json:"metadata"`
+			Spec     struct {
+				Template podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// ParseWorkload reads the Workload from the passed object of the passed kind.
+// It returns false if objects of that kind don't run containers.
+func ParseWorkload(kind string, object json.RawMessage) (Workload, bool, error) {
+	var metadata []objectMeta
+	var spec podSpec
+	var err error
+
+	switch kind {
+	case "Pod":
+		var p podTemplate
+		err = json.Unmarshal(object, &p)
+		metadata, spec = []objectMeta{p.Metadata}, p.Spec
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		var w workload
+		err = json.Unmarshal(object, &w)
+		metadata, spec = []objectMeta{w.Spec.Template.Metadata, w.Metadata}, w.Spec.Template.Spec
+	case "PodTemplate":
+		var t podTemplateObject
+		err = json.Unmarshal(object, &t)
+		metadata, spec = []objectMeta{t.Template.Metadata, t.Metadata}, t.Template.Spec
+	case "CronJob":
+		var c cronJob
+		err = json.Unmarshal(object, &c)
+		jobTemplate := c.Spec.JobTemplate
+		metadata = []objectMeta{jobTemplate.Spec.Template.Metadata, jobTemplate.Metadata, c.Metadata}
+		spec = jobTemplate.Spec.Template.Spec
+	default:
+		return Workload{}, false, nil
+	}
+
+	if nil != err {
+		return Workload{}, true, fmt.Errorf("could not read %s: %w", kind, err)
+	}
+
+	workload := Workload{
+		Annotations: make(map[string]string),
+		Images:      make([]string, 0),
+	}
+
+	for _, meta := range metadata {
+		for key, value := range meta.Annotations {
+			workload.Annotations[key] = value
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, containers := range [][]container{spec.InitContainers, spec.Containers, spec.EphemeralContainers} {
+		for _, c := range containers {
+			if "" == c.Image {
+				return Workload{}, true, fmt.Errorf("container %q in %s has no image", c.Name, kind)
+			}
+
+			if !seen[c.Image] {
+				seen[c.Image] = true
+				workload.Images = append(workload.Images, c.Image)
+			}
+		}
+	}
+
+	return workload, true, nil
+}
@@ -0,0 +1,96 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkload(t *testing.T) {
+	testCases := []struct {
+		kind        string
+		object      string
+		images      []string
+		annotations map[string]string
+	}{
+		{
+			kind: "Pod",
+			object: `{
+				"metadata": {"annotations": {"team": "payments"}},
+				"spec": {
+					"initContainers": [{"name": "migrate", "image": "gcr.io/app/migrate:1.0"}],
+					"containers": [
+						{"name": "app", "image": "gcr.io/app/server@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2"},
+						{"name": "proxy", "image": "envoyproxy/envoy:v1.26"}
+					],
+					"ephemeralContainers": [
+						{"name": "debug", "image": "busybox"},
+						{"name": "debug-again", "image": "busybox"}
+					]
+				}
+			}`,
+			images: []string{
+				"gcr.io/app/migrate:1.0",
+				"gcr.io/app/server@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2",
+				"envoyproxy/envoy:v1.26",
+				"busybox",
+			},
+			annotations: map[string]string{"team": "payments"},
+		},
+		{
+			kind: "Deployment",
+			object: `{
+				"metadata": {"annotations": {"team": "payments"}},
+				"spec": {"template": {
+					"metadata": {"annotations": {"team": "ignored", "sidecar": "true"}},
+					"spec": {"containers": [{"name": "app", "image": "gcr.io/app/server:2.0"}]}
+				}}
+			}`,
+			images:      []string{"gcr.io/app/server:2.0"},
+			annotations: map[string]string{"team": "payments", "sidecar": "true"},
+		},
+		{
+			kind: "CronJob",
+			object: `{
+				"metadata": {"annotations": {"team": "payments"}},
+				"spec": {"jobTemplate": {"spec": {"template": {
+					"spec": {"containers": [{"name": "report", "image": "gcr.io/app/report:3.0"}]}
+				}}}}
+			}`,
+			images:      []string{"gcr.io/app/report:3.0"},
+			annotations: map[string]string{"team": "payments"},
+		},
+		{
+			kind: "PodTemplate",
+			object: `{
+				"template": {"spec": {"containers": [{"name": "app", "image": "gcr.io/app/server:2.0"}]}}
+			}`,
+			images:      []string{"gcr.io/app/server:2.0"},
+			annotations: map[string]string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.kind, func(t *testing.T) {
+			workload, ok, err := ParseWorkload(testCase.kind, []byte(testCase.object))
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, testCase.images, workload.Images)
+			assert.Equal(t, testCase.annotations, workload.Annotations)
+		})
+	}
+}
+
+func TestParseWorkloadErrors(t *testing.T) {
+	_, ok, err := ParseWorkload("ConfigMap", []byte(`{"data": {}}`))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = ParseWorkload("Pod", []byte(`{"spec": []}`))
+	assert.Error(t, err)
+	assert.True(t, ok)
+
+	_, _, err = ParseWorkload("Pod", []byte(`{"spec": {"containers": [{"name": "app"}]}}`))
+	assert.EqualError(t, err, `container "app" in Pod has no image`)
+}
@@ -1,14 +1,33 @@
 package voucher
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafeas/voucher/v2/attestation"
 	"github.com/grafeas/voucher/v2/signer"
 )
 
+// ErrNoVerifier is returned when an attestation is verified without an
+// AttestationVerifier to verify it with.
+var ErrNoVerifier = errors.New("cannot verify attestation, no verifier configured")
+
+// ErrDigestMismatch is returned when an attestation's payload refers to an
+// image other than the one being verified.
+var ErrDigestMismatch = errors.New("attestation payload does not match image digest")
+
 // Attestation is a structure that contains the Attestation data that we want
 // to create an MetadataItem from.
 type Attestation struct {
 	CheckName string
 	Body      string
+
+	// PayloadType is the DSSE payload type of the Body. If set, the Body is
+	// signed as the payload of a DSSE envelope, and is replaced by the signed
+	// envelope when the Attestation is signed.
+	PayloadType string
 }
 
 // NewAttestation creates a new Attestation for the check with the passed name,
@@ -30,8 +49,13 @@ type SignedAttestation struct {
 }
 
 // SignAttestation takes a keyring and attestation and signs the body of the
-// payload with it, updating the Attestation's Signature field.
+// payload with it, updating the Attestation's Signature field. Attestations
+// with a PayloadType are signed as DSSE envelopes.
 func SignAttestation(s signer.AttestationSigner, attestation Attestation) (SignedAttestation, error) {
+	if "" != attestation.PayloadType {
+		return signEnvelope(s, attestation)
+	}
+
 	signature, keyID, err := s.Sign(attestation.CheckName, attestation.Body)
 	if nil != err {
 		return SignedAttestation{}, err
@@ -44,6 +68,37 @@ func SignAttestation(s signer.AttestationSigner, attestation Attestation) (Signe
 	}, nil
 }
 
+// signEnvelope signs the pre-authentication encoding of the attestation's
+// body, and replaces the body with a DSSE envelope holding the signature.
+func signEnvelope(s signer.AttestationSigner, a Attestation) (SignedAttestation, error) {
+	signature, keyID, err := s.Sign(a.CheckName, string(attestation.PAE(a.PayloadType, []byte(a.Body))))
+	if nil != err {
+		return SignedAttestation{}, err
+	}
+
+	envelope, err := json.Marshal(attestation.Envelope{
+		PayloadType: a.PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString([]byte(a.Body)),
+		Signatures: []attestation.EnvelopeSignature{
+			{
+				KeyID: keyID,
+				Sig:   base64.StdEncoding.EncodeToString([]byte(signature)),
+			},
+		},
+	})
+	if nil != err {
+		return SignedAttestation{}, err
+	}
+
+	a.Body = string(envelope)
+
+	return SignedAttestation{
+		Attestation: a,
+		Signature:   signature,
+		KeyID:       keyID,
+	}, nil
+}
+
 // SignedAttestationToResult returns a CheckResults from the SignedAttestation
 // passed to it. Check names is set as appropriate.
 func SignedAttestationToResult(attestation SignedAttestation) CheckResult {
@@ -54,3 +109,60 @@ func SignedAttestationToResult(attestation SignedAttestation) CheckResult {
 		Details:  attestation,
 	}
 }
+
+// VerifyAttestation verifies that the SignedAttestation was signed by the key
+// configured for its check, and that its payload was created for the passed
+// image. Returns an error describing why the attestation was rejected, or nil
+// if it can be trusted.
+func VerifyAttestation(v signer.AttestationVerifier, imageData ImageData, signedAttestation SignedAttestation) error {
+	if nil == v {
+		return ErrNoVerifier
+	}
+
+	if envelope, err := attestation.ParseEnvelope([]byte(signedAttestation.Body)); nil == err {
+		return verifyEnvelope(v, imageData, signedAttestation, envelope)
+	}
+
+	err := v.Verify(signedAttestation.CheckName, signedAttestation.Body, signedAttestation.Signature, signedAttestation.KeyID)
+	if nil != err {
+		return err
+	}
+
+	payload, err := attestation.ParsePayload(signedAttestation.Body)
+	if nil != err {
+		return fmt.Errorf("could not parse attestation payload: %w", err)
+	}
+
+	if payload.Critical.Image.DockerManifestDigest != imageData.Digest() {
+		return fmt.Errorf("%w: payload is for %s", ErrDigestMismatch, payload.Critical.Image.DockerManifestDigest)
+	}
+
+	return nil
+}
+
+// verifyEnvelope verifies that the DSSE envelope in the SignedAttestation's
+// body was signed by the key configured for its check, and that the in-toto
+// statement it holds is about the passed image.
+func verifyEnvelope(v signer.AttestationVerifier, imageData ImageData, signedAttestation SignedAttestation, envelope attestation.Envelope) error {
+	payload, err := envelope.DecodePayload()
+	if nil != err {
+		return fmt.Errorf("could not decode attestation payload: %w", err)
+	}
+
+	message := string(attestation.PAE(envelope.PayloadType, payload))
+	err = v.Verify(signedAttestation.CheckName, message, signedAttestation.Signature, signedAttestation.KeyID)
+	if nil != err {
+		return err
+	}
+
+	statement, err := attestation.ParseStatement(payload)
+	if nil != err {
+		return fmt.Errorf("could not parse attestation payload: %w", err)
+	}
+
+	if !statement.HasSubject(imageData.Digest()) {
+		return fmt.Errorf("%w: statement is not about %s", ErrDigestMismatch, imageData.Digest())
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package attestation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Envelope is a DSSE (Dead Simple Signing Envelope) envelope, which wraps a
+// payload and its signatures.
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a signature in a DSSE envelope.
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// ParseEnvelope parses the JSON encoded DSSE envelope in the passed bytes.
+func ParseEnvelope(b []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(b, &envelope); nil != err {
+		return Envelope{}, err
+	}
+
+	if "" == envelope.PayloadType {
+		return Envelope{}, fmt.Errorf("envelope has no payload type")
+	}
+
+	return envelope, nil
+}
+
+// DecodePayload returns the decoded payload of the envelope.
+func (e Envelope) DecodePayload() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(e.Payload)
+}
+
+// PAE returns the DSSE pre-authentication encoding of the passed payload
+// type and payload, which is the message that envelope signatures sign.
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
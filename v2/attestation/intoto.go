@@ -0,0 +1,62 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	// InTotoPayloadType is the DSSE payload type of in-toto statements.
+	InTotoPayloadType = "application/vnd.in-toto+json"
+
+	// StatementType is the type of in-toto v1 statements.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// statementTypePrefix is the prefix shared by all versions of the in-toto
+	// statement type.
+	statementTypePrefix = "https://in-toto.io/Statement/"
+)
+
+// Subject is an artifact that an in-toto statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Statement is an in-toto statement, which makes a claim (the predicate)
+// about a set of artifacts (the subjects).
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate,omitempty"`
+}
+
+// ParseStatement parses the JSON encoded in-toto statement in the passed bytes.
+func ParseStatement(b []byte) (Statement, error) {
+	var statement Statement
+	if err := json.Unmarshal(b, &statement); nil != err {
+		return Statement{}, err
+	}
+
+	if !strings.HasPrefix(statement.Type, statementTypePrefix) {
+		return Statement{}, fmt.Errorf("not an in-toto statement: %q", statement.Type)
+	}
+
+	return statement, nil
+}
+
+// HasSubject returns true if one of the statement's subjects has the passed
+// digest.
+func (s Statement) HasSubject(d digest.Digest) bool {
+	for _, subject := range s.Subject {
+		if value, ok := subject.Digest[string(d.Algorithm())]; ok && value == d.Encoded() {
+			return true
+		}
+	}
+
+	return false
+}
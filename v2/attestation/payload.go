@@ -40,6 +40,15 @@ func (p Payload) ToString() (string, error) {
 	return string(b), nil
 }
 
+// ParsePayload parses the JSON encoded payload in the passed string.
+func ParsePayload(body string) (Payload, error) {
+	var p Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		return Payload{}, err
+	}
+	return p, nil
+}
+
 // NewPayload creates a new Binauth specific payload for the image at
 // the passed URL.
 func NewPayload(reference reference.Canonical) Payload {
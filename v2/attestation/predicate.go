@@ -0,0 +1,54 @@
+package attestation
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/docker/distribution/reference"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// VoucherPredicateType is the predicate type of the in-toto statements that
+// Voucher creates for the checks an image passed.
+const VoucherPredicateType = "https://github.com/grafeas/voucher/check-result/v1"
+
+// VoucherPredicate is the predicate of the in-toto statements that Voucher
+// creates, describing the check that an image passed.
+type VoucherPredicate struct {
+	Check       string                  `json:"check"`
+	Details     interface{}             `json:"details,omitempty"`
+	BuildDetail *repository.BuildDetail `json:"buildDetail,omitempty"`
+	Timestamp   time.Time               `json:"timestamp"`
+}
+
+// NewStatement creates a new in-toto statement about the image at the passed
+// reference, with the passed predicate.
+func NewStatement(ref reference.Canonical, predicateType string, predicate interface{}) (Statement, error) {
+	b, err := json.Marshal(predicate)
+	if nil != err {
+		return Statement{}, err
+	}
+
+	return Statement{
+		Type: StatementType,
+		Subject: []Subject{
+			{
+				Name:   ref.Name(),
+				Digest: map[string]string{string(ref.Digest().Algorithm()): ref.Digest().Encoded()},
+			},
+		},
+		PredicateType: predicateType,
+		Predicate:     b,
+	}, nil
+}
+
+// ToString returns the statement as a JSON encoded string, or returns an
+// error.
+func (s Statement) ToString() (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,105 @@
+package voucher
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/signer"
+	"github.com/grafeas/voucher/v2/signer/pgp"
+)
+
+func newTestKeyRing(t *testing.T) *pgp.KeyRing {
+	t.Helper()
+
+	keyring := pgp.NewKeyRing()
+
+	keyFile, err := os.Open("../testdata/testkey.asc")
+	require.NoError(t, err, "failed to open key file")
+	defer keyFile.Close()
+
+	err = pgp.AddKeyToKeyRingFromReader(keyring, "snakeoil", keyFile)
+	require.NoError(t, err, "failed to add key to keyring")
+
+	return keyring
+}
+
+func newTestSignedAttestation(t *testing.T, s signer.AttestationSigner, imageData ImageData) SignedAttestation {
+	t.Helper()
+
+	payload, err := attestation.NewPayload(imageData).ToString()
+	require.NoError(t, err)
+
+	signedAttestation, err := SignAttestation(s, NewAttestation("snakeoil", payload))
+	require.NoError(t, err)
+
+	return signedAttestation
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	keyring := newTestKeyRing(t)
+	imageData := newTestImageData(t)
+	signedAttestation := newTestSignedAttestation(t, keyring, imageData)
+
+	assert.NoError(t, VerifyAttestation(keyring, imageData, signedAttestation))
+
+	assert.ErrorIs(t, VerifyAttestation(nil, imageData, signedAttestation), ErrNoVerifier)
+
+	otherImage, err := NewImageData("localhost.local/path/to/image@sha256:97db2bc359ccc94d3b2d6f5daa4173e9e91c513b0dcd961408adbb95ec5e5ce5")
+	require.NoError(t, err)
+	assert.ErrorIs(t, VerifyAttestation(keyring, otherImage, signedAttestation), ErrDigestMismatch)
+
+	forged := signedAttestation
+	forged.Body = newTestSignedAttestation(t, keyring, otherImage).Body
+	assert.ErrorIs(t, VerifyAttestation(keyring, imageData, forged), signer.ErrInvalidSignature)
+
+	wrongKey := signedAttestation
+	wrongKey.KeyID = "0123456789ABCDEF"
+	assert.ErrorIs(t, VerifyAttestation(keyring, imageData, wrongKey), signer.ErrKeyMismatch)
+
+	unsigned := signedAttestation
+	unsigned.CheckName = "diy"
+	assert.ErrorIs(t, VerifyAttestation(keyring, imageData, unsigned), signer.ErrNoKeyForCheck)
+}
+
+func TestVerifyEnvelopeAttestation(t *testing.T) {
+	keyring := newTestKeyRing(t)
+	imageData := newTestImageData(t)
+
+	newSignedStatement := func(imageData ImageData) SignedAttestation {
+		statement, err := attestation.NewStatement(imageData, attestation.VoucherPredicateType, attestation.VoucherPredicate{Check: "snakeoil"})
+		require.NoError(t, err)
+
+		payload, err := statement.ToString()
+		require.NoError(t, err)
+
+		a := NewAttestation("snakeoil", payload)
+		a.PayloadType = attestation.InTotoPayloadType
+
+		signedAttestation, err := SignAttestation(keyring, a)
+		require.NoError(t, err)
+
+		return signedAttestation
+	}
+
+	signedAttestation := newSignedStatement(imageData)
+
+	envelope, err := attestation.ParseEnvelope([]byte(signedAttestation.Body))
+	require.NoError(t, err)
+	assert.Equal(t, attestation.InTotoPayloadType, envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+	assert.Equal(t, signedAttestation.KeyID, envelope.Signatures[0].KeyID)
+
+	assert.NoError(t, VerifyAttestation(keyring, imageData, signedAttestation))
+
+	otherImage, err := NewImageData("localhost.local/path/to/image@sha256:97db2bc359ccc94d3b2d6f5daa4173e9e91c513b0dcd961408adbb95ec5e5ce5")
+	require.NoError(t, err)
+	assert.ErrorIs(t, VerifyAttestation(keyring, otherImage, signedAttestation), ErrDigestMismatch)
+
+	forged := signedAttestation
+	forged.Body = newSignedStatement(otherImage).Body
+	assert.ErrorIs(t, VerifyAttestation(keyring, imageData, forged), signer.ErrInvalidSignature)
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/docker/distribution/reference"
+	"golang.org/x/oauth2"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// Composite is a voucher.Auth which delegates to the first of its providers which
+// is for the image's domain.
+type Composite struct {
+	providers []voucher.Auth
+}
+
+// GetTokenSource gets the oauth2.TokenSource from the provider for the
+// passed image.
+func (c *Composite) GetTokenSource(ctx context.Context, ref reference.Named) (oauth2.TokenSource, error) {
+	p := c.providerFor(ref)
+	if nil == p {
+		return nil, NewAuthError("does not match domain", ref)
+	}
+	return p.GetTokenSource(ctx, ref)
+}
+
+// ToClient returns a new http.Client from the provider for the passed image.
+func (c *Composite) ToClient(ctx context.Context, image reference.Named) (*http.Client, error) {
+	p := c.providerFor(image)
+	if nil == p {
+		return nil, NewAuthError("does not match domain", image)
+	}
+	return p.ToClient(ctx, image)
+}
+
+// IsForDomain returns true if any of the providers are for the passed image's
+// domain.
+func (c *Composite) IsForDomain(image reference.Named) bool {
+	return nil != c.providerFor(image)
+}
+
+// providerFor returns the first provider for the passed image's domain, or
+// nil if there isn't one.
+func (c *Composite) providerFor(image reference.Named) voucher.Auth {
+	for _, p := range c.providers {
+		if p.IsForDomain(image) {
+			return p
+		}
+	}
+	return nil
+}
+
+// NewComposite creates a new Composite Auth, which uses the first of the
+// passed providers which is for an image's domain. More specific providers
+// should be passed first.
+func NewComposite(providers ...voucher.Auth) *Composite {
+	return &Composite{
+		providers: providers,
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// domainAuth is a voucher.Auth for a single domain.
+type domainAuth struct {
+	domain string
+	client *http.Client
+}
+
+func (a *domainAuth) GetTokenSource(ctx context.Context, ref reference.Named) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: a.domain}), nil
+}
+
+func (a *domainAuth) ToClient(ctx context.Context, image reference.Named) (*http.Client, error) {
+	return a.client, nil
+}
+
+func (a *domainAuth) IsForDomain(image reference.Named) bool {
+	return reference.Domain(image) == a.domain
+}
+
+func TestComposite(t *testing.T) {
+	gcr := &domainAuth{domain: "gcr.io", client: new(http.Client)}
+	ghcr := &domainAuth{domain: "ghcr.io", client: new(http.Client)}
+
+	composite := NewComposite(gcr, ghcr)
+
+	image, err := reference.ParseNamed("ghcr.io/grafeas/voucher")
+	require.NoError(t, err)
+
+	assert.True(t, composite.IsForDomain(image))
+
+	client, err := composite.ToClient(context.Background(), image)
+	require.NoError(t, err)
+	assert.Same(t, ghcr.client, client)
+
+	tokenSource, err := composite.GetTokenSource(context.Background(), image)
+	require.NoError(t, err)
+	token, err := tokenSource.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io", token.AccessToken)
+
+	image, err = reference.ParseNamed("quay.io/grafeas/voucher")
+	require.NoError(t, err)
+
+	assert.False(t, composite.IsForDomain(image))
+
+	_, err = composite.ToClient(context.Background(), image)
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"golang.org/x/oauth2"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/auth"
+	"github.com/grafeas/voucher/v2/docker/uri"
+)
+
+// ErrNoTokenAuth is returned when a token source is requested for a registry
+// which does not use bearer token authentication.
+var ErrNoTokenAuth = errors.New("registry does not use token authentication")
+
+// expiryDelta is how long before a token expires that it is refreshed.
+const expiryDelta = 10 * time.Second
+
+// registryAuth implements voucher.Auth for registries which implement the
+// Docker registry authentication specification, using either basic
+// authentication or the bearer token handshake.
+type registryAuth struct {
+	domain      string            // The domain this Auth is for, or empty for all domains.
+	credentials Credentials       // [optional] The credentials to authenticate with.
+	base        http.RoundTripper // The transport used to connect to registries.
+}
+
+// GetTokenSource returns an oauth2.TokenSource which performs the registry
+// token handshake to get pull access to the passed repository.
+func (a *registryAuth) GetTokenSource(ctx context.Context, ref reference.Named) (oauth2.TokenSource, error) {
+	if !a.IsForDomain(ref) {
+		return nil, auth.NewAuthError("does not match domain", ref)
+	}
+
+	return oauth2.ReuseTokenSource(nil, &tokenSource{
+		ctx:  ctx,
+		auth: a,
+		ref:  ref,
+	}), nil
+}
+
+// ToClient returns a new http.Client which authenticates with the registry
+// that the passed image is stored in, responding to the registry's
+// authentication challenges.
+func (a *registryAuth) ToClient(ctx context.Context, image reference.Named) (*http.Client, error) {
+	if !a.IsForDomain(image) {
+		return nil, auth.NewAuthError("does not match domain", image)
+	}
+
+	return &http.Client{
+		Transport: &transport{
+			auth:  a,
+			host:  uri.GetRegistryHost(image),
+			scope: repositoryScope(image),
+		},
+	}, nil
+}
+
+// IsForDomain returns true if this Auth should be used for the passed image.
+func (a *registryAuth) IsForDomain(image reference.Named) bool {
+	return "" == a.domain || reference.Domain(image) == a.domain
+}
+
+// NewAuth creates a new voucher.Auth which authenticates with the registry
+// at the passed domain using the passed Credentials.
+func NewAuth(domain string, credentials Credentials) voucher.Auth {
+	return &registryAuth{
+		domain:      NormalizeDomain(domain),
+		credentials: credentials,
+		base:        auth.DefaultTransport,
+	}
+}
+
+// NewAnonymousAuth creates a new voucher.Auth which connects to any registry
+// without credentials, performing the token handshake where required. This
+// allows access to public images.
+func NewAnonymousAuth() voucher.Auth {
+	return &registryAuth{
+		base: auth.DefaultTransport,
+	}
+}
+
+// repositoryScope returns the token scope for pulling the passed image.
+func repositoryScope(ref reference.Named) string {
+	return "repository:" + reference.Path(ref) + ":pull"
+}
+
+// tokenSource implements oauth2.TokenSource using the registry token
+// handshake.
+type tokenSource struct {
+	ctx  context.Context
+	auth *registryAuth
+	ref  reference.Named
+}
+
+// Token pings the registry to get its authentication challenge, and requests
+// a token from the challenge's realm.
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	pingURL := "https://" + uri.GetRegistryHost(s.ref) + "/v2/"
+
+	request, err := http.NewRequestWithContext(s.ctx, http.MethodGet, pingURL, nil)
+	if nil != err {
+		return nil, err
+	}
+
+	resp, err := s.auth.base.RoundTrip(request)
+	if nil != err {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	c, ok := parseChallenge(resp)
+	if !ok || "bearer" != c.scheme {
+		return nil, ErrNoTokenAuth
+	}
+
+	return requestToken(s.ctx, &http.Client{Transport: s.auth.base}, c, repositoryScope(s.ref), s.auth.credentials)
+}
+
+// transport is a http.RoundTripper which authenticates requests to a
+// registry. Requests to other hosts, such as the storage that registries
+// redirect blob downloads to, are sent without credentials.
+type transport struct {
+	auth  *registryAuth
+	host  string
+	scope string
+
+	mu            sync.Mutex
+	authorization string    // The current value of the Authorization header.
+	expiry        time.Time // When the authorization expires, or zero if it doesn't.
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.auth.base.RoundTrip(req)
+	}
+
+	resp, err := t.auth.base.RoundTrip(t.withAuthorization(req))
+	if nil != err || http.StatusUnauthorized != resp.StatusCode {
+		return resp, err
+	}
+
+	c, ok := parseChallenge(resp)
+	if !ok {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if err = t.authorize(req.Context(), c); nil != err {
+		return nil, fmt.Errorf("failed to authenticate with %s: %w", t.host, err)
+	}
+
+	return t.auth.base.RoundTrip(t.withAuthorization(req))
+}
+
+// withAuthorization returns a copy of the passed request with the current
+// Authorization header set, if there is one.
+func (t *transport) withAuthorization(req *http.Request) *http.Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if "" == t.authorization || (!t.expiry.IsZero() && time.Now().Add(expiryDelta).After(t.expiry)) {
+		return req
+	}
+
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", t.authorization)
+	return authorized
+}
+
+// authorize responds to the passed challenge, updating the Authorization
+// header used for future requests.
+func (t *transport) authorize(ctx context.Context, c challenge) error {
+	switch c.scheme {
+	case "basic":
+		if "" == t.auth.credentials.Username && "" == t.auth.credentials.Password {
+			return errors.New("registry requires basic authentication, but no credentials are configured")
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		request.SetBasicAuth(t.auth.credentials.Username, t.auth.credentials.Password)
+
+		t.mu.Lock()
+		t.authorization, t.expiry = request.Header.Get("Authorization"), time.Time{}
+		t.mu.Unlock()
+		return nil
+	case "bearer":
+		// Challenges for requests without a repository (such as pings) have
+		// no scope, so fall back to pulling the image's repository.
+		scope := c.parameters["scope"]
+		if "" == scope {
+			scope = t.scope
+		}
+
+		token, err := requestToken(ctx, &http.Client{Transport: t.auth.base}, c, scope, t.auth.credentials)
+		if nil != err {
+			return err
+		}
+
+		t.mu.Lock()
+		t.authorization, t.expiry = token.Type()+" "+token.AccessToken, token.Expiry
+		t.mu.Unlock()
+		return nil
+	}
+
+	return fmt.Errorf("unsupported authentication scheme %q", c.scheme)
+}
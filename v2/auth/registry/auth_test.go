@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rewriteTransport sends all requests to the test server.
+type rewriteTransport struct {
+	host string
+	base http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Host = t.host
+	return t.base.RoundTrip(req)
+}
+
+func newTestAuth(t *testing.T, server *httptest.Server, credentials Credentials) *registryAuth {
+	t.Helper()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	return &registryAuth{
+		domain:      "registry.example.com",
+		credentials: credentials,
+		base: &rewriteTransport{
+			host: serverURL.Host,
+			base: server.Client().Transport,
+		},
+	}
+}
+
+func newTestImage(t *testing.T, name string) reference.Named {
+	t.Helper()
+
+	ref, err := reference.ParseNamed(name)
+	require.NoError(t, err)
+	return ref
+}
+
+// newTokenRegistry creates a registry which requires a bearer token, issued
+// to the passed user, to read manifests.
+func newTokenRegistry(t *testing.T, username, password string) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			user, pass, _ := r.BasicAuth()
+			if user != username || pass != password {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "registry.example.com", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:team/image:pull", r.URL.Query().Get("scope"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"token": "secret-token", "expires_in": 300})
+		case "/v2/", "/v2/team/image/manifests/latest":
+			if r.Header.Get("Authorization") != "Bearer secret-token" {
+				scope := ""
+				if r.URL.Path != "/v2/" {
+					scope = `,scope="repository:team/image:pull"`
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="https://registry.example.com/token",service="registry.example.com"`+scope)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte("manifest"))
+		case "/storage/blob":
+			assert.Empty(t, r.Header.Get("Authorization"), "credentials were sent to blob storage")
+			_, _ = w.Write([]byte("blob"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestToClientWithTokenHandshake(t *testing.T) {
+	server := newTokenRegistry(t, "user", "pass")
+	a := newTestAuth(t, server, Credentials{Username: "user", Password: "pass"})
+	image := newTestImage(t, "registry.example.com/team/image")
+
+	client, err := a.ToClient(context.Background(), image)
+	require.NoError(t, err)
+
+	resp, err := client.Get("https://registry.example.com/v2/team/image/manifests/latest")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = client.Get("https://storage.example.com/storage/blob")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestToClientWithBadCredentials(t *testing.T) {
+	server := newTokenRegistry(t, "user", "pass")
+	a := newTestAuth(t, server, Credentials{Username: "user", Password: "wrong"})
+
+	client, err := a.ToClient(context.Background(), newTestImage(t, "registry.example.com/team/image"))
+	require.NoError(t, err)
+
+	_, err = client.Get("https://registry.example.com/v2/team/image/manifests/latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to authenticate with registry.example.com")
+}
+
+func TestGetTokenSource(t *testing.T) {
+	server := newTokenRegistry(t, "user", "pass")
+	a := newTestAuth(t, server, Credentials{Username: "user", Password: "pass"})
+
+	tokenSource, err := a.GetTokenSource(context.Background(), newTestImage(t, "registry.example.com/team/image"))
+	require.NoError(t, err)
+
+	token, err := tokenSource.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", token.AccessToken)
+	assert.True(t, token.Valid())
+}
+
+func TestToClientWithBasicAuth(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("manifest"))
+	}))
+	defer server.Close()
+
+	a := newTestAuth(t, server, Credentials{Username: "user", Password: "pass"})
+	image := newTestImage(t, "registry.example.com/team/image")
+
+	client, err := a.ToClient(context.Background(), image)
+	require.NoError(t, err)
+
+	resp, err := client.Get("https://registry.example.com/v2/team/image/manifests/latest")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	tokenSource, err := a.GetTokenSource(context.Background(), image)
+	require.NoError(t, err)
+
+	_, err = tokenSource.Token()
+	assert.Equal(t, ErrNoTokenAuth, err)
+}
+
+func TestIsForDomain(t *testing.T) {
+	a := NewAuth("https://index.docker.io/v1/", Credentials{Username: "user"})
+	assert.True(t, a.IsForDomain(newTestImage(t, "docker.io/library/alpine")))
+	assert.False(t, a.IsForDomain(newTestImage(t, "ghcr.io/grafeas/voucher")))
+
+	_, err := a.ToClient(context.Background(), newTestImage(t, "ghcr.io/grafeas/voucher"))
+	assert.Error(t, err)
+
+	anonymous := NewAnonymousAuth()
+	assert.True(t, anonymous.IsForDomain(newTestImage(t, "ghcr.io/grafeas/voucher")))
+}
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"net/http"
+	"strings"
+)
+
+// challenge is an authentication challenge from a WWW-Authenticate header.
+type challenge struct {
+	scheme     string
+	parameters map[string]string
+}
+
+// parseChallenge parses the first challenge in the WWW-Authenticate header
+// of the passed response. Returns false if there is no challenge.
+func parseChallenge(resp *http.Response) (challenge, bool) {
+	header := strings.TrimSpace(resp.Header.Get("WWW-Authenticate"))
+	if "" == header {
+		return challenge{}, false
+	}
+
+	scheme, rest, _ := strings.Cut(header, " ")
+	c := challenge{
+		scheme:     strings.ToLower(scheme),
+		parameters: make(map[string]string),
+	}
+
+	for rest = strings.TrimSpace(rest); "" != rest; rest = strings.TrimLeft(rest, ", ") {
+		var key string
+		key, rest, _ = strings.Cut(rest, "=")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			value, rest = parseQuoted(rest[1:])
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		c.parameters[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return c, true
+}
+
+// parseQuoted returns the quoted string at the start of s, which has had its
+// opening quote removed, and the remainder of s after the closing quote.
+func parseQuoted(s string) (string, string) {
+	var value strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				value.WriteByte(s[i])
+			}
+		case '"':
+			return value.String(), s[i+1:]
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return value.String(), ""
+}
@@ -0,0 +1,33 @@
+package registry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChallenge(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("WWW-Authenticate", `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/alpine:pull,push"`)
+
+	c, ok := parseChallenge(resp)
+	require.True(t, ok)
+	assert.Equal(t, "bearer", c.scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/alpine:pull,push",
+	}, c.parameters)
+
+	resp.Header.Set("WWW-Authenticate", `Basic realm="Registry \"Realm\""`)
+	c, ok = parseChallenge(resp)
+	require.True(t, ok)
+	assert.Equal(t, "basic", c.scheme)
+	assert.Equal(t, `Registry "Realm"`, c.parameters["realm"])
+
+	resp.Header.Del("WWW-Authenticate")
+	_, ok = parseChallenge(resp)
+	assert.False(t, ok)
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// dockerHubDomain is the domain that the reference package uses for images
+// stored on Docker Hub.
+const dockerHubDomain = "docker.io"
+
+// Credentials are the credentials used to authenticate with a registry.
+// Either a username and password, or an identity token (an OAuth2 refresh
+// token) can be set.
+type Credentials struct {
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// IsEmpty returns true if the Credentials are not set.
+func (c Credentials) IsEmpty() bool {
+	return "" == c.Username && "" == c.Password && "" == c.IdentityToken
+}
+
+// dockerConfig is the subset of the Docker CLI's config.json that voucher
+// uses.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// ErrCredentialHelpersUnsupported is returned when a Docker config file
+// stores its credentials in a credential helper, rather than in the file.
+var ErrCredentialHelpersUnsupported = errors.New("docker credential helpers are not supported")
+
+// ReadDockerConfig reads the registry credentials stored in a Docker CLI
+// config.json file, keyed by registry domain.
+func ReadDockerConfig(path string) (map[string]Credentials, error) {
+	b, err := os.ReadFile(path)
+	if nil != err {
+		return nil, err
+	}
+
+	var config dockerConfig
+	if err = json.Unmarshal(b, &config); nil != err {
+		return nil, fmt.Errorf("failed to parse docker config %s: %w", path, err)
+	}
+
+	if 0 == len(config.Auths) && ("" != config.CredsStore || 0 != len(config.CredHelpers)) {
+		return nil, ErrCredentialHelpersUnsupported
+	}
+
+	credentials := make(map[string]Credentials, len(config.Auths))
+	for registry, entry := range config.Auths {
+		creds := Credentials{
+			Username:      entry.Username,
+			Password:      entry.Password,
+			IdentityToken: entry.IdentityToken,
+		}
+
+		if "" != entry.Auth {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if nil != err {
+				return nil, fmt.Errorf("invalid auth for %s in docker config: %w", registry, err)
+			}
+
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid auth for %s in docker config: missing password", registry)
+			}
+			creds.Username, creds.Password = username, password
+		}
+
+		if creds.IsEmpty() {
+			continue
+		}
+
+		credentials[NormalizeDomain(registry)] = creds
+	}
+
+	return credentials, nil
+}
+
+// NormalizeDomain converts a registry address, as it is written in a Docker
+// config file, to the domain used in image references. For example,
+// "https://index.docker.io/v1/" becomes "docker.io".
+func NormalizeDomain(registry string) string {
+	domain := registry
+	if strings.Contains(domain, "://") {
+		if u, err := url.Parse(domain); nil == err {
+			domain = u.Host
+		}
+	}
+
+	domain, _, _ = strings.Cut(domain, "/")
+
+	switch domain {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubDomain
+	}
+
+	return domain
+}
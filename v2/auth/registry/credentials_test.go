@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDockerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNzOndvcmQ="},
+    "ghcr.io": {"username": "octocat", "password": "ghp_token"},
+    "registry.example.com:5000": {"identitytoken": "refresh"},
+    "empty.example.com": {}
+  }
+}`), 0600)
+	require.NoError(t, err)
+
+	credentials, err := ReadDockerConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]Credentials{
+		"docker.io":                 {Username: "user", Password: "pass:word"},
+		"ghcr.io":                   {Username: "octocat", Password: "ghp_token"},
+		"registry.example.com:5000": {IdentityToken: "refresh"},
+	}, credentials)
+}
+
+func TestReadDockerConfigWithCredentialHelper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"credsStore": "desktop"}`), 0600))
+
+	_, err := ReadDockerConfig(path)
+	assert.Equal(t, ErrCredentialHelpersUnsupported, err)
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	assert.Equal(t, "docker.io", NormalizeDomain("https://index.docker.io/v1/"))
+	assert.Equal(t, "docker.io", NormalizeDomain("registry-1.docker.io"))
+	assert.Equal(t, "ghcr.io", NormalizeDomain("ghcr.io"))
+	assert.Equal(t, "localhost:5000", NormalizeDomain("http://localhost:5000"))
+	assert.Equal(t, "quay.io", NormalizeDomain("quay.io/organization"))
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// clientID is the client ID voucher identifies itself with when requesting
+// tokens with an identity token.
+const clientID = "voucher"
+
+// defaultTokenExpiry is how long tokens are assumed to be valid for if the
+// token server does not say, as defined by the registry token specification.
+const defaultTokenExpiry = 60 * time.Second
+
+// ErrNoRealm is returned when a registry's bearer challenge has no realm to
+// request tokens from.
+var ErrNoRealm = errors.New("registry token challenge has no realm")
+
+// tokenResponse is the response from a registry token server.
+type tokenResponse struct {
+	Token       string    `json:"token"`
+	AccessToken string    `json:"access_token"`
+	ExpiresIn   int       `json:"expires_in"`
+	IssuedAt    time.Time `json:"issued_at"`
+}
+
+// requestToken requests a bearer token from the realm in the passed
+// challenge, for the passed scope. Credentials are only sent if they are set,
+// allowing anonymous access to public repositories.
+func requestToken(ctx context.Context, client *http.Client, c challenge, scope string, credentials Credentials) (*oauth2.Token, error) {
+	realm := c.parameters["realm"]
+	if "" == realm {
+		return nil, ErrNoRealm
+	}
+
+	if "" == scope {
+		scope = c.parameters["scope"]
+	}
+
+	var request *http.Request
+	var err error
+
+	if "" != credentials.IdentityToken {
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", credentials.IdentityToken)
+		form.Set("client_id", clientID)
+		form.Set("service", c.parameters["service"])
+		form.Set("scope", scope)
+
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, realm, strings.NewReader(form.Encode()))
+		if nil != err {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, realm, nil)
+		if nil != err {
+			return nil, err
+		}
+
+		query := request.URL.Query()
+		if service := c.parameters["service"]; "" != service {
+			query.Set("service", service)
+		}
+		if "" != scope {
+			query.Set("scope", scope)
+		}
+		request.URL.RawQuery = query.Encode()
+
+		if "" != credentials.Username || "" != credentials.Password {
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+	}
+
+	resp, err := client.Do(request)
+	if nil != err {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if nil != err {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to get registry token with status %q: %s", resp.Status, b)
+	}
+
+	var tr tokenResponse
+	if err = json.Unmarshal(b, &tr); nil != err {
+		return nil, fmt.Errorf("failed to parse registry token: %w", err)
+	}
+
+	token := &oauth2.Token{
+		AccessToken: tr.Token,
+		TokenType:   "Bearer",
+	}
+	if "" == token.AccessToken {
+		token.AccessToken = tr.AccessToken
+	}
+	if "" == token.AccessToken {
+		return nil, errors.New("registry token server returned no token")
+	}
+
+	issuedAt := tr.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+
+	expiresIn := time.Duration(tr.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = defaultTokenExpiry
+	}
+	token.Expiry = issuedAt.Add(expiresIn)
+
+	return token, nil
+}
@@ -0,0 +1,31 @@
+package voucher
+
+// BatchRequest describes the Voucher API batch request structure.
+type BatchRequest struct {
+	ImageURLs []string `json:"image_urls"`
+}
+
+// BatchResponse describes the response from a batch Check call, with a
+// Response for each image. It is only successful if every image passed.
+type BatchResponse struct {
+	Success   bool       `json:"success"`
+	Responses []Response `json:"responses"`
+}
+
+// NewBatchResponse creates a new BatchResponse with the passed Responses,
+// which is successful if all of them are successful.
+func NewBatchResponse(responses []Response) BatchResponse {
+	batchResponse := BatchResponse{
+		Success:   true,
+		Responses: responses,
+	}
+
+	for _, response := range responses {
+		if !response.Success {
+			batchResponse.Success = false
+			break
+		}
+	}
+
+	return batchResponse
+}
@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by a Cache when it has no value for a key, or the
+// value has expired.
+var ErrMiss = errors.New("cache miss")
+
+// Cache stores values for a limited time.
+type Cache interface {
+	// Get returns the value stored for the key, or ErrMiss if there is none
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value for the key, until the TTL has passed
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the value stored for the key, if there is one
+	Delete(ctx context.Context, key string) error
+	Close() error
+}
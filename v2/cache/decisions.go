@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// Decisions caches the results of checks, so checks that have recently been
+// run against an image don't need to be run again. Results are keyed by the
+// image reference (including its digest), the check name and a hash of the
+// configuration they were produced with.
+type Decisions struct {
+	cache      Cache
+	configHash string
+	ttl        time.Duration
+	failureTTL time.Duration
+	checkTTLs  map[string]time.Duration
+}
+
+// NewDecisions creates a Decisions which stores results in the passed Cache.
+// Passing results are kept for ttl, or the check's TTL in checkTTLs if it has
+// one, and failing results for failureTTL. A TTL of zero disables caching.
+func NewDecisions(cache Cache, configHash string, ttl, failureTTL time.Duration, checkTTLs map[string]time.Duration) *Decisions {
+	return &Decisions{
+		cache:      cache,
+		configHash: configHash,
+		ttl:        ttl,
+		failureTTL: failureTTL,
+		checkTTLs:  checkTTLs,
+	}
+}
+
+// Get returns the cached result of the named check for the passed image, and
+// true if there was one. The returned result has Cached set. Errors reading
+// the cache are logged, and treated as misses.
+func (d *Decisions) Get(ctx context.Context, imageData voucher.ImageData, check string) (voucher.CheckResult, bool) {
+	var result voucher.CheckResult
+
+	value, err := d.cache.Get(ctx, d.key(imageData, check))
+	if nil != err {
+		if !errors.Is(err, ErrMiss) {
+			log.Warningf("failed to read cached result of %s for %s: %s", check, imageData, err)
+		}
+		return result, false
+	}
+
+	if err = json.Unmarshal(value, &result); nil != err {
+		log.Warningf("failed to decode cached result of %s for %s: %s", check, imageData, err)
+		return result, false
+	}
+
+	result.ImageData = imageData
+	result.Cached = true
+	return result, true
+}
+
+// Put caches the passed result, if its TTL isn't zero. Failing results use the
+// failure TTL, and passing results whose attestations couldn't be created are
+// not cached. Results that aren't cached remove any earlier result, so a
+// stale result never outlives a newer one. Errors writing the cache are
+// logged.
+func (d *Decisions) Put(ctx context.Context, result voucher.CheckResult) {
+	key := d.key(result.ImageData, result.Name)
+
+	ttl := d.resultTTL(result)
+	if ttl <= 0 {
+		if err := d.cache.Delete(ctx, key); nil != err {
+			log.Warningf("failed to remove cached result of %s for %s: %s", result.Name, result.ImageData, err)
+		}
+		return
+	}
+
+	result.Cached = false
+	value, err := json.Marshal(result)
+	if nil != err {
+		log.Warningf("failed to encode result of %s for %s: %s", result.Name, result.ImageData, err)
+		return
+	}
+
+	if err = d.cache.Set(ctx, key, value, ttl); nil != err {
+		log.Warningf("failed to cache result of %s for %s: %s", result.Name, result.ImageData, err)
+	}
+}
+
+// Close closes the underlying Cache.
+func (d *Decisions) Close() error {
+	return d.cache.Close()
+}
+
+// resultTTL returns how long the passed result should be cached for.
+func (d *Decisions) resultTTL(result voucher.CheckResult) time.Duration {
+	if !result.Success {
+		return d.failureTTL
+	}
+
+	// the check passed, but its attestation couldn't be created.
+	if "" != result.Err {
+		return 0
+	}
+
+	if ttl, ok := d.checkTTLs[result.Name]; ok {
+		return ttl
+	}
+	return d.ttl
+}
+
+// key returns the cache key of the named check's result for the passed image.
+func (d *Decisions) key(imageData voucher.ImageData, check string) string {
+	return "voucher:" + d.configHash + ":" + check + ":" + imageData.String()
+}
@@ -0,0 +1,123 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cache"
+	"github.com/grafeas/voucher/v2/cache/memory"
+)
+
+// ttlCache is a cache.Cache which records the TTL of each value.
+type ttlCache struct {
+	*memory.Cache
+	ttls map[string]time.Duration
+}
+
+func (c *ttlCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.ttls[key] = ttl
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+// brokenCache is a cache.Cache which can't be reached.
+type brokenCache struct{}
+
+func (brokenCache) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (brokenCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (brokenCache) Delete(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func (brokenCache) Close() error {
+	return nil
+}
+
+func TestDecisions(t *testing.T) {
+	ctx := context.Background()
+
+	imageData, err := voucher.NewImageData("gcr.io/voucher/app@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	otherImage, err := voucher.NewImageData("gcr.io/voucher/other@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	c := &ttlCache{Cache: memory.NewCache(0), ttls: make(map[string]time.Duration)}
+	decisions := cache.NewDecisions(c, "abc123", time.Hour, time.Minute, map[string]time.Duration{
+		"slsa":   10 * time.Minute,
+		"nobody": 0,
+	})
+
+	for _, result := range []voucher.CheckResult{
+		{Name: "diy", Success: true, Attested: true, Details: map[string]interface{}{"id": "1"}},
+		{Name: "slsa", Success: true, Attested: true},
+		{Name: "snakeoil", Success: false},
+		{Name: "provenance", Success: false, Err: "no occurrences returned for image"},
+		{Name: "cosign", Success: true, Err: "failed to sign attestation"},
+		{Name: "nobody", Success: true, Attested: true},
+	} {
+		result.ImageData = imageData
+		decisions.Put(ctx, result)
+	}
+
+	assert.Equal(t, map[string]time.Duration{
+		"voucher:abc123:diy:" + imageData.String():        time.Hour,
+		"voucher:abc123:slsa:" + imageData.String():       10 * time.Minute,
+		"voucher:abc123:snakeoil:" + imageData.String():   time.Minute,
+		"voucher:abc123:provenance:" + imageData.String(): time.Minute,
+	}, c.ttls)
+
+	result, ok := decisions.Get(ctx, imageData, "diy")
+	require.True(t, ok)
+	assert.Equal(t, voucher.CheckResult{
+		ImageData: imageData,
+		Name:      "diy",
+		Success:   true,
+		Attested:  true,
+		Details:   map[string]interface{}{"id": "1"},
+		Cached:    true,
+	}, result)
+
+	result, ok = decisions.Get(ctx, imageData, "snakeoil")
+	require.True(t, ok)
+	assert.False(t, result.Success)
+
+	result, ok = decisions.Get(ctx, imageData, "provenance")
+	require.True(t, ok)
+	assert.Equal(t, "no occurrences returned for image", result.Err)
+
+	for _, check := range []string{"cosign", "nobody"} {
+		_, ok = decisions.Get(ctx, imageData, check)
+		assert.False(t, ok, check)
+	}
+
+	// results that aren't cached replace earlier results.
+	decisions.Put(ctx, voucher.CheckResult{Name: "diy", ImageData: imageData, Success: true, Err: "failed to sign attestation"})
+	_, ok = decisions.Get(ctx, imageData, "diy")
+	assert.False(t, ok)
+
+	// results are specific to the image, not just the digest.
+	_, ok = decisions.Get(ctx, otherImage, "slsa")
+	assert.False(t, ok)
+
+	// results are specific to the configuration.
+	_, ok = cache.NewDecisions(c, "def456", time.Hour, time.Minute, nil).Get(ctx, imageData, "slsa")
+	assert.False(t, ok)
+
+	// errors reaching the cache are misses.
+	broken := cache.NewDecisions(brokenCache{}, "abc123", time.Hour, time.Minute, nil)
+	broken.Put(ctx, voucher.CheckResult{Name: "diy", ImageData: imageData, Success: true})
+	_, ok = broken.Get(ctx, imageData, "diy")
+	assert.False(t, ok)
+}
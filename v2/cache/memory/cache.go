@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafeas/voucher/v2/cache"
+)
+
+// DefaultSize is the number of entries a Cache holds if it's created with a
+// size of zero.
+const DefaultSize = 10000
+
+// entry is a value in the Cache.
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Cache is an in-memory cache.Cache, which evicts the least recently used
+// entry when it's full.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+var _ cache.Cache = (*Cache)(nil)
+
+// NewCache creates a Cache which holds up to size entries.
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	return &Cache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored for the key, or cache.ErrMiss if there is none
+// or it has expired.
+func (c *Cache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, cache.ErrMiss
+	}
+
+	e := element.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(element)
+		return nil, cache.ErrMiss
+	}
+
+	c.order.MoveToFront(element)
+	return e.value, nil
+}
+
+// Set stores the value for the key until the TTL has passed, evicting the
+// least recently used entry if the Cache is full.
+func (c *Cache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(ttl)
+
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the value stored for the key, if there is one.
+func (c *Cache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the Cache, including any that have
+// expired but haven't been evicted.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Close does nothing, as the Cache has no resources to release.
+func (c *Cache) Close() error {
+	return nil
+}
+
+// remove removes the passed element from the Cache. The caller must hold the
+// Cache's lock.
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/cache"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	c := NewCache(2)
+	c.now = func() time.Time { return now }
+
+	_, err := c.Get(ctx, "a")
+	assert.Equal(t, cache.ErrMiss, err)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Second))
+
+	value, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	// "b" is now the least recently used entry, so adding "c" evicts it.
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.Equal(t, 2, c.Len())
+
+	_, err = c.Get(ctx, "b")
+	assert.Equal(t, cache.ErrMiss, err)
+
+	// replacing an entry updates its value and expiry.
+	require.NoError(t, c.Set(ctx, "c", []byte("4"), 2*time.Minute))
+	value, err = c.Get(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("4"), value)
+	assert.Equal(t, 2, c.Len())
+
+	now = now.Add(time.Minute)
+
+	_, err = c.Get(ctx, "a")
+	assert.Equal(t, cache.ErrMiss, err)
+	assert.Equal(t, 1, c.Len())
+
+	value, err = c.Get(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("4"), value)
+
+	require.NoError(t, c.Delete(ctx, "c"))
+	require.NoError(t, c.Delete(ctx, "c"))
+	_, err = c.Get(ctx, "c")
+	assert.Equal(t, cache.ErrMiss, err)
+	assert.Equal(t, 0, c.Len())
+
+	assert.NoError(t, c.Close())
+}
+
+func TestDefaultSize(t *testing.T) {
+	assert.Equal(t, DefaultSize, NewCache(0).size)
+}
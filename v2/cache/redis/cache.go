@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/grafeas/voucher/v2/cache"
+)
+
+const (
+	// DefaultPoolSize is the number of connections a Cache keeps if its
+	// Config doesn't set a pool size.
+	DefaultPoolSize = 4
+
+	// DefaultTimeout is how long a Cache waits for a command if its Config
+	// doesn't set a timeout.
+	DefaultTimeout = time.Second
+)
+
+// Config is the configuration of a Cache.
+type Config struct {
+	// Address is the host and port of the server, such as "localhost:6379"
+	Address string
+	// Username and Password are sent with AUTH, if Password is set
+	Username string
+	Password string
+	// DB is the database to SELECT
+	DB int
+	// TLS, if not nil, is used to connect to the server over TLS
+	TLS *tls.Config
+	// PoolSize is the number of connections kept open
+	PoolSize int
+	// Timeout is how long to wait for each command
+	Timeout time.Duration
+}
+
+// Cache is a cache.Cache which stores values in Redis, or a server which
+// speaks the Redis protocol such as Valkey or Memorystore.
+type Cache struct {
+	client *redis.Client
+}
+
+var _ cache.Cache = (*Cache)(nil)
+
+// NewCache creates a Cache which connects to the server in the passed Config,
+// and checks that the server can be reached.
+func NewCache(config Config) (*Cache, error) {
+	if "" == config.Address {
+		return nil, errors.New("redis address is not set")
+	}
+
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultPoolSize
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	c := &Cache{
+		client: redis.NewClient(&redis.Options{
+			Addr:         config.Address,
+			Username:     config.Username,
+			Password:     config.Password,
+			DB:           config.DB,
+			TLSConfig:    config.TLS,
+			PoolSize:     config.PoolSize,
+			DialTimeout:  config.Timeout,
+			ReadTimeout:  config.Timeout,
+			WriteTimeout: config.Timeout,
+		}),
+	}
+
+	if err := c.client.Ping(context.Background()).Err(); nil != err {
+		c.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return c, nil
+}
+
+// Get returns the value stored for the key, or cache.ErrMiss if there is none.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, cache.ErrMiss
+	}
+	return value, err
+}
+
+// Set stores the value for the key, which Redis expires after the TTL.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// a TTL of 0 would keep the value forever.
+	if ttl < time.Millisecond {
+		ttl = time.Millisecond
+	}
+
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes the value stored for the key, if there is one.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
+// Close closes the Cache's connections.
+func (c *Cache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/cache"
+)
+
+func newServer(t *testing.T, password string) *miniredis.Miniredis {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	if "" != password {
+		server.RequireAuth(password)
+	}
+	return server
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t, "secret")
+
+	c, err := NewCache(Config{Address: server.Addr(), Password: "secret", DB: 2})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Get(ctx, "missing")
+	assert.Equal(t, cache.ErrMiss, err)
+
+	value := []byte("{\"name\":\"diy\",\r\n\"success\":true}")
+	require.NoError(t, c.Set(ctx, "voucher:diy", value, 90*time.Second))
+
+	cached, err := c.Get(ctx, "voucher:diy")
+	require.NoError(t, err)
+	assert.Equal(t, value, cached)
+
+	assert.Equal(t, 90*time.Second, server.DB(2).TTL("voucher:diy"))
+
+	// connections are opened again after the server restarts.
+	server.Close()
+	require.NoError(t, server.Restart())
+
+	cached, err = c.Get(ctx, "voucher:diy")
+	require.NoError(t, err)
+	assert.Equal(t, value, cached)
+
+	require.NoError(t, c.Delete(ctx, "voucher:diy"))
+	require.NoError(t, c.Delete(ctx, "voucher:diy"))
+	_, err = c.Get(ctx, "voucher:diy")
+	assert.Equal(t, cache.ErrMiss, err)
+
+	// values expire after their TTL.
+	require.NoError(t, c.Set(ctx, "voucher:diy", value, 90*time.Second))
+	server.FastForward(91 * time.Second)
+	_, err = c.Get(ctx, "voucher:diy")
+	assert.Equal(t, cache.ErrMiss, err)
+}
+
+func TestNewCacheErrors(t *testing.T) {
+	server := newServer(t, "secret")
+
+	_, err := NewCache(Config{})
+	assert.EqualError(t, err, "redis address is not set")
+
+	_, err = NewCache(Config{Address: server.Addr(), Password: "wrong"})
+	assert.ErrorContains(t, err, "failed to connect to redis: WRONGPASS")
+
+	_, err = NewCache(Config{Address: server.Addr()})
+	assert.ErrorContains(t, err, "failed to connect to redis: NOAUTH")
+}
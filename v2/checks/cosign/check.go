@@ -0,0 +1,55 @@
+package cosign
+
+import (
+	"context"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/sigstore"
+)
+
+// check verifies that the passed image has been signed with cosign, by a
+// trusted key or a trusted keyless identity.
+type check struct {
+	auth     voucher.Auth
+	verifier *sigstore.Verifier
+}
+
+// SetAuth sets the authentication system that this check will use
+// for its run.
+func (c *check) SetAuth(auth voucher.Auth) {
+	c.auth = auth
+}
+
+// Check fetches the image's cosign signatures from its registry and returns
+// true if at least one of them is trusted.
+func (c *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
+	if nil == c.auth {
+		return false, voucher.ErrNoAuth
+	}
+
+	client, err := c.auth.ToClient(ctx, i)
+	if nil != err {
+		return false, err
+	}
+
+	signatures, err := sigstore.RequestSignatures(client, i)
+	if nil != err {
+		return false, err
+	}
+
+	if _, err = c.verifier.VerifyImage(signatures, i.Digest()); nil != err {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// NewCheckFactory creates a new CheckFactory for a cosign check which uses
+// the passed Verifier.
+func NewCheckFactory(verifier *sigstore.Verifier) voucher.CheckFactory {
+	return func() voucher.Check {
+		return &check{
+			verifier: verifier,
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/sigstore"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+func TestCosignCheck(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	server := fixture.NewRegistry(i, fixture.SignWithKey(sigstoretest.SimpleSigningPayload(t, i), ""))
+
+	cosignCheck := NewCheckFactory(sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false))()
+	cosignCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(server))
+
+	pass, err := cosignCheck.Check(context.Background(), i)
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+}
+
+func TestCosignCheckKeyless(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	server := fixture.NewRegistry(i, fixture.SignKeyless(sigstoretest.SimpleSigningPayload(t, i), "", "release@example.com", "https://accounts.google.com"))
+
+	identities := []sigstore.Identity{{Issuer: "https://accounts.google.com", Subject: "release@example.com"}}
+
+	cosignCheck := NewCheckFactory(sigstore.NewVerifier(nil, fixture.TrustedRoot(), identities, false))()
+	cosignCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(server))
+
+	pass, err := cosignCheck.Check(context.Background(), i)
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+}
+
+func TestCosignCheckUntrustedSignature(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	other := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	server := fixture.NewRegistry(i, other.SignWithKey(sigstoretest.SimpleSigningPayload(t, i), ""))
+
+	cosignCheck := NewCheckFactory(sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false))()
+	cosignCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(server))
+
+	pass, err := cosignCheck.Check(context.Background(), i)
+	assert.EqualError(t, err, "no trusted signatures: "+sigstore.ErrUntrustedKey.Error())
+	assert.False(t, pass, "check passed when it should have failed")
+}
+
+func TestCosignCheckUnsigned(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	cosignCheck := NewCheckFactory(sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false))()
+	cosignCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(fixture.NewRegistry(i)))
+
+	pass, err := cosignCheck.Check(context.Background(), i)
+	assert.Equal(t, sigstore.ErrNoSignatures, err)
+	assert.False(t, pass, "check passed when it should have failed")
+}
+
+func TestCosignCheckWithNoAuth(t *testing.T) {
+	cosignCheck := NewCheckFactory(sigstore.NewVerifier(nil, nil, nil, false))()
+
+	pass, err := cosignCheck.Check(context.Background(), vtesting.NewTestReference(t))
+	assert.Equal(t, voucher.ErrNoAuth, err)
+	assert.False(t, pass, "check passed when it should have failed")
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/docker/distribution/reference"
+
 	voucher "github.com/grafeas/voucher/v2"
 	"github.com/grafeas/voucher/v2/docker"
 )
@@ -18,6 +20,7 @@ var ErrNotFromRepo = errors.New("image is not from a valid repo")
 type check struct {
 	auth       voucher.Auth
 	validRepos []string
+	platforms  []string
 }
 
 // SetValidRepos sets the repos that images must be in to get signed by the
@@ -26,6 +29,12 @@ func (d *check) SetValidRepos(repos []string) {
 	d.validRepos = repos
 }
 
+// SetPlatforms sets the platforms of a multi-platform image that must
+// have a configuration. If no platforms are set, all of them are checked.
+func (d *check) SetPlatforms(platforms []string) {
+	d.platforms = platforms
+}
+
 // SetAuth sets the authentication system that this check will use
 // for its run.
 func (d *check) SetAuth(auth voucher.Auth) {
@@ -42,27 +51,59 @@ func (d *check) isFromValidRepo(i voucher.ImageData) bool {
 	return false
 }
 
-// check checks if an image was built by a trusted source
+// Check checks if an image was built by a trusted source
 func (d *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
+	ok, _, err := d.CheckWithDetails(ctx, i)
+	return ok, err
+}
+
+// CheckWithDetails checks if an image was built by a trusted source, like
+// Check. If the image is a manifest list or OCI index, each of the selected
+// platform images must have a configuration, and their results are returned
+// as the details.
+func (d *check) CheckWithDetails(ctx context.Context, i voucher.ImageData) (bool, interface{}, error) {
 	if !d.isFromValidRepo(i) {
-		return false, ErrNotFromRepo
+		return false, nil, ErrNotFromRepo
 	}
 
 	if nil == d.auth {
-		return false, voucher.ErrNoAuth
+		return false, nil, voucher.ErrNoAuth
+	}
+
+	platforms, err := docker.ParsePlatforms(d.platforms)
+	if nil != err {
+		return false, nil, err
 	}
 
 	client, err := d.auth.ToClient(ctx, i)
 	if nil != err {
-		return false, err
+		return false, nil, err
 	}
 
-	_, err = docker.RequestImageConfig(client, i)
+	images, err := docker.RequestPlatformImages(client, i)
 	if nil != err {
-		return false, err
+		return false, nil, err
+	}
+
+	images, err = docker.SelectPlatforms(images, platforms)
+	if nil != err {
+		return false, nil, err
+	}
+
+	ok, results, err := docker.CheckPlatforms(images, func(ref reference.Canonical) (bool, error) {
+		_, err := docker.RequestImageConfig(client, ref)
+		if nil != err {
+			return false, err
+		}
+
+		return true, nil
+	})
+
+	if nil == results {
+		return ok, nil, err
 	}
 
-	return true, nil
+	return ok, results, err
 }
 
 func init() {
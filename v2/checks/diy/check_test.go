@@ -74,3 +74,26 @@ func TestFailingDIYCheck(t *testing.T) {
 	assert.Containsf(t, err.Error(), "image doesn't exist", "check error format is incorrect, should be \"image doesn't exist\": \"%s\"", err)
 	assert.False(t, pass, "check passed when it should have failed")
 }
+
+func TestDIYCheckManifestList(t *testing.T) {
+	server := vtesting.NewTestDockerServer(t)
+
+	i := vtesting.NewTestManifestListReference(t)
+
+	diyCheck := new(check)
+	diyCheck.SetAuth(vtesting.NewAuth(server))
+	diyCheck.SetValidRepos([]string{
+		i.Name(),
+	})
+
+	pass, details, err := diyCheck.CheckWithDetails(context.Background(), i)
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+	assert.Len(t, details, 3)
+
+	diyCheck.SetPlatforms([]string{"linux/s390x"})
+
+	pass, err = diyCheck.Check(context.Background(), i)
+	assert.EqualError(t, err, "image does not support platform linux/s390x")
+	assert.False(t, pass, "check passed when it should have failed")
+}
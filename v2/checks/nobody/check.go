@@ -3,6 +3,8 @@ package nobody
 import (
 	"context"
 
+	"github.com/docker/distribution/reference"
+
 	voucher "github.com/grafeas/voucher/v2"
 	"github.com/grafeas/voucher/v2/docker"
 )
@@ -10,7 +12,8 @@ import (
 // check is for verifying that the passed image does not run as
 // root or user 0.
 type check struct {
-	auth voucher.Auth
+	auth      voucher.Auth
+	platforms []string
 }
 
 // SetAuth sets the authentication system that this check will use
@@ -19,25 +22,61 @@ func (n *check) SetAuth(auth voucher.Auth) {
 	n.auth = auth
 }
 
+// SetPlatforms sets the platforms of a multi-platform image that must
+// not run as root. If no platforms are set, all of them are checked.
+func (n *check) SetPlatforms(platforms []string) {
+	n.platforms = platforms
+}
+
 // Check verifies if the image runs as root and returns a boolean (true if
 // the user is not root, false otherwise) and an error as response.
 func (n *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
+	ok, _, err := n.CheckWithDetails(ctx, i)
+	return ok, err
+}
+
+// CheckWithDetails verifies if the image runs as root, like Check. If the
+// image is a manifest list or OCI index, each of the selected platform
+// images is checked, and their results are returned as the details.
+func (n *check) CheckWithDetails(ctx context.Context, i voucher.ImageData) (bool, interface{}, error) {
 	if nil == n.auth {
-		return false, voucher.ErrNoAuth
+		return false, nil, voucher.ErrNoAuth
+	}
+
+	platforms, err := docker.ParsePlatforms(n.platforms)
+	if nil != err {
+		return false, nil, err
 	}
 
 	client, err := n.auth.ToClient(ctx, i)
 	if nil != err {
-		return false, err
+		return false, nil, err
 	}
 
-	imageConfig, err := docker.RequestImageConfig(client, i)
+	images, err := docker.RequestPlatformImages(client, i)
+	if nil != err {
+		return false, nil, err
+	}
 
+	images, err = docker.SelectPlatforms(images, platforms)
 	if nil != err {
-		return false, err
+		return false, nil, err
+	}
+
+	ok, results, err := docker.CheckPlatforms(images, func(ref reference.Canonical) (bool, error) {
+		imageConfig, err := docker.RequestImageConfig(client, ref)
+		if nil != err {
+			return false, err
+		}
+
+		return !imageConfig.RunsAsRoot(), nil
+	})
+
+	if nil == results {
+		return ok, nil, err
 	}
 
-	return !imageConfig.RunsAsRoot(), nil
+	return ok, results, err
 }
 
 func init() {
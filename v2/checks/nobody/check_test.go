@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/docker"
 	vtesting "github.com/grafeas/voucher/v2/testing"
 )
 
@@ -76,3 +77,48 @@ func TestFailingNobodyCheck(t *testing.T) {
 	require.NoError(t, err, "check should have failed with error, but didn't")
 	assert.False(t, pass, "check passed when it should have failed")
 }
+
+func TestNobodyCheckManifestList(t *testing.T) {
+	server := vtesting.NewTestDockerServer(t)
+
+	i := vtesting.NewTestManifestListReference(t)
+
+	nobodyCheck := new(check)
+	nobodyCheck.SetAuth(vtesting.NewAuth(server))
+
+	// every platform must pass, and the linux/arm64 image runs as root.
+	pass, details, err := nobodyCheck.CheckWithDetails(context.Background(), i)
+	require.NoError(t, err)
+	assert.False(t, pass, "check passed when it should have failed")
+
+	results, ok := details.([]docker.PlatformResult)
+	require.True(t, ok, "check details should be platform results")
+	require.Len(t, results, 3)
+	assert.Equal(t, docker.PlatformResult{
+		Platform: "linux/arm64",
+		Digest:   "sha256:b248c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da",
+	}, results[0])
+	assert.True(t, results[1].Success)
+	assert.True(t, results[2].Success)
+
+	// only the listed platforms must pass.
+	nobodyCheck.SetPlatforms([]string{"linux/amd64", "windows/amd64"})
+
+	pass, details, err = nobodyCheck.CheckWithDetails(context.Background(), i)
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+	assert.Len(t, details, 2)
+}
+
+func TestNobodyCheckSingleImageHasNoDetails(t *testing.T) {
+	server := vtesting.NewTestDockerServer(t)
+
+	nobodyCheck := new(check)
+	nobodyCheck.SetAuth(vtesting.NewAuth(server))
+	nobodyCheck.SetPlatforms([]string{"linux/arm64"})
+
+	pass, details, err := nobodyCheck.CheckWithDetails(context.Background(), vtesting.NewTestReference(t))
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+	assert.Nil(t, details)
+}
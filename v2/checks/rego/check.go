@@ -0,0 +1,127 @@
+package rego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/open-policy-agent/opa/rego"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// ErrNoScanner is the error returned when a module refers to vulnerabilities
+// but no scanner has been configured.
+var ErrNoScanner = errors.New("no scanner configured for rego check")
+
+// ErrNoMetadataClient is the error returned when a module refers to the build
+// or commit but no MetadataClient has been configured.
+var ErrNoMetadataClient = errors.New("no metadata client configured for rego check")
+
+// ErrNoRepositoryClient is the error returned when a module refers to the
+// commit but no repository client has been configured.
+var ErrNoRepositoryClient = errors.New("no repository client configured for rego check")
+
+// ErrUndefinedResult is the error returned when a module's package is not
+// defined after evaluation.
+var ErrUndefinedResult = errors.New("rego module did not produce a result")
+
+// check evaluates a Rego module against an input document describing the
+// image.
+type check struct {
+	module           *Module
+	auth             voucher.Auth
+	scanner          voucher.VulnerabilityScanner
+	metadataClient   voucher.MetadataClient
+	repositoryClient repository.Client
+}
+
+// SetAuth sets the authentication system that this check will use
+// for its run.
+func (c *check) SetAuth(auth voucher.Auth) {
+	c.auth = auth
+}
+
+// SetScanner sets the scanner that this check will use for its run.
+func (c *check) SetScanner(scanner voucher.VulnerabilityScanner) {
+	c.scanner = scanner
+}
+
+// SetMetadataClient sets the MetadataClient for this Check.
+func (c *check) SetMetadataClient(metadataClient voucher.MetadataClient) {
+	c.metadataClient = metadataClient
+}
+
+// SetRepositoryClient sets the repository.Client for this Check.
+func (c *check) SetRepositoryClient(repositoryClient repository.Client) {
+	c.repositoryClient = repositoryClient
+}
+
+// Check evaluates the module against the image. The image passes if the
+// module's `allow` rule is true and its `deny` set is empty. Any `deny`
+// messages are returned in the error.
+func (c *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
+	input, err := c.newInput(ctx, i)
+	if nil != err {
+		return false, err
+	}
+
+	results, err := c.module.query.Eval(ctx, rego.EvalInput(input))
+	if nil != err {
+		return false, err
+	}
+
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return false, ErrUndefinedResult
+	}
+
+	document, ok := results[0].Expressions[0].Value.(map[string]interface{})
+	if !ok {
+		return false, ErrUndefinedResult
+	}
+
+	if denied := denyMessages(document["deny"]); len(denied) > 0 {
+		return false, fmt.Errorf("denied by %s: %s", c.module.Name, strings.Join(denied, ", "))
+	}
+
+	allowed, _ := document["allow"].(bool)
+	return allowed, nil
+}
+
+// denyMessages converts the value of a module's `deny` rule to a list of
+// messages.
+func denyMessages(value interface{}) []string {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	messages := make([]string, 0, len(values))
+	for _, v := range values {
+		messages = append(messages, fmt.Sprint(v))
+	}
+	return messages
+}
+
+// domain returns the registry domain of the passed image.
+func domain(named reference.Named) string {
+	return reference.Domain(named)
+}
+
+// path returns the path of the passed image within its registry.
+func path(named reference.Named) string {
+	return reference.Path(named)
+}
+
+// NewCheckFactory returns a voucher.CheckFactory that creates Checks which
+// evaluate the passed Module.
+func NewCheckFactory(module *Module) voucher.CheckFactory {
+	return func() voucher.Check {
+		return &check{
+			module: module,
+		}
+	}
+}
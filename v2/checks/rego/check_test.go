@@ -0,0 +1,205 @@
+package rego
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+const nobodyModule = `package voucher.nobody
+
+default allow = false
+
+allow {
+	input.config.User == "nobody"
+}
+`
+
+const vulnerabilitiesModule = `package voucher.vulnerabilities
+
+allow = true
+
+deny[msg] {
+	vuln := input.vulnerabilities[_]
+	vuln.severity == "critical"
+	msg := sprintf("%s is critical", [vuln.name])
+}
+`
+
+const signedModule = `package voucher.signed
+
+default allow = false
+
+allow {
+	input.build.repository == "https://github.com/grafeas/voucher"
+	input.commit.is_signed
+	input.commit.pull_requests[_].is_merged
+}
+`
+
+const imageModule = `package voucher.image
+
+default allow = false
+
+allow {
+	input.image.domain == "localhost"
+}
+`
+
+func newCheck(t *testing.T, source string) *check {
+	t.Helper()
+
+	module, err := NewModule(context.Background(), "test", "test.rego", source)
+	require.NoError(t, err)
+
+	c, ok := NewCheckFactory(module)().(*check)
+	require.True(t, ok)
+	return c
+}
+
+func TestLoadModules(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nobody.rego"), []byte(nobodyModule), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "signed.rego"), []byte(signedModule), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a module"), 0600))
+
+	modules, err := LoadModules(context.Background(), dir)
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+
+	assert.Equal(t, "nobody", modules[0].Name)
+	assert.True(t, modules[0].needs(inputConfig))
+	assert.False(t, modules[0].needs(inputBuild))
+
+	assert.Equal(t, "signed", modules[1].Name)
+	assert.True(t, modules[1].needs(inputBuild))
+	assert.True(t, modules[1].needs(inputCommit))
+	assert.False(t, modules[1].needs(inputConfig))
+}
+
+func TestLoadModulesWithInvalidModule(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.rego"), []byte("package broken\n\nallow {"), 0600))
+
+	_, err := LoadModules(context.Background(), dir)
+	assert.Error(t, err)
+}
+
+func TestRegoCheckWithImage(t *testing.T) {
+	c := newCheck(t, imageModule)
+
+	pass, err := c.Check(context.Background(), vtesting.NewTestReference(t))
+	require.NoError(t, err)
+	assert.True(t, pass)
+}
+
+func TestRegoCheckWithImageConfig(t *testing.T) {
+	server := vtesting.NewTestDockerServer(t)
+
+	c := newCheck(t, nobodyModule)
+	c.SetAuth(vtesting.NewAuth(server))
+
+	pass, err := c.Check(context.Background(), vtesting.NewTestReference(t))
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+
+	pass, err = c.Check(context.Background(), vtesting.NewNobodyBadTestReference(t))
+	require.NoError(t, err)
+	assert.False(t, pass, "check passed when it should have failed")
+}
+
+func TestRegoCheckWithNoAuth(t *testing.T) {
+	c := newCheck(t, nobodyModule)
+
+	pass, err := c.Check(context.Background(), vtesting.NewTestReference(t))
+	assert.Equal(t, voucher.ErrNoAuth, err)
+	assert.False(t, pass)
+}
+
+func TestRegoCheckWithVulnerabilities(t *testing.T) {
+	c := newCheck(t, vulnerabilitiesModule)
+	c.SetScanner(vtesting.NewScanner(t,
+		voucher.Vulnerability{
+			Name:     "cve-the-worst",
+			Severity: voucher.CriticalSeverity,
+		},
+		voucher.Vulnerability{
+			Name:     "cve-this-is-fine",
+			Severity: voucher.NegligibleSeverity,
+		},
+	))
+
+	pass, err := c.Check(context.Background(), vtesting.NewTestReference(t))
+	require.Error(t, err)
+	assert.Equal(t, "denied by test: cve-the-worst is critical", err.Error())
+	assert.False(t, pass)
+}
+
+func TestRegoCheckWithNoScanner(t *testing.T) {
+	c := newCheck(t, vulnerabilitiesModule)
+
+	pass, err := c.Check(context.Background(), vtesting.NewTestReference(t))
+	assert.Equal(t, ErrNoScanner, err)
+	assert.False(t, pass)
+}
+
+func TestRegoCheckWithCommit(t *testing.T) {
+	ctx := context.Background()
+	i := vtesting.NewTestReference(t)
+	buildDetail := repository.BuildDetail{
+		RepositoryURL: "https://github.com/grafeas/voucher",
+		Commit:        "7e3f4e5ea8b4f4d0ba2e9a2b6bcbf6e3c1e0a1b2",
+	}
+
+	metadataClient := new(voucher.MockMetadataClient)
+	metadataClient.On("GetBuildDetail", ctx, i).Return(buildDetail, nil)
+
+	repositoryClient := new(repository.MockClient)
+	repositoryClient.On("GetCommit", ctx, buildDetail).Return(repository.Commit{
+		IsSigned: true,
+		AssociatedPullRequests: []repository.PullRequest{
+			repository.NewPullRequest("main", "feature", true, repository.NewCommitRef("https://github.com/grafeas/voucher/commit/7e3f4e5"), true),
+		},
+	}, nil)
+
+	c := newCheck(t, signedModule)
+	c.SetMetadataClient(metadataClient)
+	c.SetRepositoryClient(repositoryClient)
+
+	pass, err := c.Check(ctx, i)
+	require.NoError(t, err)
+	assert.True(t, pass)
+
+	metadataClient.AssertExpectations(t)
+	repositoryClient.AssertExpectations(t)
+}
+
+func TestRegoCheckWithNoBuildDetail(t *testing.T) {
+	ctx := context.Background()
+	i := vtesting.NewTestReference(t)
+
+	metadataClient := new(voucher.MockMetadataClient)
+	metadataClient.On("GetBuildDetail", ctx, i).Return(repository.BuildDetail{}, &voucher.NoMetadataError{Type: voucher.BuildDetailsType, Err: errors.New("no build")})
+
+	repositoryClient := new(repository.MockClient)
+
+	c := newCheck(t, signedModule)
+	c.SetMetadataClient(metadataClient)
+	c.SetRepositoryClient(repositoryClient)
+
+	pass, err := c.Check(ctx, i)
+	require.NoError(t, err)
+	assert.False(t, pass)
+
+	repositoryClient.AssertNotCalled(t, "GetCommit", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,227 @@
+package rego
+
+import (
+	"context"
+	"encoding/json"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/docker"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// Keys in the input document passed to Rego modules.
+const (
+	inputImage           = "image"
+	inputConfig          = "config"
+	inputBuild           = "build"
+	inputVulnerabilities = "vulnerabilities"
+	inputCommit          = "commit"
+)
+
+// image is the representation of the image in the input document.
+type image struct {
+	Reference string `json:"reference"`
+	Name      string `json:"name"`
+	Domain    string `json:"domain"`
+	Path      string `json:"path"`
+	Digest    string `json:"digest"`
+}
+
+// vulnerability is the representation of a voucher.Vulnerability in the input
+// document, with the severity written as a string.
+type vulnerability struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	FixedBy     string `json:"fixed_by"`
+	Package     string `json:"package"`
+}
+
+// commit is the representation of a repository.Commit in the input document.
+type commit struct {
+	URL          string        `json:"url"`
+	Status       string        `json:"status"`
+	IsSigned     bool          `json:"is_signed"`
+	Checks       []commitCheck `json:"checks"`
+	PullRequests []pullRequest `json:"pull_requests"`
+}
+
+// commitCheck is the representation of a repository.Check in the input
+// document.
+type commitCheck struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// pullRequest is the representation of a repository.PullRequest in the input
+// document, with the merge commit written as its URL.
+type pullRequest struct {
+	BaseBranch           string `json:"base_branch"`
+	HeadBranch           string `json:"head_branch"`
+	IsMerged             bool   `json:"is_merged"`
+	MergeCommit          string `json:"merge_commit"`
+	HasRequiredApprovals bool   `json:"has_required_approvals"`
+}
+
+// newCommit returns the representation of the passed Commit in the input
+// document.
+func newCommit(c repository.Commit) commit {
+	checks := make([]commitCheck, 0, len(c.Checks))
+	for _, check := range c.Checks {
+		checks = append(checks, commitCheck{
+			Status:     check.Status,
+			Conclusion: check.Conclusion,
+		})
+	}
+
+	pullRequests := make([]pullRequest, 0, len(c.AssociatedPullRequests))
+	for _, pr := range c.AssociatedPullRequests {
+		pullRequests = append(pullRequests, pullRequest{
+			BaseBranch:           pr.BaseBranchName,
+			HeadBranch:           pr.HeadBranchName,
+			IsMerged:             pr.IsMerged,
+			MergeCommit:          pr.MergeCommit.URL,
+			HasRequiredApprovals: pr.HasRequiredApprovals,
+		})
+	}
+
+	return commit{
+		URL:          c.URL,
+		Status:       c.Status,
+		IsSigned:     c.IsSigned,
+		Checks:       checks,
+		PullRequests: pullRequests,
+	}
+}
+
+// newInput builds the input document for the passed Module, only fetching the
+// data the module refers to.
+func (c *check) newInput(ctx context.Context, i voucher.ImageData) (map[string]interface{}, error) {
+	input := map[string]interface{}{
+		inputImage: image{
+			Reference: i.String(),
+			Name:      i.Name(),
+			Domain:    domain(i),
+			Path:      path(i),
+			Digest:    i.Digest().String(),
+		},
+	}
+
+	if c.module.needs(inputConfig) {
+		config, err := c.getImageConfig(ctx, i)
+		if nil != err {
+			return nil, err
+		}
+		input[inputConfig] = config
+	}
+
+	if c.module.needs(inputVulnerabilities) {
+		vulnerabilities, err := c.getVulnerabilities(ctx, i)
+		if nil != err {
+			return nil, err
+		}
+		input[inputVulnerabilities] = vulnerabilities
+	}
+
+	if c.module.needs(inputBuild) || c.module.needs(inputCommit) {
+		buildDetail, err := c.getBuildDetail(ctx, i)
+		if nil != err {
+			return nil, err
+		}
+
+		// Images without build metadata leave both the build and commit
+		// undefined, so that modules can decide how to treat them.
+		if nil == buildDetail {
+			return input, nil
+		}
+		input[inputBuild] = buildDetail
+
+		if c.module.needs(inputCommit) {
+			repoCommit, err := c.getCommit(ctx, *buildDetail)
+			if nil != err {
+				return nil, err
+			}
+			input[inputCommit] = newCommit(repoCommit)
+		}
+	}
+
+	return input, nil
+}
+
+// getImageConfig returns the image's configuration as a generic JSON object.
+func (c *check) getImageConfig(ctx context.Context, i voucher.ImageData) (interface{}, error) {
+	if nil == c.auth {
+		return nil, voucher.ErrNoAuth
+	}
+
+	client, err := c.auth.ToClient(ctx, i)
+	if nil != err {
+		return nil, err
+	}
+
+	imageConfig, err := docker.RequestImageConfig(client, i)
+	if nil != err {
+		return nil, err
+	}
+
+	b, err := json.Marshal(imageConfig)
+	if nil != err {
+		return nil, err
+	}
+
+	var config interface{}
+	err = json.Unmarshal(b, &config)
+	return config, err
+}
+
+// getVulnerabilities returns the vulnerabilities found in the image.
+func (c *check) getVulnerabilities(ctx context.Context, i voucher.ImageData) ([]vulnerability, error) {
+	if nil == c.scanner {
+		return nil, ErrNoScanner
+	}
+
+	vulns, err := c.scanner.Scan(ctx, i)
+	if nil != err {
+		return nil, err
+	}
+
+	vulnerabilities := make([]vulnerability, 0, len(vulns))
+	for _, vuln := range vulns {
+		vulnerabilities = append(vulnerabilities, vulnerability{
+			Name:        vuln.Name,
+			Description: vuln.Description,
+			Severity:    vuln.Severity.String(),
+			FixedBy:     vuln.FixedBy,
+			Package:     vuln.Package,
+		})
+	}
+
+	return vulnerabilities, nil
+}
+
+// getBuildDetail returns the image's BuildDetail, or nil if the image has no
+// build metadata.
+func (c *check) getBuildDetail(ctx context.Context, i voucher.ImageData) (*repository.BuildDetail, error) {
+	if nil == c.metadataClient {
+		return nil, ErrNoMetadataClient
+	}
+
+	buildDetail, err := c.metadataClient.GetBuildDetail(ctx, i)
+	if nil != err {
+		if voucher.IsNoMetadataError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &buildDetail, nil
+}
+
+// getCommit returns the Commit the image was built from.
+func (c *check) getCommit(ctx context.Context, buildDetail repository.BuildDetail) (repository.Commit, error) {
+	if nil == c.repositoryClient {
+		return repository.Commit{}, ErrNoRepositoryClient
+	}
+
+	return c.repositoryClient.GetCommit(ctx, buildDetail)
+}
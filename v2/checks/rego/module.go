@@ -0,0 +1,106 @@
+package rego
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// moduleExtension is the file extension of Rego modules.
+const moduleExtension = ".rego"
+
+// Module is a compiled Rego module which can be evaluated as a Check.
+type Module struct {
+	Name   string // The name of the Check, taken from the module's filename.
+	inputs map[string]bool
+	query  rego.PreparedEvalQuery
+}
+
+// needs returns true if the module refers to the passed key of the input
+// document.
+func (m *Module) needs(key string) bool {
+	return m.inputs[key] || m.inputs[""]
+}
+
+// NewModule parses and compiles the Rego source code in the passed string,
+// creating a Module for the Check with the passed name. The module's package
+// must define an `allow` rule, and may define a `deny` set of messages.
+func NewModule(ctx context.Context, name, filename, source string) (*Module, error) {
+	parsed, err := ast.ParseModule(filename, source)
+	if nil != err {
+		return nil, err
+	}
+
+	query, err := rego.New(
+		rego.Query(parsed.Package.Path.String()),
+		rego.ParsedModule(parsed),
+	).PrepareForEval(ctx)
+	if nil != err {
+		return nil, err
+	}
+
+	return &Module{
+		Name:   name,
+		inputs: inputKeys(parsed),
+		query:  query,
+	}, nil
+}
+
+// LoadModules loads each of the Rego modules in the passed directory. Each
+// Module is named after its file, without the ".rego" extension.
+func LoadModules(ctx context.Context, dir string) ([]*Module, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+moduleExtension))
+	if nil != err {
+		return nil, err
+	}
+
+	modules := make([]*Module, 0, len(files))
+	for _, filename := range files {
+		source, err := os.ReadFile(filename)
+		if nil != err {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(filename), moduleExtension)
+		module, err := NewModule(ctx, name, filename, string(source))
+		if nil != err {
+			return nil, fmt.Errorf("failed to load rego module %s: %w", filename, err)
+		}
+
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// inputKeys returns the top level keys of the input document that the module
+// refers to. If the module refers to the whole input document, the returned
+// map contains the empty string.
+func inputKeys(module *ast.Module) map[string]bool {
+	keys := make(map[string]bool)
+
+	ast.WalkRefs(module, func(ref ast.Ref) bool {
+		if !ref[0].Equal(ast.InputRootDocument) {
+			return false
+		}
+
+		if len(ref) < 2 {
+			keys[""] = true
+			return false
+		}
+
+		if key, ok := ref[1].Value.(ast.String); ok {
+			keys[string(key)] = true
+		} else {
+			keys[""] = true
+		}
+		return false
+	})
+
+	return keys
+}
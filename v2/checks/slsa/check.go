@@ -0,0 +1,58 @@
+package slsa
+
+import (
+	"context"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/slsa"
+)
+
+// check verifies that the passed image has signed SLSA provenance, which was
+// produced by a trusted builder from a trusted source.
+type check struct {
+	auth     voucher.Auth
+	verifier *slsa.Verifier
+}
+
+// SetAuth sets the authentication system that this check will use
+// for its run.
+func (c *check) SetAuth(auth voucher.Auth) {
+	c.auth = auth
+}
+
+// Check fetches the image's SLSA provenance from its registry and returns
+// true if it is trusted.
+func (c *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
+	ok, _, err := c.CheckWithDetails(ctx, i)
+	return ok, err
+}
+
+// CheckWithDetails fetches the image's SLSA provenance from its registry and
+// returns true if it is trusted, along with the trusted provenance.
+func (c *check) CheckWithDetails(ctx context.Context, i voucher.ImageData) (bool, interface{}, error) {
+	if nil == c.auth {
+		return false, nil, voucher.ErrNoAuth
+	}
+
+	client, err := c.auth.ToClient(ctx, i)
+	if nil != err {
+		return false, nil, err
+	}
+
+	provenance, err := c.verifier.VerifyImage(client, i)
+	if nil != err {
+		return false, nil, err
+	}
+
+	return true, provenance, nil
+}
+
+// NewCheckFactory creates a new CheckFactory for a slsa check which uses
+// the passed Verifier.
+func NewCheckFactory(verifier *slsa.Verifier) voucher.CheckFactory {
+	return func() voucher.Check {
+		return &check{
+			verifier: verifier,
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package slsa
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/sigstore"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+	"github.com/grafeas/voucher/v2/slsa"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+const testBuilderID = "https://github.com/grafeas/voucher/.github/workflows/builder.yml@refs/heads/main"
+
+// newTestProvenance returns SLSA v1 provenance of the passed image, signed
+// with the fixture's key.
+func newTestProvenance(t *testing.T, fixture *sigstoretest.Fixture, i voucher.ImageData) *sigstore.Signature {
+	t.Helper()
+
+	statement, err := attestation.NewStatement(i, slsa.ProvenanceV1PredicateType, json.RawMessage(`{
+		"buildDefinition": {
+			"buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+			"resolvedDependencies": [{"uri": "git+https://github.com/grafeas/voucher@refs/heads/main", "digest": {"gitCommit": "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"}}]
+		},
+		"runDetails": {"builder": {"id": "`+testBuilderID+`"}}
+	}`))
+	require.NoError(t, err)
+
+	payload, err := statement.ToString()
+	require.NoError(t, err)
+
+	return fixture.SignWithKey([]byte(payload), attestation.InTotoPayloadType)
+}
+
+func newTestCheck(fixture *sigstoretest.Fixture, policy slsa.Policy) voucher.Check {
+	signatures := sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false)
+	return NewCheckFactory(slsa.NewVerifier(signatures, policy))()
+}
+
+func TestSLSACheck(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	slsaCheck := newTestCheck(fixture, slsa.Policy{
+		Builders:   []slsa.Builder{{ID: testBuilderID, Level: 3}},
+		SourceURIs: []string{"https://github.com/grafeas/voucher"},
+		SourceRefs: []string{"refs/heads/main"},
+		MinLevel:   3,
+	})
+	slsaCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(fixture.NewRegistry(i, newTestProvenance(t, fixture, i))))
+
+	pass, details, err := slsaCheck.(voucher.DetailedCheck).CheckWithDetails(context.Background(), i)
+	require.NoError(t, err)
+	assert.True(t, pass, "check failed when it should have passed")
+	assert.Equal(t, testBuilderID, details.(slsa.Provenance).BuilderID)
+}
+
+func TestSLSACheckUntrustedSource(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	slsaCheck := newTestCheck(fixture, slsa.Policy{
+		Builders:   []slsa.Builder{{ID: testBuilderID}},
+		SourceURIs: []string{"https://github.com/grafeas/grafeas"},
+	})
+	slsaCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(fixture.NewRegistry(i, newTestProvenance(t, fixture, i))))
+
+	pass, err := slsaCheck.Check(context.Background(), i)
+	assert.EqualError(t, err, "no trusted provenance: "+slsa.ErrUntrustedSource.Error()+": git+https://github.com/grafeas/voucher")
+	assert.False(t, pass, "check passed when it should have failed")
+}
+
+func TestSLSACheckWithoutProvenance(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	slsaCheck := newTestCheck(fixture, slsa.Policy{Builders: []slsa.Builder{{ID: testBuilderID}}})
+	slsaCheck.(voucher.AuthorizedCheck).SetAuth(vtesting.NewAuth(fixture.NewRegistry(i)))
+
+	pass, err := slsaCheck.Check(context.Background(), i)
+	assert.Equal(t, slsa.ErrNoProvenance, err)
+	assert.False(t, pass, "check passed when it should have failed")
+}
+
+func TestSLSACheckWithNoAuth(t *testing.T) {
+	slsaCheck := newTestCheck(sigstoretest.NewFixture(t), slsa.Policy{})
+
+	pass, err := slsaCheck.Check(context.Background(), vtesting.NewTestReference(t))
+	assert.Equal(t, voucher.ErrNoAuth, err)
+	assert.False(t, pass, "check passed when it should have failed")
+}
@@ -3,6 +3,7 @@ package snakeoil
 import (
 	"context"
 	"errors"
+	"time"
 
 	voucher "github.com/grafeas/voucher/v2"
 )
@@ -14,7 +15,8 @@ var ErrNoScanner = errors.New("no scanner configured for snakeoil")
 // check verifies if there are any known vulnerabilities for the
 // passed image.
 type check struct {
-	scanner voucher.VulnerabilityScanner
+	scanner    voucher.VulnerabilityScanner
+	exceptions voucher.VulnerabilityExceptions
 }
 
 // SetScanner sets the scanner that Snakeoil should use.
@@ -22,6 +24,12 @@ func (s *check) SetScanner(newScanner voucher.VulnerabilityScanner) {
 	s.scanner = newScanner
 }
 
+// SetVulnerabilityExceptions sets the exceptions for vulnerabilities that
+// Snakeoil should accept.
+func (s *check) SetVulnerabilityExceptions(exceptions voucher.VulnerabilityExceptions) {
+	s.exceptions = exceptions
+}
+
 // Check verifies if the image has known vulnerabilities
 func (s *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
 	if nil == s.scanner {
@@ -33,8 +41,9 @@ func (s *check) Check(ctx context.Context, i voucher.ImageData) (bool, error) {
 		return false, err
 	}
 
-	if 0 != len(vulns) {
-		return false, voucher.NewVulnerabilityError(vulns)
+	blocking, waived := s.exceptions.Waive(i, vulns, time.Now())
+	if 0 != len(blocking) {
+		return false, voucher.NewWaivedVulnerabilityError(blocking, waived)
 	}
 
 	return true, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -67,3 +68,64 @@ func TestSnakeoilWithVulnerabilities(t *testing.T) {
 	assert.Containsf(t, err.Error(), "cve-this-is-fine (negligible)", "error message is incorrectly formatted: %s", err)
 	assert.False(t, status, "check passed when it should have failed")
 }
+
+func TestSnakeoilWithExceptions(t *testing.T) {
+	check := new(check)
+
+	i, err := voucher.NewImageData("gcr.io/path/to/image@sha256:97db2bc359ccc94d3b2d6f5daa4173e9e91c513b0dcd961408adbb95ec5e5ce5")
+	require.NoErrorf(t, err, "failed to get ImageData: %s", err)
+
+	scanner := vtesting.NewScanner(t,
+		voucher.Vulnerability{
+			Name:     "cve-the-worst",
+			Severity: voucher.CriticalSeverity,
+		},
+		voucher.Vulnerability{
+			Name:     "cve-expired",
+			Severity: voucher.HighSeverity,
+		},
+	)
+
+	check.SetScanner(scanner)
+	check.SetVulnerabilityExceptions(voucher.VulnerabilityExceptions{
+		{
+			Vulnerability: "cve-the-worst",
+			Images:        []string{"gcr.io/path"},
+			Expires:       time.Now().Add(time.Hour),
+			Justification: "not exploitable",
+		},
+		{
+			Vulnerability: "cve-expired",
+			Expires:       time.Now().Add(-time.Hour),
+			Justification: "it was fine",
+		},
+	})
+
+	status, err := check.Check(context.Background(), i)
+	require.Error(t, err, "check returned no errors, when it should have")
+
+	var vulnErr voucher.VulnerabilitiesError
+	require.ErrorAs(t, err, &vulnErr)
+	require.Len(t, vulnErr.Vulnerabilities, 1)
+	assert.Equal(t, "cve-expired", vulnErr.Vulnerabilities[0].Name)
+	require.Len(t, vulnErr.Waived, 1)
+	assert.Equal(t, "cve-the-worst", vulnErr.Waived[0].Name)
+	assert.False(t, status, "check passed when it should have failed")
+
+	check.SetVulnerabilityExceptions(voucher.VulnerabilityExceptions{
+		{
+			Vulnerability: "cve-the-worst",
+			Expires:       time.Now().Add(time.Hour),
+			Justification: "not exploitable",
+		},
+		{
+			Vulnerability: "cve-expired",
+			Expires:       time.Now().Add(time.Hour),
+			Justification: "renewed",
+		},
+	})
+
+	status, err = check.Check(context.Background(), i)
+	assert.NoError(t, err)
+	assert.True(t, status, "check failed when all vulnerabilities were waived")
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/docker/distribution/reference"
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// CheckBatch executes a request to a Voucher server, to the batch URI of the
+// appropriate check, with each of the passed reference.Canonicals. Returns a
+// voucher.BatchResponse, with a voucher.Response for each image in the same
+// order, and an error.
+func (c *Client) CheckBatch(ctx context.Context, check string, images []reference.Canonical) (voucher.BatchResponse, error) {
+	batchReq := voucher.BatchRequest{
+		ImageURLs: make([]string, 0, len(images)),
+	}
+	for _, image := range images {
+		batchReq.ImageURLs = append(batchReq.ImageURLs, image.String())
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(batchReq); err != nil {
+		return voucher.BatchResponse{}, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.toVoucherBatchURL(check), &buf)
+	if err != nil {
+		return voucher.BatchResponse{}, fmt.Errorf("could create voucher request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var batchResp voucher.BatchResponse
+	if err := c.do(req, &batchResp); err != nil {
+		return voucher.BatchResponse{}, err
+	}
+	return batchResp, nil
+}
+
+func (c *Client) toVoucherBatchURL(checkname string) string {
+	newVoucherURL := c.CopyURL()
+	newVoucherURL.Path = path.Join(newVoucherURL.Path, "batch", checkname)
+	return newVoucherURL.String()
+}
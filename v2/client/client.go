@@ -154,11 +154,19 @@ func (c *Client) newVoucherRequest(ctx context.Context, url string, image refere
 	if err := json.NewEncoder(&buf).Encode(voucherReq); err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	req, err := c.newRequest(ctx, http.MethodPost, url, &buf)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
 	if c.userAgent != "" {
 		req.Header.Set("User-Agent", c.userAgent)
 	}
@@ -173,9 +181,19 @@ func (c *Client) doVoucherRequest(ctx context.Context, url string, image referen
 	if err != nil {
 		return nil, fmt.Errorf("could create voucher request: %w", err)
 	}
+
+	var voucherResp voucher.Response
+	if err := c.do(req, &voucherResp); err != nil {
+		return nil, err
+	}
+	return &voucherResp, nil
+}
+
+// do sends the passed request and decodes the JSON response into out.
+func (c *Client) do(req *http.Request, out interface{}) error {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
@@ -184,12 +202,8 @@ func (c *Client) doVoucherRequest(ctx context.Context, url string, image referen
 		if err == nil {
 			err = fmt.Errorf("failed to get response: %s", strings.TrimSpace(string(b)))
 		}
-		return nil, err
+		return err
 	}
 
-	var voucherResp voucher.Response
-	if err := json.NewDecoder(resp.Body).Decode(&voucherResp); err != nil {
-		return nil, err
-	}
-	return &voucherResp, nil
+	return json.NewDecoder(resp.Body).Decode(out)
 }
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/grafeas/voucher/v2"
@@ -77,6 +78,29 @@ func TestVoucher_CustomUserAgent(t *testing.T) {
 	assert.True(t, res.Success)
 }
 
+func TestVoucher_CheckBatch(t *testing.T) {
+	const failingImage = "gcr.io/project/other@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	v := &mockVoucher{t: t}
+	v.checks = append(v.checks, &voucher.Response{Image: image, Success: true})
+	srv := httptest.NewServer(v)
+	defer srv.Close()
+
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	var _ voucher.BatchInterface = c
+
+	res, err := c.CheckBatch(context.Background(), "diy", []reference.Canonical{canonical(t, image), canonical(t, failingImage)})
+	require.NoError(t, err)
+	assert.False(t, res.Success)
+	require.Len(t, res.Responses, 2)
+	assert.Equal(t, image, res.Responses[0].Image)
+	assert.True(t, res.Responses[0].Success)
+	assert.Equal(t, failingImage, res.Responses[1].Image)
+	assert.False(t, res.Responses[1].Success)
+}
+
 func TestVoucher_Verify(t *testing.T) {
 	v := &mockVoucher{t: t}
 	v.verifications = append(v.verifications, &voucher.Response{Image: image, Success: true})
@@ -106,6 +130,11 @@ func (v *mockVoucher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(v.t, client.DefaultUserAgent, hdr.Get("User-Agent"))
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/batch/") {
+		v.serveBatch(w, r)
+		return
+	}
+
 	var req voucher.Request
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "", http.StatusBadRequest)
@@ -119,21 +148,36 @@ func (v *mockVoucher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		search = v.checks
 	}
 
-	var res *voucher.Response
+	w.Header().Add("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(findResponse(search, req.ImageURL))
+}
+
+func (v *mockVoucher) serveBatch(w http.ResponseWriter, r *http.Request) {
+	var req voucher.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	responses := make([]voucher.Response, 0, len(req.ImageURLs))
+	for _, imageURL := range req.ImageURLs {
+		responses = append(responses, *findResponse(v.checks, imageURL))
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(voucher.NewBatchResponse(responses))
+}
+
+func findResponse(search []*voucher.Response, imageURL string) *voucher.Response {
 	for _, r := range search {
-		if r.Image == req.ImageURL {
-			res = r
-			break
+		if r.Image == imageURL {
+			return r
 		}
 	}
-	if res == nil {
-		res = &voucher.Response{
-			Image:   req.ImageURL,
-			Success: false,
-		}
+	return &voucher.Response{
+		Image:   imageURL,
+		Success: false,
 	}
-	w.Header().Add("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(res)
 }
 
 func canonical(t *testing.T, image string) reference.Canonical {
@@ -143,3 +187,66 @@ func canonical(t *testing.T, image string) reference.Canonical {
 	require.True(t, ok)
 	return canonical
 }
+
+func TestVoucher_CheckAsync(t *testing.T) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/diy", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "true", r.URL.Query().Get("async"))
+
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(voucher.Job{ID: "1234", Check: "diy", Image: image, Status: voucher.JobQueued})
+	})
+	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, client.DefaultUserAgent, r.Header.Get("User-Agent"))
+
+		job := voucher.Job{ID: strings.TrimPrefix(r.URL.Path, "/jobs/"), Status: voucher.JobRunning}
+		switch job.ID {
+		case "1234":
+			polls++
+			if polls > 1 {
+				job.Status = voucher.JobDone
+				job.Response = &voucher.Response{Image: image, Success: true}
+			}
+		case "broken":
+			job.Status = voucher.JobFailed
+			job.Err = "server has been misconfigured"
+		default:
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(job)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	job, err := c.CheckAsync(context.Background(), "diy", canonical(t, image))
+	require.NoError(t, err)
+	assert.Equal(t, "1234", job.ID)
+	assert.Equal(t, voucher.JobQueued, job.Status)
+
+	res, err := c.WaitForJob(context.Background(), job.ID, time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, 2, polls)
+
+	_, err = c.WaitForJob(context.Background(), "broken", time.Millisecond)
+	assert.EqualError(t, err, "job broken failed: server has been misconfigured")
+
+	_, err = c.GetJob(context.Background(), "missing")
+	assert.EqualError(t, err, "failed to get response: job not found")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	polls = -100
+	_, err = c.WaitForJob(ctx, "1234", time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
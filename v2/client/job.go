@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// DefaultJobPollInterval is how often WaitForJob polls a job when it's passed
+// an interval of zero.
+const DefaultJobPollInterval = 5 * time.Second
+
+// CheckAsync starts an asynchronous run of the passed check or check group
+// against the passed reference.Canonical, and returns the queued voucher.Job.
+// Use WaitForJob to wait for its voucher.Response.
+func (c *Client) CheckAsync(ctx context.Context, check string, image reference.Canonical) (voucher.Job, error) {
+	req, err := c.newVoucherRequest(ctx, c.toVoucherAsyncCheckURL(check), image)
+	if err != nil {
+		return voucher.Job{}, fmt.Errorf("could create voucher request: %w", err)
+	}
+
+	var job voucher.Job
+	if err := c.do(req, &job); err != nil {
+		return voucher.Job{}, err
+	}
+	return job, nil
+}
+
+// GetJob returns the current state of the job with the passed ID, including
+// the results of the checks that have finished so far.
+func (c *Client) GetJob(ctx context.Context, id string) (voucher.Job, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.toVoucherJobURL(id), nil)
+	if err != nil {
+		return voucher.Job{}, fmt.Errorf("could create voucher request: %w", err)
+	}
+
+	var job voucher.Job
+	if err := c.do(req, &job); err != nil {
+		return voucher.Job{}, err
+	}
+	return job, nil
+}
+
+// WaitForJob polls the job with the passed ID every interval until it has
+// finished, and returns its voucher.Response. Returns an error if the job
+// failed, or if the context is done first.
+func (c *Client) WaitForJob(ctx context.Context, id string, interval time.Duration) (voucher.Response, error) {
+	if interval <= 0 {
+		interval = DefaultJobPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil {
+			return voucher.Response{}, err
+		}
+
+		if voucher.JobFailed == job.Status {
+			return voucher.Response{}, fmt.Errorf("job %s failed: %s", id, job.Err)
+		}
+
+		if voucher.JobDone == job.Status && job.Response != nil {
+			return *job.Response, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return voucher.Response{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) toVoucherAsyncCheckURL(checkname string) string {
+	newVoucherURL := c.CopyURL()
+	newVoucherURL.Path = path.Join(newVoucherURL.Path, checkname)
+	newVoucherURL.RawQuery = url.Values{"async": []string{"true"}}.Encode()
+	return newVoucherURL.String()
+}
+
+func (c *Client) toVoucherJobURL(id string) string {
+	newVoucherURL := c.CopyURL()
+	newVoucherURL.Path = path.Join(newVoucherURL.Path, "jobs", id)
+	return newVoucherURL.String()
+}
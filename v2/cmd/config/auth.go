@@ -1,10 +1,79 @@
 package config
 
 import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/docker/distribution/reference"
+
+	homedir "github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
 	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/auth"
 	"github.com/grafeas/voucher/v2/auth/google"
+	"github.com/grafeas/voucher/v2/auth/registry"
 )
 
-func newAuth() voucher.Auth {
-	return google.NewAuth()
+// newAuth creates the voucher.Auth used to connect to registries. Registries
+// with credentials in the Docker config file in `registry_auth.docker_config`
+// or in the `registries` secrets use those credentials. Google registries
+// otherwise use Google's default credentials, and if `registry_auth.anonymous`
+// is set, all other registries are connected to anonymously.
+func newAuth(secrets *Secrets) voucher.Auth {
+	credentials := make(map[string]registry.Credentials)
+
+	if path := viper.GetString("registry_auth.docker_config"); "" != path {
+		dockerCredentials, err := readDockerConfig(path)
+		if nil != err {
+			log.Errorf("could not read docker config, continuing without its credentials: %s", err)
+		}
+		for domain, creds := range dockerCredentials {
+			credentials[domain] = creds
+		}
+	}
+
+	if nil != secrets {
+		for domain, creds := range secrets.RegistryAuthentication {
+			credentials[registry.NormalizeDomain(domain)] = creds
+		}
+	}
+
+	domains := make([]string, 0, len(credentials))
+	for domain := range credentials {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	providers := make([]voucher.Auth, 0, len(domains)+2)
+	for _, domain := range domains {
+		providers = append(providers, registry.NewAuth(domain, credentials[domain]))
+	}
+
+	providers = append(providers, google.NewAuth())
+
+	if viper.GetBool("registry_auth.anonymous") {
+		providers = append(providers, registry.NewAnonymousAuth())
+	}
+
+	return auth.NewComposite(providers...)
+}
+
+// NewRegistryClient creates an http.Client which can connect to the registry
+// that the passed image is stored in, with the credentials that checks use.
+func NewRegistryClient(ctx context.Context, secrets *Secrets, image reference.Named) (*http.Client, error) {
+	return newAuth(secrets).ToClient(ctx, image)
+}
+
+// readDockerConfig reads the credentials in the Docker config file at the
+// passed path, which may start with "~".
+func readDockerConfig(path string) (map[string]registry.Credentials, error) {
+	expanded, err := homedir.Expand(path)
+	if nil != err {
+		return nil, err
+	}
+
+	return registry.ReadDockerConfig(expanded)
 }
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+
+	"github.com/grafeas/voucher/v2/cache"
+	"github.com/grafeas/voucher/v2/cache/memory"
+	"github.com/grafeas/voucher/v2/cache/redis"
+)
+
+// defaultCacheTTL is how long passing check results are cached for, if
+// `cache.ttl` is not set.
+const defaultCacheTTL = 5 * time.Minute
+
+// NewDecisionCache creates the cache of check results configured in the
+// `cache` block, or returns nil if `cache.backend` is not set. Results are
+// keyed by a hash of the configuration, so changing the configuration
+// invalidates them.
+func NewDecisionCache(secrets *Secrets) (*cache.Decisions, error) {
+	var backend cache.Cache
+	var err error
+
+	switch name := viper.GetString("cache.backend"); name {
+	case "":
+		return nil, nil
+	case "memory":
+		backend = memory.NewCache(viper.GetInt("cache.size"))
+	case "redis":
+		backend, err = newRedisCache(secrets)
+		if nil != err {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cache backend %q is unknown, supported values are 'memory' or 'redis'", name)
+	}
+
+	configHash, err := ConfigHash()
+	if nil != err {
+		backend.Close()
+		return nil, err
+	}
+
+	ttl := defaultCacheTTL
+	if viper.IsSet("cache.ttl") {
+		ttl = time.Duration(viper.GetInt("cache.ttl")) * time.Second
+	}
+
+	var checkTTLs map[string]int
+	if err = viper.UnmarshalKey("cache.ttls", &checkTTLs); nil != err {
+		backend.Close()
+		return nil, fmt.Errorf("could not read cache.ttls: %w", err)
+	}
+
+	ttls := make(map[string]time.Duration, len(checkTTLs))
+	for check, seconds := range checkTTLs {
+		ttls[check] = time.Duration(seconds) * time.Second
+	}
+
+	return cache.NewDecisions(
+		backend,
+		configHash,
+		ttl,
+		time.Duration(viper.GetInt("cache.failure_ttl"))*time.Second,
+		ttls,
+	), nil
+}
+
+// newRedisCache creates a cache.Cache which connects to the server in the
+// `cache.redis` block, with the password from the secrets.
+func newRedisCache(secrets *Secrets) (*redis.Cache, error) {
+	config := redis.Config{
+		Address:  viper.GetString("cache.redis.address"),
+		Username: viper.GetString("cache.redis.username"),
+		DB:       viper.GetInt("cache.redis.db"),
+	}
+
+	if nil != secrets {
+		config.Password = secrets.Redis.Password
+	}
+
+	if viper.GetBool("cache.redis.tls") {
+		config.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return redis.NewCache(config)
+}
+
+// ConfigHash returns a short hash of the current configuration, and of the
+// files it names that checks read, such as rego modules and trusted keys, so
+// that changing those files also invalidates cached results.
+func ConfigHash() (string, error) {
+	settings, err := json.Marshal(viper.AllSettings())
+	if nil != err {
+		return "", fmt.Errorf("could not hash configuration: %w", err)
+	}
+
+	files, err := configFiles()
+	if nil != err {
+		return "", fmt.Errorf("could not hash configuration: %w", err)
+	}
+
+	hash := sha256.New()
+	hash.Write(settings)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if nil != err {
+			return "", fmt.Errorf("could not hash configuration: %w", err)
+		}
+
+		fileHash := sha256.Sum256(content)
+		fmt.Fprintf(hash, "\n%s %x", file, fileHash)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:8]), nil
+}
+
+// configFiles returns the sorted paths of the files named in the
+// configuration which change the results of checks: the rego modules in
+// `rego.dir`, the `vulnerability_exceptions_file`, the git signers, and the
+// trusted keys and roots of the sigstore verifiers.
+func configFiles() ([]string, error) {
+	paths := []string{
+		viper.GetString("vulnerability_exceptions_file"),
+		viper.GetString("git.gpg_keyring"),
+		viper.GetString("git.allowed_signers"),
+	}
+
+	for _, key := range []string{"cosign", "slsa", "report.signers"} {
+		paths = append(paths, viper.GetStringSlice(key+".keys")...)
+		paths = append(paths, viper.GetString(key+".trusted_root"))
+	}
+
+	if dir := viper.GetString("rego.dir"); "" != dir {
+		modules, err := filepath.Glob(filepath.Join(dir, "*.rego"))
+		if nil != err {
+			return nil, err
+		}
+		paths = append(paths, modules...)
+	}
+
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if "" == path {
+			continue
+		}
+
+		expanded, err := homedir.Expand(path)
+		if nil != err {
+			return nil, err
+		}
+		files = append(files, expanded)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+func TestNewDecisionCache(t *testing.T) {
+	decisions, err := NewDecisionCache(nil)
+	require.NoError(t, err)
+	assert.Nil(t, decisions)
+
+	viper.Set("cache.backend", "memcached")
+	defer viper.Set("cache", nil)
+
+	_, err = NewDecisionCache(nil)
+	assert.EqualError(t, err, `cache backend "memcached" is unknown, supported values are 'memory' or 'redis'`)
+
+	viper.Set("cache.backend", "redis")
+	_, err = NewDecisionCache(nil)
+	assert.EqualError(t, err, "redis address is not set")
+
+	viper.Set("cache.backend", "memory")
+	viper.Set("cache.ttls", map[string]interface{}{"diy": 0})
+
+	decisions, err = NewDecisionCache(nil)
+	require.NoError(t, err)
+	require.NotNil(t, decisions)
+	defer decisions.Close()
+
+	imageData, err := voucher.NewImageData("gcr.io/voucher/app@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	decisions.Put(ctx, voucher.CheckResult{Name: "nobody", ImageData: imageData, Success: true})
+	decisions.Put(ctx, voucher.CheckResult{Name: "diy", ImageData: imageData, Success: true})
+
+	_, ok := decisions.Get(ctx, imageData, "nobody")
+	assert.True(t, ok)
+
+	_, ok = decisions.Get(ctx, imageData, "diy")
+	assert.False(t, ok)
+}
+
+func TestConfigHash(t *testing.T) {
+	hash, err := ConfigHash()
+	require.NoError(t, err)
+	assert.Len(t, hash, 16)
+
+	viper.Set("failon", "critical")
+	defer viper.Set("failon", nil)
+
+	changed, err := ConfigHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+}
+
+func TestConfigHashCoversFiles(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "approved_base.rego")
+	require.NoError(t, os.WriteFile(module, []byte("package voucher\n\nallow = true\n"), 0600))
+
+	viper.Set("rego.dir", dir)
+	defer viper.Set("rego.dir", nil)
+
+	hash, err := ConfigHash()
+	require.NoError(t, err)
+
+	// editing a module changes the hash, though the configuration is the
+	// same.
+	require.NoError(t, os.WriteFile(module, []byte("package voucher\n\nallow = false\n"), 0600))
+
+	changed, err := ConfigHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+
+	viper.Set("vulnerability_exceptions_file", filepath.Join(dir, "missing.toml"))
+	defer viper.Set("vulnerability_exceptions_file", nil)
+
+	_, err = ConfigHash()
+	assert.Error(t, err)
+}
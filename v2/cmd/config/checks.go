@@ -35,6 +35,14 @@ func setCheckScanner(check voucher.Check, scanner voucher.VulnerabilityScanner)
 	}
 }
 
+// setCheckVulnerabilityExceptions sets the vulnerability exceptions on the
+// passed Check, if that Check implements VulnerabilityExceptionCheck.
+func setCheckVulnerabilityExceptions(check voucher.Check, exceptions voucher.VulnerabilityExceptions) {
+	if exceptionCheck, ok := check.(voucher.VulnerabilityExceptionCheck); ok {
+		exceptionCheck.SetVulnerabilityExceptions(exceptions)
+	}
+}
+
 // setCheckMetadataClient sets the MetadataClient for the passed Check, if that Check implements
 // MetadataCheck.
 func setCheckMetadataClient(check voucher.Check, metadataClient voucher.MetadataClient) {
@@ -72,15 +80,28 @@ func setCheckRepositoryClient(check voucher.Check, repositoryClient repository.C
 // NewCheckSuite creates a new checks.Suite with the requested
 // Checks, passing any necessary configuration details to the
 // checks.
-func NewCheckSuite(metadataClient voucher.MetadataClient, repositoryClient repository.Client, names ...string) (*voucher.Suite, error) {
-	auth := newAuth()
+func NewCheckSuite(secrets *Secrets, metadataClient voucher.MetadataClient, repositoryClient repository.Client, names ...string) (*voucher.Suite, error) {
+	auth := newAuth(secrets)
 	repos := validRepos()
-	scanner := newScanner(metadataClient)
 	checksuite := voucher.NewSuite()
 
+	selectedPlatforms, err := platforms()
+	if nil != err {
+		return checksuite, fmt.Errorf("can't create check suite: %s", err)
+	}
+
+	scanner := newPlatformScanner(newScanner(metadataClient, auth), auth, selectedPlatforms)
+
+	checkMetadataClient := newProvenanceMetadataClient(metadataClient, auth)
+
 	trustedBuildCreators := viper.GetStringSlice("trusted_builder_identities")
 	trustedProjects := viper.GetStringSlice("trusted_projects")
 
+	exceptions, err := GetVulnerabilityExceptionsFromConfig()
+	if nil != err {
+		return checksuite, fmt.Errorf("can't create check suite: %s", err)
+	}
+
 	checks, err := voucher.GetCheckFactories(names...)
 	if nil != err {
 		return checksuite, fmt.Errorf("can't create check suite: %s", err)
@@ -89,12 +110,20 @@ func NewCheckSuite(metadataClient voucher.MetadataClient, repositoryClient repos
 	for name, check := range checks {
 		setCheckAuth(check, auth)
 		setCheckScanner(check, scanner)
-		setCheckMetadataClient(check, metadataClient)
+		setCheckVulnerabilityExceptions(check, exceptions)
+		setCheckMetadataClient(check, checkMetadataClient)
 		setCheckValidRepos(check, repos)
+		setCheckPlatforms(check, selectedPlatforms)
 		setCheckTrustedIdentitiesAndProjects(check, trustedBuildCreators, trustedProjects)
 		setCheckRepositoryClient(check, repositoryClient)
 
+		format, err := payloadFormat(name)
+		if nil != err {
+			return checksuite, fmt.Errorf("can't create check suite: %s", err)
+		}
+
 		checksuite.Add(name, check)
+		checksuite.SetPayloadFormat(name, format)
 	}
 
 	return checksuite, nil
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/viper"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// exceptionDateLayout is the layout for exception expiry dates which don't
+// include a time.
+const exceptionDateLayout = "2006-01-02"
+
+// GetVulnerabilityExceptionsFromConfig reads the `[[vulnerability_exceptions]]`
+// blocks from the configuration, as well as those in the file configured in
+// `vulnerability_exceptions_file`.
+func GetVulnerabilityExceptionsFromConfig() (voucher.VulnerabilityExceptions, error) {
+	exceptions, err := readVulnerabilityExceptions(viper.GetViper())
+	if nil != err {
+		return nil, err
+	}
+
+	if filename := viper.GetString("vulnerability_exceptions_file"); "" != filename {
+		v := viper.New()
+		v.SetConfigFile(filename)
+		if err = v.ReadInConfig(); nil != err {
+			return nil, fmt.Errorf("could not read vulnerability exceptions file: %w", err)
+		}
+
+		fileExceptions, err := readVulnerabilityExceptions(v)
+		if nil != err {
+			return nil, err
+		}
+		exceptions = append(exceptions, fileExceptions...)
+	}
+
+	if err = exceptions.Validate(); nil != err {
+		return nil, fmt.Errorf("invalid vulnerability exception: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+// readVulnerabilityExceptions reads the `vulnerability_exceptions` key from
+// the passed Viper.
+func readVulnerabilityExceptions(v *viper.Viper) (voucher.VulnerabilityExceptions, error) {
+	var exceptions voucher.VulnerabilityExceptions
+
+	err := v.UnmarshalKey("vulnerability_exceptions", &exceptions, viper.DecodeHook(exceptionDecodeHook))
+	if nil != err {
+		return nil, fmt.Errorf("could not read vulnerability exceptions: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+// exceptionDecodeHook converts dates in the configuration into time.Times.
+// Dates can be written as "2006-01-02" or in RFC 3339 format.
+func exceptionDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Time{}) || from == to {
+		return data, nil
+	}
+
+	date, ok := data.(string)
+	if !ok {
+		return data, nil
+	}
+
+	if expires, err := time.Parse(exceptionDateLayout, date); nil == err {
+		return expires, nil
+	}
+
+	return time.Parse(time.RFC3339, date)
+}
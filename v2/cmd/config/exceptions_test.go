@@ -0,0 +1,100 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cmd/config"
+)
+
+func TestGetVulnerabilityExceptionsFromConfig(t *testing.T) {
+	exceptionsFile := filepath.Join(t.TempDir(), "exceptions.json")
+	err := os.WriteFile(exceptionsFile, []byte(`{
+  "vulnerability_exceptions": [
+    {
+      "vulnerability": "CVE-2021-3711",
+      "package": "openssl",
+      "expires": "2030-06-30T12:00:00Z",
+      "justification": "Not reachable from our code"
+    }
+  ]
+}`), 0600)
+	require.NoError(t, err)
+
+	viper.Reset()
+	viper.SetConfigType("toml")
+	err = viper.ReadConfig(strings.NewReader(`
+vulnerability_exceptions_file = "` + exceptionsFile + `"
+
+[[vulnerability_exceptions]]
+vulnerability = "CVE-2022-0778"
+images = ["gcr.io/project/*"]
+expires = "2030-01-01"
+justification = "Fixed in the next base image"
+
+[[vulnerability_exceptions]]
+vulnerability = "CVE-2022-1292"
+expires = "2030-02-01"
+justification = "Only affects c_rehash"
+`))
+	require.NoError(t, err)
+
+	exceptions, err := config.GetVulnerabilityExceptionsFromConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, voucher.VulnerabilityExceptions{
+		{
+			Vulnerability: "CVE-2022-0778",
+			Images:        []string{"gcr.io/project/*"},
+			Expires:       time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			Justification: "Fixed in the next base image",
+		},
+		{
+			Vulnerability: "CVE-2022-1292",
+			Expires:       time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC),
+			Justification: "Only affects c_rehash",
+		},
+		{
+			Vulnerability: "CVE-2021-3711",
+			Package:       "openssl",
+			Expires:       time.Date(2030, 6, 30, 12, 0, 0, 0, time.UTC),
+			Justification: "Not reachable from our code",
+		},
+	}, exceptions)
+}
+
+func TestGetVulnerabilityExceptionsFromConfigWithoutJustification(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("toml")
+	err := viper.ReadConfig(strings.NewReader(`
+[[vulnerability_exceptions]]
+vulnerability = "CVE-2022-0778"
+expires = "2030-01-01"
+`))
+	require.NoError(t, err)
+
+	_, err = config.GetVulnerabilityExceptionsFromConfig()
+	assert.ErrorIs(t, err, voucher.ErrNoExceptionJustification)
+}
+
+func TestGetVulnerabilityExceptionsFromConfigWithoutExpiry(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("toml")
+	err := viper.ReadConfig(strings.NewReader(`
+[[vulnerability_exceptions]]
+vulnerability = "CVE-2022-0778"
+justification = "Fixed in the next base image"
+`))
+	require.NoError(t, err)
+
+	_, err = config.GetVulnerabilityExceptionsFromConfig()
+	assert.ErrorIs(t, err, voucher.ErrNoExceptionExpiry)
+}
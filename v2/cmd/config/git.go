@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+
+	"github.com/grafeas/voucher/v2/repository"
+	"github.com/grafeas/voucher/v2/repository/git"
+)
+
+// newGitClient creates a plain git repository.Client for the repository
+// group with the passed alias. Repositories are cloned from the group's
+// `clone-url`, into mirrors in the `git.cache_dir` directory, which are
+// fetched every `git.fetch_interval` seconds. Commits and tags are verified
+// against the keys in `git.allowed_signers` and `git.gpg_keyring`.
+func newGitClient(auth *repository.Auth, alias string) (repository.Client, error) {
+	// repositories may be cloned without credentials.
+	if nil != auth && "" == auth.Type() {
+		auth = nil
+	}
+
+	cacheDir := viper.GetString("git.cache_dir")
+	if "" == cacheDir {
+		cacheDir = filepath.Join(os.TempDir(), "voucher-git")
+	}
+
+	cacheDir, err := homedir.Expand(cacheDir)
+	if nil != err {
+		return nil, err
+	}
+
+	signers, err := newGitSigners()
+	if nil != err {
+		return nil, err
+	}
+
+	return git.NewClient(auth, git.Options{
+		CacheDir:      cacheDir,
+		CloneURL:      viper.GetString("repository." + alias + ".clone-url"),
+		FetchInterval: time.Duration(viper.GetInt("git.fetch_interval")) * time.Second,
+		Signers:       signers,
+	})
+}
+
+// newGitSigners creates a git.Signers from the SSH allowed signers file and
+// the armored OpenPGP keyring configured in the `git` block.
+func newGitSigners() (*git.Signers, error) {
+	var readers [2]io.Reader
+	for i, key := range []string{"git.gpg_keyring", "git.allowed_signers"} {
+		path := viper.GetString(key)
+		if "" == path {
+			continue
+		}
+
+		path, err := homedir.Expand(path)
+		if nil != err {
+			return nil, err
+		}
+
+		file, err := os.Open(path)
+		if nil != err {
+			return nil, fmt.Errorf("could not open %s: %w", key, err)
+		}
+		defer file.Close()
+
+		readers[i] = file
+	}
+
+	return git.NewSigners(readers[0], readers[1])
+}
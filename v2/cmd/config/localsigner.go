@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+
+	localsigner "github.com/grafeas/voucher/v2/signer/local"
+)
+
+// localKey is a PKCS#8 private key file configured in `[[local_keys]]`.
+type localKey struct {
+	Check string `mapstructure:"check"`
+	Path  string `mapstructure:"path"`
+}
+
+// getLocalKeyRing creates a local signer with the private keys in the files
+// configured in `[[local_keys]]`, and the PEM encoded keys in the secrets'
+// "local_keys" block.
+func getLocalKeyRing(secrets *Secrets) (*localsigner.Signer, error) {
+	var keys []localKey
+	if err := viper.UnmarshalKey("local_keys", &keys); nil != err {
+		return nil, fmt.Errorf("could not read local_keys: %w", err)
+	}
+
+	keyring := localsigner.NewSigner()
+	for _, key := range keys {
+		path, err := homedir.Expand(key.Path)
+		if nil != err {
+			return nil, err
+		}
+
+		if err = keyring.AddKeyFromFile(key.Check, path); nil != err {
+			return nil, err
+		}
+	}
+
+	if nil != secrets {
+		for check, key := range secrets.LocalKeys {
+			if err := keyring.AddKey(check, []byte(key)); nil != err {
+				return nil, err
+			}
+		}
+	}
+
+	if 0 == len(keys) && (nil == secrets || 0 == len(secrets.LocalKeys)) {
+		return nil, errors.New("no local keys configured")
+	}
+
+	return keyring, nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalSigner(t *testing.T) {
+	fileKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, secretKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(key interface{}) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	path := filepath.Join(t.TempDir(), "diy.pem")
+	require.NoError(t, os.WriteFile(path, encode(fileKey), 0600))
+
+	viper.Set("signer", "local")
+	viper.Set("local_keys", []map[string]interface{}{{"check": "diy", "path": path}})
+	defer viper.Set("signer", nil)
+	defer viper.Set("local_keys", nil)
+
+	secrets := &Secrets{LocalKeys: map[string]string{"nobody": string(encode(secretKey))}}
+
+	verifier := NewAttestationVerifier(secrets)
+	require.NotNil(t, verifier)
+
+	keyring := NewAttestationSigner(secrets)
+	require.NotNil(t, keyring)
+
+	for _, check := range []string{"diy", "nobody"} {
+		signature, keyID, err := keyring.Sign(check, "body")
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify(check, "body", signature, keyID))
+	}
+
+	viper.Set("local_keys", []map[string]interface{}{{"check": "diy", "path": filepath.Join(t.TempDir(), "missing.pem")}})
+	assert.Nil(t, NewAttestationSigner(secrets))
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -9,12 +10,29 @@ import (
 	voucher "github.com/grafeas/voucher/v2"
 	"github.com/grafeas/voucher/v2/containeranalysis"
 	"github.com/grafeas/voucher/v2/grafeas"
+	"github.com/grafeas/voucher/v2/local"
 	"github.com/grafeas/voucher/v2/signer"
 )
 
-// NewMetadataClient creates a new MetadataClient.
+// shared holds the attestation signer that every MetadataClient in the
+// process uses, and the "local" MetadataClient. Signers such as PKCS#11
+// tokens and Vault logins hold process-wide state, and are slow to set up,
+// and the local client's database must be seen by every request, so they are
+// created the first time they're needed rather than for each request, and
+// closed by CloseMetadataClients.
+var shared struct {
+	sync.Mutex
+	keyring signer.AttestationSigner
+	local   *local.Client
+}
+
+// newAttestationSigner creates the shared attestation signer.
+var newAttestationSigner = NewAttestationSigner
+
+// NewMetadataClient creates a new MetadataClient, which signs attestations
+// with the attestation signer shared by the process.
 func NewMetadataClient(ctx context.Context, secrets *Secrets) (voucher.MetadataClient, error) {
-	keyring := NewAttestationSigner(secrets)
+	keyring := sharedAttestationSigner(secrets)
 
 	if viper.GetString("image_project") != "" {
 		log.Warning("`image_project` is deprecated. Please rely on the `valid_repos` configuration option to limit where images come from.")
@@ -37,6 +55,8 @@ func NewMetadataClient(ctx context.Context, secrets *Secrets) (voucher.MetadataC
 			keyring,
 			grafeas.NewAPIService(viper.GetString("grafeasos.hostname"), viper.GetString("grafeasos.version")),
 		)
+	case "local":
+		return sharedLocalClient(keyring)
 	default:
 		log.Warning("`metadata_client` option is not set, defaulting to \"containeranalysis\"")
 		return containeranalysis.NewClient(
@@ -48,6 +68,102 @@ func NewMetadataClient(ctx context.Context, secrets *Secrets) (voucher.MetadataC
 	}
 }
 
+// sharedLocalClient returns the local MetadataClient shared by the process,
+// creating it if it doesn't exist yet, seeded with the fixtures configured in
+// "local.fixtures". Closing the returned client does nothing.
+func sharedLocalClient(keyring signer.AttestationSigner) (voucher.MetadataClient, error) {
+	shared.Lock()
+	defer shared.Unlock()
+
+	if nil == shared.local {
+		client, err := local.NewClient(viper.GetString("local.path"), keyring)
+		if nil != err {
+			return nil, err
+		}
+
+		if fixtures := viper.GetString("local.fixtures"); "" != fixtures {
+			if err = client.LoadFixtures(fixtures); nil != err {
+				return nil, err
+			}
+		}
+
+		shared.local = client
+	}
+
+	return sharedLocal{shared.local}, nil
+}
+
+// sharedLocal is the shared local MetadataClient, which is only closed by
+// CloseMetadataClients.
+type sharedLocal struct {
+	*local.Client
+}
+
+func (sharedLocal) Close() {}
+
+// sharedAttestationSigner returns the attestation signer shared by the
+// process, creating it if it doesn't exist yet, or nil if there is no signer.
+// Closing the returned signer does nothing, so MetadataClients can close it
+// without closing it for everyone else.
+func sharedAttestationSigner(secrets *Secrets) signer.AttestationSigner {
+	shared.Lock()
+	defer shared.Unlock()
+
+	if nil == shared.keyring {
+		// a signer which failed to be created is tried again next time.
+		shared.keyring = newAttestationSigner(secrets)
+		if nil == shared.keyring {
+			return nil
+		}
+	}
+
+	if verifier, ok := shared.keyring.(signer.AttestationVerifier); ok {
+		return sharedVerifier{sharedSigner{shared.keyring}, verifier}
+	}
+	return sharedSigner{shared.keyring}
+}
+
+// CloseMetadataClients closes the attestation signer and local MetadataClient
+// shared by the process, once it's done with them.
+func CloseMetadataClients() error {
+	shared.Lock()
+	defer shared.Unlock()
+
+	if nil != shared.local {
+		shared.local.Close()
+		shared.local = nil
+	}
+
+	if nil == shared.keyring {
+		return nil
+	}
+
+	err := shared.keyring.Close()
+	shared.keyring = nil
+	return err
+}
+
+// sharedSigner is the shared attestation signer, which is only closed by
+// CloseMetadataClients.
+type sharedSigner struct {
+	signer.AttestationSigner
+}
+
+func (sharedSigner) Close() error {
+	return nil
+}
+
+// sharedVerifier is the shared attestation signer, when it can also verify
+// attestations.
+type sharedVerifier struct {
+	sharedSigner
+	verifier signer.AttestationVerifier
+}
+
+func (v sharedVerifier) Verify(checkName, body, signature, keyID string) error {
+	return v.verifier.Verify(checkName, body, signature, keyID)
+}
+
 // NewAttestationSigner creates a new attestation signer
 func NewAttestationSigner(secrets *Secrets) signer.AttestationSigner {
 	signerName := viper.GetString("signer")
@@ -68,8 +184,49 @@ func NewAttestationSigner(secrets *Secrets) signer.AttestationSigner {
 			log.Println("could not load KMS keyring from config, continuing without attestation support: ", err)
 			return nil
 		}
+		if nil == keyring {
+			return nil
+		}
+		return keyring
+	} else if signerName == "local" {
+		keyring, err := getLocalKeyRing(secrets)
+		if nil != err {
+			log.Println("could not load local keys, continuing without attestation support: ", err)
+			return nil
+		}
+		return keyring
+	} else if signerName == "pkcs11" {
+		keyring, err := getPKCS11KeyRing(secrets)
+		if nil != err {
+			log.Println("could not load PKCS#11 keys, continuing without attestation support: ", err)
+			return nil
+		}
+		return keyring
+	} else if signerName == "vault" {
+		keyring, err := getVaultKeyRing(secrets)
+		if nil != err {
+			log.Println("could not load Vault keys, continuing without attestation support: ", err)
+			return nil
+		}
 		return keyring
 	}
-	log.Printf("signer %q is unknown, supported values are 'kms' or 'pgp'\n", signerName)
+	log.Printf("signer %q is unknown, supported values are 'kms', 'local', 'pgp', 'pkcs11' or 'vault'\n", signerName)
 	return nil
 }
+
+// NewAttestationVerifier returns the attestation signer shared by the process
+// as an attestation verifier, which verifies attestations against the keys
+// configured for the signer, or nil if the signer can't verify attestations.
+func NewAttestationVerifier(secrets *Secrets) signer.AttestationVerifier {
+	keyring := sharedAttestationSigner(secrets)
+	if nil == keyring {
+		return nil
+	}
+
+	verifier, ok := keyring.(signer.AttestationVerifier)
+	if !ok {
+		log.Printf("signer %q cannot verify attestations\n", viper.GetString("signer"))
+		return nil
+	}
+	return verifier
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/signer"
+)
+
+// countingSigner is an AttestationSigner which counts how often it's used
+// and closed.
+type countingSigner struct {
+	mu     sync.Mutex
+	signed int
+	closed int
+}
+
+func (s *countingSigner) Sign(checkName, body string) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if 0 != s.closed {
+		return "", "", signer.ErrNoKeyForCheck
+	}
+	s.signed++
+	return "signature", "key", nil
+}
+
+func (s *countingSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed++
+	return nil
+}
+
+func TestMetadataClientsShareSigner(t *testing.T) {
+	viper.Set("metadata_client", "local")
+	defer viper.Set("metadata_client", nil)
+
+	// start without a signer shared by an earlier test.
+	require.NoError(t, CloseMetadataClients())
+
+	keyring := &countingSigner{}
+	created := 0
+	newAttestationSigner = func(*Secrets) signer.AttestationSigner {
+		created++
+		return keyring
+	}
+	defer func() { newAttestationSigner = NewAttestationSigner }()
+
+	ref, err := reference.Parse("gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+	image := ref.(reference.Canonical)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			client, err := NewMetadataClient(context.Background(), nil)
+			require.NoError(t, err)
+			defer client.Close()
+
+			_, err = client.AddAttestationToImage(context.Background(), image, voucher.NewAttestation("diy", "body"))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created, "the signer should be created once")
+	assert.Equal(t, 2, keyring.signed)
+	assert.Equal(t, 0, keyring.closed, "closing a MetadataClient shouldn't close the shared signer")
+
+	require.NoError(t, CloseMetadataClients())
+	assert.Equal(t, 1, keyring.closed)
+}
+
+func TestLocalMetadataClientIsShared(t *testing.T) {
+	viper.Set("metadata_client", "local")
+	defer viper.Set("metadata_client", nil)
+
+	require.NoError(t, CloseMetadataClients())
+	newAttestationSigner = func(*Secrets) signer.AttestationSigner {
+		return &countingSigner{}
+	}
+	defer func() { newAttestationSigner = NewAttestationSigner }()
+	defer CloseMetadataClients()
+
+	ref, err := reference.Parse("gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+	image := ref.(reference.Canonical)
+
+	// the check request attests the image.
+	client, err := NewMetadataClient(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = client.AddAttestationToImage(context.Background(), image, voucher.NewAttestation("diy", "body"))
+	require.NoError(t, err)
+	client.Close()
+
+	// and a later verify request sees the attestation.
+	client, err = NewMetadataClient(context.Background(), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	attestations, err := client.GetAttestations(context.Background(), image)
+	require.NoError(t, err)
+	assert.Len(t, attestations, 1)
+}
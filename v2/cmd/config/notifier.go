@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/spf13/viper"
+
+	"github.com/grafeas/voucher/v2/notifier"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const (
+	// defaultNotificationRateLimit is the number of notifications each target
+	// is sent per `notifications.rate_interval`, if
+	// `notifications.rate_limit` is not set.
+	defaultNotificationRateLimit = 10
+
+	// defaultNotificationRateInterval is the interval the rate limit applies
+	// to, if `notifications.rate_interval` is not set.
+	defaultNotificationRateInterval = time.Minute
+)
+
+// notificationTarget is a `[[notifications.targets]]` block.
+type notificationTarget struct {
+	Name     string   `mapstructure:"name"`
+	Format   string   `mapstructure:"format"`
+	URL      string   `mapstructure:"url"`
+	Groups   []string `mapstructure:"groups"`
+	Template string   `mapstructure:"template"`
+}
+
+// NewNotifier creates a Dispatcher which sends notifications of rejected
+// images to the targets in the `notifications` block, or returns nil if
+// there are none. Targets without a URL use the one in the notifications
+// secrets with the target's name.
+func NewNotifier(secrets *Secrets) (*notifier.Dispatcher, error) {
+	var targetConfigs []notificationTarget
+	if err := viper.UnmarshalKey("notifications.targets", &targetConfigs); nil != err {
+		return nil, fmt.Errorf("could not read notifications.targets: %w", err)
+	}
+
+	if 0 == len(targetConfigs) {
+		return nil, nil
+	}
+
+	targets := make([]*notifier.Target, 0, len(targetConfigs))
+	for _, targetConfig := range targetConfigs {
+		url := targetConfig.URL
+		if "" == url && nil != secrets {
+			url = secrets.Notifications[targetConfig.Name]
+		}
+
+		target, err := notifier.NewTarget(
+			targetConfig.Name,
+			url,
+			notifier.Format(targetConfig.Format),
+			targetConfig.Groups,
+			targetConfig.Template,
+		)
+		if nil != err {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	dispatcherConfig := notifier.Config{
+		Retries:      notifier.DefaultRetries,
+		RetryDelay:   time.Duration(viper.GetInt("notifications.retry_delay")) * time.Second,
+		Timeout:      time.Duration(viper.GetInt("notifications.timeout")) * time.Second,
+		QueueSize:    viper.GetInt("notifications.queue_size"),
+		RateLimit:    defaultNotificationRateLimit,
+		RateInterval: defaultNotificationRateInterval,
+	}
+
+	if viper.IsSet("notifications.retries") {
+		dispatcherConfig.Retries = viper.GetInt("notifications.retries")
+	}
+
+	if viper.IsSet("notifications.rate_limit") {
+		dispatcherConfig.RateLimit = viper.GetInt("notifications.rate_limit")
+	}
+
+	if viper.IsSet("notifications.rate_interval") {
+		dispatcherConfig.RateInterval = time.Duration(viper.GetInt("notifications.rate_interval")) * time.Second
+	}
+
+	return notifier.NewDispatcher(dispatcherConfig, targets, newBuildDetailFunc(secrets)), nil
+}
+
+// newBuildDetailFunc returns a notifier.BuildDetailFunc which looks up build
+// details with a new MetadataClient.
+func newBuildDetailFunc(secrets *Secrets) notifier.BuildDetailFunc {
+	return func(ctx context.Context, image reference.Canonical) (repository.BuildDetail, error) {
+		metadataClient, err := NewMetadataClient(ctx, secrets)
+		if nil != err {
+			return repository.BuildDetail{}, fmt.Errorf("failed to create MetadataClient: %w", err)
+		}
+		defer metadataClient.Close()
+
+		return metadataClient.GetBuildDetail(ctx, image)
+	}
+}
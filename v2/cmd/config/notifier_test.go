@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotifier(t *testing.T) {
+	dispatcher, err := NewNotifier(nil)
+	require.NoError(t, err)
+	assert.Nil(t, dispatcher)
+
+	viper.Set("notifications.targets", []map[string]interface{}{
+		{"name": "security", "format": "slack", "groups": []string{"production"}},
+	})
+	defer viper.Set("notifications", nil)
+
+	_, err = NewNotifier(nil)
+	assert.EqualError(t, err, "notification target has no url: security")
+
+	dispatcher, err = NewNotifier(&Secrets{Notifications: map[string]string{"security": "https://hooks.slack.com/services/T0/B0/X"}})
+	require.NoError(t, err)
+	require.NotNil(t, dispatcher)
+	assert.NoError(t, dispatcher.Close())
+
+	viper.Set("notifications.targets", []map[string]interface{}{
+		{"name": "security", "format": "email", "url": "https://hooks.example.com"},
+	})
+
+	_, err = NewNotifier(nil)
+	assert.EqualError(t, err, `notification format "email" is unknown, supported values are 'webhook', 'slack' or 'teams'`)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/spf13/viper"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// errInTotoWithPGP is returned when in-toto payloads are configured with the
+// pgp signer, whose armored OpenPGP signatures can't be verified as DSSE
+// signatures.
+var errInTotoWithPGP = errors.New("in-toto payloads can't be signed by the pgp signer")
+
+// payloadFormat returns the format of the payloads of the attestations
+// created for the check with the passed name. The format set for the check in
+// "payload_formats" overrides the "payload_format" used by every check.
+func payloadFormat(name string) (voucher.PayloadFormat, error) {
+	key := "payload_formats." + name
+	if !viper.IsSet(key) {
+		key = "payload_format"
+	}
+
+	format, err := voucher.ParsePayloadFormat(viper.GetString(key))
+	if nil != err {
+		return "", err
+	}
+
+	signerName := viper.GetString("signer")
+	if voucher.InTotoPayloadFormat == format && ("pgp" == signerName || "" == signerName) {
+		return "", errInTotoWithPGP
+	}
+
+	return format, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+func TestPayloadFormat(t *testing.T) {
+	defer viper.Set("payload_format", nil)
+	defer viper.Set("payload_formats.diy", nil)
+	defer viper.Set("signer", nil)
+
+	viper.Set("signer", "local")
+
+	format, err := payloadFormat("diy")
+	assert.NoError(t, err)
+	assert.Equal(t, voucher.BinAuthzPayloadFormat, format)
+
+	viper.Set("payload_format", "in-toto")
+	viper.Set("payload_formats.diy", "binauthz")
+
+	format, err = payloadFormat("diy")
+	assert.NoError(t, err)
+	assert.Equal(t, voucher.BinAuthzPayloadFormat, format)
+
+	format, err = payloadFormat("nobody")
+	assert.NoError(t, err)
+	assert.Equal(t, voucher.InTotoPayloadFormat, format)
+
+	viper.Set("payload_formats.diy", "cyclonedx")
+
+	_, err = payloadFormat("diy")
+	assert.EqualError(t, err, `unknown payload format: "cyclonedx"`)
+
+	// in-toto payloads are signed as DSSE envelopes, which pgp can't sign.
+	viper.Set("payload_formats.diy", "in-toto")
+	viper.Set("signer", "pgp")
+
+	_, err = payloadFormat("diy")
+	assert.Equal(t, errInTotoWithPGP, err)
+
+	_, err = payloadFormat("nobody")
+	assert.Equal(t, errInTotoWithPGP, err)
+
+	viper.Set("payload_format", "binauthz")
+
+	format, err = payloadFormat("nobody")
+	assert.NoError(t, err)
+	assert.Equal(t, voucher.BinAuthzPayloadFormat, format)
+}
@@ -0,0 +1,62 @@
+//go:build cgo
+
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/grafeas/voucher/v2/signer"
+	"github.com/grafeas/voucher/v2/signer/pkcs11"
+)
+
+// pkcs11Key is a key pair in a PKCS#11 token configured in `[[pkcs11_keys]]`.
+type pkcs11Key struct {
+	Check   string `mapstructure:"check"`
+	Label   string `mapstructure:"label"`
+	Algo    string `mapstructure:"algo"`
+	Padding string `mapstructure:"padding"`
+}
+
+// getPKCS11KeyRing creates a PKCS#11 signer for the token configured in the
+// "pkcs11" block, with the keys configured in `[[pkcs11_keys]]`. The token's
+// PIN is read from the secrets' "pkcs11" block, or from "pkcs11.pin" if it's
+// not in the secrets.
+func getPKCS11KeyRing(secrets *Secrets) (signer.AttestationSigner, error) {
+	var keys []pkcs11Key
+	if err := viper.UnmarshalKey("pkcs11_keys", &keys); nil != err {
+		return nil, fmt.Errorf("could not read pkcs11_keys: %w", err)
+	}
+
+	if 0 == len(keys) {
+		return nil, errors.New("no PKCS#11 keys configured")
+	}
+
+	signerKeys := make(map[string]pkcs11.Key, len(keys))
+	for _, key := range keys {
+		signerKeys[key.Check] = pkcs11.Key{
+			Label:   key.Label,
+			Algo:    key.Algo,
+			Padding: key.Padding,
+		}
+	}
+
+	pin := viper.GetString("pkcs11.pin")
+	if nil != secrets && "" != secrets.PKCS11.PIN {
+		pin = secrets.PKCS11.PIN
+	}
+
+	keyring, err := pkcs11.NewSigner(pkcs11.Config{
+		Module:     viper.GetString("pkcs11.module"),
+		TokenLabel: viper.GetString("pkcs11.token_label"),
+		PIN:        pin,
+		Sessions:   viper.GetInt("pkcs11.sessions"),
+	}, signerKeys)
+	if nil != err {
+		return nil, err
+	}
+
+	return keyring, nil
+}
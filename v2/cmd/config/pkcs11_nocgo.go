@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package config
+
+import (
+	"errors"
+
+	"github.com/grafeas/voucher/v2/signer"
+)
+
+// getPKCS11KeyRing returns an error, as PKCS#11 modules can only be loaded
+// by binaries built with cgo.
+func getPKCS11KeyRing(secrets *Secrets) (signer.AttestationSigner, error) {
+	return nil, errors.New("PKCS#11 signing requires voucher to be built with cgo")
+}
@@ -0,0 +1,28 @@
+//go:build cgo
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPKCS11Signer(t *testing.T) {
+	viper.Set("signer", "pkcs11")
+	defer viper.Set("signer", nil)
+
+	_, err := getPKCS11KeyRing(nil)
+	assert.EqualError(t, err, "no PKCS#11 keys configured")
+
+	viper.Set("pkcs11.module", filepath.Join(t.TempDir(), "missing.so"))
+	viper.Set("pkcs11_keys", []map[string]interface{}{{"check": "diy", "label": "diy", "algo": "SHA256"}})
+	defer viper.Set("pkcs11.module", nil)
+	defer viper.Set("pkcs11_keys", nil)
+
+	_, err = getPKCS11KeyRing(&Secrets{PKCS11: PKCS11Secrets{PIN: "1234"}})
+	assert.Contains(t, err.Error(), "failed to load PKCS#11 module")
+	assert.Nil(t, NewAttestationSigner(nil))
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/docker"
+)
+
+// platforms returns the platforms of multi-platform images that checks must
+// pass for, based on the `platform_policy` option. The "all" policy (the
+// default) returns no platforms, meaning every platform in an image is
+// checked. The "listed" policy returns the platforms in `platforms`.
+func platforms() ([]string, error) {
+	switch policy := viper.GetString("platform_policy"); policy {
+	case "", "all":
+		return nil, nil
+	case "listed":
+		listed := viper.GetStringSlice("platforms")
+		if 0 == len(listed) {
+			return nil, fmt.Errorf("platform_policy %q requires at least one platform in `platforms`", policy)
+		}
+
+		if _, err := docker.ParsePlatforms(listed); nil != err {
+			return nil, err
+		}
+
+		return listed, nil
+	default:
+		return nil, fmt.Errorf("not a valid platform_policy: %q, supported values are 'all' or 'listed'", policy)
+	}
+}
+
+// setCheckPlatforms sets the platforms for the passed Check, if that Check
+// implements PlatformCheck.
+func setCheckPlatforms(check voucher.Check, platforms []string) {
+	if platformCheck, ok := check.(voucher.PlatformCheck); ok {
+		platformCheck.SetPlatforms(platforms)
+	}
+}
+
+// platformScanner is a VulnerabilityScanner which scans each of the platform
+// images in a manifest list or OCI index with another VulnerabilityScanner,
+// and returns all of their vulnerabilities.
+type platformScanner struct {
+	scanner   voucher.VulnerabilityScanner
+	auth      voucher.Auth
+	platforms []string
+}
+
+// newPlatformScanner creates a new platformScanner wrapping the passed
+// VulnerabilityScanner.
+func newPlatformScanner(scanner voucher.VulnerabilityScanner, auth voucher.Auth, platforms []string) *platformScanner {
+	return &platformScanner{
+		scanner:   scanner,
+		auth:      auth,
+		platforms: platforms,
+	}
+}
+
+// FailOn sets severity level that a vulnerability must match or exceed to
+// prompt a failure.
+func (s *platformScanner) FailOn(severity voucher.Severity) {
+	s.scanner.FailOn(severity)
+}
+
+// Scan gets the vulnerabilities for an Image. If the image is a manifest list
+// or OCI index, the vulnerabilities of each of the selected platform images are
+// returned. Images from registries the Auth can't connect to are scanned as
+// they are.
+func (s *platformScanner) Scan(ctx context.Context, i voucher.ImageData) ([]voucher.Vulnerability, error) {
+	if nil == s.auth || !s.auth.IsForDomain(i) {
+		return s.scanner.Scan(ctx, i)
+	}
+
+	platforms, err := docker.ParsePlatforms(s.platforms)
+	if nil != err {
+		return []voucher.Vulnerability{}, err
+	}
+
+	client, err := s.auth.ToClient(ctx, i)
+	if nil != err {
+		return []voucher.Vulnerability{}, err
+	}
+
+	images, err := docker.RequestPlatformImages(client, i)
+	if nil != err {
+		return []voucher.Vulnerability{}, err
+	}
+
+	images, err = docker.SelectPlatforms(images, platforms)
+	if nil != err {
+		return []voucher.Vulnerability{}, err
+	}
+
+	vulns := make([]voucher.Vulnerability, 0)
+	seen := make(map[string]bool)
+	for _, image := range images {
+		imageVulns, err := s.scanner.Scan(ctx, image.Reference)
+		if nil != err {
+			if "" != image.Platform.String() {
+				err = fmt.Errorf("platform %s: %w", image.Platform, err)
+			}
+			return []voucher.Vulnerability{}, err
+		}
+
+		for _, vuln := range imageVulns {
+			key := vuln.Name + "\x00" + vuln.Package
+			if !seen[key] {
+				seen[key] = true
+				vulns = append(vulns, vuln)
+			}
+		}
+	}
+
+	return vulns, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+func TestPlatforms(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   string
+		listed   []string
+		expected []string
+		err      string
+	}{
+		{name: "default"},
+		{name: "all", policy: "all", listed: []string{"linux/amd64"}},
+		{name: "listed", policy: "listed", listed: []string{"linux/amd64", "linux/arm64/v8"}, expected: []string{"linux/amd64", "linux/arm64/v8"}},
+		{name: "listed without platforms", policy: "listed", err: `platform_policy "listed" requires at least one platform in ` + "`platforms`"},
+		{name: "invalid platform", policy: "listed", listed: []string{"linux"}, err: `invalid platform "linux", must be os/architecture[/variant]`},
+		{name: "invalid policy", policy: "some", err: `not a valid platform_policy: "some", supported values are 'all' or 'listed'`},
+	}
+
+	defer viper.Set("platform_policy", nil)
+	defer viper.Set("platforms", nil)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			viper.Set("platform_policy", c.policy)
+			viper.Set("platforms", c.listed)
+
+			selected, err := platforms()
+			if "" != c.err {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, selected)
+		})
+	}
+}
+
+// digestScanner returns the vulnerabilities configured for the digest of the
+// scanned image.
+type digestScanner map[string][]voucher.Vulnerability
+
+func (s digestScanner) FailOn(voucher.Severity) {}
+
+func (s digestScanner) Scan(_ context.Context, i voucher.ImageData) ([]voucher.Vulnerability, error) {
+	return s[i.Digest().String()], nil
+}
+
+func TestPlatformScanner(t *testing.T) {
+	server := vtesting.NewTestDockerServer(t)
+	defer server.Close()
+
+	shared := voucher.Vulnerability{Name: "CVE-2022-0778", Package: "openssl", Severity: voucher.HighSeverity}
+	armOnly := voucher.Vulnerability{Name: "CVE-2021-3711", Package: "openssl", Severity: voucher.CriticalSeverity}
+
+	scanner := newPlatformScanner(digestScanner{
+		"sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da": {shared},
+		"sha256:b248c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da": {shared, armOnly},
+	}, vtesting.NewAuth(server), nil)
+
+	vulns, err := scanner.Scan(context.Background(), vtesting.NewTestManifestListReference(t))
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.Vulnerability{shared, armOnly}, vulns)
+
+	scanner.platforms = []string{"linux/amd64"}
+
+	vulns, err = scanner.Scan(context.Background(), vtesting.NewTestManifestListReference(t))
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.Vulnerability{shared}, vulns)
+
+	vulns, err = scanner.Scan(context.Background(), vtesting.NewNobodyBadTestReference(t))
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.Vulnerability{shared, armOnly}, vulns)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/viper"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/policy"
+)
+
+// GetPoliciesFromConfig reads the `[[policy.<group>]]` blocks from the
+// configuration, returning the Policies for each check group.
+func GetPoliciesFromConfig() (map[string]policy.Policies, error) {
+	policies := make(map[string]policy.Policies)
+
+	err := viper.UnmarshalKey("policy", &policies, viper.DecodeHook(policyDecodeHook))
+	if nil != err {
+		return nil, fmt.Errorf("could not read policies: %w", err)
+	}
+
+	for group, groupPolicies := range policies {
+		if err := groupPolicies.Validate(); nil != err {
+			return nil, fmt.Errorf("invalid policy for %q: %w", group, err)
+		}
+	}
+
+	return policies, nil
+}
+
+// policyDecodeHook converts strings in the configuration into Severities, and
+// allows a single check name to be used in place of a policy.Rule.
+func policyDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+
+	switch to {
+	case reflect.TypeOf(voucher.Severity(0)):
+		return voucher.StringToSeverity(data.(string))
+	case reflect.TypeOf(policy.Rule{}):
+		return policy.Rule{Check: data.(string)}, nil
+	}
+
+	return data, nil
+}
@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cmd/config"
+	"github.com/grafeas/voucher/v2/policy"
+)
+
+func TestGetPoliciesFromConfig(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("toml")
+	err := viper.ReadConfig(strings.NewReader(`
+[[policy.production]]
+images = ["gcr.io/prod-*"]
+require = "approved"
+
+[[policy.production]]
+failon = "medium"
+require = { all_of = ["diy", "snakeoil"], n = 1, n_of = [{ check = "provenance" }, { any_of = ["nobody", "is_shopify"] }] }
+
+[policy.production.severity]
+snakeoil = "low"
+`))
+	require.NoError(t, err)
+
+	policies, err := config.GetPoliciesFromConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]policy.Policies{
+		"production": {
+			{
+				Images:  []string{"gcr.io/prod-*"},
+				Require: policy.Rule{Check: "approved"},
+			},
+			{
+				FailOn:     voucher.MediumSeverity,
+				Severities: map[string]voucher.Severity{"snakeoil": voucher.LowSeverity},
+				Require: policy.Rule{
+					AllOf: []policy.Rule{{Check: "diy"}, {Check: "snakeoil"}},
+					N:     1,
+					NOf: []policy.Rule{
+						{Check: "provenance"},
+						{AnyOf: []policy.Rule{{Check: "nobody"}, {Check: "is_shopify"}}},
+					},
+				},
+			},
+		},
+	}, policies)
+}
+
+func TestGetInvalidPoliciesFromConfig(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("toml")
+	err := viper.ReadConfig(strings.NewReader(`
+[[policy.production]]
+require = { n = 3, n_of = ["diy", "nobody"] }
+`))
+	require.NoError(t, err)
+
+	_, err = config.GetPoliciesFromConfig()
+	assert.EqualError(t, err, `invalid policy for "production": policy 0: n must be between 1 and 2, was 3`)
+}
@@ -1,16 +1,94 @@
 package config
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
+	"github.com/spf13/viper"
+
 	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/checks/cosign"
 	"github.com/grafeas/voucher/v2/checks/org"
+	"github.com/grafeas/voucher/v2/checks/rego"
+	slsacheck "github.com/grafeas/voucher/v2/checks/slsa"
 )
 
-func RegisterDynamicChecks() {
+// RegisterDynamicChecks registers the Checks which are defined in the
+// configuration, rather than in code.
+func RegisterDynamicChecks() error {
 	orgs := GetOrganizationsFromConfig()
 	for alias, organization := range orgs {
 		orgCheck := org.NewOrganizationCheckFactory(organization)
 		voucher.RegisterCheckFactory("is_"+strings.ToLower(alias), orgCheck)
 	}
+
+	if err := registerCosignCheck(); nil != err {
+		return err
+	}
+
+	if err := registerSLSACheck(); nil != err {
+		return err
+	}
+
+	return registerRegoChecks()
+}
+
+// registerCosignCheck registers the "cosign" Check if the `cosign` block is
+// configured.
+func registerCosignCheck() error {
+	if !viper.IsSet("cosign") {
+		return nil
+	}
+
+	verifier, err := newSigstoreVerifier("cosign")
+	if nil != err {
+		return err
+	}
+
+	voucher.RegisterCheckFactory("cosign", cosign.NewCheckFactory(verifier))
+
+	return nil
+}
+
+// registerSLSACheck registers the "slsa" Check if the `slsa` block is
+// configured.
+func registerSLSACheck() error {
+	if !viper.IsSet("slsa") {
+		return nil
+	}
+
+	verifier, err := newSLSAVerifier()
+	if nil != err {
+		return err
+	}
+
+	slsaVerifier = verifier
+	voucher.RegisterCheckFactory("slsa", slsacheck.NewCheckFactory(verifier))
+
+	return nil
+}
+
+// registerRegoChecks registers a Check for each Rego module in the directory
+// configured in "rego.dir". Returns an error if a module is named after a
+// Check that is already registered, rather than replacing that Check.
+func registerRegoChecks() error {
+	dir := viper.GetString("rego.dir")
+	if "" == dir {
+		return nil
+	}
+
+	modules, err := rego.LoadModules(context.Background(), dir)
+	if nil != err {
+		return err
+	}
+
+	for _, module := range modules {
+		if voucher.IsCheckFactoryRegistered(module.Name) {
+			return fmt.Errorf("rego module %q is named after a check which is already registered", module.Name)
+		}
+		voucher.RegisterCheckFactory(module.Name, rego.NewCheckFactory(module))
+	}
+
+	return nil
 }
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+const testRegoModule = `package voucher.test
+
+default allow = true
+`
+
+func TestRegisterRegoChecks(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rego_registered.rego"), []byte(testRegoModule), 0600))
+
+	viper.Set("rego.dir", dir)
+	defer viper.Set("rego.dir", "")
+
+	require.NoError(t, registerRegoChecks())
+	assert.True(t, voucher.IsCheckFactoryRegistered("rego_registered"))
+
+	// a module can't replace a check which is already registered, such as
+	// the one registered for it before.
+	err := registerRegoChecks()
+	assert.EqualError(t, err, `rego module "rego_registered" is named after a check which is already registered`)
+}
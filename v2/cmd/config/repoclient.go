@@ -4,8 +4,20 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/spf13/viper"
+
 	"github.com/grafeas/voucher/v2/repository"
+	"github.com/grafeas/voucher/v2/repository/bitbucket"
 	"github.com/grafeas/voucher/v2/repository/github"
+	"github.com/grafeas/voucher/v2/repository/gitlab"
+)
+
+const (
+	githubProvider          = "github"
+	gitlabProvider          = "gitlab"
+	bitbucketProvider       = "bitbucket"
+	bitbucketServerProvider = "bitbucket-server"
+	gitProvider             = "git"
 )
 
 // NewRepositoryClient creates a new repository.Client for the given repository URL. The URL may be in any known
@@ -16,27 +28,67 @@ func NewRepositoryClient(ctx context.Context, keyring repository.KeyRing, repoUR
 		return nil, fmt.Errorf("error parsing url %s", repoURL)
 	}
 
-	token, err := getTokenForOrg(keyring, *org)
+	alias, token, err := getTokenForOrg(keyring, *org)
 	if nil != err {
 		return nil, err
 	}
 
-	switch org.VCS {
-	case "github.com":
+	switch getRepositoryProvider(alias, *org) {
+	case githubProvider:
 		return github.NewClient(context.Background(), token)
+	case gitlabProvider:
+		return gitlab.NewClient(ctx, token, getRepositoryAPIURL(alias, "https://"+org.VCS+"/api/v4"))
+	case bitbucketProvider:
+		return bitbucket.NewCloudClient(ctx, token, getRepositoryAPIURL(alias, bitbucket.CloudAPIURL))
+	case bitbucketServerProvider:
+		return bitbucket.NewServerClient(ctx, token, getRepositoryAPIURL(alias, "https://"+org.VCS+"/rest/api/1.0"))
+	case gitProvider:
+		return newGitClient(token, alias)
 	}
 
 	return nil, fmt.Errorf("unknown repository %s", repoURL)
 }
 
-func getTokenForOrg(keyring repository.KeyRing, org repository.Organization) (*repository.Auth, error) {
+func getTokenForOrg(keyring repository.KeyRing, org repository.Organization) (string, *repository.Auth, error) {
 	orgs := GetOrganizationsFromConfig()
 	if alias, ok := getOrgAlias(orgs, org); ok {
 		token := keyring[alias]
-		return &token, nil
+		return alias, &token, nil
+	}
+
+	return "", nil, fmt.Errorf("failed to get token for %s", org.Alias)
+}
+
+// getRepositoryProvider returns the provider of the repositories of the
+// passed organization. The provider is set with the "provider" key of the
+// organization's repository block, which is required for self-managed
+// instances. Otherwise it's determined from the organization's domain.
+func getRepositoryProvider(alias string, org repository.Organization) string {
+	if provider := viper.GetString("repository." + alias + ".provider"); "" != provider {
+		return provider
+	}
+
+	switch org.VCS {
+	case "github.com":
+		return githubProvider
+	case "gitlab.com":
+		return gitlabProvider
+	case "bitbucket.org":
+		return bitbucketProvider
+	}
+
+	return ""
+}
+
+// getRepositoryAPIURL returns the API URL of the repositories of the
+// organization with the passed alias, which is set with the "api-url" key of
+// the organization's repository block. Defaults to the passed URL.
+func getRepositoryAPIURL(alias string, defaultURL string) string {
+	if apiURL := viper.GetString("repository." + alias + ".api-url"); "" != apiURL {
+		return apiURL
 	}
 
-	return nil, fmt.Errorf("failed to get token for %s", org.Alias)
+	return defaultURL
 }
 
 func getOrgAlias(orgs map[string]repository.Organization, repoOrg repository.Organization) (matchingKey string, foundMatch bool) {
@@ -58,8 +110,9 @@ func getOrgAlias(orgs map[string]repository.Organization, repoOrg repository.Org
 			longestMatch = alias
 		}
 
-		if org.Name == repoOrg.Name {
-			matchLength = 2
+		// the most nested matching organization is the longest match.
+		if org.Includes(repoOrg.Name) && 2+len(org.Name) > matchLength {
+			matchLength = 2 + len(org.Name)
 			longestMatch = alias
 		}
 	}
@@ -76,5 +129,5 @@ func isMatch(org, repoOrg repository.Organization) bool {
 		return false
 	}
 
-	return org.Name == "" || org.Name == repoOrg.Name
+	return org.Name == "" || org.Includes(repoOrg.Name)
 }
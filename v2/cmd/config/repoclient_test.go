@@ -8,7 +8,10 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/grafeas/voucher/v2/repository"
+	"github.com/grafeas/voucher/v2/repository/bitbucket"
+	"github.com/grafeas/voucher/v2/repository/git"
 	"github.com/grafeas/voucher/v2/repository/github"
+	"github.com/grafeas/voucher/v2/repository/gitlab"
 )
 
 func TestValidRepo(t *testing.T) {
@@ -38,10 +41,74 @@ func TestInvalidRepo(t *testing.T) {
 	assert.False(t, github.IsGithubRepoClient(client), "received github client with invalid org for ", repoURL)
 }
 
+func TestGitlabRepo(t *testing.T) {
+	viper.Set("repository", map[string]interface{}{
+		"gitlab":   map[string]interface{}{"org-url": "gitlab.com/group/"},
+		"internal": map[string]interface{}{"org-url": "git.example.com/platform/", "provider": "gitlab", "api-url": "https://git.example.com/api/v4"},
+	})
+	defer viper.Set("repository", nil)
+
+	keyring := repository.KeyRing{
+		"gitlab":   repository.Auth{Token: "gitlab-token"},
+		"internal": repository.Auth{Token: "internal-token"},
+	}
+
+	for _, repoURL := range []string{
+		"https://gitlab.com/group/subgroup/my-app",
+		"https://git.example.com/platform/my-app",
+	} {
+		client, err := NewRepositoryClient(context.Background(), keyring, repoURL)
+		assert.NoError(t, err)
+		assert.True(t, gitlab.IsGitlabRepoClient(client), "received client is not a gitlab client for ", repoURL)
+	}
+}
+
+func TestBitbucketRepo(t *testing.T) {
+	viper.Set("repository", map[string]interface{}{
+		"cloud":  map[string]interface{}{"org-url": "bitbucket.org/workspace"},
+		"server": map[string]interface{}{"org-url": "bitbucket.example.com/scm/proj/", "provider": "bitbucket-server"},
+	})
+	defer viper.Set("repository", nil)
+
+	keyring := repository.KeyRing{
+		"cloud":  repository.Auth{Username: "user", Password: "app-password"},
+		"server": repository.Auth{Token: "http-access-token"},
+	}
+
+	for _, repoURL := range []string{
+		"https://bitbucket.org/workspace/my-app",
+		"https://bitbucket.example.com/scm/proj/my-app.git",
+	} {
+		client, err := NewRepositoryClient(context.Background(), keyring, repoURL)
+		assert.NoError(t, err)
+		assert.True(t, bitbucket.IsBitbucketRepoClient(client), "received client is not a bitbucket client for ", repoURL)
+	}
+}
+
+func TestGitRepo(t *testing.T) {
+	viper.Set("repository", map[string]interface{}{
+		"internal": map[string]interface{}{"org-url": "git.example.com/platform", "provider": "git", "clone-url": "ssh://git@git.example.com"},
+	})
+	viper.Set("git", map[string]interface{}{"cache_dir": t.TempDir()})
+	defer viper.Set("repository", nil)
+	defer viper.Set("git", nil)
+
+	// repositories on plain git servers may be cloned without secrets.
+	client, err := NewRepositoryClient(context.Background(), repository.KeyRing{}, "https://git.example.com/platform/my-app")
+	assert.NoError(t, err)
+	assert.True(t, git.IsGitRepoClient(client), "received client is not a git client")
+
+	viper.Set("git.allowed_signers", "testdata/missing_allowed_signers")
+	_, err = NewRepositoryClient(context.Background(), repository.KeyRing{}, "https://git.example.com/platform/my-app")
+	assert.Error(t, err)
+}
+
 func TestGetOrgAlias(t *testing.T) {
 	orgs := map[string]repository.Organization{
 		"apple":  {Alias: "apple", VCS: "github.com", Name: "my-org"},
 		"banana": {Alias: "banana", VCS: "github.com", Name: ""},
+		"cherry": {Alias: "cherry", VCS: "gitlab.com", Name: "group"},
+		"durian": {Alias: "durian", VCS: "gitlab.com", Name: "group/subgroup"},
 	}
 
 	cases := []struct {
@@ -54,6 +121,10 @@ func TestGetOrgAlias(t *testing.T) {
 		{str: "github.com/other-org", expectedAlias: "banana", expectedFoundMatch: true},
 		{str: "github.com", expectedAlias: "banana", expectedFoundMatch: true},
 		{str: "gitea.com/hello", expectedAlias: "", expectedFoundMatch: false},
+		{str: "gitlab.com/group/other/", expectedAlias: "cherry", expectedFoundMatch: true},
+		{str: "gitlab.com/group/subgroup/", expectedAlias: "durian", expectedFoundMatch: true},
+		{str: "gitlab.com/group/subgroup/nested/", expectedAlias: "durian", expectedFoundMatch: true},
+		{str: "gitlab.com/groups/", expectedAlias: "", expectedFoundMatch: false},
 	}
 
 	for _, testCase := range cases {
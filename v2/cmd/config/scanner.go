@@ -5,9 +5,10 @@ import (
 	"github.com/spf13/viper"
 
 	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/report"
 )
 
-func newScanner(metadataClient voucher.MetadataClient) (scanner voucher.VulnerabilityScanner) {
+func newScanner(metadataClient voucher.MetadataClient, auth voucher.Auth) (scanner voucher.VulnerabilityScanner) {
 	scannerName := viper.GetString("scanner")
 	switch scannerName {
 	case "gca", "g":
@@ -15,6 +16,8 @@ func newScanner(metadataClient voucher.MetadataClient) (scanner voucher.Vulnerab
 		scanner = voucher.NewScanner(metadataClient)
 	case "metadata":
 		scanner = voucher.NewScanner(metadataClient)
+	case "trivy", "grype":
+		scanner = newReportScanner(scannerName, auth)
 	default:
 		scanner = nil
 	}
@@ -32,3 +35,30 @@ func newScanner(metadataClient voucher.MetadataClient) (scanner voucher.Vulnerab
 
 	return
 }
+
+// newReportScanner creates a scanner which reads vulnerability reports in the
+// format with the passed name, either from the directory configured in
+// `report.dir` or from OCI artifacts attached to the image which are signed
+// by the signers configured in `report.signers`.
+func newReportScanner(formatName string, auth voucher.Auth) voucher.VulnerabilityScanner {
+	format, err := report.GetFormat(formatName)
+	if nil != err {
+		log.Fatal(err)
+	}
+
+	var source report.Source
+	switch sourceName := viper.GetString("report.source"); sourceName {
+	case "path":
+		source = report.NewDirectorySource(viper.GetString("report.dir"))
+	case "referrer":
+		verifier, err := newSigstoreVerifier("report.signers")
+		if nil != err {
+			log.Fatalf("reading reports from referrers requires trusted signers: %s", err)
+		}
+		source = report.NewReferrerSource(auth, viper.GetString("report.artifact_type"), verifier)
+	default:
+		log.Fatalf("not a valid report source: %q, supported values are 'path' or 'referrer'", sourceName)
+	}
+
+	return report.NewScanner(format, source)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/viper"
 	"go.mozilla.org/sops/v3/decrypt"
 
+	"github.com/grafeas/voucher/v2/auth/registry"
 	"github.com/grafeas/voucher/v2/repository"
 	"github.com/grafeas/voucher/v2/signer/pgp"
 )
@@ -16,9 +17,38 @@ import (
 // Secrets represents the format that the ejson configuration is structured
 // in.
 type Secrets struct {
-	Keys                     map[string]string  `json:"openpgpkeys"`
-	RepositoryAuthentication repository.KeyRing `json:"repositories"`
-	Datadog                  DatadogSecrets     `json:"datadog"`
+	Keys                     map[string]string               `json:"openpgpkeys"`
+	LocalKeys                map[string]string               `json:"local_keys"`
+	PKCS11                   PKCS11Secrets                   `json:"pkcs11"`
+	Vault                    VaultSecrets                    `json:"vault"`
+	Redis                    RedisSecrets                    `json:"redis"`
+	Notifications            map[string]string               `json:"notifications"`
+	RegistryWebhook          RegistryWebhookSecrets          `json:"registry_webhook"`
+	RepositoryAuthentication repository.KeyRing              `json:"repositories"`
+	RegistryAuthentication   map[string]registry.Credentials `json:"registries"`
+	Datadog                  DatadogSecrets                  `json:"datadog"`
+}
+
+// PKCS11Secrets holds the secrets used to log in to a PKCS#11 token.
+type PKCS11Secrets struct {
+	PIN string `json:"pin"`
+}
+
+// VaultSecrets holds the secrets used to log in to Vault.
+type VaultSecrets struct {
+	Token    string `json:"token"`
+	SecretID string `json:"secret_id"`
+}
+
+// RedisSecrets holds the password used to connect to the decision cache.
+type RedisSecrets struct {
+	Password string `json:"password"`
+}
+
+// RegistryWebhookSecrets holds the secret that registries send or sign their
+// notifications with.
+type RegistryWebhookSecrets struct {
+	Secret string `json:"secret"`
 }
 
 type DatadogSecrets struct {
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+
+	"github.com/grafeas/voucher/v2/sigstore"
+)
+
+// newSigstoreVerifier creates a sigstore.Verifier from the configuration
+// block with the passed key (eg. "cosign"). The block holds the paths of the
+// trusted public keys (`keys`) and trusted root (`trusted_root`), the
+// trusted keyless identities (`[[<key>.identities]]`), and whether
+// signatures made with keys must be in a transparency log (`require_tlog`).
+func newSigstoreVerifier(key string) (*sigstore.Verifier, error) {
+	paths := viper.GetStringSlice(key + ".keys")
+	for i := range paths {
+		expanded, err := homedir.Expand(paths[i])
+		if nil != err {
+			return nil, err
+		}
+		paths[i] = expanded
+	}
+
+	keys, err := sigstore.LoadPublicKeys(paths...)
+	if nil != err {
+		return nil, fmt.Errorf("could not load %s keys: %w", key, err)
+	}
+
+	var root *sigstore.TrustedRoot
+	if path := viper.GetString(key + ".trusted_root"); "" != path {
+		if path, err = homedir.Expand(path); nil != err {
+			return nil, err
+		}
+
+		if root, err = sigstore.LoadTrustedRoot(path); nil != err {
+			return nil, fmt.Errorf("could not load %s trusted root: %w", key, err)
+		}
+	}
+
+	var identities []sigstore.Identity
+	if err = viper.UnmarshalKey(key+".identities", &identities); nil != err {
+		return nil, fmt.Errorf("could not read %s identities: %w", key, err)
+	}
+
+	for _, identity := range identities {
+		if err = identity.Validate(); nil != err {
+			return nil, fmt.Errorf("invalid %s identity: %w", key, err)
+		}
+	}
+
+	if 0 == len(keys) && 0 == len(identities) {
+		return nil, fmt.Errorf("%s requires at least one key or identity", key)
+	}
+
+	if 0 < len(identities) && nil == root {
+		return nil, fmt.Errorf("%s identities require a trusted_root", key)
+	}
+
+	return sigstore.NewVerifier(keys, root, identities, viper.GetBool(key+".require_tlog")), nil
+}
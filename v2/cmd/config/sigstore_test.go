@@ -0,0 +1,160 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cmd/config"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+)
+
+func TestRegisterSLSACheck(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+
+	keyFile := filepath.Join(t.TempDir(), "slsa.pub")
+	require.NoError(t, os.WriteFile(keyFile, fixture.PublicKeyPEM(), 0600))
+
+	cases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "builders",
+			config: `
+[slsa]
+keys = ["` + keyFile + `"]
+source_uris = ["https://github.com/grafeas/voucher"]
+min_level = 3
+
+[[slsa.builders]]
+id = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml"
+level = 3
+`,
+		},
+		{
+			name: "no builders",
+			config: `
+[slsa]
+keys = ["` + keyFile + `"]
+`,
+			err: "slsa policy must trust at least one builder",
+		},
+		{
+			name: "no keys",
+			config: `
+[[slsa.builders]]
+id = "https://tekton.dev/chains/v2"
+`,
+			err: "slsa requires at least one key or identity",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("toml")
+			require.NoError(t, viper.ReadConfig(strings.NewReader(c.config)))
+
+			err := config.RegisterDynamicChecks()
+			if "" != c.err {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, voucher.IsCheckFactoryRegistered("slsa"))
+		})
+	}
+}
+
+func TestRegisterCosignCheck(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(keyFile, fixture.PublicKeyPEM(), 0600))
+
+	rootFile := filepath.Join(dir, "trusted_root.json")
+	require.NoError(t, os.WriteFile(rootFile, fixture.TrustedRootJSON(), 0600))
+
+	cases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "keys and identities",
+			config: `
+[cosign]
+keys = ["` + keyFile + `"]
+trusted_root = "` + rootFile + `"
+
+[[cosign.identities]]
+issuer = "https://token.actions.githubusercontent.com"
+subject_regexp = "https://github.com/grafeas/.*"
+`,
+		},
+		{
+			name: "no keys or identities",
+			config: `
+[cosign]
+require_tlog = true
+`,
+			err: "cosign requires at least one key or identity",
+		},
+		{
+			name: "identities without trusted root",
+			config: `
+[[cosign.identities]]
+issuer = "https://token.actions.githubusercontent.com"
+subject = "release@example.com"
+`,
+			err: "cosign identities require a trusted_root",
+		},
+		{
+			name: "invalid identity",
+			config: `
+[cosign]
+trusted_root = "` + rootFile + `"
+
+[[cosign.identities]]
+subject = "release@example.com"
+`,
+			err: "invalid cosign identity: identity must have an issuer",
+		},
+		{
+			name: "missing key",
+			config: `
+[cosign]
+keys = ["` + filepath.Join(dir, "missing.pub") + `"]
+`,
+			err: "could not load cosign keys",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			viper.Reset()
+			viper.SetConfigType("toml")
+			require.NoError(t, viper.ReadConfig(strings.NewReader(c.config)))
+
+			err := config.RegisterDynamicChecks()
+			if "" != c.err {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, voucher.IsCheckFactoryRegistered("cosign"))
+		})
+	}
+}
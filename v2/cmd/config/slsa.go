@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+	"github.com/grafeas/voucher/v2/slsa"
+)
+
+// slsaVerifier is the slsa.Verifier created when the "slsa" check is
+// registered, so the keys and trusted root it reads from disk are only read
+// once, rather than for each check suite.
+var slsaVerifier *slsa.Verifier
+
+// newSLSAVerifier creates a slsa.Verifier from the `slsa` configuration
+// block. The block holds the keys and identities that provenance must be
+// signed by, as read by newSigstoreVerifier, and the slsa.Policy that
+// provenance must satisfy.
+func newSLSAVerifier() (*slsa.Verifier, error) {
+	signatures, err := newSigstoreVerifier("slsa")
+	if nil != err {
+		return nil, err
+	}
+
+	var policy slsa.Policy
+	if err = viper.UnmarshalKey("slsa", &policy); nil != err {
+		return nil, fmt.Errorf("could not read slsa policy: %w", err)
+	}
+
+	if err = policy.Validate(); nil != err {
+		return nil, err
+	}
+
+	return slsa.NewVerifier(signatures, policy), nil
+}
+
+// provenanceMetadataClient is a voucher.MetadataClient which falls back to
+// the build details in an image's SLSA provenance, when the MetadataClient it
+// wraps has no build details for the image.
+type provenanceMetadataClient struct {
+	voucher.MetadataClient
+	auth     voucher.Auth
+	verifier *slsa.Verifier
+}
+
+// newProvenanceMetadataClient wraps the passed MetadataClient in a
+// provenanceMetadataClient if the "slsa" check has been registered, using the
+// check's slsa.Verifier.
+func newProvenanceMetadataClient(metadataClient voucher.MetadataClient, auth voucher.Auth) voucher.MetadataClient {
+	if nil == metadataClient || nil == slsaVerifier {
+		return metadataClient
+	}
+
+	return &provenanceMetadataClient{
+		MetadataClient: metadataClient,
+		auth:           auth,
+		verifier:       slsaVerifier,
+	}
+}
+
+// GetBuildDetail returns the build details of the passed image from the
+// wrapped MetadataClient, or from the image's trusted SLSA provenance if the
+// MetadataClient has none.
+func (c *provenanceMetadataClient) GetBuildDetail(ctx context.Context, ref voucher.ImageData) (repository.BuildDetail, error) {
+	buildDetail, err := c.MetadataClient.GetBuildDetail(ctx, ref)
+	if !voucher.IsNoMetadataError(err) {
+		return buildDetail, err
+	}
+
+	client, err := c.auth.ToClient(ctx, ref)
+	if nil != err {
+		return repository.BuildDetail{}, err
+	}
+
+	provenance, err := c.verifier.VerifyImage(client, ref)
+	if nil != err {
+		return repository.BuildDetail{}, &voucher.NoMetadataError{
+			Type: voucher.BuildDetailsType,
+			Err:  err,
+		}
+	}
+
+	return provenance.BuildDetail(), nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/repository"
+	"github.com/grafeas/voucher/v2/sigstore"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+	"github.com/grafeas/voucher/v2/slsa"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+func TestProvenanceMetadataClient(t *testing.T) {
+	ctx := context.Background()
+	fixture := sigstoretest.NewFixture(t)
+	i := vtesting.NewTestReference(t)
+
+	builderID := "https://github.com/grafeas/voucher/.github/workflows/builder.yml@refs/heads/main"
+	statement, err := attestation.NewStatement(i, slsa.ProvenanceV1PredicateType, json.RawMessage(`{
+		"buildDefinition": {
+			"resolvedDependencies": [{"uri": "git+https://github.com/grafeas/voucher@refs/heads/main", "digest": {"gitCommit": "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"}}]
+		},
+		"runDetails": {"builder": {"id": "`+builderID+`"}}
+	}`))
+	require.NoError(t, err)
+
+	payload, err := statement.ToString()
+	require.NoError(t, err)
+
+	server := fixture.NewRegistry(i, fixture.SignWithKey([]byte(payload), attestation.InTotoPayloadType))
+
+	metadataClient := new(voucher.MockMetadataClient)
+	metadataClient.On("GetBuildDetail", ctx, i).Return(repository.BuildDetail{}, &voucher.NoMetadataError{
+		Type: voucher.BuildDetailsType,
+		Err:  errors.New("no occurrences"),
+	})
+
+	client := &provenanceMetadataClient{
+		MetadataClient: metadataClient,
+		auth:           vtesting.NewAuth(server),
+		verifier: slsa.NewVerifier(
+			sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false),
+			slsa.Policy{Builders: []slsa.Builder{{ID: builderID}}},
+		),
+	}
+
+	buildDetail, err := client.GetBuildDetail(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/grafeas/voucher", buildDetail.RepositoryURL)
+	assert.Equal(t, "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59", buildDetail.Commit)
+	assert.Equal(t, builderID, buildDetail.BuildCreator)
+
+	// images without trusted provenance have no build details.
+	client.verifier = slsa.NewVerifier(
+		sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false),
+		slsa.Policy{Builders: []slsa.Builder{{ID: "https://github.com/grafeas/builder"}}},
+	)
+
+	_, err = client.GetBuildDetail(ctx, i)
+	assert.True(t, voucher.IsNoMetadataError(err), "expected NoMetadataError, got %v", err)
+
+	// build details from the metadata service are preferred.
+	stored := repository.BuildDetail{RepositoryURL: "https://github.com/grafeas/grafeas"}
+
+	metadataClient = new(voucher.MockMetadataClient)
+	metadataClient.On("GetBuildDetail", ctx, i).Return(stored, nil)
+	client.MetadataClient = metadataClient
+
+	buildDetail, err = client.GetBuildDetail(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, stored, buildDetail)
+}
+
+func TestProvenanceVerifierIsLoadedOnce(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+
+	keyFile := filepath.Join(t.TempDir(), "slsa.pub")
+	require.NoError(t, os.WriteFile(keyFile, fixture.PublicKeyPEM(), 0600))
+
+	viper.Set("slsa", map[string]interface{}{
+		"keys":     []string{keyFile},
+		"builders": []map[string]interface{}{{"id": "https://tekton.dev/chains/v2"}},
+	})
+	defer viper.Set("slsa", nil)
+	defer func() { slsaVerifier = nil }()
+
+	require.NoError(t, registerSLSACheck())
+	require.NotNil(t, slsaVerifier)
+
+	// check suites use the verifier from registration, without reading the
+	// key again.
+	require.NoError(t, os.Remove(keyFile))
+
+	metadataClient := new(voucher.MockMetadataClient)
+	client, ok := newProvenanceMetadataClient(metadataClient, nil).(*provenanceMetadataClient)
+	require.True(t, ok)
+	assert.Same(t, slsaVerifier, client.verifier)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/grafeas/voucher/v2/signer/vault"
+)
+
+// vaultKey is a Transit key configured in `[[vault_keys]]`.
+type vaultKey struct {
+	Check   string `mapstructure:"check"`
+	Name    string `mapstructure:"name"`
+	Algo    string `mapstructure:"algo"`
+	Padding string `mapstructure:"padding"`
+}
+
+// getVaultKeyRing creates a Vault Transit signer for the server configured in
+// the "vault" block, with the keys configured in `[[vault_keys]]`. Tokens and
+// AppRole secret IDs are read from the secrets' "vault" block.
+func getVaultKeyRing(secrets *Secrets) (*vault.Signer, error) {
+	var keys []vaultKey
+	if err := viper.UnmarshalKey("vault_keys", &keys); nil != err {
+		return nil, fmt.Errorf("could not read vault_keys: %w", err)
+	}
+
+	if 0 == len(keys) {
+		return nil, errors.New("no vault keys configured")
+	}
+
+	signerKeys := make(map[string]vault.Key, len(keys))
+	for _, key := range keys {
+		signerKeys[key.Check] = vault.Key{
+			Name:    key.Name,
+			Algo:    key.Algo,
+			Padding: key.Padding,
+		}
+	}
+
+	auth := vault.Auth{
+		Method:  viper.GetString("vault.auth_method"),
+		Mount:   viper.GetString("vault.auth_mount"),
+		RoleID:  viper.GetString("vault.role_id"),
+		Role:    viper.GetString("vault.role"),
+		JWTPath: viper.GetString("vault.jwt_path"),
+	}
+
+	if nil != secrets {
+		auth.Token = secrets.Vault.Token
+		auth.SecretID = secrets.Vault.SecretID
+	}
+
+	return vault.NewSigner(vault.Config{
+		Address:   viper.GetString("vault.address"),
+		Namespace: viper.GetString("vault.namespace"),
+		Mount:     viper.GetString("vault.mount"),
+		Auth:      auth,
+	}, signerKeys)
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultSigner(t *testing.T) {
+	var login map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/voucher-approle/login":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&login))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": "token"}})
+		case "/v1/auth/token/revoke-self":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("signer", "vault")
+	defer viper.Set("signer", nil)
+
+	_, err := getVaultKeyRing(nil)
+	assert.EqualError(t, err, "no vault keys configured")
+
+	viper.Set("vault_keys", []map[string]interface{}{{"check": "diy", "name": "diy-attestor", "algo": "SHA256"}})
+	viper.Set("vault.address", server.URL)
+	viper.Set("vault.auth_method", "approle")
+	viper.Set("vault.auth_mount", "voucher-approle")
+	viper.Set("vault.role_id", "role")
+	defer viper.Set("vault_keys", nil)
+	defer viper.Set("vault", nil)
+
+	keyring := NewAttestationSigner(&Secrets{Vault: VaultSecrets{SecretID: "secret"}})
+	require.NotNil(t, keyring)
+	assert.Equal(t, map[string]interface{}{"role_id": "role", "secret_id": "secret"}, login)
+	assert.NoError(t, keyring.Close())
+
+	viper.Set("vault_keys", []map[string]interface{}{{"check": "diy", "name": "diy-attestor", "algo": "MD5"}})
+	assert.Nil(t, NewAttestationSigner(nil))
+}
+
+func TestVaultSignerIsShared(t *testing.T) {
+	var logins, revokes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			atomic.AddInt32(&logins, 1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": "token"}})
+		case "/v1/auth/token/revoke-self":
+			atomic.AddInt32(&revokes, 1)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// start without a signer shared by an earlier test.
+	require.NoError(t, CloseMetadataClients())
+
+	viper.Set("signer", "vault")
+	viper.Set("metadata_client", "local")
+	viper.Set("vault_keys", []map[string]interface{}{{"check": "diy", "name": "diy-attestor", "algo": "SHA256"}})
+	viper.Set("vault.address", server.URL)
+	viper.Set("vault.auth_method", "approle")
+	viper.Set("vault.role_id", "role")
+	defer viper.Set("signer", nil)
+	defer viper.Set("metadata_client", nil)
+	defer viper.Set("vault_keys", nil)
+	defer viper.Set("vault", nil)
+
+	secrets := &Secrets{Vault: VaultSecrets{SecretID: "secret"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			client, err := NewMetadataClient(context.Background(), secrets)
+			require.NoError(t, err)
+			client.Close()
+
+			// verifying attestations uses the same signer.
+			assert.NotNil(t, NewAttestationVerifier(secrets))
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&logins), "vault should be logged in to once")
+	assert.EqualValues(t, 0, atomic.LoadInt32(&revokes), "the token shouldn't be revoked while it's shared")
+
+	require.NoError(t, CloseMetadataClients())
+	assert.EqualValues(t, 1, atomic.LoadInt32(&revokes))
+}
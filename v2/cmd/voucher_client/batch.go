@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/docker/distribution/reference"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// checkBatch checks the passed images with the voucher server, in one request.
+func checkBatch(ctx context.Context, client voucher.BatchInterface, check string, canonicalRefs []reference.Canonical) error {
+	for _, canonicalRef := range canonicalRefs {
+		fmt.Printf("Submitting image to Voucher: %s\n", canonicalRef.String())
+	}
+
+	batchResp, err := client.CheckBatch(ctx, check, canonicalRefs)
+	if nil != err {
+		return fmt.Errorf("signing images failed: %s", err)
+	}
+
+	for i := range batchResp.Responses {
+		fmt.Printf("\n%s: ", batchResp.Responses[i].Image)
+		fmt.Println(formatResponse(&batchResp.Responses[i]))
+	}
+
+	if !batchResp.Success {
+		return errImageCheckFailed
+	}
+
+	return nil
+}
+
+// LookupAndCheckBatch looks up each of the passed images, and checks them
+// with the Voucher server in one request.
+func LookupAndCheckBatch(args []string) {
+	var err error
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	client, err := getVoucherClient(ctx)
+	if nil != err {
+		errorf("creating client failed: %s", err)
+		os.Exit(1)
+	}
+
+	canonicalRefs := make([]reference.Canonical, 0, len(args))
+	for _, image := range args {
+		canonicalRef, err := lookupCanonical(ctx, image)
+		if nil != err {
+			errorf("getting canonical reference failed: %s", err)
+			os.Exit(1)
+		}
+		canonicalRefs = append(canonicalRefs, canonicalRef)
+	}
+
+	err = checkBatch(ctx, client, getCheck(), canonicalRefs)
+	if nil != err {
+		errorf("checking images with voucher failed: %s", err)
+		os.Exit(1)
+	}
+}
@@ -25,7 +25,7 @@ func getCheck() string {
 	return defaultConfig.Check
 }
 
-func getVoucherClient(ctx context.Context) (voucher.Interface, error) {
+func getVoucherClient(ctx context.Context) (voucher.BatchInterface, error) {
 	options := []client.Option{
 		client.WithUserAgent(fmt.Sprintf("voucher-client/%s", version)),
 	}
@@ -21,11 +21,14 @@ var rootCmd = &cobra.Command{
 	Short: "voucher_client sends images to a Voucher server to be reviewed",
 	Long: `voucher_client is a frontend for Voucher server, which allows users to send 
 images for analysis. It automatically resolves tags to digests when it encounters
-them.`,
+them. Several images can be checked at once, in one batch request.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return errors.New("missing the image to check")
 		}
+		if verify && len(args) > 1 {
+			return errors.New("only one image can be verified at a time")
+		}
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -33,6 +36,10 @@ them.`,
 			LookupAndVerify(args)
 			return
 		}
+		if len(args) > 1 {
+			LookupAndCheckBatch(args)
+			return
+		}
 		LookupAndCheck(args)
 	},
 }
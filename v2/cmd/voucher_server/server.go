@@ -4,11 +4,13 @@ import (
 	"io"
 	"log"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/grafeas/voucher/v2/cmd/config"
 	"github.com/grafeas/voucher/v2/server"
+	"github.com/grafeas/voucher/v2/subscriber"
 )
 
 var serverCmd = &cobra.Command{
@@ -23,6 +25,22 @@ var serverCmd = &cobra.Command{
 			RequireAuth: viper.GetBool("server.require_auth"),
 			Username:    viper.GetString("server.username"),
 			PassHash:    viper.GetString("server.password"),
+
+			JobWorkers:   viper.GetInt("server.job_workers"),
+			JobQueueSize: viper.GetInt("server.job_queue_size"),
+			JobTTL:       viper.GetInt("server.job_ttl"),
+
+			BatchConcurrency: viper.GetInt("server.batch_concurrency"),
+			BatchSize:        viper.GetInt("server.batch_size"),
+
+			AdmissionFailOpen:         viper.GetBool("admission.fail_open"),
+			AdmissionExemptNamespaces: viper.GetStringSlice("admission.exempt_namespaces"),
+			AdmissionBreakGlass:       viper.GetString("admission.break_glass_annotation"),
+			AdmissionRequireDigest:    true,
+		}
+
+		if viper.IsSet("admission.require_digest") {
+			serverConfig.AdmissionRequireDigest = viper.GetBool("admission.require_digest")
 		}
 
 		secrets, err := config.ReadSecrets()
@@ -37,7 +55,11 @@ var serverCmd = &cobra.Command{
 			defer closer.Close()
 		}
 
-		config.RegisterDynamicChecks()
+		if err := config.RegisterDynamicChecks(); err != nil {
+			log.Fatalf("Error registering checks: %v", err)
+		}
+
+		defer config.CloseMetadataClients()
 
 		if config.IsCloudRun() {
 			serverConfig.RequireAuth = false
@@ -45,10 +67,57 @@ var serverCmd = &cobra.Command{
 
 		voucherServer := server.NewServer(&serverConfig, secrets, metricsClient)
 
+		decisions, err := config.NewDecisionCache(secrets)
+		if err != nil {
+			log.Fatalf("Error configuring decision cache: %v", err)
+		} else if decisions != nil {
+			defer decisions.Close()
+			voucherServer.SetDecisionCache(decisions)
+		}
+
+		notifier, err := config.NewNotifier(secrets)
+		if err != nil {
+			log.Fatalf("Error configuring notifications: %v", err)
+		} else if notifier != nil {
+			defer notifier.Close()
+			voucherServer.SetNotifier(notifier)
+		}
+
 		for groupName, checks := range config.GetRequiredChecksFromConfig() {
 			voucherServer.SetCheckGroup(groupName, checks)
 		}
 
+		policies, err := config.GetPoliciesFromConfig()
+		if err != nil {
+			log.Fatalf("Error loading policies: %v", err)
+		}
+
+		for groupName, groupPolicy := range policies {
+			voucherServer.SetPolicy(groupName, groupPolicy)
+		}
+
+		if viper.GetString("pubsub.push.audience") != "" || viper.GetString("pubsub.push.service_account") != "" {
+			subscriberConfig := subscriber.Config{
+				RequiredChecks:     config.GetRequiredChecksFromConfig()["all"],
+				DryRun:             viper.GetBool("dryrun"),
+				Timeout:            serverConfig.Timeout,
+				Notifier:           notifier,
+				PushAudience:       viper.GetString("pubsub.push.audience"),
+				PushServiceAccount: viper.GetString("pubsub.push.service_account"),
+			}
+			if err := subscriberConfig.ValidatePush(); err != nil {
+				log.Fatalf("Error configuring pub/sub push: %v", err)
+			}
+			voucherSubscriber := subscriber.NewSubscriber(&subscriberConfig, secrets, metricsClient, logrus.StandardLogger())
+
+			voucherServer.AddRoute(server.Route{
+				Name:        "Pub/Sub Push",
+				Method:      "POST",
+				Path:        subscriber.PushPath,
+				HandlerFunc: voucherSubscriber.HandlePush,
+			})
+		}
+
 		voucherServer.Serve()
 	},
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -17,7 +19,19 @@ var subscriberCmd = &cobra.Command{
 	Use:   "subscriber",
 	Short: "Runs the subscriber",
 	Long: `Run the go subscriber that automatically vouches for images on the specified subscription and project
-	use --project=<project> --subscription=<subscription> to specify the project and subscription you want the subscriber to pull from`,
+	use --project=<project> --subscription=<subscription> to specify the project and subscription you want the subscriber to pull from
+	or use --listen=<address> to receive notifications from container registries instead`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if "" != viper.GetString("registry_webhook.address") {
+			return nil
+		}
+
+		if "" == viper.GetString("pubsub.project") || "" == viper.GetString("pubsub.subscription") {
+			return errors.New("--project and --subscription are required, unless --listen is set")
+		}
+
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		var log = &logrus.Logger{
 			Out:       os.Stderr,
@@ -38,7 +52,11 @@ var subscriberCmd = &cobra.Command{
 			defer closer.Close()
 		}
 
-		config.RegisterDynamicChecks()
+		if err := config.RegisterDynamicChecks(); err != nil {
+			log.Fatalf("error registering checks: %s", err)
+		}
+
+		defer config.CloseMetadataClients()
 
 		subscriberConfig := subscriber.Config{
 			Project:        viper.GetString("pubsub.project"),
@@ -46,9 +64,46 @@ var subscriberCmd = &cobra.Command{
 			RequiredChecks: config.GetRequiredChecksFromConfig()["all"],
 			DryRun:         viper.GetBool("dryrun"),
 			Timeout:        viper.GetInt("pubsub.timeout"),
+
+			MaxAttempts:     viper.GetInt("pubsub.max_attempts"),
+			MinBackoff:      time.Duration(viper.GetInt("pubsub.min_backoff")) * time.Second,
+			MaxBackoff:      time.Duration(viper.GetInt("pubsub.max_backoff")) * time.Second,
+			DeadLetterTopic: viper.GetString("pubsub.dead_letter_topic"),
+
+			WebhookAddress:   viper.GetString("registry_webhook.address"),
+			WebhookWorkers:   viper.GetInt("registry_webhook.workers"),
+			WebhookQueueSize: viper.GetInt("registry_webhook.queue_size"),
+
+			PushAudience:       viper.GetString("pubsub.push.audience"),
+			PushServiceAccount: viper.GetString("pubsub.push.service_account"),
 		}
+
+		if err := subscriberConfig.ValidatePush(); nil != err {
+			log.Fatalf("error configuring pub/sub push: %s", err)
+		}
+
+		if secrets != nil {
+			subscriberConfig.WebhookSecret = secrets.RegistryWebhook.Secret
+		}
+
+		notifier, err := config.NewNotifier(secrets)
+		if err != nil {
+			log.Fatalf("error configuring notifications: %s", err)
+		} else if notifier != nil {
+			defer notifier.Close()
+			subscriberConfig.Notifier = notifier
+		}
+
 		voucherSubscriber := subscriber.NewSubscriber(&subscriberConfig, secrets, metricsClient, log)
 
+		if "" != subscriberConfig.WebhookAddress {
+			err = voucherSubscriber.ServeWebhooks(context.Background())
+			if err != nil {
+				log.Errorf("couldn't serve registry webhooks: %s", err)
+			}
+			return
+		}
+
 		err = voucherSubscriber.Subscribe(context.Background())
 		if err != nil {
 			log.Errorf("couldn't pull pub/sub messages: %s", err)
@@ -59,13 +114,15 @@ var subscriberCmd = &cobra.Command{
 func init() {
 	cobra.OnInitialize(config.InitConfig)
 
-	subscriberCmd.Flags().StringP("project", "p", "", "pub/sub project that has the subsciprion (required)")
-	_ = subscriberCmd.MarkFlagRequired("project")
+	subscriberCmd.Flags().StringP("project", "p", "", "pub/sub project that has the subsciprion (required unless --listen is set)")
 	viper.BindPFlag("pubsub.project", subscriberCmd.Flags().Lookup("project"))
-	subscriberCmd.Flags().StringP("subscription", "s", "", "pub/sub topic subscription (required)")
-	_ = subscriberCmd.MarkFlagRequired("subscription")
+	subscriberCmd.Flags().StringP("subscription", "s", "", "pub/sub topic subscription (required unless --listen is set)")
 	viper.BindPFlag("pubsub.subscription", subscriberCmd.Flags().Lookup("subscription"))
+	subscriberCmd.Flags().StringP("listen", "l", "", "address to receive container registry webhooks on, such as \":8080\"")
+	viper.BindPFlag("registry_webhook.address", subscriberCmd.Flags().Lookup("listen"))
 	subscriberCmd.Flags().StringVarP(&config.FileName, "config", "c", "", "path to config")
 	subscriberCmd.Flags().IntP("timeout", "", 240, "number of seconds that should be dedicated to a Voucher call")
 	viper.BindPFlag("pubsub.timeout", subscriberCmd.Flags().Lookup("timeout"))
+	subscriberCmd.Flags().StringP("dead-letter-topic", "", "", "pub/sub topic that messages which can't be handled are published to")
+	viper.BindPFlag("pubsub.dead_letter_topic", subscriberCmd.Flags().Lookup("dead-letter-topic"))
 }
@@ -20,6 +20,11 @@ func OccurrenceToAttestation(checkName string, occ *grafeas.Occurrence) voucher.
 
 	signedAttestation.Body = string(attestationDetails.GetSerializedPayload())
 
+	if signatures := attestationDetails.GetSignatures(); len(signatures) > 0 {
+		signedAttestation.Signature = string(signatures[0].GetSignature())
+		signedAttestation.KeyID = signatures[0].GetPublicKeyId()
+	}
+
 	return signedAttestation
 }
 
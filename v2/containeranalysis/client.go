@@ -13,7 +13,6 @@ import (
 	grafeas "google.golang.org/genproto/googleapis/grafeas/v1"
 
 	voucher "github.com/grafeas/voucher/v2"
-	"github.com/grafeas/voucher/v2/attestation"
 	"github.com/grafeas/voucher/v2/docker/uri"
 	"github.com/grafeas/voucher/v2/repository"
 	"github.com/grafeas/voucher/v2/signer"
@@ -34,14 +33,10 @@ func (g *Client) CanAttest() bool {
 	return nil != g.keyring
 }
 
-// NewPayloadBody returns a payload body appropriate for this MetadataClient.
-func (g *Client) NewPayloadBody(ref reference.Canonical) (string, error) {
-	payload, err := attestation.NewPayload(ref).ToString()
-	if err != nil {
-		return "", err
-	}
-
-	return payload, err
+// NewPayloadBody returns a payload body in the requested format for this
+// MetadataClient.
+func (g *Client) NewPayloadBody(ctx context.Context, ref reference.Canonical, request voucher.PayloadRequest) (string, error) {
+	return voucher.NewPayloadBody(ctx, g, ref, request)
 }
 
 // AddAttestationToImage adds a new attestation with the passed Attestation
@@ -34,8 +34,14 @@ func getSeverity(severity string) voucher.Severity {
 func OccurrenceToVulnerability(occ *grafeas.Occurrence) voucher.Vulnerability {
 	vulnDetails := occ.GetDetails().(*grafeas.Occurrence_Vulnerability).Vulnerability
 
-	return voucher.Vulnerability{
+	vuln := voucher.Vulnerability{
 		Name:     strings.Replace(occ.GetNoteName(), vulProject, "", 1),
 		Severity: getSeverity(grafeas.Severity_name[int32(vulnDetails.EffectiveSeverity)]),
 	}
+
+	if packageIssues := vulnDetails.GetPackageIssue(); len(packageIssues) > 0 {
+		vuln.Package = packageIssues[0].GetAffectedPackage()
+	}
+
+	return vuln
 }
@@ -0,0 +1,11 @@
+package voucher
+
+import "context"
+
+// DetailedCheck represents a Voucher check that can describe how it reached
+// its result. The returned details are stored in the CheckResult's
+// CheckDetails.
+type DetailedCheck interface {
+	Check
+	CheckWithDetails(ctx context.Context, i ImageData) (bool, interface{}, error)
+}
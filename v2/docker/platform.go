@@ -0,0 +1,221 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/reference"
+)
+
+// unknownPlatform is the platform value used by BuildKit for the attestation
+// manifests it stores alongside the images in an index. Those entries are not
+// runnable images and are skipped.
+const unknownPlatform = "unknown"
+
+// Platform describes the platform an image was built for.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// ParsePlatform parses a platform in the "os/architecture[/variant]" format
+// (eg. "linux/arm64/v8"). Returns a Platform or an error if the platform is
+// malformed.
+func ParsePlatform(platform string) (Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Platform{}, fmt.Errorf("invalid platform %q, must be os/architecture[/variant]", platform)
+	}
+
+	for _, part := range parts {
+		if "" == part {
+			return Platform{}, fmt.Errorf("invalid platform %q, must be os/architecture[/variant]", platform)
+		}
+	}
+
+	p := Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}
+
+	if 3 == len(parts) {
+		p.Variant = parts[2]
+	}
+
+	return p, nil
+}
+
+// String returns the platform in the "os/architecture[/variant]" format.
+func (p Platform) String() string {
+	if "" == p.OS && "" == p.Architecture {
+		return ""
+	}
+
+	if "" == p.Variant {
+		return p.OS + "/" + p.Architecture
+	}
+
+	return p.OS + "/" + p.Architecture + "/" + p.Variant
+}
+
+// Matches returns true if the passed Platform is the same as this one. A
+// Platform without a variant matches every variant of the same OS and
+// architecture.
+func (p Platform) Matches(other Platform) bool {
+	if p.OS != other.OS || p.Architecture != other.Architecture {
+		return false
+	}
+
+	return "" == p.Variant || p.Variant == other.Variant
+}
+
+// PlatformImage is a single platform image, either the only image behind a
+// reference or one of the images in a manifest list or OCI index.
+type PlatformImage struct {
+	Platform  Platform
+	Reference reference.Canonical
+}
+
+// RequestPlatformImages requests the manifest for the passed reference and
+// returns the images it describes. If the reference points at a manifest list
+// or OCI index, a PlatformImage is returned for every platform in it.
+// Otherwise a single PlatformImage with an empty Platform and the passed
+// reference is returned.
+func RequestPlatformImages(client *http.Client, ref reference.Canonical) ([]PlatformImage, error) {
+	manifest, err := RequestManifest(client, ref)
+	if nil != err {
+		return nil, err
+	}
+
+	list, ok := manifest.(*manifestlist.DeserializedManifestList)
+	if !ok {
+		return []PlatformImage{{Reference: ref}}, nil
+	}
+
+	images := make([]PlatformImage, 0, len(list.Manifests))
+	for _, descriptor := range list.Manifests {
+		if unknownPlatform == descriptor.Platform.OS && unknownPlatform == descriptor.Platform.Architecture {
+			continue
+		}
+
+		platformRef, err := reference.WithDigest(reference.TrimNamed(ref), descriptor.Digest)
+		if nil != err {
+			return nil, fmt.Errorf("invalid manifest for platform %s/%s: %w", descriptor.Platform.OS, descriptor.Platform.Architecture, err)
+		}
+
+		images = append(images, PlatformImage{
+			Platform: Platform{
+				OS:           descriptor.Platform.OS,
+				Architecture: descriptor.Platform.Architecture,
+				Variant:      descriptor.Platform.Variant,
+			},
+			Reference: platformRef,
+		})
+	}
+
+	if 0 == len(images) {
+		return nil, fmt.Errorf("manifest list for %s does not contain any images", ref)
+	}
+
+	return images, nil
+}
+
+// isSingleImage returns true if the passed images are the result of
+// requesting a reference that isn't a manifest list.
+func isSingleImage(images []PlatformImage) bool {
+	return 1 == len(images) && "" == images[0].Platform.String()
+}
+
+// SelectPlatforms filters the passed images down to the passed platforms. If
+// no platforms are passed, all of the images are returned. Images without a
+// Platform (images that aren't part of a manifest list) are always returned.
+// Returns an error if one of the platforms is not in the image.
+func SelectPlatforms(images []PlatformImage, platforms []Platform) ([]PlatformImage, error) {
+	if 0 == len(platforms) || isSingleImage(images) {
+		return images, nil
+	}
+
+	selected := make([]PlatformImage, 0, len(platforms))
+	for _, platform := range platforms {
+		found := false
+		for _, image := range images {
+			if platform.Matches(image.Platform) {
+				selected = append(selected, image)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("image does not support platform %s", platform)
+		}
+	}
+
+	return selected, nil
+}
+
+// ParsePlatforms parses each of the passed platforms with ParsePlatform.
+func ParsePlatforms(platforms []string) ([]Platform, error) {
+	parsed := make([]Platform, 0, len(platforms))
+	for _, platform := range platforms {
+		p, err := ParsePlatform(platform)
+		if nil != err {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+
+	return parsed, nil
+}
+
+// PlatformResult describes the result of checking a single platform image.
+type PlatformResult struct {
+	Platform string `json:"platform"`
+	Digest   string `json:"digest"`
+	Success  bool   `json:"success"`
+	Err      string `json:"error,omitempty"`
+}
+
+// CheckPlatforms runs the passed function against each of the passed images.
+// All of the images must pass for CheckPlatforms to return true. If the images
+// come from a manifest list, a PlatformResult is returned for each of them,
+// and any errors are combined into one error naming the failing platforms.
+// Otherwise the result of the function is returned as is, without any
+// PlatformResults.
+func CheckPlatforms(images []PlatformImage, check func(ref reference.Canonical) (bool, error)) (bool, []PlatformResult, error) {
+	if isSingleImage(images) {
+		ok, err := check(images[0].Reference)
+		return ok, nil, err
+	}
+
+	success := true
+	results := make([]PlatformResult, 0, len(images))
+	errs := make([]string, 0)
+
+	for _, image := range images {
+		ok, err := check(image.Reference)
+
+		result := PlatformResult{
+			Platform: image.Platform.String(),
+			Digest:   image.Reference.Digest().String(),
+			Success:  ok && nil == err,
+		}
+
+		if nil != err {
+			result.Err = err.Error()
+			errs = append(errs, fmt.Sprintf("platform %s: %s", result.Platform, err))
+		}
+
+		success = success && result.Success
+		results = append(results, result)
+	}
+
+	if 0 < len(errs) {
+		return false, results, errors.New(strings.Join(errs, "; "))
+	}
+
+	return success, results, nil
+}
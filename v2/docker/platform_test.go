@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+func TestParsePlatform(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Platform
+		err      bool
+	}{
+		{input: "linux/amd64", expected: Platform{OS: "linux", Architecture: "amd64"}},
+		{input: "linux/arm64/v8", expected: Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		{input: "linux", err: true},
+		{input: "linux/", err: true},
+		{input: "linux/arm/v7/extra", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			platform, err := ParsePlatform(c.input)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, platform)
+			assert.Equal(t, c.input, platform.String())
+		})
+	}
+}
+
+func TestRequestPlatformImages(t *testing.T) {
+	ref := vtesting.NewTestManifestListReference(t)
+
+	client, server := vtesting.PrepareDockerTest(t, ref)
+	defer server.Close()
+
+	images, err := RequestPlatformImages(client, ref)
+	require.NoError(t, err)
+	require.Len(t, images, 3)
+
+	assert.Equal(t, "linux/arm64", images[0].Platform.String())
+	assert.Equal(t, "windows/amd64", images[1].Platform.String())
+	assert.Equal(t, "linux/amd64", images[2].Platform.String())
+	assert.Equal(
+		t,
+		"localhost/path/to/image@sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da",
+		images[2].Reference.String(),
+	)
+}
+
+func TestRequestPlatformImagesOCIIndex(t *testing.T) {
+	ref := vtesting.NewTestOCIIndexReference(t)
+
+	client, server := vtesting.PrepareDockerTest(t, ref)
+	defer server.Close()
+
+	images, err := RequestPlatformImages(client, ref)
+	require.NoError(t, err)
+
+	// the attestation manifest should be skipped.
+	require.Len(t, images, 1)
+	assert.Equal(t, "linux/amd64", images[0].Platform.String())
+	assert.Equal(t, vtesting.NewTestOCIReference(t).String(), images[0].Reference.String())
+}
+
+func TestRequestPlatformImagesSingleImage(t *testing.T) {
+	ref := vtesting.NewTestReference(t)
+
+	client, server := vtesting.PrepareDockerTest(t, ref)
+	defer server.Close()
+
+	images, err := RequestPlatformImages(client, ref)
+	require.NoError(t, err)
+	assert.Equal(t, []PlatformImage{{Reference: ref}}, images)
+}
+
+func TestSelectPlatforms(t *testing.T) {
+	images := []PlatformImage{
+		{Platform: Platform{OS: "linux", Architecture: "amd64"}},
+		{Platform: Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+	}
+
+	selected, err := SelectPlatforms(images, nil)
+	require.NoError(t, err)
+	assert.Equal(t, images, selected)
+
+	selected, err = SelectPlatforms(images, []Platform{{OS: "linux", Architecture: "arm64"}})
+	require.NoError(t, err)
+	assert.Equal(t, images[1:], selected)
+
+	_, err = SelectPlatforms(images, []Platform{{OS: "windows", Architecture: "amd64"}})
+	assert.EqualError(t, err, "image does not support platform windows/amd64")
+
+	single := []PlatformImage{{}}
+	selected, err = SelectPlatforms(single, []Platform{{OS: "windows", Architecture: "amd64"}})
+	require.NoError(t, err)
+	assert.Equal(t, single, selected)
+}
+
+func TestCheckPlatforms(t *testing.T) {
+	ref := vtesting.NewTestManifestListReference(t)
+
+	client, server := vtesting.PrepareDockerTest(t, ref)
+	defer server.Close()
+
+	images, err := RequestPlatformImages(client, ref)
+	require.NoError(t, err)
+
+	ok, results, err := CheckPlatforms(images, func(ref reference.Canonical) (bool, error) {
+		switch ref.Digest().String() {
+		case "sha256:b248c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da":
+			return false, errors.New("runs as root")
+		default:
+			return true, nil
+		}
+	})
+
+	assert.False(t, ok)
+	assert.EqualError(t, err, "platform linux/arm64: runs as root")
+	require.Len(t, results, 3)
+	assert.Equal(t, PlatformResult{
+		Platform: "linux/arm64",
+		Digest:   "sha256:b248c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da",
+		Err:      "runs as root",
+	}, results[0])
+	assert.True(t, results[1].Success)
+	assert.True(t, results[2].Success)
+}
+
+func TestCheckPlatformsSingleImage(t *testing.T) {
+	ref := vtesting.NewTestReference(t)
+
+	ok, results, err := CheckPlatforms([]PlatformImage{{Reference: ref}}, func(reference.Canonical) (bool, error) {
+		return true, nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, results)
+}
@@ -0,0 +1,250 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/grafeas/voucher/v2/docker/uri"
+)
+
+const (
+	referrersType = "referrers"
+	blobType      = "blob"
+
+	mediaTypeOCIIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// Descriptor describes content stored in a registry, such as an artifact
+// which refers to an image.
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       digest.Digest     `json:"digest"`
+	Size         int64             `json:"size"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// artifactManifest is an OCI manifest describing an artifact.
+type artifactManifest struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Config       Descriptor        `json:"config"`
+	Layers       []Descriptor      `json:"layers"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// referrersIndex is the OCI index returned by the referrers API.
+type referrersIndex struct {
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// RequestReferrers requests the artifacts which refer to the passed image and
+// have the passed artifact type. Registries which do not support the OCI
+// referrers API are queried using the referrers tag schema instead
+// (eg. "sha256-<digest>"). Returns an empty slice if there are no referrers.
+func RequestReferrers(client *http.Client, ref reference.Canonical, artifactType string) ([]Descriptor, error) {
+	index, found, err := requestReferrersIndex(client, uri.GetReferrersURI(ref, artifactType))
+	if nil == err && !found {
+		index, _, err = requestReferrersIndex(client, uri.GetManifestURI(ref, referrersTag(ref.Digest())))
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	referrers := make([]Descriptor, 0, len(index.Manifests))
+	for _, descriptor := range index.Manifests {
+		if "" == artifactType || descriptor.ArtifactType == artifactType {
+			referrers = append(referrers, descriptor)
+		}
+	}
+
+	return referrers, nil
+}
+
+// RequestArtifact requests the content of the first layer of the artifact
+// described by the passed Descriptor, which is stored in the same repository
+// as the passed image.
+func RequestArtifact(client *http.Client, ref reference.Named, artifact Descriptor) ([]byte, error) {
+	manifest, err := requestArtifactManifest(client, ref, artifact)
+	if nil != err {
+		return nil, err
+	}
+
+	if 0 == len(manifest.Layers) {
+		return nil, NewManifestError(fmt.Errorf("artifact %s has no layers", artifact.Digest))
+	}
+
+	return RequestBlob(client, ref, manifest.Layers[0].Digest)
+}
+
+// RequestArtifactAnnotations requests the annotations of the manifest of the
+// artifact described by the passed Descriptor. Unlike the annotations in the
+// Descriptor, which the registry copies into its referrers index, these are
+// covered by the artifact's digest.
+func RequestArtifactAnnotations(client *http.Client, ref reference.Named, artifact Descriptor) (map[string]string, error) {
+	manifest, err := requestArtifactManifest(client, ref, artifact)
+	if nil != err {
+		return nil, err
+	}
+
+	return manifest.Annotations, nil
+}
+
+// requestArtifactManifest requests the manifest of the artifact described by
+// the passed Descriptor, checking that it matches the Descriptor's digest.
+func requestArtifactManifest(client *http.Client, ref reference.Named, artifact Descriptor) (*artifactManifest, error) {
+	request, err := http.NewRequest(http.MethodGet, uri.GetManifestURI(ref, string(artifact.Digest)), nil)
+	if nil != err {
+		return nil, err
+	}
+
+	request.Header.Add("Accept", mediaTypeOCIManifest)
+
+	b, err := doRequest(client, request, manifestType, artifact.Digest)
+	if nil != err {
+		return nil, err
+	}
+
+	var manifest artifactManifest
+	if err = json.Unmarshal(b, &manifest); nil != err {
+		return nil, NewManifestError(err)
+	}
+
+	return &manifest, nil
+}
+
+// RequestLayers requests the OCI manifest with the passed tag from the
+// repository of the passed image, and returns the descriptors of its layers.
+// This is used to read artifacts stored under tags derived from an image's
+// digest (eg. cosign's "sha256-<digest>.sig"). Returns an empty slice if the
+// tag does not exist.
+func RequestLayers(client *http.Client, ref reference.Named, tag string) ([]Descriptor, error) {
+	request, err := http.NewRequest(http.MethodGet, uri.GetManifestURI(ref, tag), nil)
+	if nil != err {
+		return nil, err
+	}
+
+	request.Header.Add("Accept", mediaTypeOCIManifest)
+	request.Header.Add("Accept", schema2.MediaTypeManifest)
+
+	resp, err := client.Do(request)
+	if nil != err {
+		return nil, &APIError{callType: manifestType, err: err}
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if nil != err {
+		return nil, &APIError{callType: manifestType, err: err}
+	}
+
+	if http.StatusNotFound == resp.StatusCode {
+		return []Descriptor{}, nil
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, &APIError{callType: manifestType, requestStatus: resp.Status, requestBody: string(b)}
+	}
+
+	var manifest artifactManifest
+	if err = json.Unmarshal(b, &manifest); nil != err {
+		return nil, NewManifestError(err)
+	}
+
+	return manifest.Layers, nil
+}
+
+// RequestBlob requests the blob with the passed digest from the repository of
+// the passed image, and verifies that its content matches the digest.
+func RequestBlob(client *http.Client, ref reference.Named, blobDigest digest.Digest) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, uri.GetBlobURI(ref, blobDigest), nil)
+	if nil != err {
+		return nil, err
+	}
+
+	return doRequest(client, request, blobType, blobDigest)
+}
+
+// requestReferrersIndex requests the referrers index from the passed URI.
+// Returns false if the index does not exist.
+func requestReferrersIndex(client *http.Client, indexURI string) (referrersIndex, bool, error) {
+	var index referrersIndex
+
+	request, err := http.NewRequest(http.MethodGet, indexURI, nil)
+	if nil != err {
+		return index, false, err
+	}
+
+	request.Header.Add("Accept", mediaTypeOCIIndex)
+
+	resp, err := client.Do(request)
+	if nil != err {
+		return index, false, &APIError{callType: referrersType, err: err}
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if nil != err {
+		return index, false, &APIError{callType: referrersType, err: err}
+	}
+
+	if http.StatusNotFound == resp.StatusCode {
+		return index, false, nil
+	}
+
+	if resp.StatusCode >= 300 {
+		return index, false, &APIError{callType: referrersType, requestStatus: resp.Status, requestBody: string(b)}
+	}
+
+	if err = json.Unmarshal(b, &index); nil != err {
+		return index, false, &APIError{callType: referrersType, err: err}
+	}
+
+	return index, true, nil
+}
+
+// doRequest executes the passed request, returning the body of the response
+// after verifying that it matches the expected digest.
+func doRequest(client *http.Client, request *http.Request, callType string, expected digest.Digest) ([]byte, error) {
+	resp, err := client.Do(request)
+	if nil != err {
+		return nil, &APIError{callType: callType, err: err}
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if nil != err {
+		return nil, &APIError{callType: callType, err: err}
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, &APIError{callType: callType, requestStatus: resp.Status, requestBody: string(b)}
+	}
+
+	if err = expected.Validate(); nil != err {
+		return nil, &APIError{callType: callType, err: err}
+	}
+
+	if actual := expected.Algorithm().FromBytes(b); actual != expected {
+		return nil, &APIError{callType: callType, err: fmt.Errorf("digest mismatch, expected %s but got %s", expected, actual)}
+	}
+
+	return b, nil
+}
+
+// referrersTag returns the tag that the referrers of the image with the
+// passed digest are stored under, on registries without the referrers API.
+func referrersTag(imageDigest digest.Digest) string {
+	return strings.Replace(string(imageDigest), ":", "-", 1)
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+const testArtifactType = "application/vnd.example.report+json"
+
+// newReferrersServer creates a registry which serves a single artifact
+// referring to the test image. If referrersAPI is false, the artifact is
+// served using the referrers tag schema instead of the referrers API.
+func newReferrersServer(t *testing.T, referrersAPI bool) (*httptest.Server, Descriptor, []byte) {
+	content := []byte(`{"report":true}`)
+	contentDigest := digest.FromBytes(content)
+
+	manifest, err := json.Marshal(artifactManifest{
+		MediaType:    mediaTypeOCIManifest,
+		ArtifactType: testArtifactType,
+		Config:       Descriptor{MediaType: "application/vnd.oci.empty.v1+json", Digest: digest.FromString("{}"), Size: 2},
+		Layers:       []Descriptor{{MediaType: "application/json", Digest: contentDigest, Size: int64(len(content))}},
+		Annotations:  map[string]string{"org.opencontainers.image.created": "2024-05-01T10:00:00Z"},
+	})
+	require.NoError(t, err)
+
+	artifact := Descriptor{
+		MediaType:    mediaTypeOCIManifest,
+		ArtifactType: testArtifactType,
+		Digest:       digest.FromBytes(manifest),
+		Size:         int64(len(manifest)),
+	}
+
+	index, err := json.Marshal(referrersIndex{
+		Manifests: []Descriptor{
+			{MediaType: mediaTypeOCIManifest, ArtifactType: "application/vnd.example.other", Digest: digest.FromString("other")},
+			artifact,
+		},
+	})
+	require.NoError(t, err)
+
+	indexPath := "/v2/path/to/image/manifests/sha256-b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da"
+	if referrersAPI {
+		indexPath = "/v2/path/to/image/referrers/sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da"
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case indexPath:
+			w.Header().Set("Content-Type", mediaTypeOCIIndex)
+			_, _ = w.Write(index)
+		case "/v2/path/to/image/manifests/" + string(artifact.Digest):
+			w.Header().Set("Content-Type", mediaTypeOCIManifest)
+			_, _ = w.Write(manifest)
+		case "/v2/path/to/image/blobs/" + string(contentDigest):
+			_, _ = w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, artifact, content
+}
+
+func TestRequestReferrers(t *testing.T) {
+	for name, referrersAPI := range map[string]bool{"referrers API": true, "referrers tag": false} {
+		t.Run(name, func(t *testing.T) {
+			server, artifact, content := newReferrersServer(t, referrersAPI)
+
+			client := &http.Client{}
+			require.NoError(t, vtesting.UpdateClient(client, server))
+
+			ref := vtesting.NewTestReference(t)
+
+			referrers, err := RequestReferrers(client, ref, testArtifactType)
+			require.NoError(t, err)
+			assert.Equal(t, []Descriptor{artifact}, referrers)
+
+			b, err := RequestArtifact(client, ref, referrers[0])
+			require.NoError(t, err)
+			assert.Equal(t, content, b)
+
+			annotations, err := RequestArtifactAnnotations(client, ref, referrers[0])
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"org.opencontainers.image.created": "2024-05-01T10:00:00Z"}, annotations)
+		})
+	}
+}
+
+func TestRequestReferrersWithNoReferrers(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, server))
+
+	referrers, err := RequestReferrers(client, vtesting.NewTestReference(t), testArtifactType)
+	require.NoError(t, err)
+	assert.Empty(t, referrers)
+}
+
+func TestRequestBlobWithDigestMismatch(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, server))
+
+	_, err := RequestBlob(client, vtesting.NewTestReference(t), digest.FromString("original"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "digest mismatch")
+}
+
+func TestRequestLayers(t *testing.T) {
+	server, artifact, content := newReferrersServer(t, true)
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, server))
+
+	ref := vtesting.NewTestReference(t)
+
+	layers, err := RequestLayers(client, ref, string(artifact.Digest))
+	require.NoError(t, err)
+	require.Len(t, layers, 1)
+	assert.Equal(t, digest.FromBytes(content), layers[0].Digest)
+
+	layers, err = RequestLayers(client, ref, "sha256-b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da.sig")
+	require.NoError(t, err)
+	assert.Empty(t, layers)
+}
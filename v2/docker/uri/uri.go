@@ -8,6 +8,11 @@ import (
 	digest "github.com/opencontainers/go-digest"
 )
 
+const (
+	dockerHubDomain = "docker.io"
+	dockerHubHost   = "registry-1.docker.io"
+)
+
 // GetTokenURI gets the token URI for the passed repository.
 func GetTokenURI(ref reference.Named) string {
 	hostname := reference.Domain(ref)
@@ -48,8 +53,31 @@ func GetDigestManifestURI(ref reference.Canonical) string {
 	return GetManifestURI(ref, string(ref.Digest()))
 }
 
-func createURL(ref reference.Named, pathSegments ...string) url.URL {
+// GetReferrersURI gets the OCI referrers API URI for the passed image,
+// filtered to the passed artifact type if it is not empty.
+func GetReferrersURI(ref reference.Canonical, artifactType string) string {
+	u := createURL(ref, reference.Path(ref), "referrers", string(ref.Digest()))
+	if "" != artifactType {
+		query := url.Values{}
+		query.Set("artifactType", artifactType)
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// GetRegistryHost gets the host of the registry the passed repository is
+// stored in. Images on Docker Hub use the "docker.io" domain, but are served
+// from "registry-1.docker.io".
+func GetRegistryHost(ref reference.Named) string {
 	hostname := reference.Domain(ref)
+	if hostname == dockerHubDomain {
+		return dockerHubHost
+	}
+	return hostname
+}
+
+func createURL(ref reference.Named, pathSegments ...string) url.URL {
+	hostname := GetRegistryHost(ref)
 
 	var path bytes.Buffer
 	path.WriteString("/v2")
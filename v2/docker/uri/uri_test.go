@@ -15,6 +15,7 @@ const (
 	testBlobURL     = "https://" + testHostname + "/v2/" + testProject + "/blobs/" + testDigest
 	testManifestURL = "https://" + testHostname + "/v2/" + testProject + "/manifests/" + testDigest
 	testTokenURL    = "https://" + testHostname + "/v2/token?scope=repository%3Atest%2Fproject%3A%2A&service=gcr.io"
+	testReferrers   = "https://" + testHostname + "/v2/" + testProject + "/referrers/" + testDigest
 )
 
 func TestGetBaseURI(t *testing.T) {
@@ -32,4 +33,19 @@ func TestGetBaseURI(t *testing.T) {
 	assert.Equal(t, path, testProject)
 	assert.Equal(t, testBlobURL, GetBlobURI(canonicalRef, canonicalRef.Digest()))
 	assert.Equal(t, testManifestURL, GetDigestManifestURI(canonicalRef))
+	assert.Equal(t, testReferrers, GetReferrersURI(canonicalRef, ""))
+	assert.Equal(t, testReferrers+"?artifactType=application%2Fjson", GetReferrersURI(canonicalRef, "application/json"))
+}
+
+func TestGetRegistryHost(t *testing.T) {
+	named, err := reference.ParseNormalizedNamed("alpine")
+	require.NoError(t, err)
+
+	assert.Equal(t, "registry-1.docker.io", GetRegistryHost(named))
+	assert.Equal(t, "https://registry-1.docker.io/v2/library/alpine/manifests/latest", GetManifestURI(named, "latest"))
+
+	named, err = reference.ParseNamed(testHostname + "/" + testProject)
+	require.NoError(t, err)
+
+	assert.Equal(t, testHostname, GetRegistryHost(named))
 }
@@ -103,7 +103,7 @@ func TestNewPayloadBody(t *testing.T) {
 	}
 	for tc, test := range tcs {
 		t.Run(tc, func(t *testing.T) {
-			payload, err := client.NewPayloadBody(test.reference)
+			payload, err := client.NewPayloadBody(context.Background(), test.reference, voucher.PayloadRequest{CheckName: "diy"})
 			expectedPayloadStr, _ := test.expectedPayload.ToString()
 			assert.Equal(t, expectedPayloadStr, payload)
 			require.NoError(t, err)
@@ -165,9 +165,11 @@ func TestGetAttestations(t *testing.T) {
 			expectedResult: []voucher.SignedAttestation{{
 				Attestation: voucher.Attestation{
 					CheckName: "notename",
-					Body:      string(objects.AttestationUnspecified),
-				}},
-			},
+					Body:      "payload",
+				},
+				Signature: "signature",
+				KeyID:     "keyid",
+			}},
 		},
 		"no data": {
 			returnOccs: objects.ListOccurrencesResponse{
@@ -215,6 +217,7 @@ func TestGetVulnerabilities(t *testing.T) {
 			expectedResult: []voucher.Vulnerability{{
 				Name:     "notename",
 				Severity: voucher.NegligibleSeverity,
+				Package:  "package_test",
 			}},
 		},
 		"no vulnerability data": {
@@ -350,7 +353,9 @@ func createAllOccurrences() []objects.Occurrence {
 		{Name: "name2", Resource: &objects.Resource{URI: "https://gcr.io/project/image@sha256:foo"},
 			NoteName: "notename", Kind: &noteKindAtt,
 			Attestation: &objects.AttestationDetails{Attestation: &objects.Attestation{
-				GenericSignedAttestation: &objects.AttestationGenericSigned{ContentType: &contentType}}}},
+				GenericSignedAttestation: &objects.AttestationGenericSigned{ContentType: &contentType,
+					SerializedPayload: []byte("payload"),
+					Signatures:        []objects.Signature{{Signature: []byte("signature"), PublicKeyID: "keyid"}}}}}},
 
 		{Name: "name3", Resource: &objects.Resource{URI: "https://gcr.io/project/image@sha256:foo"},
 			NoteName: "notename", Kind: &noteKindB,
@@ -29,7 +29,17 @@ func (ad *AttestationDetails) AsVoucherAttestation(checkName string) voucher.Sig
 		},
 	}
 
-	signedAttestation.Body = string(*ad.Attestation.GenericSignedAttestation.ContentType)
+	generic := ad.Attestation.GenericSignedAttestation
+	if nil == generic {
+		return signedAttestation
+	}
+
+	signedAttestation.Body = string(generic.SerializedPayload)
+
+	if len(generic.Signatures) > 0 {
+		signedAttestation.Signature = string(generic.Signatures[0].Signature)
+		signedAttestation.KeyID = generic.Signatures[0].PublicKeyID
+	}
 
 	return signedAttestation
 }
@@ -40,7 +50,8 @@ func NewAttestation(signedAttestation voucher.SignedAttestation) *AttestationDet
 	return &AttestationDetails{Attestation: &Attestation{
 		GenericSignedAttestation: &AttestationGenericSigned{
 			Signatures: []Signature{{Signature: []byte(signedAttestation.Signature),
-				PublicKeyID: signedAttestation.KeyID}}, ContentType: &contentType}}}
+				PublicKeyID: signedAttestation.KeyID}}, ContentType: &contentType,
+			SerializedPayload: []byte(signedAttestation.Body)}}}
 }
 
 // Attestation based on
@@ -54,7 +65,7 @@ type Attestation struct {
 type AttestationGenericSigned struct {
 	ContentType       *AttestationSignedContentType `json:"contentType,omitempty"`
 	Signatures        []Signature                   `json:"signatures,omitempty"`
-	SerializedPayload string                        `json:"serializedPayload,omitempty"`
+	SerializedPayload []byte                        `json:"serializedPayload,omitempty"`
 }
 
 // Signature based on
@@ -47,6 +47,10 @@ func (vd *VulnerabilityDetails) AsVoucherVulnerability(noteName, vulProject stri
 
 	vul.Severity = getSeverity(vd.EffectiveSeverity)
 
+	if len(vd.PackageIssue) > 0 && nil != vd.PackageIssue[0].AffectedLocation {
+		vul.Package = vd.PackageIssue[0].AffectedLocation.Package
+	}
+
 	return
 }
 
@@ -2,6 +2,8 @@ package voucher
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/docker/distribution/reference"
 )
@@ -25,3 +27,16 @@ func NewImageData(url string) (ImageData, error) {
 
 	return canonicalRef, nil
 }
+
+// MatchesImagePath returns true if the glob pattern matches the passed image
+// path, or any of its parents. For example, "gcr.io/prod-*" matches
+// "gcr.io/prod-app/image".
+func MatchesImagePath(pattern, name string) bool {
+	segments := strings.Split(name, "/")
+	for i := range segments {
+		if ok, _ := path.Match(pattern, strings.Join(segments[:i+1], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
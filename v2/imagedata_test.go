@@ -22,3 +22,11 @@ func TestNewImageData(t *testing.T) {
 	_, err = NewImageData("gcr.io/path/to/image@sha256:97db2bc359ccc94d3b2d6f5daa4173e9e91c513b0dcd961408adbb95ec5e5ce5")
 	assert.NoError(err)
 }
+
+func TestMatchesImagePath(t *testing.T) {
+	assert.True(t, MatchesImagePath("gcr.io/prod-*", "gcr.io/prod-team/app"))
+	assert.True(t, MatchesImagePath("gcr.io/prod-team/app", "gcr.io/prod-team/app"))
+	assert.True(t, MatchesImagePath("gcr.io/*/app", "gcr.io/prod-team/app"))
+	assert.False(t, MatchesImagePath("gcr.io/prod-*", "gcr.io/staging/prod-app"))
+	assert.False(t, MatchesImagePath("gcr.io/prod-team/app", "gcr.io/prod-team"))
+}
@@ -12,3 +12,9 @@ type Interface interface {
 	Check(ctx context.Context, check string, image reference.Canonical) (Response, error)
 	Verify(ctx context.Context, check string, image reference.Canonical) (Response, error)
 }
+
+// BatchInterface is an Interface which can also check many images in one call.
+type BatchInterface interface {
+	Interface
+	CheckBatch(ctx context.Context, check string, images []reference.Canonical) (BatchResponse, error)
+}
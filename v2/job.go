@@ -0,0 +1,39 @@
+package voucher
+
+import "time"
+
+// JobStatus is the state of an asynchronous check Job.
+type JobStatus string
+
+const (
+	// JobQueued is the status of a Job that is waiting for a worker.
+	JobQueued JobStatus = "queued"
+	// JobRunning is the status of a Job whose checks are being run.
+	JobRunning JobStatus = "running"
+	// JobDone is the status of a Job that has finished, and has a Response.
+	JobDone JobStatus = "done"
+	// JobFailed is the status of a Job that could not be run.
+	JobFailed JobStatus = "failed"
+)
+
+// Finished returns true if a Job with this status will not change again.
+func (status JobStatus) Finished() bool {
+	return JobDone == status || JobFailed == status
+}
+
+// Job describes an asynchronous run of a check or check group, as returned by
+// the Voucher API. Results holds the result of each check that has finished so
+// far, and Response is set once the Job is done.
+type Job struct {
+	ID        string        `json:"id"`
+	Check     string        `json:"check"`
+	Image     string        `json:"image"`
+	Status    JobStatus     `json:"status"`
+	Checks    []string      `json:"checks"`
+	Completed int           `json:"completed"`
+	Results   []CheckResult `json:"results"`
+	Response  *Response     `json:"response,omitempty"`
+	Err       string        `json:"error,omitempty"`
+	Created   time.Time     `json:"created"`
+	Updated   time.Time     `json:"updated"`
+}
@@ -0,0 +1,214 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/docker/distribution/reference"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+	"github.com/grafeas/voucher/v2/signer"
+)
+
+var errCannotAttest = errors.New("cannot create attestations, keyring is empty")
+
+var errNoBuildDetail = errors.New("no build detail stored for image")
+
+// Client implements voucher.MetadataClient, storing metadata in memory and,
+// optionally, in a JSON file. It is intended for local development and
+// testing, where a metadata service isn't available.
+type Client struct {
+	mu      sync.RWMutex
+	db      *Database
+	path    string                   // [optional] The file the Database is persisted to.
+	keyring signer.AttestationSigner // The keyring used for signing metadata.
+}
+
+// CanAttest returns true if the client can create and sign attestations.
+func (c *Client) CanAttest() bool {
+	return nil != c.keyring
+}
+
+// NewPayloadBody returns a payload body in the requested format for this
+// MetadataClient.
+func (c *Client) NewPayloadBody(ctx context.Context, ref reference.Canonical, request voucher.PayloadRequest) (string, error) {
+	return voucher.NewPayloadBody(ctx, c, ref, request)
+}
+
+// AddAttestationToImage adds a new attestation with the passed Attestation
+// to the image described by ImageData.
+func (c *Client) AddAttestationToImage(ctx context.Context, ref reference.Canonical, attestation voucher.Attestation) (voucher.SignedAttestation, error) {
+	if !c.CanAttest() {
+		return voucher.SignedAttestation{}, errCannotAttest
+	}
+
+	signedAttestation, err := voucher.SignAttestation(c.keyring, attestation)
+	if nil != err {
+		return voucher.SignedAttestation{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	image := c.image(ref)
+	if image.hasAttestation(newAttestation(signedAttestation)) {
+		// Mirror the metadata services, which refuse to store the same
+		// attestation twice.
+		signedAttestation.Signature = ""
+		return signedAttestation, nil
+	}
+
+	image.Attestations = append(image.Attestations, newAttestation(signedAttestation))
+
+	return signedAttestation, c.save()
+}
+
+// GetAttestations returns all of the attestations associated with an image.
+func (c *Client) GetAttestations(ctx context.Context, ref reference.Canonical) ([]voucher.SignedAttestation, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	image, ok := c.db.Images[ref.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	attestations := make([]voucher.SignedAttestation, 0, len(image.Attestations))
+	for _, a := range image.Attestations {
+		attestations = append(attestations, a.toSignedAttestation())
+	}
+
+	return attestations, nil
+}
+
+// GetVulnerabilities returns the detected vulnerabilities for the Image described by voucher.ImageData.
+func (c *Client) GetVulnerabilities(ctx context.Context, ref reference.Canonical) ([]voucher.Vulnerability, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	image, ok := c.db.Images[ref.String()]
+	if !ok {
+		return []voucher.Vulnerability{}, nil
+	}
+
+	vulnerabilities := make([]voucher.Vulnerability, 0, len(image.Vulnerabilities))
+	for _, v := range image.Vulnerabilities {
+		vuln, err := v.toVoucherVulnerability()
+		if nil != err {
+			return []voucher.Vulnerability{}, err
+		}
+		vulnerabilities = append(vulnerabilities, vuln)
+	}
+
+	return vulnerabilities, nil
+}
+
+// GetBuildDetail gets the BuildDetail for the passed image.
+func (c *Client) GetBuildDetail(ctx context.Context, ref reference.Canonical) (repository.BuildDetail, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	image, ok := c.db.Images[ref.String()]
+	if !ok || nil == image.BuildDetail {
+		return repository.BuildDetail{}, &voucher.NoMetadataError{
+			Type: voucher.BuildDetailsType,
+			Err:  errNoBuildDetail,
+		}
+	}
+
+	return *image.BuildDetail, nil
+}
+
+// SetBuildDetail stores the BuildDetail for the passed image.
+func (c *Client) SetBuildDetail(ref reference.Canonical, buildDetail repository.BuildDetail) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.image(ref).BuildDetail = &buildDetail
+
+	return c.save()
+}
+
+// SetVulnerabilities stores the vulnerabilities for the passed image,
+// replacing any which were stored previously.
+func (c *Client) SetVulnerabilities(ref reference.Canonical, vulnerabilities []voucher.Vulnerability) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	image := c.image(ref)
+	image.Vulnerabilities = make([]Vulnerability, 0, len(vulnerabilities))
+	for _, vuln := range vulnerabilities {
+		image.Vulnerabilities = append(image.Vulnerabilities, newVulnerability(vuln))
+	}
+
+	return c.save()
+}
+
+// LoadFixtures adds the metadata in the JSON file at the passed path to the
+// Client. The file uses the same format as the Client's database.
+func (c *Client) LoadFixtures(path string) error {
+	fixtures, err := ReadDatabase(path)
+	if nil != err {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.db.merge(fixtures)
+
+	return c.save()
+}
+
+// Close closes the Client's keyring.
+func (c *Client) Close() {
+	if nil != c.keyring {
+		_ = c.keyring.Close()
+	}
+}
+
+// image returns the Image stored for the passed reference, creating it if
+// it doesn't exist. Must be called with the lock held.
+func (c *Client) image(ref reference.Canonical) *Image {
+	image, ok := c.db.Images[ref.String()]
+	if !ok {
+		image = new(Image)
+		c.db.Images[ref.String()] = image
+	}
+	return image
+}
+
+// save writes the Database to the Client's file, if it has one. Must be
+// called with the lock held.
+func (c *Client) save() error {
+	if "" == c.path {
+		return nil
+	}
+	return writeDatabase(c.path, c.db)
+}
+
+// NewClient creates a new local Client. If path is not empty, the metadata
+// stored in the JSON file at that path is loaded, and all changes are
+// written back to it. Otherwise, metadata is only stored in memory.
+func NewClient(path string, keyring signer.AttestationSigner) (*Client, error) {
+	db := &Database{
+		Images: make(map[string]*Image),
+	}
+
+	if "" != path {
+		var err error
+
+		db, err = ReadDatabase(path)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	return &Client{
+		db:      db,
+		path:    path,
+		keyring: keyring,
+	}, nil
+}
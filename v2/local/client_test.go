@@ -0,0 +1,160 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+const fixtures = `{
+  "images": {
+    "localhost/path/to/image@sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da": {
+      "build_detail": {
+        "repository": "https://github.com/grafeas/voucher",
+        "commit": "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"
+      },
+      "vulnerabilities": [
+        {"name": "cve-the-worst", "severity": "critical"}
+      ]
+    }
+  }
+}`
+
+func TestClientWithFixtures(t *testing.T) {
+	ctx := context.Background()
+	i := vtesting.NewTestReference(t)
+
+	fixturesPath := filepath.Join(t.TempDir(), "fixtures.json")
+	require.NoError(t, os.WriteFile(fixturesPath, []byte(fixtures), 0600))
+
+	client, err := NewClient("", nil)
+	require.NoError(t, err)
+	require.NoError(t, client.LoadFixtures(fixturesPath))
+
+	buildDetail, err := client.GetBuildDetail(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/grafeas/voucher", buildDetail.RepositoryURL)
+
+	vulns, err := client.GetVulnerabilities(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.Vulnerability{{Name: "cve-the-worst", Severity: voucher.CriticalSeverity}}, vulns)
+
+	_, err = client.GetBuildDetail(ctx, vtesting.NewBadTestReference(t))
+	assert.True(t, voucher.IsNoMetadataError(err), "expected NoMetadataError, got %v", err)
+}
+
+func TestClientAttestations(t *testing.T) {
+	ctx := context.Background()
+	i := vtesting.NewTestReference(t)
+
+	client, err := NewClient("", nil)
+	require.NoError(t, err)
+	assert.False(t, client.CanAttest())
+
+	_, err = client.AddAttestationToImage(ctx, i, voucher.NewAttestation("snakeoil", "body"))
+	assert.Equal(t, errCannotAttest, err)
+
+	client, err = NewClient("", vtesting.NewPGPSigner(t))
+	require.NoError(t, err)
+	assert.True(t, client.CanAttest())
+
+	payload, err := client.NewPayloadBody(ctx, i, voucher.PayloadRequest{CheckName: "snakeoil"})
+	require.NoError(t, err)
+
+	signed, err := client.AddAttestationToImage(ctx, i, voucher.NewAttestation("snakeoil", payload))
+	require.NoError(t, err)
+	assert.NotEmpty(t, signed.Signature)
+
+	attestations, err := client.GetAttestations(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.SignedAttestation{signed}, attestations)
+
+	// adding the same attestation again does not store a duplicate.
+	duplicate, err := client.AddAttestationToImage(ctx, i, voucher.NewAttestation("snakeoil", payload))
+	require.NoError(t, err)
+	assert.Empty(t, duplicate.Signature)
+
+	attestations, err = client.GetAttestations(ctx, i)
+	require.NoError(t, err)
+	assert.Len(t, attestations, 1)
+}
+
+func TestClientPersistence(t *testing.T) {
+	ctx := context.Background()
+	i := vtesting.NewTestReference(t)
+	path := filepath.Join(t.TempDir(), "metadata.json")
+
+	client, err := NewClient(path, vtesting.NewPGPSigner(t))
+	require.NoError(t, err)
+
+	buildDetail := repository.BuildDetail{
+		RepositoryURL: "https://github.com/grafeas/voucher",
+		BuildCreator:  "builder@example.com",
+	}
+	require.NoError(t, client.SetBuildDetail(i, buildDetail))
+	require.NoError(t, client.SetVulnerabilities(i, []voucher.Vulnerability{
+		{Name: "cve-this-is-fine", Severity: voucher.NegligibleSeverity},
+	}))
+
+	signed, err := client.AddAttestationToImage(ctx, i, voucher.NewAttestation("snakeoil", "body"))
+	require.NoError(t, err)
+	client.Close()
+
+	reopened, err := NewClient(path, nil)
+	require.NoError(t, err)
+
+	storedBuildDetail, err := reopened.GetBuildDetail(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, buildDetail, storedBuildDetail)
+
+	vulns, err := reopened.GetVulnerabilities(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.Vulnerability{{Name: "cve-this-is-fine", Severity: voucher.NegligibleSeverity}}, vulns)
+
+	attestations, err := reopened.GetAttestations(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, []voucher.SignedAttestation{signed}, attestations)
+}
+
+func TestReadDatabaseWithInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := NewClient(path, nil)
+	assert.Error(t, err)
+}
+
+func TestLoadFixturesTwice(t *testing.T) {
+	ctx := context.Background()
+	i := vtesting.NewTestReference(t)
+
+	fixturesPath := filepath.Join(t.TempDir(), "fixtures.json")
+	require.NoError(t, os.WriteFile(fixturesPath, []byte(`{
+  "images": {
+    "localhost/path/to/image@sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da": {
+      "attestations": [
+        {"check_name": "diy", "body": "body", "signature": "signature", "key_id": "key"}
+      ]
+    }
+  }
+}`), 0600))
+
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	for n := 0; n < 2; n++ {
+		client, err := NewClient(path, nil)
+		require.NoError(t, err)
+		require.NoError(t, client.LoadFixtures(fixturesPath))
+
+		attestations, err := client.GetAttestations(ctx, i)
+		require.NoError(t, err)
+		assert.Len(t, attestations, 1, "fixtures shouldn't be added to the file again")
+	}
+}
@@ -0,0 +1,193 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// Database is the metadata stored by the local Client. Images are keyed by
+// their canonical reference (eg. "gcr.io/project/image@sha256:...").
+type Database struct {
+	Images map[string]*Image `json:"images"`
+}
+
+// Image is the metadata stored for a single image.
+type Image struct {
+	BuildDetail     *repository.BuildDetail `json:"build_detail,omitempty"`
+	Vulnerabilities []Vulnerability         `json:"vulnerabilities,omitempty"`
+	Attestations    []Attestation           `json:"attestations,omitempty"`
+}
+
+// Vulnerability is a voucher.Vulnerability as it is stored in the Database,
+// with the severity written as a string (eg. "critical").
+type Vulnerability struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity"`
+	FixedBy     string `json:"fixed_by,omitempty"`
+	Package     string `json:"package,omitempty"`
+}
+
+// Attestation is a voucher.SignedAttestation as it is stored in the Database.
+type Attestation struct {
+	CheckName   string `json:"check_name"`
+	Body        string `json:"body"`
+	PayloadType string `json:"payload_type,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	KeyID       string `json:"key_id,omitempty"`
+}
+
+// newVulnerability converts a voucher.Vulnerability to a Vulnerability.
+func newVulnerability(vuln voucher.Vulnerability) Vulnerability {
+	return Vulnerability{
+		Name:        vuln.Name,
+		Description: vuln.Description,
+		Severity:    vuln.Severity.String(),
+		FixedBy:     vuln.FixedBy,
+		Package:     vuln.Package,
+	}
+}
+
+// toVoucherVulnerability converts the Vulnerability to a voucher.Vulnerability.
+func (v Vulnerability) toVoucherVulnerability() (voucher.Vulnerability, error) {
+	severity, err := voucher.StringToSeverity(v.Severity)
+	if nil != err {
+		return voucher.Vulnerability{}, err
+	}
+
+	return voucher.Vulnerability{
+		Name:        v.Name,
+		Description: v.Description,
+		Severity:    severity,
+		FixedBy:     v.FixedBy,
+		Package:     v.Package,
+	}, nil
+}
+
+// newAttestation converts a voucher.SignedAttestation to an Attestation.
+func newAttestation(signedAttestation voucher.SignedAttestation) Attestation {
+	return Attestation{
+		CheckName:   signedAttestation.CheckName,
+		Body:        signedAttestation.Body,
+		PayloadType: signedAttestation.PayloadType,
+		Signature:   signedAttestation.Signature,
+		KeyID:       signedAttestation.KeyID,
+	}
+}
+
+// toSignedAttestation converts the Attestation to a voucher.SignedAttestation.
+func (a Attestation) toSignedAttestation() voucher.SignedAttestation {
+	attestation := voucher.NewAttestation(a.CheckName, a.Body)
+	attestation.PayloadType = a.PayloadType
+
+	return voucher.SignedAttestation{
+		Attestation: attestation,
+		Signature:   a.Signature,
+		KeyID:       a.KeyID,
+	}
+}
+
+// merge adds the metadata in the passed Database to this Database. Build
+// details and vulnerabilities in the passed Database replace those already
+// stored, while attestations are added to the stored attestations, unless
+// they are already stored, so merging the same Database twice changes
+// nothing.
+func (db *Database) merge(other *Database) {
+	if nil == db.Images {
+		db.Images = make(map[string]*Image, len(other.Images))
+	}
+
+	for ref, otherImage := range other.Images {
+		if nil == otherImage {
+			continue
+		}
+
+		image, ok := db.Images[ref]
+		if !ok {
+			image = new(Image)
+			db.Images[ref] = image
+		}
+
+		if nil != otherImage.BuildDetail {
+			image.BuildDetail = otherImage.BuildDetail
+		}
+		if nil != otherImage.Vulnerabilities {
+			image.Vulnerabilities = otherImage.Vulnerabilities
+		}
+		for _, attestation := range otherImage.Attestations {
+			if !image.hasAttestation(attestation) {
+				image.Attestations = append(image.Attestations, attestation)
+			}
+		}
+	}
+}
+
+// hasAttestation returns true if the Image has an attestation for the same
+// check, with the same body, as the passed Attestation.
+func (image *Image) hasAttestation(attestation Attestation) bool {
+	for _, existing := range image.Attestations {
+		if existing.CheckName == attestation.CheckName && existing.Body == attestation.Body {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadDatabase reads a Database from the JSON file at the passed path.
+// Returns an empty Database if the file does not exist.
+func ReadDatabase(path string) (*Database, error) {
+	db := &Database{
+		Images: make(map[string]*Image),
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return db, nil
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, db)
+	if nil != err {
+		return nil, err
+	}
+
+	if nil == db.Images {
+		db.Images = make(map[string]*Image)
+	}
+
+	return db, nil
+}
+
+// writeDatabase writes the Database to the JSON file at the passed path,
+// replacing the file atomically so that readers never see partial writes.
+func writeDatabase(path string, db *Database) error {
+	b, err := json.MarshalIndent(db, "", "  ")
+	if nil != err {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if nil != err {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(b); nil != err {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); nil != err {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
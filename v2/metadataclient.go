@@ -12,7 +12,7 @@ import (
 // with the Metadata server.
 type MetadataClient interface {
 	CanAttest() bool
-	NewPayloadBody(ImageData) (string, error)
+	NewPayloadBody(context.Context, ImageData, PayloadRequest) (string, error)
 	GetVulnerabilities(context.Context, ImageData) ([]Vulnerability, error)
 	GetBuildDetail(context.Context, reference.Canonical) (repository.BuildDetail, error)
 	AddAttestationToImage(context.Context, ImageData, Attestation) (SignedAttestation, error)
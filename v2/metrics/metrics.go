@@ -14,6 +14,12 @@ type Client interface {
 	CheckAttestationStart(string)
 	CheckAttestationError(string, error)
 	CheckAttestationSuccess(string)
+	CheckCacheHit(string)
+	CheckCacheMiss(string)
 	PubSubMessageReceived()
 	PubSubTotalLatency(time.Duration)
+	PubSubMessageAcked()
+	PubSubMessageRetried()
+	PubSubMessageDeadLettered()
+	PubSubMessageDropped()
 }
@@ -15,5 +15,11 @@ func (*NoopClient) CheckRunSuccess(string)                        {}
 func (*NoopClient) CheckAttestationStart(string)                  {}
 func (*NoopClient) CheckAttestationError(string, error)           {}
 func (*NoopClient) CheckAttestationSuccess(string)                {}
+func (*NoopClient) CheckCacheHit(string)                          {}
+func (*NoopClient) CheckCacheMiss(string)                         {}
 func (*NoopClient) PubSubMessageReceived()                        {}
 func (*NoopClient) PubSubTotalLatency(time.Duration)              {}
+func (*NoopClient) PubSubMessageAcked()                           {}
+func (*NoopClient) PubSubMessageRetried()                         {}
+func (*NoopClient) PubSubMessageDeadLettered()                    {}
+func (*NoopClient) PubSubMessageDropped()                         {}
@@ -29,8 +29,15 @@ type OpenTelemetryClient struct {
 	attestSuccess syncint64.Counter
 	attestLatency syncint64.Histogram
 
-	pubsubMsgReceived syncint64.Counter
-	pubsubMsgLatency  syncint64.Histogram
+	cacheHit  syncint64.Counter
+	cacheMiss syncint64.Counter
+
+	pubsubMsgReceived     syncint64.Counter
+	pubsubMsgLatency      syncint64.Histogram
+	pubsubMsgAcked        syncint64.Counter
+	pubsubMsgRetried      syncint64.Counter
+	pubsubMsgDeadLettered syncint64.Counter
+	pubsubMsgDropped      syncint64.Counter
 }
 
 // Please follow https://prometheus.io/docs/practices/naming/ for metric/label naming conventions.
@@ -58,6 +65,9 @@ func NewOpenTelemetryClient(mp metric.MeterProvider, hook shutdownHook) (*OpenTe
 	if err := addAttestMetrics(meter, client); err != nil {
 		return nil, err
 	}
+	if err := addCacheMetrics(meter, client); err != nil {
+		return nil, err
+	}
 	if err := addPubSubMetrics(meter, client); err != nil {
 		return nil, err
 	}
@@ -108,6 +118,18 @@ func addAttestMetrics(ip syncint64.InstrumentProvider, client *OpenTelemetryClie
 	return
 }
 
+func addCacheMetrics(ip syncint64.InstrumentProvider, client *OpenTelemetryClient) (err error) {
+	client.cacheHit, err = ip.Counter("voucher_check_cache_hit_total")
+	if err != nil {
+		return fmt.Errorf("failed to create voucher_check_cache_hit_total counter: %w", err)
+	}
+	client.cacheMiss, err = ip.Counter("voucher_check_cache_miss_total")
+	if err != nil {
+		return fmt.Errorf("failed to create voucher_check_cache_miss_total counter: %w", err)
+	}
+	return
+}
+
 func addPubSubMetrics(ip syncint64.InstrumentProvider, client *OpenTelemetryClient) (err error) {
 	client.pubsubMsgReceived, err = ip.Counter("voucher_pubsub_message_received_total")
 	if err != nil {
@@ -117,6 +139,22 @@ func addPubSubMetrics(ip syncint64.InstrumentProvider, client *OpenTelemetryClie
 	if err != nil {
 		return fmt.Errorf("failed to create voucher_pubsub_message_latency_milliseconds histogram: %w", err)
 	}
+	client.pubsubMsgAcked, err = ip.Counter("voucher_pubsub_message_acked_total")
+	if err != nil {
+		return fmt.Errorf("failed to create voucher_pubsub_message_acked_total counter: %w", err)
+	}
+	client.pubsubMsgRetried, err = ip.Counter("voucher_pubsub_message_retried_total")
+	if err != nil {
+		return fmt.Errorf("failed to create voucher_pubsub_message_retried_total counter: %w", err)
+	}
+	client.pubsubMsgDeadLettered, err = ip.Counter("voucher_pubsub_message_dead_lettered_total")
+	if err != nil {
+		return fmt.Errorf("failed to create voucher_pubsub_message_dead_lettered_total counter: %w", err)
+	}
+	client.pubsubMsgDropped, err = ip.Counter("voucher_pubsub_message_dropped_total")
+	if err != nil {
+		return fmt.Errorf("failed to create voucher_pubsub_message_dropped_total counter: %w", err)
+	}
 	return
 }
 
@@ -166,6 +204,14 @@ func (o *OpenTelemetryClient) CheckAttestationLatency(check string, dur time.Dur
 	o.recordMillis(o.attestLatency, dur, attrCheckName.String(check))
 }
 
+func (o *OpenTelemetryClient) CheckCacheHit(check string) {
+	o.incr(o.cacheHit, attrCheckName.String(check))
+}
+
+func (o *OpenTelemetryClient) CheckCacheMiss(check string) {
+	o.incr(o.cacheMiss, attrCheckName.String(check))
+}
+
 func (o *OpenTelemetryClient) PubSubMessageReceived() {
 	o.incr(o.pubsubMsgReceived)
 }
@@ -174,6 +220,22 @@ func (o *OpenTelemetryClient) PubSubTotalLatency(dur time.Duration) {
 	o.recordMillis(o.pubsubMsgLatency, dur)
 }
 
+func (o *OpenTelemetryClient) PubSubMessageAcked() {
+	o.incr(o.pubsubMsgAcked)
+}
+
+func (o *OpenTelemetryClient) PubSubMessageRetried() {
+	o.incr(o.pubsubMsgRetried)
+}
+
+func (o *OpenTelemetryClient) PubSubMessageDeadLettered() {
+	o.incr(o.pubsubMsgDeadLettered)
+}
+
+func (o *OpenTelemetryClient) PubSubMessageDropped() {
+	o.incr(o.pubsubMsgDropped)
+}
+
 func (o *OpenTelemetryClient) incr(counter syncint64.Counter, labels ...attribute.KeyValue) {
 	o.withContext(func(ctx context.Context) { counter.Add(ctx, 1, labels...) })
 }
@@ -28,7 +28,7 @@ func TestOpenTelemetryClient(t *testing.T) {
 	metrics, err := reader.Collect(context.Background())
 	require.NoError(t, err)
 	require.Len(t, metrics.ScopeMetrics, 1)
-	require.Len(t, metrics.ScopeMetrics[0].Metrics, 11, "total metric count")
+	require.Len(t, metrics.ScopeMetrics[0].Metrics, 17, "total metric count")
 
 	// Verify the metrics we triggered are present:
 	names := make(map[string]struct{}, len(metrics.ScopeMetrics[0].Metrics))
@@ -73,6 +73,18 @@ func (d *StatsdClient) CheckAttestationError(check string, err error) {
 	_ = d.client.Event(createDataDogErrorEvent(check, "Voucher Check Attestation Error", err))
 }
 
+// CheckCacheHit tracks the number of check results that were read from the
+// decision cache instead of running the check
+func (d *StatsdClient) CheckCacheHit(check string) {
+	_ = d.client.Incr("voucher.check.cache.hit", []string{"check:" + check}, d.samplingRate)
+}
+
+// CheckCacheMiss tracks the number of checks that had to be run because their
+// result wasn't in the decision cache
+func (d *StatsdClient) CheckCacheMiss(check string) {
+	_ = d.client.Incr("voucher.check.cache.miss", []string{"check:" + check}, d.samplingRate)
+}
+
 // PubSubMessageReceived tracks the number of messages received from pub/sub
 func (d *StatsdClient) PubSubMessageReceived() {
 	_ = d.client.Incr("auto_voucher.message.received", []string{}, d.samplingRate)
@@ -83,6 +95,30 @@ func (d *StatsdClient) PubSubTotalLatency(duration time.Duration) {
 	_ = d.client.Timing("auto_voucher.latency", duration, []string{}, d.samplingRate)
 }
 
+// PubSubMessageAcked tracks the number of pub/sub messages that were handled
+// and acknowledged
+func (d *StatsdClient) PubSubMessageAcked() {
+	_ = d.client.Incr("auto_voucher.message.acked", []string{}, d.samplingRate)
+}
+
+// PubSubMessageRetried tracks the number of pub/sub messages that failed and
+// will be delivered again
+func (d *StatsdClient) PubSubMessageRetried() {
+	_ = d.client.Incr("auto_voucher.message.retried", []string{}, d.samplingRate)
+}
+
+// PubSubMessageDeadLettered tracks the number of pub/sub messages that were
+// given up on and published to the dead letter topic
+func (d *StatsdClient) PubSubMessageDeadLettered() {
+	_ = d.client.Incr("auto_voucher.message.dead_lettered", []string{}, d.samplingRate)
+}
+
+// PubSubMessageDropped tracks the number of pub/sub messages that were given
+// up on without being published to a dead letter topic
+func (d *StatsdClient) PubSubMessageDropped() {
+	_ = d.client.Incr("auto_voucher.message.dropped", []string{}, d.samplingRate)
+}
+
 func createDataDogErrorEvent(check, title string, err error) *statsd.Event {
 	event := statsd.NewEvent(title, err.Error())
 	event.AlertType = statsd.Error
@@ -14,3 +14,12 @@ func (m *MockCheck) Check(ctx context.Context, i ImageData) (bool, error) {
 	args := m.Called(ctx, i)
 	return args.Bool(0), args.Error(1)
 }
+
+type MockDetailedCheck struct {
+	MockCheck
+}
+
+func (m *MockDetailedCheck) CheckWithDetails(ctx context.Context, i ImageData) (bool, interface{}, error) {
+	args := m.Called(ctx, i)
+	return args.Bool(0), args.Get(1), args.Error(2)
+}
@@ -18,8 +18,8 @@ func (m *MockMetadataClient) CanAttest() bool {
 	return args.Bool(0)
 }
 
-func (m *MockMetadataClient) NewPayloadBody(imageData ImageData) (string, error) {
-	args := m.Called(imageData)
+func (m *MockMetadataClient) NewPayloadBody(ctx context.Context, imageData ImageData, request PayloadRequest) (string, error) {
+	args := m.Called(ctx, imageData, request)
 	return args.String(0), args.Error(1)
 }
 
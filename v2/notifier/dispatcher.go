@@ -0,0 +1,256 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const (
+	// DefaultRetries is the number of times a failed delivery should be
+	// retried, if the number isn't configured.
+	DefaultRetries = 3
+
+	// DefaultRetryDelay is how long the Dispatcher waits before retrying a
+	// delivery the first time if its Config doesn't set a delay. The delay
+	// doubles with each retry.
+	DefaultRetryDelay = time.Second
+
+	// DefaultTimeout is how long each delivery can take if the Dispatcher's
+	// Config doesn't set a timeout.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultQueueSize is the number of Notifications that can wait to be
+	// delivered if the Dispatcher's Config doesn't set a size.
+	DefaultQueueSize = 100
+)
+
+// BuildDetailFunc looks up the BuildDetail of an image.
+type BuildDetailFunc func(context.Context, reference.Canonical) (repository.BuildDetail, error)
+
+// Config is the configuration of a Dispatcher.
+type Config struct {
+	// Retries is the number of times a failed delivery is retried
+	Retries int
+	// RetryDelay is how long to wait before the first retry
+	RetryDelay time.Duration
+	// Timeout is how long each delivery can take
+	Timeout time.Duration
+	// QueueSize is the number of Notifications that can wait to be delivered
+	QueueSize int
+	// RateLimit is the number of Notifications each Target is sent per
+	// RateInterval. Targets aren't rate limited if it is zero.
+	RateLimit    int
+	RateInterval time.Duration
+}
+
+// Dispatcher delivers Notifications to the Targets that want them, in the
+// background.
+type Dispatcher struct {
+	config      Config
+	targets     []*target
+	buildDetail BuildDetailFunc
+	client      *http.Client
+	now         func() time.Time
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan delivery
+	done   chan struct{}
+}
+
+// target is a Target and its rate limit.
+type target struct {
+	*Target
+	limiter    *rate.Limiter
+	suppressed int
+}
+
+// delivery is a Notification to deliver to a Target.
+type delivery struct {
+	notification Notification
+	target       *Target
+}
+
+// NewDispatcher creates a Dispatcher which delivers Notifications to the
+// passed Targets. If buildDetail is not nil, it is used to look up the build
+// details of images in Notifications which don't have them.
+func NewDispatcher(config Config, targets []*Target, buildDetail BuildDetailFunc) *Dispatcher {
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+
+	d := &Dispatcher{
+		config:      config,
+		targets:     make([]*target, 0, len(targets)),
+		buildDetail: buildDetail,
+		client:      &http.Client{Timeout: config.Timeout},
+		now:         time.Now,
+		queue:       make(chan delivery, config.QueueSize),
+		done:        make(chan struct{}),
+	}
+
+	for _, t := range targets {
+		limiter := rate.NewLimiter(rate.Inf, 0)
+		if config.RateLimit > 0 && config.RateInterval > 0 {
+			limiter = rate.NewLimiter(rate.Every(config.RateInterval/time.Duration(config.RateLimit)), config.RateLimit)
+		}
+		d.targets = append(d.targets, &target{Target: t, limiter: limiter})
+	}
+
+	go d.work()
+
+	return d
+}
+
+// Notify queues the passed Notification for delivery to each of the Targets
+// that want failures of its check group, unless they have reached their rate
+// limit. It doesn't wait for the Notification to be delivered.
+func (d *Dispatcher) Notify(notification Notification) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	for _, t := range d.targets {
+		if !t.wants(notification.Group) {
+			continue
+		}
+
+		if !t.limiter.AllowN(d.now(), 1) {
+			t.suppressed++
+			log.WithFields(log.Fields{
+				"target": t.name,
+				"image":  notification.Image,
+			}).Warning("notification suppressed by rate limit")
+			continue
+		}
+
+		n := notification
+		n.Suppressed = t.suppressed
+
+		select {
+		case d.queue <- delivery{notification: n, target: t.Target}:
+			t.suppressed = 0
+		default:
+			t.suppressed++
+			log.WithFields(log.Fields{
+				"target": t.name,
+				"image":  notification.Image,
+			}).Warning("notification queue is full, dropping notification")
+		}
+	}
+}
+
+// Close stops accepting Notifications, and waits for the queued ones to be
+// delivered.
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	<-d.done
+	return nil
+}
+
+// work delivers queued Notifications until the Dispatcher is closed.
+func (d *Dispatcher) work() {
+	defer close(d.done)
+
+	for next := range d.queue {
+		notification := d.withBuildDetail(next.notification)
+
+		if err := d.deliver(next.target, notification); nil != err {
+			log.WithFields(log.Fields{
+				"target": next.target.name,
+				"image":  notification.Image,
+			}).WithError(err).Error("failed to deliver notification")
+		}
+	}
+}
+
+// withBuildDetail returns the passed Notification with the build details of
+// its image, if it doesn't have them and they can be looked up.
+func (d *Dispatcher) withBuildDetail(notification Notification) Notification {
+	if nil != notification.Build || nil == d.buildDetail || nil == notification.reference {
+		return notification
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+	defer cancel()
+
+	buildDetail, err := d.buildDetail(ctx, notification.reference)
+	if nil != err {
+		log.WithField("image", notification.Image).WithError(err).Warning("could not get build detail for notification")
+		return notification
+	}
+
+	notification.Build = &buildDetail
+	return notification
+}
+
+// deliver posts the Notification to the Target, retrying with an increasing
+// delay if the Target can't be reached or responds with an error that may be
+// temporary.
+func (d *Dispatcher) deliver(t *Target, notification Notification) error {
+	body, err := t.body(notification)
+	if nil != err {
+		return err
+	}
+
+	var retry bool
+
+	delay := d.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err = d.post(t, body)
+		if nil == err || !retry || attempt >= d.config.Retries {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post sends the body to the Target once, and returns the error, if any, and
+// whether it is worth trying again.
+func (d *Dispatcher) post(t *Target, body []byte) (bool, error) {
+	resp, err := d.client.Post(t.url, "application/json", bytes.NewReader(body))
+	if nil != err {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("%s responded with %s", t.name, resp.Status)
+	return http.StatusTooManyRequests == resp.StatusCode || resp.StatusCode >= 500, err
+}
@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// hooks is a server which records the messages posted to it, responding to
+// each with the next of its statuses.
+type hooks struct {
+	sync.Mutex
+	statuses []int
+	messages []webhookMessage
+}
+
+func (h *hooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Lock()
+	defer h.Unlock()
+
+	var message webhookMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); nil != err {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	h.messages = append(h.messages, message)
+
+	status := http.StatusOK
+	if 0 < len(h.statuses) {
+		status, h.statuses = h.statuses[0], h.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (h *hooks) received() []webhookMessage {
+	h.Lock()
+	defer h.Unlock()
+
+	return append([]webhookMessage{}, h.messages...)
+}
+
+func newTestTarget(t *testing.T, h *hooks, groups ...string) *Target {
+	t.Helper()
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	target, err := NewTarget("hooks", server.URL, Webhook, groups, "")
+	require.NoError(t, err)
+	return target
+}
+
+func TestDispatcher(t *testing.T) {
+	h := &hooks{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	ignored := &hooks{}
+
+	lookups := 0
+	buildDetail := func(_ context.Context, image reference.Canonical) (repository.BuildDetail, error) {
+		lookups++
+		assert.Equal(t, testImage, image.String())
+		return repository.BuildDetail{Commit: "5d8a8b5"}, nil
+	}
+
+	d := NewDispatcher(
+		Config{Retries: 2, RetryDelay: time.Millisecond},
+		[]*Target{newTestTarget(t, h, "production"), newTestTarget(t, ignored, "staging")},
+		buildDetail,
+	)
+
+	d.Notify(newTestNotification(t))
+	require.NoError(t, d.Close())
+
+	// the first two attempts failed, and were retried.
+	messages := h.received()
+	require.Len(t, messages, 3)
+	assert.Equal(t, "production", messages[2].Group)
+	require.NotNil(t, messages[2].Build)
+	assert.Equal(t, "5d8a8b5", messages[2].Build.Commit)
+	assert.Equal(t, 1, lookups)
+
+	assert.Empty(t, ignored.received())
+
+	// notifications are dropped once the Dispatcher is closed.
+	d.Notify(newTestNotification(t))
+	assert.Len(t, h.received(), 3)
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	h := &hooks{statuses: []int{http.StatusBadRequest, http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+
+	d := NewDispatcher(Config{Retries: 1, RetryDelay: time.Millisecond}, []*Target{newTestTarget(t, h)}, nil)
+
+	// client errors aren't retried.
+	d.Notify(newTestNotification(t))
+
+	// server errors are retried, up to the limit.
+	d.Notify(newTestNotification(t))
+	require.NoError(t, d.Close())
+
+	assert.Len(t, h.received(), 3)
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	h := &hooks{}
+
+	now := time.Now()
+
+	d := NewDispatcher(Config{RateLimit: 2, RateInterval: time.Hour}, []*Target{newTestTarget(t, h)}, nil)
+	d.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		d.Notify(newTestNotification(t))
+	}
+
+	now = now.Add(time.Hour)
+
+	d.Notify(newTestNotification(t))
+	require.NoError(t, d.Close())
+
+	messages := h.received()
+	require.Len(t, messages, 3)
+	assert.Equal(t, 0, messages[1].Suppressed)
+	assert.Equal(t, 3, messages[2].Suppressed)
+}
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"github.com/docker/distribution/reference"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// Notification describes an image which failed a check group.
+type Notification struct {
+	Group      string                  `json:"group"`
+	Image      string                  `json:"image"`
+	Err        string                  `json:"error,omitempty"`
+	Failures   []voucher.CheckResult   `json:"failures"`
+	Build      *repository.BuildDetail `json:"build,omitempty"`
+	Suppressed int                     `json:"suppressed,omitempty"`
+
+	reference reference.Canonical
+}
+
+// NewNotification creates a Notification for the passed Response to the
+// check group with the passed name, listing the checks the image failed.
+func NewNotification(group string, image reference.Canonical, response voucher.Response) Notification {
+	notification := Notification{
+		Group:     group,
+		Image:     response.Image,
+		Err:       response.Err,
+		Failures:  make([]voucher.CheckResult, 0),
+		reference: image,
+	}
+
+	for _, result := range response.Results {
+		if !result.Success {
+			notification.Failures = append(notification.Failures, result)
+		}
+	}
+
+	return notification
+}
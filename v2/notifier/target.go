@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Format is the format of the messages a Target posts.
+type Format string
+
+const (
+	// Webhook posts the Notification as JSON, with the message in its "text"
+	// field.
+	Webhook Format = "webhook"
+
+	// Slack posts the message to a Slack incoming webhook.
+	Slack Format = "slack"
+
+	// Teams posts the message to a Microsoft Teams incoming webhook.
+	Teams Format = "teams"
+)
+
+// DefaultTemplate is the template used for the messages of Targets which
+// aren't created with one. It is executed with the Notification.
+const DefaultTemplate = `Voucher rejected {{.Image}} for "{{.Group}}"{{with .Err}}: {{.}}{{end}}
+{{range .Failures}}- {{.Name}} failed{{with .Err}}: {{.}}{{end}}
+{{end}}{{with .Build}}{{with .RepositoryURL}}Repository: {{.}}
+{{end}}{{with .Commit}}Commit: {{.}}
+{{end}}{{with .BuildURL}}Build: {{.}}
+{{end}}{{end}}{{with .Suppressed}}{{.}} earlier notifications were suppressed by the rate limit.
+{{end}}`
+
+var errNoTargetURL = errors.New("notification target has no url")
+
+// Target is somewhere that Notifications are posted.
+type Target struct {
+	name     string
+	url      string
+	format   Format
+	groups   []string
+	template *template.Template
+}
+
+// NewTarget creates a Target which posts messages in the passed Format to the
+// passed URL, for failures of the passed check groups, or of every check group
+// if none are passed. The messages are rendered with the passed template text,
+// or DefaultTemplate if it is empty.
+func NewTarget(name, url string, format Format, groups []string, text string) (*Target, error) {
+	if "" == url {
+		return nil, fmt.Errorf("%w: %s", errNoTargetURL, name)
+	}
+
+	switch format {
+	case Webhook, Slack, Teams:
+	default:
+		return nil, fmt.Errorf("notification format %q is unknown, supported values are 'webhook', 'slack' or 'teams'", format)
+	}
+
+	if "" == text {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if nil != err {
+		return nil, fmt.Errorf("could not parse template for %s: %w", name, err)
+	}
+
+	return &Target{
+		name:     name,
+		url:      url,
+		format:   format,
+		groups:   groups,
+		template: tmpl,
+	}, nil
+}
+
+// Name returns the name of the Target.
+func (t *Target) Name() string {
+	return t.name
+}
+
+// wants returns true if the Target posts failures of the passed check group.
+func (t *Target) wants(group string) bool {
+	if 0 == len(t.groups) {
+		return true
+	}
+
+	for _, g := range t.groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// body renders the passed Notification as the body of a request to the
+// Target.
+func (t *Target) body(notification Notification) ([]byte, error) {
+	var text bytes.Buffer
+	if err := t.template.Execute(&text, notification); nil != err {
+		return nil, fmt.Errorf("could not render message: %w", err)
+	}
+	message := strings.TrimSpace(text.String())
+
+	switch t.format {
+	case Slack:
+		return json.Marshal(slackMessage{Text: message})
+	case Teams:
+		return json.Marshal(teamsMessage{
+			Type:       "MessageCard",
+			Context:    "https://schema.org/extensions",
+			Summary:    "Voucher rejected " + notification.Image,
+			ThemeColor: "D13438",
+			Title:      "Voucher rejected an image",
+			// Teams only breaks lines between paragraphs.
+			Text: strings.ReplaceAll(message, "\n", "\n\n"),
+		})
+	}
+
+	return json.Marshal(webhookMessage{Notification: notification, Text: message})
+}
+
+// webhookMessage is the body posted to Webhook Targets.
+type webhookMessage struct {
+	Notification
+	Text string `json:"text"`
+}
+
+// slackMessage is the body posted to Slack Targets.
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// teamsMessage is the body posted to Teams Targets, a legacy actionable
+// message card, which Teams incoming webhooks accept.
+type teamsMessage struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const testImage = "gcr.io/voucher/app@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2"
+
+func newTestNotification(t *testing.T) Notification {
+	t.Helper()
+
+	imageData, err := voucher.NewImageData(testImage)
+	require.NoError(t, err)
+
+	return NewNotification("production", imageData, voucher.Response{
+		Image:   testImage,
+		Success: false,
+		Err:     "check diy failed",
+		Results: []voucher.CheckResult{
+			{Name: "diy", Err: "image is not from a valid repo"},
+			{Name: "snakeoil", Success: true, Attested: true},
+		},
+	})
+}
+
+func TestNewNotification(t *testing.T) {
+	notification := newTestNotification(t)
+
+	assert.Equal(t, "production", notification.Group)
+	assert.Equal(t, testImage, notification.Image)
+	assert.Equal(t, "check diy failed", notification.Err)
+	assert.Equal(t, []voucher.CheckResult{{Name: "diy", Err: "image is not from a valid repo"}}, notification.Failures)
+}
+
+func TestNewTargetErrors(t *testing.T) {
+	_, err := NewTarget("security", "", Slack, nil, "")
+	assert.ErrorIs(t, err, errNoTargetURL)
+
+	_, err = NewTarget("security", "https://hooks.example.com", "email", nil, "")
+	assert.EqualError(t, err, `notification format "email" is unknown, supported values are 'webhook', 'slack' or 'teams'`)
+
+	_, err = NewTarget("security", "https://hooks.example.com", Slack, nil, "{{.Image")
+	assert.Error(t, err)
+}
+
+func TestTargetWants(t *testing.T) {
+	everything, err := NewTarget("security", "https://hooks.example.com", Slack, nil, "")
+	require.NoError(t, err)
+	assert.True(t, everything.wants("production"))
+	assert.True(t, everything.wants("all"))
+
+	production, err := NewTarget("security", "https://hooks.example.com", Slack, []string{"production"}, "")
+	require.NoError(t, err)
+	assert.True(t, production.wants("production"))
+	assert.False(t, production.wants("all"))
+}
+
+func TestTargetBody(t *testing.T) {
+	notification := newTestNotification(t)
+	notification.Build = &repository.BuildDetail{
+		RepositoryURL: "https://github.com/grafeas/voucher",
+		Commit:        "5d8a8b5",
+		BuildURL:      "https://console.cloud.google.com/cloud-build/builds/1",
+	}
+	notification.Suppressed = 2
+
+	message := "Voucher rejected " + testImage + ` for "production": check diy failed
+- diy failed: image is not from a valid repo
+Repository: https://github.com/grafeas/voucher
+Commit: 5d8a8b5
+Build: https://console.cloud.google.com/cloud-build/builds/1
+2 earlier notifications were suppressed by the rate limit.`
+
+	slack, err := NewTarget("security", "https://hooks.example.com", Slack, nil, "")
+	require.NoError(t, err)
+
+	body, err := slack.body(notification)
+	require.NoError(t, err)
+
+	var slackBody slackMessage
+	require.NoError(t, json.Unmarshal(body, &slackBody))
+	assert.Equal(t, message, slackBody.Text)
+
+	teams, err := NewTarget("security", "https://hooks.example.com", Teams, nil, "")
+	require.NoError(t, err)
+
+	body, err = teams.body(notification)
+	require.NoError(t, err)
+
+	var teamsBody teamsMessage
+	require.NoError(t, json.Unmarshal(body, &teamsBody))
+	assert.Equal(t, "MessageCard", teamsBody.Type)
+	assert.Equal(t, "Voucher rejected "+testImage, teamsBody.Summary)
+	assert.Contains(t, teamsBody.Text, "check diy failed\n\n- diy failed")
+
+	webhook, err := NewTarget("security", "https://hooks.example.com", Webhook, nil, "{{.Image}} failed {{len .Failures}} checks")
+	require.NoError(t, err)
+
+	body, err = webhook.body(notification)
+	require.NoError(t, err)
+
+	var webhookBody webhookMessage
+	require.NoError(t, json.Unmarshal(body, &webhookBody))
+	assert.Equal(t, testImage+" failed 1 checks", webhookBody.Text)
+	assert.Equal(t, "production", webhookBody.Group)
+	assert.Equal(t, notification.Failures, webhookBody.Failures)
+	assert.Equal(t, notification.Build, webhookBody.Build)
+}
@@ -0,0 +1,72 @@
+package voucher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafeas/voucher/v2/attestation"
+)
+
+// PayloadFormat is the format of the payload of a check's attestations.
+type PayloadFormat string
+
+const (
+	// BinAuthzPayloadFormat is the "Google cloud binauthz container signature"
+	// payload, which Binary Authorization consumes. It is the default format.
+	BinAuthzPayloadFormat PayloadFormat = "binauthz"
+
+	// InTotoPayloadFormat is an in-toto statement describing the check the
+	// image passed, which is signed as the payload of a DSSE envelope.
+	InTotoPayloadFormat PayloadFormat = "in-toto"
+)
+
+// ParsePayloadFormat returns the PayloadFormat with the passed name. An empty
+// name is the BinAuthzPayloadFormat.
+func ParsePayloadFormat(name string) (PayloadFormat, error) {
+	switch format := PayloadFormat(name); format {
+	case "":
+		return BinAuthzPayloadFormat, nil
+	case BinAuthzPayloadFormat, InTotoPayloadFormat:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unknown payload format: %q", name)
+}
+
+// PayloadRequest describes the attestation that a payload body is created for.
+type PayloadRequest struct {
+	Format    PayloadFormat
+	CheckName string
+	Details   interface{}
+}
+
+// NewPayloadBody returns a payload body in the requested format for an
+// attestation of the image described by ImageData. In-toto payloads include
+// the image's BuildDetail, if the MetadataClient has one. MetadataClients
+// call this to implement their NewPayloadBody method.
+func NewPayloadBody(ctx context.Context, client MetadataClient, imageData ImageData, request PayloadRequest) (string, error) {
+	if InTotoPayloadFormat != request.Format {
+		return attestation.NewPayload(imageData).ToString()
+	}
+
+	predicate := attestation.VoucherPredicate{
+		Check:     request.CheckName,
+		Details:   request.Details,
+		Timestamp: time.Now().UTC(),
+	}
+
+	buildDetail, err := client.GetBuildDetail(ctx, imageData)
+	if nil == err {
+		predicate.BuildDetail = &buildDetail
+	} else if !IsNoMetadataError(err) {
+		return "", err
+	}
+
+	statement, err := attestation.NewStatement(imageData, attestation.VoucherPredicateType, predicate)
+	if nil != err {
+		return "", err
+	}
+
+	return statement.ToString()
+}
@@ -0,0 +1,95 @@
+package voucher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+func TestParsePayloadFormat(t *testing.T) {
+	for name, expected := range map[string]PayloadFormat{
+		"":         BinAuthzPayloadFormat,
+		"binauthz": BinAuthzPayloadFormat,
+		"in-toto":  InTotoPayloadFormat,
+	} {
+		format, err := ParsePayloadFormat(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := ParsePayloadFormat("slsa")
+	assert.EqualError(t, err, `unknown payload format: "slsa"`)
+}
+
+func TestNewPayloadBody(t *testing.T) {
+	ctx := context.Background()
+	imageData := newTestImageData(t)
+	buildDetail := repository.BuildDetail{
+		RepositoryURL: "https://github.com/grafeas/voucher",
+		Commit:        "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59",
+	}
+
+	metadataClient := new(MockMetadataClient)
+	metadataClient.On("GetBuildDetail", ctx, imageData).Return(buildDetail, nil)
+
+	// the binauthz format is the default.
+	body, err := NewPayloadBody(ctx, metadataClient, imageData, PayloadRequest{CheckName: "diy"})
+	require.NoError(t, err)
+	payload, err := attestation.ParsePayload(body)
+	require.NoError(t, err)
+	assert.Equal(t, attestation.NewPayload(imageData), payload)
+
+	body, err = NewPayloadBody(ctx, metadataClient, imageData, PayloadRequest{
+		Format:    InTotoPayloadFormat,
+		CheckName: "diy",
+		Details:   []string{"linux/amd64"},
+	})
+	require.NoError(t, err)
+
+	statement, err := attestation.ParseStatement([]byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, attestation.StatementType, statement.Type)
+	assert.Equal(t, attestation.VoucherPredicateType, statement.PredicateType)
+	assert.Equal(t, []attestation.Subject{
+		{Name: imageData.Name(), Digest: map[string]string{"sha256": imageData.Digest().Encoded()}},
+	}, statement.Subject)
+
+	var predicate struct {
+		attestation.VoucherPredicate
+		Details []string `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(statement.Predicate, &predicate))
+	assert.Equal(t, "diy", predicate.Check)
+	assert.Equal(t, []string{"linux/amd64"}, predicate.Details)
+	assert.Equal(t, &buildDetail, predicate.BuildDetail)
+	assert.False(t, predicate.Timestamp.IsZero())
+}
+
+func TestNewPayloadBodyWithoutBuildDetail(t *testing.T) {
+	ctx := context.Background()
+	imageData := newTestImageData(t)
+	request := PayloadRequest{Format: InTotoPayloadFormat, CheckName: "diy"}
+
+	metadataClient := new(MockMetadataClient)
+	metadataClient.On("GetBuildDetail", ctx, imageData).Return(repository.BuildDetail{}, &NoMetadataError{Type: BuildDetailsType, Err: errors.New("no build")})
+
+	body, err := NewPayloadBody(ctx, metadataClient, imageData, request)
+	require.NoError(t, err)
+	assert.NotContains(t, body, "buildDetail")
+
+	errMetadata := errors.New("metadata service is down")
+
+	metadataClient = new(MockMetadataClient)
+	metadataClient.On("GetBuildDetail", mock.Anything, imageData).Return(repository.BuildDetail{}, errMetadata)
+
+	_, err = NewPayloadBody(ctx, metadataClient, imageData, request)
+	assert.Equal(t, errMetadata, err)
+}
@@ -0,0 +1,10 @@
+package voucher
+
+// PlatformCheck represents a Voucher check that checks each platform image in
+// a multi-platform image (a manifest list or OCI index). Platforms are
+// formatted as "os/architecture[/variant]". If no platforms are set, every
+// platform in the image must pass the check.
+type PlatformCheck interface {
+	Check
+	SetPlatforms(platforms []string)
+}
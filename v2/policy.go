@@ -0,0 +1,38 @@
+package voucher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+)
+
+// Policy decides if the results of the Checks that were run against an image
+// are acceptable.
+type Policy interface {
+	// Evaluate returns an error describing why the passed results do not
+	// satisfy the Policy, or nil if they do.
+	Evaluate(reference.Reference, []CheckResult) error
+}
+
+// AllChecksPolicy is the default Policy, which requires every CheckResult to
+// be successful.
+var AllChecksPolicy Policy = allChecksPolicy{}
+
+type allChecksPolicy struct{}
+
+// Evaluate returns an error listing the failed checks, if any failed.
+func (allChecksPolicy) Evaluate(_ reference.Reference, results []CheckResult) error {
+	failed := make([]string, 0, len(results))
+	for _, result := range results {
+		if !result.Success {
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("check(s) failed: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// Policy is a set of requirements on the Checks run against an image. A Policy
+// can be limited to images under certain paths, and can treat failures of
+// low severity Checks as advisory.
+type Policy struct {
+	// Images is a list of glob patterns, matched against the image's path and
+	// each of its parent paths. If empty, the Policy applies to every image.
+	Images []string `mapstructure:"images"`
+
+	// FailOn is the minimum Severity a failing Check must have to fail the
+	// Policy. Failures of Checks with a lower Severity are ignored.
+	FailOn voucher.Severity `mapstructure:"failon"`
+
+	// Severities maps Check names to their Severity. Checks that are not
+	// listed are treated as critical.
+	Severities map[string]voucher.Severity `mapstructure:"severity"`
+
+	// Require is the Rule that the Check results must satisfy.
+	Require Rule `mapstructure:"require"`
+}
+
+// Validate returns an error if the Policy is misconfigured.
+func (p Policy) Validate() error {
+	for _, pattern := range p.Images {
+		if _, err := path.Match(pattern, ""); nil != err {
+			return fmt.Errorf("invalid image pattern %q: %w", pattern, err)
+		}
+	}
+
+	return p.Require.Validate()
+}
+
+// AppliesTo returns true if the Policy applies to the passed image.
+func (p Policy) AppliesTo(ref reference.Reference) bool {
+	if len(p.Images) == 0 {
+		return true
+	}
+
+	named, ok := ref.(reference.Named)
+	if !ok {
+		return false
+	}
+
+	for _, pattern := range p.Images {
+		if voucher.MatchesImagePath(pattern, named.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Evaluate returns an error if the passed results do not satisfy the Policy.
+func (p Policy) Evaluate(ref reference.Reference, results []voucher.CheckResult) error {
+	return p.Require.evaluate(p.passed(results))
+}
+
+// severity returns the Severity configured for the Check with the passed name.
+func (p Policy) severity(check string) voucher.Severity {
+	if severity, ok := p.Severities[check]; ok {
+		return severity
+	}
+	return voucher.CriticalSeverity
+}
+
+// passed returns a function which reports if the named Check passed under this
+// Policy. A Check passes if it succeeded, or if it failed but its Severity is
+// lower than the Policy's FailOn. Checks without a result never pass.
+func (p Policy) passed(results []voucher.CheckResult) func(string) bool {
+	return func(check string) bool {
+		for _, result := range results {
+			if result.Name != check {
+				continue
+			}
+			return result.Success || p.severity(check) < p.FailOn
+		}
+		return false
+	}
+}
+
+// Policies is a collection of Policy, which implements voucher.Policy.
+type Policies []Policy
+
+var _ voucher.Policy = Policies(nil)
+
+// Validate returns an error if any of the Policies are misconfigured.
+func (policies Policies) Validate() error {
+	for i, p := range policies {
+		if err := p.Validate(); nil != err {
+			return fmt.Errorf("policy %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Checks returns the names of all of the Checks the Policies refer to.
+func (policies Policies) Checks() []string {
+	checks := make([]string, 0)
+	for _, p := range policies {
+		checks = appendMissing(checks, p.Require.Checks()...)
+	}
+	return checks
+}
+
+// Evaluate returns an error if the passed results do not satisfy every Policy
+// that applies to the image. If no Policy applies to the image, every result
+// must be successful.
+func (policies Policies) Evaluate(ref reference.Reference, results []voucher.CheckResult) error {
+	if !policies.appliesTo(ref) {
+		return voucher.AllChecksPolicy.Evaluate(ref, results)
+	}
+
+	failures := make([]string, 0)
+	for _, p := range policies {
+		if !p.AppliesTo(ref) {
+			continue
+		}
+
+		if err := p.Evaluate(ref, results); nil != err {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New("policy not satisfied: " + strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// ForChecks returns a voucher.Policy which evaluates the Policies for the
+// images they apply to. Images which none of the Policies apply to only have
+// to pass the passed checks, rather than every check the Policies refer to.
+func (policies Policies) ForChecks(checks []string) voucher.Policy {
+	return scopedPolicies{policies: policies, checks: checks}
+}
+
+// appliesTo returns true if any of the Policies apply to the passed image.
+func (policies Policies) appliesTo(ref reference.Reference) bool {
+	for _, p := range policies {
+		if p.AppliesTo(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedPolicies is Policies which only require the results of a set of
+// checks for images that none of the Policies apply to.
+type scopedPolicies struct {
+	policies Policies
+	checks   []string
+}
+
+// Evaluate returns an error if the passed results do not satisfy every Policy
+// that applies to the image. If no Policy applies to the image, the results of
+// each of the scoped checks must be successful.
+func (scoped scopedPolicies) Evaluate(ref reference.Reference, results []voucher.CheckResult) error {
+	if scoped.policies.appliesTo(ref) {
+		return scoped.policies.Evaluate(ref, results)
+	}
+
+	required := make([]voucher.CheckResult, 0, len(results))
+	for _, result := range results {
+		for _, check := range scoped.checks {
+			if result.Name == check {
+				required = append(required, result)
+				break
+			}
+		}
+	}
+
+	return voucher.AllChecksPolicy.Evaluate(ref, required)
+}
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+func newTestImageData(t *testing.T, url string) voucher.ImageData {
+	t.Helper()
+	imageData, err := voucher.NewImageData(url + "@sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da")
+	require.NoError(t, err)
+	return imageData
+}
+
+func newTestResults(passing map[string]bool) []voucher.CheckResult {
+	results := make([]voucher.CheckResult, 0, len(passing))
+	for name, success := range passing {
+		results = append(results, voucher.CheckResult{Name: name, Success: success})
+	}
+	return results
+}
+
+func TestRuleEvaluate(t *testing.T) {
+	results := newTestResults(map[string]bool{
+		"diy":        true,
+		"nobody":     true,
+		"snakeoil":   false,
+		"provenance": false,
+	})
+
+	cases := []struct {
+		name     string
+		rule     Rule
+		expected string
+	}{
+		{"check passes", Rule{Check: "diy"}, ""},
+		{"check fails", Rule{Check: "snakeoil"}, `check "snakeoil" did not pass`},
+		{"missing check fails", Rule{Check: "approved"}, `check "approved" did not pass`},
+		{"all of", Rule{AllOf: []Rule{{Check: "diy"}, {Check: "nobody"}}}, ""},
+		{"all of fails", Rule{AllOf: []Rule{{Check: "diy"}, {Check: "snakeoil"}}}, `check "snakeoil" did not pass`},
+		{"any of", Rule{AnyOf: []Rule{{Check: "snakeoil"}, {Check: "nobody"}}}, ""},
+		{"any of fails", Rule{AnyOf: []Rule{{Check: "snakeoil"}, {Check: "provenance"}}}, "none of snakeoil, provenance passed"},
+		{"n of", Rule{N: 2, NOf: []Rule{{Check: "diy"}, {Check: "nobody"}, {Check: "snakeoil"}}}, ""},
+		{"n of fails", Rule{N: 2, NOf: []Rule{{Check: "diy"}, {Check: "provenance"}, {Check: "snakeoil"}}}, "1 of diy, provenance, snakeoil passed, 2 required"},
+		{
+			"nested",
+			Rule{Check: "diy", AnyOf: []Rule{{Check: "snakeoil"}, {AllOf: []Rule{{Check: "nobody"}, {Check: "diy"}}}}},
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := Policy{Require: tc.rule}
+			err := p.Evaluate(nil, results)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Equal(t, tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	assert.NoError(t, Rule{Check: "diy"}.Validate())
+	assert.Equal(t, errEmptyRule, Rule{}.Validate())
+	assert.Equal(t, errEmptyRule, Rule{AllOf: []Rule{{}}}.Validate())
+	assert.EqualError(t, Rule{N: 3, NOf: []Rule{{Check: "diy"}, {Check: "nobody"}}}.Validate(), "n must be between 1 and 2, was 3")
+	assert.EqualError(t, Rule{NOf: []Rule{{Check: "diy"}}}.Validate(), "n must be between 1 and 1, was 0")
+}
+
+func TestPolicySeverity(t *testing.T) {
+	results := newTestResults(map[string]bool{
+		"diy":    true,
+		"nobody": false,
+	})
+
+	p := Policy{
+		FailOn:     voucher.MediumSeverity,
+		Severities: map[string]voucher.Severity{"nobody": voucher.LowSeverity},
+		Require:    Rule{AllOf: []Rule{{Check: "diy"}, {Check: "nobody"}}},
+	}
+	assert.NoError(t, p.Evaluate(nil, results))
+
+	p.Severities["nobody"] = voucher.HighSeverity
+	assert.Error(t, p.Evaluate(nil, results))
+}
+
+func TestPoliciesEvaluate(t *testing.T) {
+	policies := Policies{
+		{
+			Images:  []string{"gcr.io/prod-*"},
+			Require: Rule{Check: "approved"},
+		},
+		{
+			Require: Rule{AllOf: []Rule{{Check: "diy"}, {Check: "nobody"}}},
+		},
+	}
+	require.NoError(t, policies.Validate())
+	assert.ElementsMatch(t, []string{"approved", "diy", "nobody"}, policies.Checks())
+
+	results := newTestResults(map[string]bool{
+		"diy":      true,
+		"nobody":   true,
+		"approved": false,
+	})
+
+	assert.NoError(t, policies.Evaluate(newTestImageData(t, "gcr.io/staging/app"), results))
+
+	err := policies.Evaluate(newTestImageData(t, "gcr.io/prod-team/app"), results)
+	if assert.Error(t, err) {
+		assert.Equal(t, `policy not satisfied: check "approved" did not pass`, err.Error())
+	}
+}
+
+func TestPoliciesFallback(t *testing.T) {
+	policies := Policies{
+		{
+			Images:  []string{"gcr.io/prod-*"},
+			Require: Rule{Check: "approved"},
+		},
+	}
+
+	results := newTestResults(map[string]bool{
+		"diy":    true,
+		"nobody": false,
+	})
+
+	err := policies.Evaluate(newTestImageData(t, "gcr.io/staging/app"), results)
+	if assert.Error(t, err) {
+		assert.Equal(t, "check(s) failed: nobody", err.Error())
+	}
+}
+
+func TestPoliciesForChecks(t *testing.T) {
+	policies := Policies{
+		{
+			Images:  []string{"gcr.io/prod-*"},
+			Require: Rule{Check: "approved"},
+		},
+	}
+
+	results := newTestResults(map[string]bool{
+		"diy":      true,
+		"approved": false,
+	})
+
+	scoped := policies.ForChecks([]string{"diy"})
+	assert.NoError(t, scoped.Evaluate(newTestImageData(t, "gcr.io/staging/app"), results))
+	assert.Error(t, scoped.Evaluate(newTestImageData(t, "gcr.io/prod-team/app"), results))
+}
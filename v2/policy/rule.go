@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errEmptyRule = errors.New("rule does not require any checks")
+
+// Rule describes which Checks must pass for a Policy to be satisfied. A Rule
+// can require a single Check, or combine other Rules. If more than one
+// requirement is set on a Rule, all of them must be met.
+type Rule struct {
+	Check string `mapstructure:"check"`  // A Check that must pass.
+	AllOf []Rule `mapstructure:"all_of"` // Rules that must all pass.
+	AnyOf []Rule `mapstructure:"any_of"` // Rules of which at least one must pass.
+	N     int    `mapstructure:"n"`      // The number of NOf Rules that must pass.
+	NOf   []Rule `mapstructure:"n_of"`   // Rules of which at least N must pass.
+}
+
+// Validate returns an error if the Rule (or any of the Rules it combines)
+// can never be evaluated.
+func (r Rule) Validate() error {
+	if r.Check == "" && len(r.AllOf) == 0 && len(r.AnyOf) == 0 && len(r.NOf) == 0 {
+		return errEmptyRule
+	}
+
+	if len(r.NOf) > 0 && (r.N < 1 || r.N > len(r.NOf)) {
+		return fmt.Errorf("n must be between 1 and %d, was %d", len(r.NOf), r.N)
+	}
+
+	for _, rules := range [][]Rule{r.AllOf, r.AnyOf, r.NOf} {
+		for _, rule := range rules {
+			if err := rule.Validate(); nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checks returns the names of all of the Checks that this Rule refers to.
+func (r Rule) Checks() []string {
+	checks := make([]string, 0)
+	if r.Check != "" {
+		checks = append(checks, r.Check)
+	}
+
+	for _, rules := range [][]Rule{r.AllOf, r.AnyOf, r.NOf} {
+		for _, rule := range rules {
+			checks = appendMissing(checks, rule.Checks()...)
+		}
+	}
+
+	return checks
+}
+
+// String returns a human readable representation of the Rule.
+func (r Rule) String() string {
+	parts := make([]string, 0, 4)
+	if r.Check != "" {
+		parts = append(parts, r.Check)
+	}
+	if len(r.AllOf) > 0 {
+		parts = append(parts, "all of ("+joinRules(r.AllOf)+")")
+	}
+	if len(r.AnyOf) > 0 {
+		parts = append(parts, "any of ("+joinRules(r.AnyOf)+")")
+	}
+	if len(r.NOf) > 0 {
+		parts = append(parts, fmt.Sprintf("%d of (%s)", r.N, joinRules(r.NOf)))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// evaluate returns an error describing the first requirement of the Rule that
+// was not met. The passed function reports if the named Check passed.
+func (r Rule) evaluate(passed func(string) bool) error {
+	if r.Check != "" && !passed(r.Check) {
+		return fmt.Errorf("check %q did not pass", r.Check)
+	}
+
+	for _, rule := range r.AllOf {
+		if err := rule.evaluate(passed); nil != err {
+			return err
+		}
+	}
+
+	if len(r.AnyOf) > 0 && countPassing(r.AnyOf, passed) == 0 {
+		return fmt.Errorf("none of %s passed", joinRules(r.AnyOf))
+	}
+
+	if len(r.NOf) > 0 {
+		if count := countPassing(r.NOf, passed); count < r.N {
+			return fmt.Errorf("%d of %s passed, %d required", count, joinRules(r.NOf), r.N)
+		}
+	}
+
+	return nil
+}
+
+// countPassing returns the number of passed Rules which are satisfied.
+func countPassing(rules []Rule, passed func(string) bool) int {
+	count := 0
+	for _, rule := range rules {
+		if nil == rule.evaluate(passed) {
+			count++
+		}
+	}
+	return count
+}
+
+func joinRules(rules []Rule) string {
+	out := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, rule.String())
+	}
+	return strings.Join(out, ", ")
+}
+
+// appendMissing appends the values to the slice if they are not already in it.
+func appendMissing(slice []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range slice {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			slice = append(slice, value)
+		}
+	}
+	return slice
+}
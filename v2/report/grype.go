@@ -0,0 +1,67 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+const grypeName = "grype"
+
+// grypeArtifactType is the artifact type that Grype JSON reports are
+// attached to images with.
+const grypeArtifactType = "application/vnd.anchore.grype.report+json"
+
+// Grype is the Format of reports written by Grype's JSON output.
+var Grype Format = grypeFormat{}
+
+// grypeReport is the subset of a Grype JSON report that voucher uses.
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name string `json:"name"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+type grypeFormat struct{}
+
+// Name returns the name of the format.
+func (grypeFormat) Name() string {
+	return grypeName
+}
+
+// ArtifactType returns the OCI artifact type of Grype reports.
+func (grypeFormat) ArtifactType() string {
+	return grypeArtifactType
+}
+
+// Parse converts a Grype JSON report into voucher.Vulnerabilities.
+func (grypeFormat) Parse(b []byte) ([]voucher.Vulnerability, error) {
+	var report grypeReport
+	if err := json.Unmarshal(b, &report); nil != err {
+		return nil, err
+	}
+
+	var vulns vulnerabilitySet
+	for _, match := range report.Matches {
+		vulns.add(voucher.Vulnerability{
+			Name:        match.Vulnerability.ID,
+			Description: match.Vulnerability.Description,
+			Severity:    toSeverity(match.Vulnerability.Severity),
+			FixedBy:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+			Package:     match.Artifact.Name,
+		})
+	}
+
+	return vulns.vulnerabilities, nil
+}
@@ -0,0 +1,81 @@
+// Package report implements voucher.VulnerabilityScanners which read the
+// JSON reports written by third-party vulnerability scanners, such as Trivy
+// and Grype.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// ErrNoReport is returned when there is no report for an image.
+var ErrNoReport = errors.New("no vulnerability report found for image")
+
+// ErrUnsignedReport is returned when none of the reports attached to an image
+// are signed by a trusted signer.
+var ErrUnsignedReport = errors.New("no vulnerability report attached to image is signed by a trusted signer")
+
+// ErrUndatedReport is returned when none of the signed reports attached to an
+// image record when they were created, so the most recent one isn't known.
+var ErrUndatedReport = errors.New("no vulnerability report attached to image has an " + CreatedAnnotation + " annotation")
+
+// Format describes a vulnerability report format, and how to convert it into
+// voucher.Vulnerabilities.
+type Format interface {
+	// Name returns the name of the format (eg. "trivy").
+	Name() string
+
+	// ArtifactType returns the OCI artifact type that reports in this format
+	// are attached to images with.
+	ArtifactType() string
+
+	// Parse converts the report into voucher.Vulnerabilities.
+	Parse([]byte) ([]voucher.Vulnerability, error)
+}
+
+// GetFormat returns the Format with the passed name.
+func GetFormat(name string) (Format, error) {
+	switch name {
+	case trivyName:
+		return Trivy, nil
+	case grypeName:
+		return Grype, nil
+	}
+	return nil, fmt.Errorf("unknown vulnerability report format %q", name)
+}
+
+// toSeverity converts a severity from a report into a voucher.Severity.
+// Reports use different capitalization, so the comparison is case
+// insensitive.
+func toSeverity(severity string) voucher.Severity {
+	s, err := voucher.StringToSeverity(strings.ToLower(severity))
+	if nil != err {
+		return voucher.UnknownSeverity
+	}
+	return s
+}
+
+// vulnerabilitySet collects Vulnerabilities, ignoring duplicate findings of
+// the same vulnerability in the same package.
+type vulnerabilitySet struct {
+	seen            map[string]bool
+	vulnerabilities []voucher.Vulnerability
+}
+
+// add adds the passed Vulnerability to the set, if it isn't already in it.
+func (s *vulnerabilitySet) add(vuln voucher.Vulnerability) {
+	if nil == s.seen {
+		s.seen = make(map[string]bool)
+	}
+
+	key := vuln.Name + "\x00" + vuln.Package
+	if s.seen[key] {
+		return
+	}
+
+	s.seen[key] = true
+	s.vulnerabilities = append(s.vulnerabilities, vuln)
+}
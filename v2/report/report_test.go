@@ -0,0 +1,125 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+const trivyReportJSON = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "localhost/path/to/image",
+  "Results": [
+    {
+      "Target": "localhost/path/to/image (debian 11.5)",
+      "Class": "os-pkgs",
+      "Vulnerabilities": [
+        {
+          "VulnerabilityID": "CVE-2022-0778",
+          "PkgName": "openssl",
+          "InstalledVersion": "1.1.1k-1",
+          "FixedVersion": "1.1.1n-0+deb11u1",
+          "Title": "openssl: Infinite loop in BN_mod_sqrt()",
+          "Severity": "HIGH"
+        },
+        {
+          "VulnerabilityID": "CVE-2022-0778",
+          "PkgName": "openssl",
+          "InstalledVersion": "1.1.1k-1",
+          "FixedVersion": "1.1.1n-0+deb11u1",
+          "Severity": "HIGH"
+        },
+        {
+          "VulnerabilityID": "CVE-2021-3711",
+          "PkgName": "libssl1.1",
+          "Description": "SM2 decryption buffer overflow",
+          "Severity": "CRITICAL"
+        }
+      ]
+    },
+    {
+      "Target": "app/go.sum",
+      "Class": "lang-pkgs"
+    }
+  ]
+}`
+
+const grypeReportJSON = `{
+  "matches": [
+    {
+      "vulnerability": {
+        "id": "CVE-2022-0778",
+        "severity": "High",
+        "description": "openssl: Infinite loop in BN_mod_sqrt()",
+        "fix": {"versions": ["1.1.1n-0+deb11u1"], "state": "fixed"}
+      },
+      "artifact": {"name": "openssl", "version": "1.1.1k-1"}
+    },
+    {
+      "vulnerability": {
+        "id": "GHSA-xxxx",
+        "severity": "Negligible",
+        "fix": {"versions": [], "state": "not-fixed"}
+      },
+      "artifact": {"name": "golang.org/x/text", "version": "v0.3.6"}
+    }
+  ],
+  "source": {"type": "image"}
+}`
+
+func TestTrivyParse(t *testing.T) {
+	vulns, err := Trivy.Parse([]byte(trivyReportJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, []voucher.Vulnerability{
+		{
+			Name:        "CVE-2022-0778",
+			Description: "openssl: Infinite loop in BN_mod_sqrt()",
+			Severity:    voucher.HighSeverity,
+			FixedBy:     "1.1.1n-0+deb11u1",
+			Package:     "openssl",
+		},
+		{
+			Name:        "CVE-2021-3711",
+			Description: "SM2 decryption buffer overflow",
+			Severity:    voucher.CriticalSeverity,
+			Package:     "libssl1.1",
+		},
+	}, vulns)
+}
+
+func TestGrypeParse(t *testing.T) {
+	vulns, err := Grype.Parse([]byte(grypeReportJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, []voucher.Vulnerability{
+		{
+			Name:        "CVE-2022-0778",
+			Description: "openssl: Infinite loop in BN_mod_sqrt()",
+			Severity:    voucher.HighSeverity,
+			FixedBy:     "1.1.1n-0+deb11u1",
+			Package:     "openssl",
+		},
+		{
+			Name:     "GHSA-xxxx",
+			Severity: voucher.NegligibleSeverity,
+			Package:  "golang.org/x/text",
+		},
+	}, vulns)
+}
+
+func TestGetFormat(t *testing.T) {
+	format, err := GetFormat("trivy")
+	require.NoError(t, err)
+	assert.Equal(t, Trivy, format)
+
+	format, err = GetFormat("grype")
+	require.NoError(t, err)
+	assert.Equal(t, Grype, format)
+
+	_, err = GetFormat("clair")
+	assert.Error(t, err)
+}
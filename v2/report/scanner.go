@@ -0,0 +1,51 @@
+package report
+
+import (
+	"context"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// Scanner implements voucher.VulnerabilityScanner, reading vulnerabilities
+// from reports written by a third-party scanner.
+type Scanner struct {
+	failOn voucher.Severity
+	format Format
+	source Source
+}
+
+// FailOn sets severity level that a vulnerability must match or exceed to
+// prompt a failure.
+func (s *Scanner) FailOn(severity voucher.Severity) {
+	s.failOn = severity
+}
+
+// Scan gets the vulnerabilities for an Image from its report.
+func (s *Scanner) Scan(ctx context.Context, i voucher.ImageData) ([]voucher.Vulnerability, error) {
+	b, err := s.source.GetReport(ctx, i, s.format)
+	if nil != err {
+		return []voucher.Vulnerability{}, err
+	}
+
+	v, err := s.format.Parse(b)
+	if nil != err {
+		return []voucher.Vulnerability{}, err
+	}
+
+	vulns := make([]voucher.Vulnerability, 0, len(v))
+	for _, item := range v {
+		if voucher.ShouldIncludeVulnerability(item, s.failOn) {
+			vulns = append(vulns, item)
+		}
+	}
+	return vulns, nil
+}
+
+// NewScanner creates a new Scanner which reads reports in the passed Format
+// from the passed Source.
+func NewScanner(format Format, source Source) *Scanner {
+	return &Scanner{
+		format: format,
+		source: source,
+	}
+}
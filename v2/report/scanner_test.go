@@ -0,0 +1,186 @@
+package report
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/docker"
+	"github.com/grafeas/voucher/v2/sigstore"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+func TestScannerWithDirectorySource(t *testing.T) {
+	dir := t.TempDir()
+	i := vtesting.NewTestReference(t)
+
+	err := os.WriteFile(filepath.Join(dir, "sha256-b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da.json"), []byte(trivyReportJSON), 0600)
+	require.NoError(t, err)
+
+	scanner := NewScanner(Trivy, NewDirectorySource(dir))
+	scanner.FailOn(voucher.CriticalSeverity)
+
+	vulns, err := scanner.Scan(context.Background(), i)
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	assert.Equal(t, "CVE-2021-3711", vulns[0].Name)
+
+	_, err = scanner.Scan(context.Background(), vtesting.NewBadTestReference(t))
+	assert.Equal(t, ErrNoReport, err)
+}
+
+func TestScannerWithReferrerSource(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/path/to/image/referrers/sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da":
+			assert.Equal(t, grypeArtifactType, r.URL.Query().Get("artifactType"))
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			_, _ = w.Write([]byte(`{"manifests": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	scanner := NewScanner(Grype, NewReferrerSource(vtesting.NewAuth(server), "", nil))
+
+	_, err := scanner.Scan(context.Background(), vtesting.NewTestReference(t))
+	assert.Equal(t, ErrNoReport, err)
+
+	scanner = NewScanner(Grype, NewReferrerSource(nil, "", nil))
+
+	_, err = scanner.Scan(context.Background(), vtesting.NewTestReference(t))
+	assert.Equal(t, voucher.ErrNoAuth, err)
+}
+
+// newReportArtifact returns the manifest of an artifact holding the passed
+// Grype report, created at the passed time, and a descriptor for it.
+func newReportArtifact(t *testing.T, reportDigest digest.Digest, created string) ([]byte, docker.Descriptor) {
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"artifactType":  grypeArtifactType,
+		"config":        docker.Descriptor{MediaType: "application/vnd.oci.empty.v1+json", Digest: digest.FromString("{}"), Size: 2},
+		"layers":        []docker.Descriptor{{MediaType: "application/json", Digest: reportDigest}},
+		"annotations":   map[string]string{CreatedAnnotation: created},
+	})
+	require.NoError(t, err)
+
+	return manifest, docker.Descriptor{
+		MediaType:    "application/vnd.oci.image.manifest.v1+json",
+		ArtifactType: grypeArtifactType,
+		Digest:       digest.FromBytes(manifest),
+		Size:         int64(len(manifest)),
+		Annotations:  map[string]string{CreatedAnnotation: created},
+	}
+}
+
+func TestScannerWithSignedReferrers(t *testing.T) {
+	i := vtesting.NewTestReference(t)
+
+	vulnerable := []byte(grypeReportJSON)
+	clean := []byte(`{"matches": []}`)
+
+	signedManifest, signed := newReportArtifact(t, digest.FromBytes(vulnerable), "2024-05-01T10:00:00Z")
+	// the unsigned report claims to be newer, but it is ignored.
+	unsignedManifest, unsigned := newReportArtifact(t, digest.FromBytes(clean), "2099-01-01T00:00:00Z")
+
+	signedRef, err := reference.WithDigest(reference.TrimNamed(i), signed.Digest)
+	require.NoError(t, err)
+
+	fixture := sigstoretest.NewFixture(t)
+	signatures := fixture.NewRegistry(signedRef, fixture.SignWithKey(sigstoretest.SimpleSigningPayload(t, signedRef), ""))
+
+	index, err := json.Marshal(map[string]interface{}{"manifests": []docker.Descriptor{unsigned, signed}})
+	require.NoError(t, err)
+
+	content := map[string][]byte{
+		"/v2/path/to/image/referrers/" + string(i.Digest()):               index,
+		"/v2/path/to/image/manifests/" + string(signed.Digest):            signedManifest,
+		"/v2/path/to/image/manifests/" + string(unsigned.Digest):          unsignedManifest,
+		"/v2/path/to/image/blobs/" + string(digest.FromBytes(vulnerable)): vulnerable,
+		"/v2/path/to/image/blobs/" + string(digest.FromBytes(clean)):      clean,
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b, ok := content[r.URL.Path]; ok {
+			_, _ = w.Write(b)
+			return
+		}
+
+		// signatures are served by the fixture's registry.
+		resp, err := signatures.Client().Get(signatures.URL + r.URL.Path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	defer server.Close()
+
+	verifier := sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false)
+
+	scanner := NewScanner(Grype, NewReferrerSource(vtesting.NewAuth(server), "", verifier))
+
+	vulns, err := scanner.Scan(context.Background(), i)
+	require.NoError(t, err)
+	assert.NotEmpty(t, vulns)
+
+	// reports signed by other keys are ignored.
+	other := sigstore.NewVerifier([]crypto.PublicKey{sigstoretest.NewFixture(t).PublicKey()}, nil, nil, false)
+
+	scanner = NewScanner(Grype, NewReferrerSource(vtesting.NewAuth(server), "", other))
+
+	_, err = scanner.Scan(context.Background(), i)
+	assert.Equal(t, ErrUnsignedReport, err)
+
+	scanner = NewScanner(Grype, NewReferrerSource(vtesting.NewAuth(server), "", nil))
+
+	_, err = scanner.Scan(context.Background(), i)
+	assert.Equal(t, ErrUnsignedReport, err)
+}
+
+func TestLatestReferrer(t *testing.T) {
+	referrer := func(d string, created string) docker.Descriptor {
+		descriptor := docker.Descriptor{Digest: digest.Digest("sha256:" + d)}
+		if "" != created {
+			descriptor.Annotations = map[string]string{CreatedAnnotation: created}
+		}
+		return descriptor
+	}
+
+	// the newest report is used, whatever order the registry lists them in.
+	latest, err := latestReferrer([]docker.Descriptor{
+		referrer("b", "2024-05-02T10:00:00Z"),
+		referrer("c", "2024-05-03T10:00:00Z"),
+		referrer("a", "2024-05-01T10:00:00Z"),
+		referrer("d", ""),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:c"), latest.Digest)
+
+	// reports created at the same time are ordered by digest.
+	latest, err = latestReferrer([]docker.Descriptor{
+		referrer("f", "2024-05-01T10:00:00Z"),
+		referrer("e", "2024-05-01T10:00:00Z"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:f"), latest.Digest)
+
+	_, err = latestReferrer([]docker.Descriptor{referrer("a", ""), referrer("b", "yesterday")})
+	assert.Equal(t, ErrUndatedReport, err)
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/docker"
+	"github.com/grafeas/voucher/v2/sigstore"
+)
+
+// CreatedAnnotation is the annotation which records when a report artifact
+// was created, as an RFC 3339 timestamp.
+const CreatedAnnotation = "org.opencontainers.image.created"
+
+// Source retrieves the vulnerability report for an image.
+type Source interface {
+	// GetReport returns the report for the passed image in the passed Format,
+	// or ErrNoReport if there isn't one.
+	GetReport(context.Context, voucher.ImageData, Format) ([]byte, error)
+}
+
+// directorySource is a Source which reads reports from a local directory.
+type directorySource struct {
+	dir string
+}
+
+// GetReport reads the report for the passed image from the directory. Reports
+// are named after the image's digest, with the algorithm separated by a dash
+// (eg. "sha256-<hex>.json").
+func (s *directorySource) GetReport(ctx context.Context, i voucher.ImageData, format Format) ([]byte, error) {
+	name := strings.Replace(i.Digest().String(), ":", "-", 1) + ".json"
+
+	b, err := os.ReadFile(filepath.Join(s.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoReport
+	}
+
+	return b, err
+}
+
+// NewDirectorySource creates a Source which reads reports from the passed
+// directory.
+func NewDirectorySource(dir string) Source {
+	return &directorySource{
+		dir: dir,
+	}
+}
+
+// referrerSource is a Source which reads reports from OCI artifacts which
+// refer to the image. Anyone who can push to the image's repository can
+// attach a report to it, so only reports signed by a trusted signer are read.
+type referrerSource struct {
+	auth         voucher.Auth
+	artifactType string
+	verifier     *sigstore.Verifier
+}
+
+// GetReport downloads the most recent signed report attached to the image, as
+// recorded by the created annotations of the reports' manifests.
+func (s *referrerSource) GetReport(ctx context.Context, i voucher.ImageData, format Format) ([]byte, error) {
+	if nil == s.auth {
+		return nil, voucher.ErrNoAuth
+	}
+
+	client, err := s.auth.ToClient(ctx, i)
+	if nil != err {
+		return nil, err
+	}
+
+	artifactType := s.artifactType
+	if "" == artifactType {
+		artifactType = format.ArtifactType()
+	}
+
+	referrers, err := docker.RequestReferrers(client, i, artifactType)
+	if nil != err {
+		return nil, err
+	}
+
+	if 0 == len(referrers) {
+		return nil, ErrNoReport
+	}
+
+	signed, err := s.signedReferrers(client, i, referrers)
+	if nil != err {
+		return nil, err
+	}
+
+	latest, err := latestReferrer(signed)
+	if nil != err {
+		return nil, err
+	}
+
+	return docker.RequestArtifact(client, i, latest)
+}
+
+// signedReferrers returns the passed referrers which are signed by a trusted
+// signer. Their annotations are replaced with those of their manifests,
+// which their signatures cover, as the annotations in the referrers index
+// are not signed. Returns ErrUnsignedReport if none of them are signed.
+func (s *referrerSource) signedReferrers(client *http.Client, i voucher.ImageData, referrers []docker.Descriptor) ([]docker.Descriptor, error) {
+	if nil == s.verifier {
+		return nil, ErrUnsignedReport
+	}
+
+	signed := make([]docker.Descriptor, 0, len(referrers))
+	for _, referrer := range referrers {
+		artifact, err := reference.WithDigest(reference.TrimNamed(i), referrer.Digest)
+		if nil != err {
+			return nil, err
+		}
+
+		signatures, err := sigstore.RequestSignatures(client, artifact)
+		if nil != err {
+			return nil, err
+		}
+
+		if _, err = s.verifier.VerifyImage(signatures, referrer.Digest); nil != err {
+			continue
+		}
+
+		if referrer.Annotations, err = docker.RequestArtifactAnnotations(client, i, referrer); nil != err {
+			return nil, err
+		}
+
+		signed = append(signed, referrer)
+	}
+
+	if 0 == len(signed) {
+		return nil, ErrUnsignedReport
+	}
+
+	return signed, nil
+}
+
+// latestReferrer returns the referrer which was created most recently.
+// Registries don't list referrers in any particular order, so referrers
+// without a created annotation are ignored, and ErrUndatedReport is returned
+// if none of them have one. Referrers created at the same time are ordered by
+// their digest.
+func latestReferrer(referrers []docker.Descriptor) (docker.Descriptor, error) {
+	var latest docker.Descriptor
+	var latestCreated time.Time
+
+	for _, referrer := range referrers {
+		created, err := time.Parse(time.RFC3339, referrer.Annotations[CreatedAnnotation])
+		if nil != err {
+			continue
+		}
+
+		if latestCreated.IsZero() || created.After(latestCreated) || (created.Equal(latestCreated) && referrer.Digest > latest.Digest) {
+			latest, latestCreated = referrer, created
+		}
+	}
+
+	if latestCreated.IsZero() {
+		return docker.Descriptor{}, ErrUndatedReport
+	}
+
+	return latest, nil
+}
+
+// NewReferrerSource creates a Source which reads reports from OCI artifacts
+// attached to images, using the passed Auth to connect to the registry. If
+// artifactType is empty, the Format's default artifact type is used. Only
+// reports whose artifacts are signed, as verified by the passed Verifier, are
+// read.
+func NewReferrerSource(auth voucher.Auth, artifactType string, verifier *sigstore.Verifier) Source {
+	return &referrerSource{
+		auth:         auth,
+		artifactType: artifactType,
+		verifier:     verifier,
+	}
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"encoding/json"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+const trivyName = "trivy"
+
+// trivyArtifactType is the artifact type that Trivy JSON reports are
+// attached to images with.
+const trivyArtifactType = "application/vnd.aquasec.trivy.report+json"
+
+// Trivy is the Format of reports written by Trivy's JSON output.
+var Trivy Format = trivyFormat{}
+
+// trivyReport is the subset of a Trivy JSON report that voucher uses.
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			PkgName         string `json:"PkgName"`
+			FixedVersion    string `json:"FixedVersion"`
+			Title           string `json:"Title"`
+			Description     string `json:"Description"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+type trivyFormat struct{}
+
+// Name returns the name of the format.
+func (trivyFormat) Name() string {
+	return trivyName
+}
+
+// ArtifactType returns the OCI artifact type of Trivy reports.
+func (trivyFormat) ArtifactType() string {
+	return trivyArtifactType
+}
+
+// Parse converts a Trivy JSON report into voucher.Vulnerabilities.
+func (trivyFormat) Parse(b []byte) ([]voucher.Vulnerability, error) {
+	var report trivyReport
+	if err := json.Unmarshal(b, &report); nil != err {
+		return nil, err
+	}
+
+	var vulns vulnerabilitySet
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			description := v.Title
+			if "" == description {
+				description = v.Description
+			}
+
+			vulns.add(voucher.Vulnerability{
+				Name:        v.VulnerabilityID,
+				Description: description,
+				Severity:    toSeverity(v.Severity),
+				FixedBy:     v.FixedVersion,
+				Package:     v.PkgName,
+			})
+		}
+	}
+
+	return vulns.vulnerabilities, nil
+}
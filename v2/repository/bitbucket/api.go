@@ -0,0 +1,183 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// pageLimit is the maximum number of pages of results requested from
+// endpoints that return lists.
+const pageLimit = 3
+
+// perPage is the number of results requested in each page.
+const perPage = 100
+
+// errNotFound is the error returned when the Bitbucket API responds with a
+// 404.
+var errNotFound = errors.New("not found")
+
+// errForbidden is the error returned when the Bitbucket API responds with a
+// 403, such as when the credentials can't read the repository's settings.
+var errForbidden = errors.New("forbidden")
+
+// errCreatingRepositoryMetadata is the error returned when we fail to create
+// repository metadata.
+var errCreatingRepositoryMetadata = errors.New("failed to create repository metadata")
+
+// api connects to a Bitbucket REST API.
+type api struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// newAPI creates a new api, which connects to the Bitbucket REST API at the
+// passed base URL. App passwords are used as basic auth, and access tokens as
+// bearer tokens.
+func newAPI(ctx context.Context, auth *repository.Auth, baseURL string) (api, error) {
+	if auth == nil {
+		return api{}, fmt.Errorf("must provide authentication")
+	}
+
+	if _, err := url.Parse(baseURL); nil != err {
+		return api{}, fmt.Errorf("invalid Bitbucket API URL: %v", err)
+	}
+
+	a := api{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+
+	switch auth.Type() {
+	case repository.TokenAuthType:
+		sts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: auth.Token},
+		)
+		a.httpClient = oauth2.NewClient(ctx, sts)
+	case repository.UserPasswordAuthType:
+		a.httpClient = &http.Client{
+			Transport: &basicAuthTransport{
+				username: auth.Username,
+				password: auth.Password,
+			},
+		}
+	default:
+		return api{}, fmt.Errorf("unsupported auth type: %s", auth.Type())
+	}
+
+	return a, nil
+}
+
+// basicAuthTransport is an http.RoundTripper which authenticates requests
+// with a username and password, such as a Bitbucket app password.
+type basicAuthTransport struct {
+	username string
+	password string
+}
+
+// RoundTrip authenticates the passed request and sends it.
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// get requests the Bitbucket API endpoint at the passed path, and decodes the
+// JSON response into v. Absolute URLs, such as the links to the next page of
+// results, are requested as is.
+func (a api) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		u = a.baseURL + path
+	}
+	if 0 < len(query) {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if nil != err {
+		return err
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if nil != err {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errNotFound
+	case http.StatusForbidden:
+		return errForbidden
+	default:
+		return fmt.Errorf("Bitbucket API request to %s failed with status %s", req.URL.Path, resp.Status)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); nil != err {
+		return fmt.Errorf("could not decode Bitbucket API response: %w", err)
+	}
+
+	return nil
+}
+
+// buildStatus converts the states of the builds of a commit to a commit
+// status. The commit fails if any build failed, and succeeds once every build
+// succeeded.
+func buildStatus(states []string) string {
+	if 0 == len(states) {
+		return repository.CommitStatusExpected
+	}
+
+	status := repository.CommitStatusSuccess
+	for _, state := range states {
+		switch state {
+		case "SUCCESSFUL":
+		case "FAILED", "STOPPED", "CANCELLED":
+			return repository.CommitStatusFAilure
+		case "INPROGRESS":
+			status = repository.CommitStatusPending
+		default:
+			if repository.CommitStatusSuccess == status {
+				status = repository.CommitStatusError
+			}
+		}
+	}
+
+	return status
+}
+
+// hasRequiredApprovals returns true if a pull request with the passed number
+// of approvals meets the number of approvals required. At least one approval
+// is always required.
+func hasRequiredApprovals(approvals, required int) bool {
+	if 1 > required {
+		required = 1
+	}
+
+	return approvals >= required
+}
+
+// commitURL returns the URL of the commit with the passed SHA in the
+// repository with the passed web URL.
+func commitURL(repositoryURL, sha string) string {
+	return strings.TrimSuffix(repositoryURL, "/") + "/commits/" + sha
+}
+
+// IsBitbucketRepoClient returns true if the passed repository.Client is a
+// Bitbucket Cloud or Bitbucket Data Center client.
+func IsBitbucketRepoClient(repositoryClient repository.Client) bool {
+	switch repositoryClient.(type) {
+	case *cloudClient, *serverClient:
+		return true
+	}
+	return false
+}
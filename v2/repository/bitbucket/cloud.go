@@ -0,0 +1,379 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// CloudAPIURL is the URL of the Bitbucket Cloud REST API.
+const CloudAPIURL = "https://api.bitbucket.org/2.0"
+
+// cloudRepository is a repository, as returned by the Bitbucket Cloud
+// repositories API.
+type cloudRepository struct {
+	FullName   string `json:"full_name"`
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		HTML link `json:"html"`
+	} `json:"links"`
+	Workspace struct {
+		Slug  string `json:"slug"`
+		Links struct {
+			HTML link `json:"html"`
+		} `json:"links"`
+	} `json:"workspace"`
+}
+
+// link is a link to a Bitbucket Cloud resource.
+type link struct {
+	Href string `json:"href"`
+}
+
+// cloudCommit is a commit, as returned by the Bitbucket Cloud commits API.
+type cloudCommit struct {
+	Hash string `json:"hash"`
+}
+
+// cloudPullRequest is a pull request, as returned by the Bitbucket Cloud pull
+// requests API.
+type cloudPullRequest struct {
+	ID     int    `json:"id"`
+	State  string `json:"state"`
+	Source struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"source"`
+	Destination struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"destination"`
+	MergeCommit  *cloudCommit `json:"merge_commit"`
+	Participants []struct {
+		Approved bool `json:"approved"`
+	} `json:"participants"`
+}
+
+// cloudBranchRestriction is a branch restriction, as returned by the
+// Bitbucket Cloud branch restrictions API.
+type cloudBranchRestriction struct {
+	Kind            string `json:"kind"`
+	Value           int    `json:"value"`
+	BranchMatchKind string `json:"branch_match_kind"`
+	Pattern         string `json:"pattern"`
+}
+
+// cloudPage is a page of results, as returned by the Bitbucket Cloud API.
+type cloudPage struct {
+	Values []json.RawMessage `json:"values"`
+	Next   string            `json:"next"`
+}
+
+// cloudClient represents the Bitbucket Cloud implementation of
+// repository.Client
+type cloudClient struct {
+	api
+}
+
+// NewCloudClient creates a new Bitbucket Cloud client, which connects to the
+// Bitbucket Cloud REST API at the passed base URL (eg. CloudAPIURL). It
+// authenticates with an app password, or with an access token.
+func NewCloudClient(ctx context.Context, auth *repository.Auth, baseURL string) (repository.Client, error) {
+	a, err := newAPI(ctx, auth, baseURL)
+	if nil != err {
+		return nil, err
+	}
+
+	return &cloudClient{api: a}, nil
+}
+
+// GetOrganization retrieves the workspace that the repository belongs to.
+func (bbc *cloudClient) GetOrganization(ctx context.Context, details repository.BuildDetail) (repository.Organization, error) {
+	r, err := bbc.getRepository(ctx, details)
+	if nil != err {
+		return repository.Organization{}, err
+	}
+
+	workspaceURL, err := url.Parse(r.Workspace.Links.HTML.Href)
+	if nil != err {
+		return repository.Organization{}, fmt.Errorf("error parsing workspace url %s", r.Workspace.Links.HTML.Href)
+	}
+
+	return repository.Organization{
+		Alias: r.Workspace.Slug,
+		VCS:   workspaceURL.Host,
+		Name:  r.Workspace.Slug,
+	}, nil
+}
+
+// GetCommit retrieves the build status and pull requests of the commit the
+// image was built from. Bitbucket Cloud doesn't verify commit signatures, so
+// commits are never considered signed.
+func (bbc *cloudClient) GetCommit(ctx context.Context, details repository.BuildDetail) (repository.Commit, error) {
+	if "" == details.Commit {
+		return repository.Commit{}, fmt.Errorf("error creating a commit url. Error: no commit in build details")
+	}
+
+	r, err := bbc.getRepository(ctx, details)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	repoPath := cloudRepositoryPath(r)
+
+	var c cloudCommit
+	if err = bbc.get(ctx, repoPath+"/commit/"+url.PathEscape(details.Commit), nil, &c); nil != err {
+		return repository.Commit{}, fmt.Errorf("GetCommit request could not be completed. Error: %s", err)
+	}
+
+	status, err := bbc.getStatus(ctx, repoPath, c.Hash)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	pullRequests, err := bbc.getPullRequests(ctx, r, c.Hash)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	return repository.NewCommit(commitURL(r.Links.HTML.Href, c.Hash), []repository.Check{}, status, false, pullRequests), nil
+}
+
+// GetDefaultBranch retrieves the main branch of the repository.
+func (bbc *cloudClient) GetDefaultBranch(ctx context.Context, details repository.BuildDetail) (repository.Branch, error) {
+	r, err := bbc.getRepository(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	return bbc.getBranch(ctx, r, r.MainBranch.Name, details.Commit)
+}
+
+// GetBranch retrieves the branch with the passed name.
+func (bbc *cloudClient) GetBranch(ctx context.Context, details repository.BuildDetail, name string) (repository.Branch, error) {
+	r, err := bbc.getRepository(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	return bbc.getBranch(ctx, r, name, details.Commit)
+}
+
+// getRepository requests the repository in the passed BuildDetail.
+func (bbc *cloudClient) getRepository(ctx context.Context, details repository.BuildDetail) (cloudRepository, error) {
+	repo := repository.NewRepositoryMetadata(details.RepositoryURL)
+	if nil == repo || "" == repo.Name {
+		return cloudRepository{}, errCreatingRepositoryMetadata
+	}
+
+	var r cloudRepository
+	if err := bbc.get(ctx, "/repositories/"+url.PathEscape(repo.Organization)+"/"+url.PathEscape(repo.Name), nil, &r); nil != err {
+		return cloudRepository{}, fmt.Errorf("error getting repository %s/%s: %s", repo.Organization, repo.Name, err)
+	}
+
+	return r, nil
+}
+
+// getBranch requests the branch with the passed name. Rather than the
+// branch's whole history, its commits are its head, followed by the commit
+// with the passed SHA if the branch contains it.
+func (bbc *cloudClient) getBranch(ctx context.Context, r cloudRepository, name, sha string) (repository.Branch, error) {
+	repoPath := cloudRepositoryPath(r)
+
+	var b struct {
+		Name   string      `json:"name"`
+		Target cloudCommit `json:"target"`
+	}
+	if err := bbc.get(ctx, repoPath+"/refs/branches/"+url.PathEscape(name), nil, &b); nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	commits := []repository.CommitRef{repository.NewCommitRef(commitURL(r.Links.HTML.Href, b.Target.Hash))}
+	if "" == sha || sha == b.Target.Hash {
+		return repository.NewBranch(b.Name, commits), nil
+	}
+
+	// the branch contains the commit if none of the commit's history is
+	// missing from the branch.
+	var p cloudPage
+	err := bbc.get(ctx, repoPath+"/commits", url.Values{"include": {sha}, "exclude": {b.Name}, "pagelen": {"1"}}, &p)
+	if nil != err && errNotFound != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	if nil == err && 0 == len(p.Values) {
+		commits = append(commits, repository.NewCommitRef(commitURL(r.Links.HTML.Href, sha)))
+	}
+
+	return repository.NewBranch(b.Name, commits), nil
+}
+
+// getStatus requests the build statuses of the commit with the passed SHA, and
+// returns the status of the commit.
+func (bbc *cloudClient) getStatus(ctx context.Context, repoPath, sha string) (string, error) {
+	items, err := bbc.getPages(ctx, repoPath+"/commit/"+url.PathEscape(sha)+"/statuses", nil)
+	if nil != err {
+		return "", fmt.Errorf("GetCommit statuses request could not be completed. Error: %s", err)
+	}
+
+	states := make([]string, 0, len(items))
+	for _, item := range items {
+		var s struct {
+			State string `json:"state"`
+		}
+		if err = json.Unmarshal(item, &s); nil != err {
+			return "", err
+		}
+		states = append(states, s.State)
+	}
+
+	return buildStatus(states), nil
+}
+
+// getPullRequests requests the pull requests associated with the commit with
+// the passed SHA, and whether the merged ones have the approvals their
+// destination branch requires.
+func (bbc *cloudClient) getPullRequests(ctx context.Context, r cloudRepository, sha string) ([]repository.PullRequest, error) {
+	repoPath := cloudRepositoryPath(r)
+
+	items, err := bbc.getPages(ctx, repoPath+"/commit/"+url.PathEscape(sha)+"/pullrequests", nil)
+	if nil != err {
+		return nil, fmt.Errorf("GetCommit pull requests request could not be completed. Error: %s", err)
+	}
+
+	var restrictions []cloudBranchRestriction
+	pullRequests := make([]repository.PullRequest, 0, len(items))
+	for _, item := range items {
+		var summary cloudPullRequest
+		if err = json.Unmarshal(item, &summary); nil != err {
+			return nil, err
+		}
+
+		// the pull requests of a commit are listed without their participants.
+		var pr cloudPullRequest
+		if err = bbc.get(ctx, repoPath+"/pullrequests/"+strconv.Itoa(summary.ID), nil, &pr); nil != err {
+			return nil, fmt.Errorf("GetCommit pull request request could not be completed. Error: %s", err)
+		}
+
+		isMerged := "MERGED" == pr.State
+
+		var mergeCommit repository.CommitRef
+		hasApprovals := false
+		if isMerged {
+			if nil == restrictions {
+				if restrictions, err = bbc.getApprovalRestrictions(ctx, repoPath); nil != err {
+					return nil, err
+				}
+			}
+
+			approvals := 0
+			for _, participant := range pr.Participants {
+				if participant.Approved {
+					approvals++
+				}
+			}
+
+			hasApprovals = hasRequiredApprovals(approvals, requiredCloudApprovals(restrictions, pr.Destination.Branch.Name))
+
+			if nil != pr.MergeCommit {
+				mergeCommit = repository.NewCommitRef(commitURL(r.Links.HTML.Href, pr.MergeCommit.Hash))
+			}
+		}
+
+		pullRequests = append(pullRequests, repository.NewPullRequest(
+			pr.Destination.Branch.Name,
+			pr.Source.Branch.Name,
+			isMerged,
+			mergeCommit,
+			hasApprovals,
+		))
+	}
+
+	return pullRequests, nil
+}
+
+// getApprovalRestrictions requests the branch restrictions which require
+// approvals to merge. Reading branch restrictions requires admin access to
+// the repository, so no restrictions are returned if it's forbidden.
+func (bbc *cloudClient) getApprovalRestrictions(ctx context.Context, repoPath string) ([]cloudBranchRestriction, error) {
+	items, err := bbc.getPages(ctx, repoPath+"/branch-restrictions", url.Values{"kind": {"require_approvals_to_merge"}})
+	if errForbidden == err || errNotFound == err {
+		return []cloudBranchRestriction{}, nil
+	}
+	if nil != err {
+		return nil, fmt.Errorf("GetCommit branch restrictions request could not be completed. Error: %s", err)
+	}
+
+	restrictions := make([]cloudBranchRestriction, 0, len(items))
+	for _, item := range items {
+		var restriction cloudBranchRestriction
+		if err = json.Unmarshal(item, &restriction); nil != err {
+			return nil, err
+		}
+		restrictions = append(restrictions, restriction)
+	}
+
+	return restrictions, nil
+}
+
+// getPages requests each page of the Bitbucket Cloud API endpoint at the
+// passed path, up to the pageLimit, and returns the items in all of the
+// pages.
+func (bbc *cloudClient) getPages(ctx context.Context, path string, query url.Values) ([]json.RawMessage, error) {
+	if nil == query {
+		query = url.Values{}
+	}
+	query.Set("pagelen", strconv.Itoa(perPage))
+
+	results := make([]json.RawMessage, 0)
+	for page := 0; page < pageLimit && "" != path; page++ {
+		var p cloudPage
+		if err := bbc.get(ctx, path, query, &p); nil != err {
+			return nil, err
+		}
+
+		results = append(results, p.Values...)
+
+		// the next page link includes the query.
+		path, query = p.Next, nil
+	}
+
+	return results, nil
+}
+
+// requiredCloudApprovals returns the number of approvals the passed branch
+// restrictions require to merge into the branch with the passed name.
+func requiredCloudApprovals(restrictions []cloudBranchRestriction, branch string) int {
+	required := 0
+	for _, restriction := range restrictions {
+		if "require_approvals_to_merge" != restriction.Kind {
+			continue
+		}
+
+		// restrictions on branch types match branches by the branching model,
+		// which isn't considered.
+		if "glob" != restriction.BranchMatchKind {
+			continue
+		}
+
+		if ok, _ := path.Match(restriction.Pattern, branch); ok && restriction.Value > required {
+			required = restriction.Value
+		}
+	}
+
+	return required
+}
+
+// cloudRepositoryPath returns the API path of the passed repository.
+func cloudRepositoryPath(r cloudRepository) string {
+	return "/repositories/" + r.FullName
+}
@@ -0,0 +1,250 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const (
+	testCommit      = "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"
+	testMergeCommit = "97db2bc359ccc94d3b2d6f5daa4173e9e91c513b"
+	testCloudURL    = "https://bitbucket.org/workspace/my-app"
+)
+
+// newCloudTestServer creates a fake Bitbucket Cloud API, serving a repository
+// with a merge commit on its main branch which was approved twice.
+func newCloudTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+
+	responses := map[string]interface{}{
+		"/2.0/repositories/workspace/my-app": map[string]interface{}{
+			"full_name":  "workspace/my-app",
+			"mainbranch": map[string]interface{}{"name": "main"},
+			"links":      map[string]interface{}{"html": map[string]interface{}{"href": testCloudURL}},
+			"workspace": map[string]interface{}{
+				"slug":  "workspace",
+				"links": map[string]interface{}{"html": map[string]interface{}{"href": "https://bitbucket.org/workspace/"}},
+			},
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testMergeCommit: map[string]interface{}{
+			"hash": testMergeCommit,
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testMergeCommit + "/statuses": map[string]interface{}{
+			"values": []map[string]interface{}{{"state": "SUCCESSFUL"}, {"state": "SUCCESSFUL"}},
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testMergeCommit + "/pullrequests": map[string]interface{}{
+			"values": []map[string]interface{}{{"id": 7}},
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testCommit: map[string]interface{}{
+			"hash": testCommit,
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testCommit + "/statuses": map[string]interface{}{
+			"values": []map[string]interface{}{{"state": "SUCCESSFUL"}},
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testCommit + "/statuses/2": map[string]interface{}{
+			"values": []map[string]interface{}{{"state": "INPROGRESS"}},
+		},
+		"/2.0/repositories/workspace/my-app/commit/" + testCommit + "/pullrequests": map[string]interface{}{
+			"values": []map[string]interface{}{{"id": 8}},
+		},
+		"/2.0/repositories/workspace/my-app/pullrequests/7": map[string]interface{}{
+			"id":           7,
+			"state":        "MERGED",
+			"source":       map[string]interface{}{"branch": map[string]interface{}{"name": "feature"}},
+			"destination":  map[string]interface{}{"branch": map[string]interface{}{"name": "main"}},
+			"merge_commit": map[string]interface{}{"hash": testMergeCommit},
+			"participants": []map[string]interface{}{{"approved": true}, {"approved": true}, {"approved": false}},
+		},
+		"/2.0/repositories/workspace/my-app/pullrequests/8": map[string]interface{}{
+			"id":           8,
+			"state":        "OPEN",
+			"source":       map[string]interface{}{"branch": map[string]interface{}{"name": "feature"}},
+			"destination":  map[string]interface{}{"branch": map[string]interface{}{"name": "main"}},
+			"participants": []map[string]interface{}{},
+		},
+		"/2.0/repositories/workspace/my-app/branch-restrictions": map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"kind": "require_approvals_to_merge", "value": 2, "branch_match_kind": "glob", "pattern": "main"},
+				{"kind": "require_approvals_to_merge", "value": 3, "branch_match_kind": "glob", "pattern": "release/*"},
+			},
+		},
+		"/2.0/repositories/workspace/my-app/refs/branches/main": map[string]interface{}{
+			"name":   "main",
+			"target": map[string]interface{}{"hash": testMergeCommit},
+		},
+		"/2.0/repositories/workspace/my-app/refs/branches/feature": map[string]interface{}{
+			"name":   "feature",
+			"target": map[string]interface{}{"hash": testCommit},
+		},
+		"/2.0/repositories/workspace/my-app/commits": nil,
+	}
+
+	// the branches which contain each commit.
+	branches := map[string][]string{
+		testMergeCommit: {"main"},
+		testCommit:      {"main", "feature"},
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || "user" != username || "app-password" != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		response, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// the first page of the statuses links to the second.
+		if "/2.0/repositories/workspace/my-app/commit/"+testCommit+"/statuses" == r.URL.Path {
+			response = map[string]interface{}{
+				"values": []map[string]interface{}{{"state": "SUCCESSFUL"}},
+				"next":   server.URL + "/2.0/repositories/workspace/my-app/commit/" + testCommit + "/statuses/2",
+			}
+		}
+
+		// commits which are included but not excluded are missing from the
+		// excluded branch.
+		if "/2.0/repositories/workspace/my-app/commits" == r.URL.Path {
+			include, exclude := r.URL.Query().Get("include"), r.URL.Query().Get("exclude")
+			values := []map[string]interface{}{{"hash": include}}
+			for _, branch := range branches[include] {
+				if exclude == branch {
+					values = []map[string]interface{}{}
+				}
+			}
+			response = map[string]interface{}{"values": values}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newCloudTestClient(t *testing.T) repository.Client {
+	t.Helper()
+
+	server := newCloudTestServer(t)
+
+	c, err := NewCloudClient(context.Background(), &repository.Auth{Username: "user", Password: "app-password"}, server.URL+"/2.0/")
+	require.NoError(t, err)
+	assert.True(t, IsBitbucketRepoClient(c))
+
+	return c
+}
+
+func TestCloudGetCommit(t *testing.T) {
+	c := newCloudTestClient(t)
+
+	commit, err := c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/my-app.git",
+		Commit:        testMergeCommit,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, repository.NewCommit(
+		testCloudURL+"/commits/"+testMergeCommit,
+		[]repository.Check{},
+		repository.CommitStatusSuccess,
+		false,
+		[]repository.PullRequest{
+			repository.NewPullRequest("main", "feature", true, repository.NewCommitRef(testCloudURL+"/commits/"+testMergeCommit), true),
+		},
+	), commit)
+
+	// the feature branch commit is still building, and in an open pull request.
+	commit, err = c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "git@bitbucket.org:workspace/my-app.git",
+		Commit:        testCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.CommitStatusPending, commit.Status)
+	require.Len(t, commit.AssociatedPullRequests, 1)
+	assert.False(t, commit.AssociatedPullRequests[0].IsMerged)
+	assert.False(t, commit.AssociatedPullRequests[0].HasRequiredApprovals)
+}
+
+func TestCloudGetDefaultBranch(t *testing.T) {
+	c := newCloudTestClient(t)
+
+	branch, err := c.GetDefaultBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/my-app",
+		Commit:        testMergeCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testCloudURL + "/commits/" + testMergeCommit),
+	}), branch)
+
+	// the merged feature branch commit is on the main branch, behind its head.
+	branch, err = c.GetDefaultBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/my-app",
+		Commit:        testCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testCloudURL + "/commits/" + testMergeCommit),
+		repository.NewCommitRef(testCloudURL + "/commits/" + testCommit),
+	}), branch)
+
+	// the merge commit isn't on the feature branch.
+	branch, err = c.GetBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/my-app",
+		Commit:        testMergeCommit,
+	}, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("feature", []repository.CommitRef{
+		repository.NewCommitRef(testCloudURL + "/commits/" + testCommit),
+	}), branch)
+
+	_, err = c.GetBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/my-app",
+	}, "production")
+	assert.EqualError(t, err, "GetBranch request could not be completed. Error: not found")
+}
+
+func TestCloudGetOrganization(t *testing.T) {
+	c := newCloudTestClient(t)
+
+	org, err := c.GetOrganization(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/my-app",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.Organization{
+		Alias: "workspace",
+		VCS:   "bitbucket.org",
+		Name:  "workspace",
+	}, org)
+
+	_, err = c.GetOrganization(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.org/workspace/other-app",
+	})
+	assert.EqualError(t, err, "error getting repository workspace/other-app: not found")
+}
+
+func TestRequiredCloudApprovals(t *testing.T) {
+	restrictions := []cloudBranchRestriction{
+		{Kind: "require_approvals_to_merge", Value: 2, BranchMatchKind: "glob", Pattern: "main"},
+		{Kind: "require_approvals_to_merge", Value: 3, BranchMatchKind: "glob", Pattern: "release/*"},
+		{Kind: "require_approvals_to_merge", Value: 5, BranchMatchKind: "branching_model"},
+	}
+
+	assert.Equal(t, 2, requiredCloudApprovals(restrictions, "main"))
+	assert.Equal(t, 3, requiredCloudApprovals(restrictions, "release/1.0"))
+	assert.Equal(t, 0, requiredCloudApprovals(restrictions, "feature"))
+}
@@ -0,0 +1,384 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// serverAPIPath is the path of the Bitbucket Data Center REST API.
+const serverAPIPath = "/rest/api/1.0"
+
+// serverBuildStatusPath is the path of the Bitbucket Data Center build status
+// REST API.
+const serverBuildStatusPath = "/rest/build-status/1.0"
+
+// serverRepository is a repository, as returned by the Bitbucket Data Center
+// repositories API.
+type serverRepository struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Self []link `json:"self"`
+	} `json:"links"`
+}
+
+// webURL returns the URL of the repository's web page.
+func (r serverRepository) webURL() string {
+	if 0 == len(r.Links.Self) {
+		return ""
+	}
+
+	return strings.TrimSuffix(r.Links.Self[0].Href, "/browse")
+}
+
+// serverRef is a branch, or the ref of a pull request, as returned by the
+// Bitbucket Data Center API.
+type serverRef struct {
+	DisplayID string `json:"displayId"`
+}
+
+// serverPullRequest is a pull request, as returned by the Bitbucket Data
+// Center pull requests API.
+type serverPullRequest struct {
+	ID        int       `json:"id"`
+	State     string    `json:"state"`
+	FromRef   serverRef `json:"fromRef"`
+	ToRef     serverRef `json:"toRef"`
+	Reviewers []struct {
+		Approved bool `json:"approved"`
+	} `json:"reviewers"`
+	Properties struct {
+		MergeCommit *struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+}
+
+// serverPage is a page of results, as returned by the Bitbucket Data Center
+// API.
+type serverPage struct {
+	Values        []json.RawMessage `json:"values"`
+	IsLastPage    bool              `json:"isLastPage"`
+	NextPageStart int               `json:"nextPageStart"`
+}
+
+// serverClient represents the Bitbucket Data Center (and Bitbucket Server)
+// implementation of repository.Client
+type serverClient struct {
+	api
+	buildStatusURL string
+}
+
+// NewServerClient creates a new Bitbucket Data Center client, which connects
+// to the REST API at the passed base URL (eg.
+// "https://bitbucket.example.com/rest/api/1.0"). It authenticates with an
+// HTTP access token, or with a username and password.
+func NewServerClient(ctx context.Context, auth *repository.Auth, baseURL string) (repository.Client, error) {
+	a, err := newAPI(ctx, auth, baseURL)
+	if nil != err {
+		return nil, err
+	}
+
+	return &serverClient{
+		api:            a,
+		buildStatusURL: strings.TrimSuffix(a.baseURL, serverAPIPath) + serverBuildStatusPath,
+	}, nil
+}
+
+// GetOrganization retrieves the project that the repository belongs to. The
+// organization is named as it is in the repository's URL (eg.
+// "scm/project"), so that it matches repository groups configured with
+// clone URLs.
+func (bbs *serverClient) GetOrganization(ctx context.Context, details repository.BuildDetail) (repository.Organization, error) {
+	repo, r, err := bbs.getRepository(ctx, details)
+	if nil != err {
+		return repository.Organization{}, err
+	}
+
+	repositoryURL, err := url.Parse(r.webURL())
+	if nil != err {
+		return repository.Organization{}, fmt.Errorf("error parsing repository url %s", r.webURL())
+	}
+
+	return repository.Organization{
+		Alias: r.Project.Key,
+		VCS:   repositoryURL.Host,
+		Name:  repo.Organization,
+	}, nil
+}
+
+// GetCommit retrieves the build status and pull requests of the commit the
+// image was built from. Commit signatures are not verified by the API, so
+// commits are never considered signed.
+func (bbs *serverClient) GetCommit(ctx context.Context, details repository.BuildDetail) (repository.Commit, error) {
+	if "" == details.Commit {
+		return repository.Commit{}, fmt.Errorf("error creating a commit url. Error: no commit in build details")
+	}
+
+	_, r, err := bbs.getRepository(ctx, details)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	repoPath := serverRepositoryPath(r)
+
+	var c struct {
+		ID string `json:"id"`
+	}
+	if err = bbs.get(ctx, repoPath+"/commits/"+url.PathEscape(details.Commit), nil, &c); nil != err {
+		return repository.Commit{}, fmt.Errorf("GetCommit request could not be completed. Error: %s", err)
+	}
+
+	status, err := bbs.getStatus(ctx, c.ID)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	pullRequests, err := bbs.getPullRequests(ctx, r, c.ID)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	return repository.NewCommit(commitURL(r.webURL(), c.ID), []repository.Check{}, status, false, pullRequests), nil
+}
+
+// GetDefaultBranch retrieves the default branch of the repository.
+func (bbs *serverClient) GetDefaultBranch(ctx context.Context, details repository.BuildDetail) (repository.Branch, error) {
+	_, r, err := bbs.getRepository(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	var b serverRef
+	if err = bbs.get(ctx, serverRepositoryPath(r)+"/branches/default", nil, &b); nil != err {
+		return repository.Branch{}, fmt.Errorf("GetDefaultBranch request could not be completed. Error: %s", err)
+	}
+
+	return bbs.getBranch(ctx, r, b.DisplayID, details.Commit)
+}
+
+// GetBranch retrieves the branch with the passed name.
+func (bbs *serverClient) GetBranch(ctx context.Context, details repository.BuildDetail, name string) (repository.Branch, error) {
+	_, r, err := bbs.getRepository(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	items, err := bbs.getPages(ctx, serverRepositoryPath(r)+"/branches", url.Values{"filterText": {name}})
+	if nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	// branches are filtered by a substring of their name.
+	for _, item := range items {
+		var b serverRef
+		if err = json.Unmarshal(item, &b); nil != err {
+			return repository.Branch{}, err
+		}
+
+		if name == b.DisplayID {
+			return bbs.getBranch(ctx, r, name, details.Commit)
+		}
+	}
+
+	return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", errNotFound)
+}
+
+// getRepository requests the repository in the passed BuildDetail. The
+// project key is the last part of the organization in the repository's URL,
+// which is prefixed with "scm" in clone URLs.
+func (bbs *serverClient) getRepository(ctx context.Context, details repository.BuildDetail) (*repository.Metadata, serverRepository, error) {
+	repo := repository.NewRepositoryMetadata(details.RepositoryURL)
+	if nil == repo || "" == repo.Name {
+		return nil, serverRepository{}, errCreatingRepositoryMetadata
+	}
+
+	projectKey := repo.Organization[strings.LastIndex(repo.Organization, "/")+1:]
+
+	var r serverRepository
+	if err := bbs.get(ctx, "/projects/"+url.PathEscape(projectKey)+"/repos/"+url.PathEscape(repo.Name), nil, &r); nil != err {
+		return nil, serverRepository{}, fmt.Errorf("error getting repository %s/%s: %s", projectKey, repo.Name, err)
+	}
+
+	return repo, r, nil
+}
+
+// getBranch requests the head of the branch with the passed name. Rather
+// than the branch's whole history, its commits are its head, followed by the
+// commit with the passed SHA if the branch contains it.
+func (bbs *serverClient) getBranch(ctx context.Context, r serverRepository, name, sha string) (repository.Branch, error) {
+	repoPath := serverRepositoryPath(r)
+
+	var head serverPage
+	if err := bbs.get(ctx, repoPath+"/commits", url.Values{"until": {"refs/heads/" + name}, "limit": {"1"}}, &head); nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	var c struct {
+		ID string `json:"id"`
+	}
+	if 0 < len(head.Values) {
+		if err := json.Unmarshal(head.Values[0], &c); nil != err {
+			return repository.Branch{}, err
+		}
+	}
+
+	commits := []repository.CommitRef{repository.NewCommitRef(commitURL(r.webURL(), c.ID))}
+	if "" == sha || sha == c.ID {
+		return repository.NewBranch(name, commits), nil
+	}
+
+	// the branch contains the commit if none of the commit's history is
+	// missing from the branch.
+	var p serverPage
+	err := bbs.get(ctx, repoPath+"/commits", url.Values{"until": {sha}, "since": {"refs/heads/" + name}, "limit": {"1"}}, &p)
+	if nil != err && errNotFound != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	if nil == err && 0 == len(p.Values) {
+		commits = append(commits, repository.NewCommitRef(commitURL(r.webURL(), sha)))
+	}
+
+	return repository.NewBranch(name, commits), nil
+}
+
+// getStatus requests the build statuses of the commit with the passed SHA, and
+// returns the status of the commit.
+func (bbs *serverClient) getStatus(ctx context.Context, sha string) (string, error) {
+	items, err := bbs.getPages(ctx, bbs.buildStatusURL+"/commits/"+url.PathEscape(sha), nil)
+	if nil != err {
+		return "", fmt.Errorf("GetCommit build status request could not be completed. Error: %s", err)
+	}
+
+	states := make([]string, 0, len(items))
+	for _, item := range items {
+		var s struct {
+			State string `json:"state"`
+		}
+		if err = json.Unmarshal(item, &s); nil != err {
+			return "", err
+		}
+		states = append(states, s.State)
+	}
+
+	return buildStatus(states), nil
+}
+
+// getPullRequests requests the pull requests associated with the commit with
+// the passed SHA, and whether the merged ones have the approvals the
+// repository requires.
+func (bbs *serverClient) getPullRequests(ctx context.Context, r serverRepository, sha string) ([]repository.PullRequest, error) {
+	repoPath := serverRepositoryPath(r)
+
+	items, err := bbs.getPages(ctx, repoPath+"/commits/"+url.PathEscape(sha)+"/pull-requests", nil)
+	if nil != err {
+		return nil, fmt.Errorf("GetCommit pull requests request could not be completed. Error: %s", err)
+	}
+
+	required := -1
+	pullRequests := make([]repository.PullRequest, 0, len(items))
+	for _, item := range items {
+		var pr serverPullRequest
+		if err = json.Unmarshal(item, &pr); nil != err {
+			return nil, err
+		}
+
+		isMerged := "MERGED" == pr.State
+
+		var mergeCommit repository.CommitRef
+		hasApprovals := false
+		if isMerged {
+			if 0 > required {
+				if required, err = bbs.getRequiredApprovers(ctx, repoPath); nil != err {
+					return nil, err
+				}
+			}
+
+			approvals := 0
+			for _, reviewer := range pr.Reviewers {
+				if reviewer.Approved {
+					approvals++
+				}
+			}
+
+			hasApprovals = hasRequiredApprovals(approvals, required)
+
+			if nil != pr.Properties.MergeCommit {
+				mergeCommit = repository.NewCommitRef(commitURL(r.webURL(), pr.Properties.MergeCommit.ID))
+			}
+		}
+
+		pullRequests = append(pullRequests, repository.NewPullRequest(
+			pr.ToRef.DisplayID,
+			pr.FromRef.DisplayID,
+			isMerged,
+			mergeCommit,
+			hasApprovals,
+		))
+	}
+
+	return pullRequests, nil
+}
+
+// getRequiredApprovers requests the number of approvals that the
+// repository's pull request settings require. Reading the settings requires
+// admin access to the repository, so no approvals are required by the
+// settings if it's forbidden.
+func (bbs *serverClient) getRequiredApprovers(ctx context.Context, repoPath string) (int, error) {
+	var settings struct {
+		RequiredApprovers int `json:"requiredApprovers"`
+	}
+
+	err := bbs.get(ctx, repoPath+"/settings/pull-requests", nil, &settings)
+	if errForbidden == err || errNotFound == err {
+		return 0, nil
+	}
+	if nil != err {
+		return 0, fmt.Errorf("GetCommit pull request settings request could not be completed. Error: %s", err)
+	}
+
+	return settings.RequiredApprovers, nil
+}
+
+// getPages requests each page of the Bitbucket Data Center API endpoint at
+// the passed path, up to the pageLimit, and returns the items in all of the
+// pages.
+func (bbs *serverClient) getPages(ctx context.Context, path string, query url.Values) ([]json.RawMessage, error) {
+	if nil == query {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(perPage))
+
+	results := make([]json.RawMessage, 0)
+	for page, start := 0, 0; page < pageLimit; page++ {
+		query.Set("start", strconv.Itoa(start))
+
+		var p serverPage
+		if err := bbs.get(ctx, path, query, &p); nil != err {
+			return nil, err
+		}
+
+		results = append(results, p.Values...)
+
+		if p.IsLastPage {
+			break
+		}
+		start = p.NextPageStart
+	}
+
+	return results, nil
+}
+
+// serverRepositoryPath returns the API path of the passed repository.
+func serverRepositoryPath(r serverRepository) string {
+	return "/projects/" + url.PathEscape(r.Project.Key) + "/repos/" + url.PathEscape(r.Slug)
+}
@@ -0,0 +1,250 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const testServerURL = "https://bitbucket.example.com/projects/PROJ/repos/my-app"
+
+// newServerTestServer creates a fake Bitbucket Data Center API, serving a
+// repository with a merge commit on its default branch.
+func newServerTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]interface{}{
+		"/rest/api/1.0/projects/proj/repos/my-app": map[string]interface{}{
+			"slug":    "my-app",
+			"project": map[string]interface{}{"key": "PROJ"},
+			"links":   map[string]interface{}{"self": []map[string]interface{}{{"href": testServerURL + "/browse"}}},
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/commits/" + testMergeCommit: map[string]interface{}{
+			"id": testMergeCommit,
+		},
+		"/rest/build-status/1.0/commits/" + testMergeCommit: map[string]interface{}{
+			"values":     []map[string]interface{}{{"state": "SUCCESSFUL"}},
+			"isLastPage": true,
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/commits/" + testMergeCommit + "/pull-requests": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"id":         7,
+				"state":      "MERGED",
+				"fromRef":    map[string]interface{}{"displayId": "feature"},
+				"toRef":      map[string]interface{}{"displayId": "main"},
+				"reviewers":  []map[string]interface{}{{"approved": true}},
+				"properties": map[string]interface{}{"mergeCommit": map[string]interface{}{"id": testMergeCommit}},
+			}},
+			"isLastPage": true,
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/commits/" + testCommit: map[string]interface{}{
+			"id": testCommit,
+		},
+		"/rest/build-status/1.0/commits/" + testCommit: nil,
+		"/rest/api/1.0/projects/PROJ/repos/my-app/commits/" + testCommit + "/pull-requests": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"id":        8,
+				"state":     "MERGED",
+				"fromRef":   map[string]interface{}{"displayId": "hotfix"},
+				"toRef":     map[string]interface{}{"displayId": "main"},
+				"reviewers": []map[string]interface{}{{"approved": false}},
+			}},
+			"isLastPage": true,
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/settings/pull-requests": map[string]interface{}{
+			"requiredApprovers": 1,
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/branches/default": map[string]interface{}{
+			"displayId": "main",
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/branches": map[string]interface{}{
+			"values":     []map[string]interface{}{{"displayId": "main"}, {"displayId": "maintenance"}, {"displayId": "hotfix"}},
+			"isLastPage": true,
+		},
+		"/rest/api/1.0/projects/PROJ/repos/my-app/commits": nil,
+	}
+
+	// the head of each branch, and the branches which contain each commit.
+	heads := map[string]string{
+		"refs/heads/main":   testMergeCommit,
+		"refs/heads/hotfix": testCommit,
+	}
+	branches := map[string][]string{
+		testMergeCommit: {"refs/heads/main"},
+		testCommit:      {"refs/heads/main", "refs/heads/hotfix"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer token" != r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		response, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// the build statuses are served a page at a time.
+		if "/rest/build-status/1.0/commits/"+testCommit == r.URL.Path {
+			response = map[string]interface{}{
+				"values":        []map[string]interface{}{{"state": "SUCCESSFUL"}},
+				"isLastPage":    false,
+				"nextPageStart": 1,
+			}
+			if "1" == r.URL.Query().Get("start") {
+				response = map[string]interface{}{
+					"values":     []map[string]interface{}{{"state": "FAILED"}},
+					"isLastPage": true,
+				}
+			}
+		}
+
+		// commits until a branch start with its head, and commits until a
+		// commit since a branch are those missing from the branch.
+		if "/rest/api/1.0/projects/PROJ/repos/my-app/commits" == r.URL.Path {
+			until, since := r.URL.Query().Get("until"), r.URL.Query().Get("since")
+			values := []map[string]interface{}{{"id": until}}
+			if head, ok := heads[until]; ok {
+				values = []map[string]interface{}{{"id": head}}
+			}
+			for _, branch := range branches[until] {
+				if since == branch {
+					values = []map[string]interface{}{}
+				}
+			}
+			response = map[string]interface{}{"values": values, "isLastPage": true}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newServerTestClient(t *testing.T) repository.Client {
+	t.Helper()
+
+	server := newServerTestServer(t)
+
+	c, err := NewServerClient(context.Background(), &repository.Auth{Token: "token"}, server.URL+"/rest/api/1.0")
+	require.NoError(t, err)
+	assert.True(t, IsBitbucketRepoClient(c))
+
+	return c
+}
+
+func TestServerGetCommit(t *testing.T) {
+	c := newServerTestClient(t)
+
+	commit, err := c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+		Commit:        testMergeCommit,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, repository.NewCommit(
+		testServerURL+"/commits/"+testMergeCommit,
+		[]repository.Check{},
+		repository.CommitStatusSuccess,
+		false,
+		[]repository.PullRequest{
+			repository.NewPullRequest("main", "feature", true, repository.NewCommitRef(testServerURL+"/commits/"+testMergeCommit), true),
+		},
+	), commit)
+
+	// the hotfix commit failed a build, and was merged without approval.
+	commit, err = c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+		Commit:        testCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.CommitStatusFAilure, commit.Status)
+	require.Len(t, commit.AssociatedPullRequests, 1)
+	assert.True(t, commit.AssociatedPullRequests[0].IsMerged)
+	assert.False(t, commit.AssociatedPullRequests[0].HasRequiredApprovals)
+}
+
+func TestServerGetDefaultBranch(t *testing.T) {
+	c := newServerTestClient(t)
+
+	branch, err := c.GetDefaultBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+		Commit:        testMergeCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testServerURL + "/commits/" + testMergeCommit),
+	}), branch)
+
+	// the hotfix commit is on the default branch, behind its head.
+	branch, err = c.GetDefaultBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+		Commit:        testCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testServerURL + "/commits/" + testMergeCommit),
+		repository.NewCommitRef(testServerURL + "/commits/" + testCommit),
+	}), branch)
+
+	// the merge commit isn't on the hotfix branch.
+	branch, err = c.GetBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+		Commit:        testMergeCommit,
+	}, "hotfix")
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("hotfix", []repository.CommitRef{
+		repository.NewCommitRef(testServerURL + "/commits/" + testCommit),
+	}), branch)
+
+	_, err = c.GetBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+	}, "maint")
+	assert.EqualError(t, err, "GetBranch request could not be completed. Error: not found")
+}
+
+func TestServerGetOrganization(t *testing.T) {
+	c := newServerTestClient(t)
+
+	org, err := c.GetOrganization(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/my-app.git",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.Organization{
+		Alias: "PROJ",
+		VCS:   "bitbucket.example.com",
+		Name:  "scm/proj",
+	}, org)
+
+	_, err = c.GetOrganization(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://bitbucket.example.com/scm/proj/other-app.git",
+	})
+	assert.EqualError(t, err, "error getting repository proj/other-app: not found")
+}
+
+func TestNewClientAuth(t *testing.T) {
+	_, err := NewCloudClient(context.Background(), nil, CloudAPIURL)
+	assert.EqualError(t, err, "must provide authentication")
+
+	_, err = NewServerClient(context.Background(), &repository.Auth{AppID: "1", InstallationID: "2", PrivateKey: "key"}, "https://bitbucket.example.com/rest/api/1.0")
+	assert.EqualError(t, err, "unsupported auth type: githubinstall")
+}
+
+func TestBuildStatus(t *testing.T) {
+	assert.Equal(t, repository.CommitStatusExpected, buildStatus(nil))
+	assert.Equal(t, repository.CommitStatusSuccess, buildStatus([]string{"SUCCESSFUL"}))
+	assert.Equal(t, repository.CommitStatusPending, buildStatus([]string{"SUCCESSFUL", "INPROGRESS"}))
+	assert.Equal(t, repository.CommitStatusFAilure, buildStatus([]string{"INPROGRESS", "STOPPED"}))
+	assert.Equal(t, repository.CommitStatusError, buildStatus([]string{"UNKNOWN"}))
+}
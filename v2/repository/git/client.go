@@ -0,0 +1,326 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// DefaultFetchInterval is how long mirrors are used for before they are
+// fetched again, if no interval is configured.
+const DefaultFetchInterval = time.Minute
+
+// StatusNotesRef is the notes ref that commit statuses are read from. CI
+// systems can record the status of a commit with
+// `git notes --ref=ci add -m success <commit>`.
+const StatusNotesRef = "refs/notes/ci"
+
+// maxCommits is the maximum number of commits listed in a branch.
+const maxCommits = 300
+
+// errCreatingRepositoryMetadata is the error returned when we fail to create
+// repository metadata.
+var errCreatingRepositoryMetadata = errors.New("failed to create repository metadata")
+
+// Options configures a git client.
+type Options struct {
+	// CacheDir is the directory that mirrors of repositories are kept in.
+	CacheDir string
+
+	// CloneURL is the URL that repositories are cloned from, followed by
+	// their organization and name. If it's empty, repositories are cloned
+	// from the URL in their build details.
+	CloneURL string
+
+	// FetchInterval is how long mirrors are used for before they are fetched
+	// again. Defaults to DefaultFetchInterval.
+	FetchInterval time.Duration
+
+	// Signers holds the keys which are trusted to sign commits and tags. If
+	// it's nil, commits are never considered signed.
+	Signers *Signers
+}
+
+// client represents the plain git implementation of repository.Client, which
+// answers queries from local mirrors of repositories.
+type client struct {
+	mirrors  *mirrors
+	cloneURL string
+	signers  *Signers
+}
+
+// NewClient creates a new git client. Auth may be nil, for repositories
+// which can be cloned without credentials (or with credentials from the
+// environment, such as an SSH agent). Otherwise tokens are sent as bearer
+// tokens, and usernames and passwords as basic auth.
+func NewClient(auth *repository.Auth, options Options) (repository.Client, error) {
+	if nil != auth && "" == authorizationHeader(auth) {
+		return nil, fmt.Errorf("unsupported auth type: %s", auth.Type())
+	}
+
+	if "" == options.CacheDir {
+		return nil, errors.New("must provide a cache directory")
+	}
+
+	if err := os.MkdirAll(options.CacheDir, 0700); nil != err {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	if 0 == options.FetchInterval {
+		options.FetchInterval = DefaultFetchInterval
+	}
+
+	return &client{
+		mirrors: &mirrors{
+			dir:           options.CacheDir,
+			auth:          auth,
+			fetchInterval: options.FetchInterval,
+		},
+		cloneURL: strings.TrimSuffix(options.CloneURL, "/"),
+		signers:  options.Signers,
+	}, nil
+}
+
+// GetOrganization returns the organization in the repository's URL, once
+// the repository has been mirrored. Plain git servers have no organizations
+// of their own.
+func (gc *client) GetOrganization(ctx context.Context, details repository.BuildDetail) (repository.Organization, error) {
+	repo, _, err := gc.getMirror(ctx, details)
+	if nil != err {
+		return repository.Organization{}, err
+	}
+
+	return repository.Organization{
+		Alias: repo.Organization,
+		VCS:   repo.VCS,
+		Name:  repo.Organization,
+	}, nil
+}
+
+// GetCommit retrieves the commit the image was built from. The commit is
+// signed if it, or an annotated tag pointing to it, has a signature by a
+// trusted signer. Merge commits are treated as merged pull requests, which
+// never have the required approvals, as git doesn't record reviews. The
+// commit's status is read from its note in StatusNotesRef.
+func (gc *client) GetCommit(ctx context.Context, details repository.BuildDetail) (repository.Commit, error) {
+	if "" == details.Commit {
+		return repository.Commit{}, errors.New("error creating a commit url. Error: no commit in build details")
+	}
+
+	repo, mr, err := gc.getMirror(ctx, details)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	sha, err := mr.resolve(ctx, details.Commit)
+	if nil != err {
+		return repository.Commit{}, fmt.Errorf("GetCommit request could not be completed. Error: %s", err)
+	}
+
+	isSigned, err := gc.isSigned(ctx, mr, sha)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	// rev-list lists the commit followed by its parents.
+	parents, err := mr.fields(ctx, "rev-list", "--parents", "--max-count=1", sha)
+	if nil != err {
+		return repository.Commit{}, fmt.Errorf("GetCommit request could not be completed. Error: %s", err)
+	}
+
+	pullRequests := []repository.PullRequest{}
+	if 2 < len(parents) {
+		baseBranch, err := gc.getBaseBranch(ctx, mr, sha)
+		if nil != err {
+			return repository.Commit{}, err
+		}
+
+		pullRequests = append(pullRequests, repository.NewPullRequest(
+			baseBranch,
+			"",
+			true,
+			repository.NewCommitRef(commitURL(repo, sha)),
+			false,
+		))
+	}
+
+	return repository.NewCommit(commitURL(repo, sha), []repository.Check{}, getStatus(ctx, mr, sha), isSigned, pullRequests), nil
+}
+
+// GetDefaultBranch retrieves the branch that the repository's HEAD points
+// to.
+func (gc *client) GetDefaultBranch(ctx context.Context, details repository.BuildDetail) (repository.Branch, error) {
+	repo, mr, err := gc.getMirror(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	name, err := getDefaultBranchName(ctx, mr)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	return getBranch(ctx, repo, mr, name)
+}
+
+// GetBranch retrieves the branch with the passed name.
+func (gc *client) GetBranch(ctx context.Context, details repository.BuildDetail, name string) (repository.Branch, error) {
+	repo, mr, err := gc.getMirror(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	return getBranch(ctx, repo, mr, name)
+}
+
+// getMirror returns the mirror of the repository in the passed BuildDetail.
+func (gc *client) getMirror(ctx context.Context, details repository.BuildDetail) (*repository.Metadata, *mirror, error) {
+	repo := repository.NewRepositoryMetadata(details.RepositoryURL)
+	if nil == repo || "" == repo.Name {
+		return nil, nil, errCreatingRepositoryMetadata
+	}
+
+	remote := details.RepositoryURL
+	if "" != gc.cloneURL {
+		remote = gc.cloneURL + "/" + repo.Organization + "/" + repo.Name
+	}
+
+	mr, err := gc.mirrors.get(ctx, remote, repo.VCS+"/"+repo.Organization+"/"+repo.Name+".git")
+	if nil != err {
+		return nil, nil, err
+	}
+
+	return repo, mr, nil
+}
+
+// isSigned returns true if the commit with the passed SHA, or an annotated
+// tag pointing to it, was signed by a trusted signer.
+func (gc *client) isSigned(ctx context.Context, mr *mirror, sha string) (bool, error) {
+	if nil == gc.signers {
+		return false, nil
+	}
+
+	object, err := mr.run(ctx, "cat-file", "commit", sha)
+	if nil != err {
+		return false, err
+	}
+
+	if payload, signature, err := splitSignedCommit([]byte(object)); nil == err && nil == gc.signers.Verify(payload, signature) {
+		return true, nil
+	}
+
+	tags, err := mr.fields(ctx, "for-each-ref", "--points-at="+sha, "--format=%(objecttype):%(refname)", "refs/tags")
+	if nil != err {
+		return false, err
+	}
+
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, "tag:") {
+			continue
+		}
+
+		object, err = mr.run(ctx, "cat-file", "tag", strings.TrimPrefix(tag, "tag:"))
+		if nil != err {
+			return false, err
+		}
+
+		if payload, signature, err := splitSignedTag([]byte(object)); nil == err && nil == gc.signers.Verify(payload, signature) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getBaseBranch returns the name of the default branch if the commit with the
+// passed SHA is in its history, or an empty string if it isn't.
+func (gc *client) getBaseBranch(ctx context.Context, mr *mirror, sha string) (string, error) {
+	name, err := getDefaultBranchName(ctx, mr)
+	if nil != err {
+		return "", err
+	}
+
+	if _, err = mr.run(ctx, "merge-base", "--is-ancestor", sha, "refs/heads/"+name); nil != err {
+		return "", nil
+	}
+
+	return name, nil
+}
+
+// getDefaultBranchName returns the name of the branch that the repository's
+// HEAD points to.
+func getDefaultBranchName(ctx context.Context, mr *mirror) (string, error) {
+	out, err := mr.run(ctx, "symbolic-ref", "--short", "HEAD")
+	if nil != err {
+		return "", fmt.Errorf("GetDefaultBranch request could not be completed. Error: %s", err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// getBranch returns the branch with the passed name, and the commits in its
+// history, most recent first. Merged branches are followed through their
+// merge commits only.
+func getBranch(ctx context.Context, repo *repository.Metadata, mr *mirror, name string) (repository.Branch, error) {
+	head, err := mr.resolve(ctx, "refs/heads/"+name)
+	if nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	shas, err := mr.fields(ctx, "rev-list", "--first-parent", "--max-count="+strconv.Itoa(maxCommits), head)
+	if nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	commits := make([]repository.CommitRef, 0, len(shas))
+	for _, sha := range shas {
+		commits = append(commits, repository.NewCommitRef(commitURL(repo, sha)))
+	}
+
+	return repository.NewBranch(name, commits), nil
+}
+
+// getStatus returns the status recorded in the note on the commit with the
+// passed SHA in StatusNotesRef. Commits without a note are expected to get a
+// status.
+func getStatus(ctx context.Context, mr *mirror, sha string) string {
+	out, err := mr.run(ctx, "notes", "--ref="+StatusNotesRef, "show", sha)
+	if nil != err {
+		return repository.CommitStatusExpected
+	}
+
+	status := strings.Fields(out)
+	if 0 == len(status) {
+		return repository.CommitStatusExpected
+	}
+
+	switch strings.ToLower(status[0]) {
+	case "success":
+		return repository.CommitStatusSuccess
+	case "failure":
+		return repository.CommitStatusFAilure
+	case "pending":
+		return repository.CommitStatusPending
+	}
+
+	return repository.CommitStatusError
+}
+
+// commitURL returns the URL of the commit with the passed SHA in the passed
+// repository.
+func commitURL(repo *repository.Metadata, sha string) string {
+	return repo.String() + "/commit/" + sha
+}
+
+// IsGitRepoClient returns true if the passed repository.Client is a plain git
+// client.
+func IsGitRepoClient(repositoryClient repository.Client) bool {
+	_, ok := repositoryClient.(*client)
+	return ok
+}
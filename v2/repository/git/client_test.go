@@ -0,0 +1,335 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const testRepositoryURL = "https://git.example.com/org/app"
+
+// fixture is a repository that the client mirrors, and the keys that sign
+// its commits and tags.
+type fixture struct {
+	t          *testing.T
+	dir        string
+	sshSigner  ssh.Signer
+	pgpEntity  *openpgp.Entity
+	signers    *Signers
+	merge      string
+	feature    string
+	initial    string
+	unapproved string
+}
+
+// newFixture creates a repository with a signed merge of a feature branch
+// into main, which CI recorded as successful.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); nil != err {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_AUTHOR_NAME", "Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "author@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Committer")
+	t.Setenv("GIT_COMMITTER_EMAIL", "committer@example.com")
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshSigner, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	pgpEntity, err := openpgp.NewEntity("Tagger", "", "tagger@example.com", nil)
+	require.NoError(t, err)
+
+	var keyring bytes.Buffer
+	w, err := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, pgpEntity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	allowedSigners := `committer@example.com namespaces="git" ` + string(ssh.MarshalAuthorizedKey(sshSigner.PublicKey()))
+
+	signers, err := NewSigners(&keyring, strings.NewReader(allowedSigners))
+	require.NoError(t, err)
+
+	f := &fixture{
+		t:         t,
+		dir:       filepath.Join(t.TempDir(), "org", "app"),
+		sshSigner: sshSigner,
+		pgpEntity: pgpEntity,
+		signers:   signers,
+	}
+
+	f.git("init", "--quiet", "--initial-branch=main", f.dir)
+	f.git("commit", "--quiet", "--allow-empty", "-m", "initial")
+	f.initial = f.git("rev-parse", "HEAD")
+
+	f.git("checkout", "--quiet", "-b", "feature")
+	f.git("commit", "--quiet", "--allow-empty", "-m", "feature")
+	f.feature = f.git("rev-parse", "HEAD")
+
+	f.git("checkout", "--quiet", "main")
+	f.git("merge", "--quiet", "--no-ff", "-m", "Merge branch 'feature'", "feature")
+	f.merge = f.signCommit(f.git("rev-parse", "HEAD"))
+	f.git("update-ref", "refs/heads/main", f.merge)
+
+	f.git("notes", "--ref=ci", "add", "-m", "success", f.merge)
+
+	return f
+}
+
+// git runs git in the fixture's repository, and returns its trimmed output.
+func (f *fixture) git(args ...string) string {
+	f.t.Helper()
+
+	cmd := exec.Command("git", args...)
+	if "init" != args[0] {
+		cmd.Dir = f.dir
+	}
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(f.t, err, string(out))
+
+	return strings.TrimSpace(string(out))
+}
+
+// write writes the passed git object to the fixture's repository, and
+// returns its SHA.
+func (f *fixture) write(objectType string, object []byte) string {
+	f.t.Helper()
+
+	cmd := exec.Command("git", "hash-object", "-t", objectType, "-w", "--stdin")
+	cmd.Dir = f.dir
+	cmd.Stdin = bytes.NewReader(object)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(f.t, err, string(out))
+
+	return strings.TrimSpace(string(out))
+}
+
+// signCommit rewrites the commit with the passed SHA with an SSH signature,
+// and returns the SHA of the signed commit.
+func (f *fixture) signCommit(sha string) string {
+	f.t.Helper()
+
+	object := []byte(f.git("cat-file", "commit", sha) + "\n")
+	signature := signSSH(f.t, f.sshSigner, object)
+
+	headers, message, _ := strings.Cut(string(object), "\n\n")
+	signed := headers + "\ngpgsig " + strings.ReplaceAll(strings.TrimSuffix(signature, "\n"), "\n", "\n ") + "\n\n" + message
+
+	return f.write("commit", []byte(signed))
+}
+
+// tag creates an annotated tag of the commit with the passed SHA, signed
+// with the passed OpenPGP entity.
+func (f *fixture) tag(name, sha string, entity *openpgp.Entity) {
+	f.t.Helper()
+
+	object := "object " + sha + "\ntype commit\ntag " + name + "\ntagger Tagger <tagger@example.com> 0 +0000\n\nrelease\n"
+
+	var signature bytes.Buffer
+	require.NoError(f.t, openpgp.ArmoredDetachSign(&signature, entity, strings.NewReader(object), nil))
+
+	f.git("update-ref", "refs/tags/"+name, f.write("tag", []byte(object+signature.String()+"\n")))
+}
+
+// signSSH returns an armored SSH signature of the passed payload in the git
+// namespace, as made by `ssh-keygen -Y sign`.
+func signSSH(t *testing.T, signer ssh.Signer, payload []byte) string {
+	t.Helper()
+
+	digest := sha512.Sum512(payload)
+
+	signed := []byte(sshSigMagic)
+	signed = appendSSHString(signed, []byte(sshSigNamespace))
+	signed = appendSSHString(signed, nil)
+	signed = appendSSHString(signed, []byte("sha512"))
+	signed = appendSSHString(signed, digest[:])
+
+	sig, err := signer.Sign(rand.Reader, signed)
+	require.NoError(t, err)
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(sshSignature{
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var armored strings.Builder
+	armored.WriteString(sshSignatureHeader + "\n")
+	for 70 < len(encoded) {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n-----END SSH SIGNATURE-----\n")
+
+	return armored.String()
+}
+
+func newTestClient(t *testing.T, f *fixture) repository.Client {
+	t.Helper()
+
+	c, err := NewClient(nil, Options{
+		CacheDir:      t.TempDir(),
+		CloneURL:      "file://" + filepath.Dir(filepath.Dir(f.dir)),
+		FetchInterval: time.Nanosecond,
+		Signers:       f.signers,
+	})
+	require.NoError(t, err)
+	assert.True(t, IsGitRepoClient(c))
+
+	return c
+}
+
+func TestGetCommit(t *testing.T) {
+	f := newFixture(t)
+	c := newTestClient(t, f)
+
+	commit, err := c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: testRepositoryURL,
+		Commit:        f.merge,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, repository.NewCommit(
+		testRepositoryURL+"/commit/"+f.merge,
+		[]repository.Check{},
+		repository.CommitStatusSuccess,
+		true,
+		[]repository.PullRequest{
+			repository.NewPullRequest("main", "", true, repository.NewCommitRef(testRepositoryURL+"/commit/"+f.merge), false),
+		},
+	), commit)
+
+	// the feature commit is unsigned, until a trusted signer tags it.
+	commit, err = c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "git@git.example.com:org/app.git",
+		Commit:        f.feature,
+	})
+	require.NoError(t, err)
+	assert.False(t, commit.IsSigned)
+	assert.Equal(t, repository.CommitStatusExpected, commit.Status)
+	assert.Empty(t, commit.AssociatedPullRequests)
+
+	untrusted, err := openpgp.NewEntity("Untrusted", "", "untrusted@example.com", nil)
+	require.NoError(t, err)
+	f.tag("untrusted", f.feature, untrusted)
+
+	commit, err = c.GetCommit(context.Background(), repository.BuildDetail{RepositoryURL: testRepositoryURL, Commit: f.feature})
+	require.NoError(t, err)
+	assert.False(t, commit.IsSigned)
+
+	f.tag("v1.0", f.feature, f.pgpEntity)
+
+	commit, err = c.GetCommit(context.Background(), repository.BuildDetail{RepositoryURL: testRepositoryURL, Commit: f.feature})
+	require.NoError(t, err)
+	assert.True(t, commit.IsSigned)
+
+	_, err = c.GetCommit(context.Background(), repository.BuildDetail{RepositoryURL: testRepositoryURL, Commit: "0000000000000000000000000000000000000000"})
+	assert.EqualError(t, err, "GetCommit request could not be completed. Error: ref not found")
+}
+
+func TestGetBranch(t *testing.T) {
+	f := newFixture(t)
+	c := newTestClient(t, f)
+
+	details := repository.BuildDetail{RepositoryURL: testRepositoryURL}
+
+	branch, err := c.GetDefaultBranch(context.Background(), details)
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testRepositoryURL + "/commit/" + f.merge),
+		repository.NewCommitRef(testRepositoryURL + "/commit/" + f.initial),
+	}), branch)
+
+	branch, err = c.GetBranch(context.Background(), details, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("feature", []repository.CommitRef{
+		repository.NewCommitRef(testRepositoryURL + "/commit/" + f.feature),
+		repository.NewCommitRef(testRepositoryURL + "/commit/" + f.initial),
+	}), branch)
+
+	_, err = c.GetBranch(context.Background(), details, "production")
+	assert.EqualError(t, err, "GetBranch request could not be completed. Error: ref not found")
+
+	// the mirror is fetched once the fetch interval has passed.
+	f.git("commit", "--quiet", "--allow-empty", "-m", "unreviewed")
+	head := f.git("rev-parse", "HEAD")
+
+	branch, err = c.GetDefaultBranch(context.Background(), details)
+	require.NoError(t, err)
+	assert.Equal(t, testRepositoryURL+"/commit/"+head, branch.CommitRefs[0].URL)
+}
+
+func TestGetOrganization(t *testing.T) {
+	f := newFixture(t)
+	c := newTestClient(t, f)
+
+	org, err := c.GetOrganization(context.Background(), repository.BuildDetail{RepositoryURL: testRepositoryURL})
+	require.NoError(t, err)
+	assert.Equal(t, repository.Organization{Alias: "org", VCS: "git.example.com", Name: "org"}, org)
+
+	_, err = c.GetOrganization(context.Background(), repository.BuildDetail{RepositoryURL: "https://git.example.com/org/other-app"})
+	assert.Error(t, err)
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(&repository.Auth{AppID: "1", InstallationID: "2", PrivateKey: "key"}, Options{CacheDir: t.TempDir()})
+	assert.EqualError(t, err, "unsupported auth type: githubinstall")
+
+	_, err = NewClient(nil, Options{})
+	assert.EqualError(t, err, "must provide a cache directory")
+
+	assert.Equal(t, "Basic dXNlcjpwYXNz", authorizationHeader(&repository.Auth{Username: "user", Password: "pass"}))
+	assert.Equal(t, "Bearer token", authorizationHeader(&repository.Auth{Token: "token"}))
+}
+
+func TestMirrorsAreFetchedIndependently(t *testing.T) {
+	f := newFixture(t)
+
+	m := &mirrors{dir: t.TempDir(), fetchInterval: time.Nanosecond}
+
+	// another mirror being fetched doesn't hold up this one.
+	other := fetchOf(filepath.Join(m.dir, "other", "app"))
+	other.Lock()
+	defer other.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mr, err := m.get(ctx, "file://"+f.dir, "org/app")
+	require.NoError(t, err)
+
+	sha, err := mr.resolve(ctx, "main")
+	require.NoError(t, err)
+	assert.Equal(t, f.merge, sha)
+
+	// a mirror which was already cloned is fetched instead.
+	_, err = m.get(ctx, "file://"+f.dir, "org/app")
+	require.NoError(t, err)
+}
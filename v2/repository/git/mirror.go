@@ -0,0 +1,188 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// errRefNotFound is the error returned when a ref does not exist in a
+// repository.
+var errRefNotFound = errors.New("ref not found")
+
+// fetches records when each mirror was last fetched, by the mirror's
+// directory. Clients are created for each image that is checked, so this is
+// shared by all of them, which also stops them from fetching the same mirror
+// at once, while other mirrors are fetched.
+var fetches = struct {
+	sync.Mutex
+	mirrors map[string]*fetch
+}{mirrors: make(map[string]*fetch)}
+
+// fetch is held while a mirror is cloned or fetched, and records when it was
+// last fetched.
+type fetch struct {
+	sync.Mutex
+	time time.Time
+}
+
+// fetchOf returns the fetch of the mirror in the passed directory.
+func fetchOf(dir string) *fetch {
+	fetches.Lock()
+	defer fetches.Unlock()
+
+	f, ok := fetches.mirrors[dir]
+	if !ok {
+		f = &fetch{}
+		fetches.mirrors[dir] = f
+	}
+	return f
+}
+
+// mirrors is a cache of bare mirrors of remote repositories, in a local
+// directory.
+type mirrors struct {
+	dir           string
+	auth          *repository.Auth
+	fetchInterval time.Duration
+}
+
+// mirror is a bare mirror of a remote repository.
+type mirror struct {
+	dir  string
+	auth *repository.Auth
+}
+
+// get returns the mirror of the repository at the passed remote URL, stored
+// at the passed path in the cache. The mirror is cloned if it's not in the
+// cache, and fetched if it wasn't fetched within the fetch interval.
+func (m *mirrors) get(ctx context.Context, remote, path string) (*mirror, error) {
+	mr := &mirror{
+		dir:  filepath.Join(m.dir, filepath.FromSlash(path)),
+		auth: m.auth,
+	}
+
+	f := fetchOf(mr.dir)
+	f.Lock()
+	defer f.Unlock()
+
+	if !f.time.IsZero() && time.Since(f.time) < m.fetchInterval {
+		return mr, nil
+	}
+
+	if _, err := os.Stat(mr.dir); os.IsNotExist(err) {
+		if err = os.MkdirAll(filepath.Dir(mr.dir), 0700); nil != err {
+			return nil, err
+		}
+
+		// clone into a temporary directory first, so that a failed clone
+		// doesn't leave a broken mirror in the cache.
+		tmp := mr.dir + ".tmp"
+		_ = os.RemoveAll(tmp)
+		if _, err = mr.git(ctx, filepath.Dir(mr.dir), "clone", "--mirror", "--quiet", "--", remote, tmp); nil != err {
+			_ = os.RemoveAll(tmp)
+			return nil, fmt.Errorf("cloning %s: %w", remote, err)
+		}
+
+		if err = os.Rename(tmp, mr.dir); nil != err {
+			return nil, err
+		}
+	} else if _, err = mr.run(ctx, "remote", "update", "--prune"); nil != err {
+		return nil, fmt.Errorf("fetching %s: %w", remote, err)
+	}
+
+	f.time = time.Now()
+
+	return mr, nil
+}
+
+// run runs the git command with the passed arguments in the mirror, and
+// returns its output.
+func (mr *mirror) run(ctx context.Context, args ...string) (string, error) {
+	return mr.git(ctx, mr.dir, args...)
+}
+
+// git runs the git command with the passed arguments in the passed
+// directory, and returns its output. Credentials are passed to git in its
+// environment, rather than its arguments, so that they aren't visible to
+// other processes.
+func (mr *mirror) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+	)
+
+	if header := authorizationHeader(mr.auth); "" != header {
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: "+header,
+		)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); nil != err {
+		if msg := strings.TrimSpace(stderr.String()); "" != msg {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return stdout.String(), nil
+}
+
+// resolve returns the SHA of the commit that the passed revision refers to.
+// Returns errRefNotFound if the revision doesn't exist.
+func (mr *mirror) resolve(ctx context.Context, revision string) (string, error) {
+	out, err := mr.run(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", revision+"^{commit}")
+	if nil != err {
+		return "", errRefNotFound
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// fields runs the git command with the passed arguments in the mirror, and
+// returns the whitespace separated fields of its output, such as SHAs or
+// ref names.
+func (mr *mirror) fields(ctx context.Context, args ...string) ([]string, error) {
+	out, err := mr.run(ctx, args...)
+	if nil != err {
+		return nil, err
+	}
+
+	return strings.Fields(out), nil
+}
+
+// authorizationHeader returns the HTTP Authorization header for the passed
+// repository.Auth. Tokens are sent as bearer tokens, and usernames and
+// passwords as basic auth.
+func authorizationHeader(auth *repository.Auth) string {
+	if nil == auth {
+		return ""
+	}
+
+	switch auth.Type() {
+	case repository.TokenAuthType:
+		return "Bearer " + auth.Token
+	case repository.UserPasswordAuthType:
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password))
+	}
+
+	return ""
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+const (
+	pgpSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+	sshSignatureHeader = "-----BEGIN SSH SIGNATURE-----"
+)
+
+// errNotSigned is the error returned when a git object has no signature.
+var errNotSigned = errors.New("object is not signed")
+
+// errUnknownSignature is the error returned when a git object's signature is
+// neither an OpenPGP nor an SSH signature, such as an X.509 signature.
+var errUnknownSignature = errors.New("unsupported signature format")
+
+// Signers holds the keys which are trusted to sign commits and tags.
+type Signers struct {
+	pgp openpgp.EntityList
+	ssh []allowedSigner
+}
+
+// NewSigners creates a new Signers, which trusts the OpenPGP keys in the
+// passed armored keyring, and the SSH keys in the passed allowed signers file
+// (as used by git's gpg.ssh.allowedSignersFile). Either may be nil.
+func NewSigners(pgpKeyring io.Reader, allowedSigners io.Reader) (*Signers, error) {
+	signers := new(Signers)
+
+	if nil != pgpKeyring {
+		entities, err := openpgp.ReadArmoredKeyRing(pgpKeyring)
+		if nil != err {
+			return nil, fmt.Errorf("reading OpenPGP keyring: %w", err)
+		}
+		signers.pgp = entities
+	}
+
+	if nil != allowedSigners {
+		ssh, err := parseAllowedSigners(allowedSigners)
+		if nil != err {
+			return nil, fmt.Errorf("reading allowed signers: %w", err)
+		}
+		signers.ssh = ssh
+	}
+
+	return signers, nil
+}
+
+// Verify returns nil if the passed signature over the passed payload was
+// made by one of the trusted keys.
+func (s *Signers) Verify(payload []byte, signature string) error {
+	switch {
+	case strings.HasPrefix(signature, pgpSignatureHeader):
+		if 0 == len(s.pgp) {
+			return errors.New("no trusted OpenPGP keys")
+		}
+		_, err := openpgp.CheckArmoredDetachedSignature(s.pgp, bytes.NewReader(payload), strings.NewReader(signature))
+		return err
+	case strings.HasPrefix(signature, sshSignatureHeader):
+		return verifySSHSignature(s.ssh, payload, signature)
+	}
+
+	return errUnknownSignature
+}
+
+// splitSignedCommit splits a raw commit object into the payload that was
+// signed, which is the commit without its "gpgsig" header, and the
+// signature.
+func splitSignedCommit(object []byte) ([]byte, string, error) {
+	var payload bytes.Buffer
+	var signature strings.Builder
+
+	lines := strings.SplitAfter(string(object), "\n")
+	inHeaders, inSignature := true, false
+	for _, line := range lines {
+		switch {
+		case !inHeaders:
+			payload.WriteString(line)
+		case "\n" == line:
+			inHeaders = false
+			payload.WriteString(line)
+		case strings.HasPrefix(line, "gpgsig "):
+			inSignature = true
+			signature.WriteString(strings.TrimPrefix(line, "gpgsig "))
+		case inSignature && strings.HasPrefix(line, " "):
+			signature.WriteString(strings.TrimPrefix(line, " "))
+		default:
+			inSignature = false
+			payload.WriteString(line)
+		}
+	}
+
+	if 0 == signature.Len() {
+		return nil, "", errNotSigned
+	}
+
+	return payload.Bytes(), signature.String(), nil
+}
+
+// splitSignedTag splits a raw annotated tag object into the payload that was
+// signed, and the signature, which follows the tag's message.
+func splitSignedTag(object []byte) ([]byte, string, error) {
+	for _, header := range []string{pgpSignatureHeader, sshSignatureHeader} {
+		if i := bytes.LastIndex(object, []byte(header)); -1 != i {
+			return object[:i], string(object[i:]), nil
+		}
+	}
+
+	return nil, "", errNotSigned
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseAllowedSigners(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+
+	signers, err := parseAllowedSigners(strings.NewReader(
+		"# trusted committers\n" +
+			"alice@example.com,bob@example.com " + publicKey + " comment\n" +
+			"\n" +
+			`carol@example.com namespaces="file,email" ` + publicKey + "\n",
+	))
+	require.NoError(t, err)
+	require.Len(t, signers, 2)
+
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, signers[0].principals)
+	assert.True(t, signers[0].allows(sshSigNamespace))
+	assert.False(t, signers[1].allows(sshSigNamespace))
+
+	// carol's key isn't allowed to sign commits, but alice's is.
+	payload := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n")
+	signature := signSSH(t, signer, payload)
+	assert.NoError(t, verifySSHSignature(signers, payload, signature))
+	assert.Error(t, verifySSHSignature(signers[1:], payload, signature))
+	assert.Error(t, verifySSHSignature(signers, []byte("tampered"), signature))
+
+	_, err = parseAllowedSigners(strings.NewReader("alice@example.com cert-authority " + publicKey + "\n"))
+	assert.EqualError(t, err, "line 1: unsupported option cert-authority")
+}
+
+func TestSplitSignedCommit(t *testing.T) {
+	payload, signature, err := splitSignedCommit([]byte("tree abc\ngpgsig -----BEGIN SSH SIGNATURE-----\n U1NI\n \n -----END SSH SIGNATURE-----\nauthor A\n\nmessage\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "tree abc\nauthor A\n\nmessage\n", string(payload))
+	assert.Equal(t, "-----BEGIN SSH SIGNATURE-----\nU1NI\n\n-----END SSH SIGNATURE-----\n", signature)
+
+	_, _, err = splitSignedCommit([]byte("tree abc\n\nmessage\n"))
+	assert.Equal(t, errNotSigned, err)
+}
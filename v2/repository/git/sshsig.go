@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSigMagic is the preamble of SSH signatures, as described by OpenSSH's
+// PROTOCOL.sshsig.
+const sshSigMagic = "SSHSIG"
+
+// sshSigNamespace is the namespace git signs commits and tags in.
+const sshSigNamespace = "git"
+
+// allowedSigner is an SSH key which is trusted to sign, as listed in an
+// allowed signers file.
+type allowedSigner struct {
+	principals []string
+	key        ssh.PublicKey
+	namespaces []string
+}
+
+// allows returns true if the allowedSigner may sign in the passed namespace.
+func (signer allowedSigner) allows(namespace string) bool {
+	if 0 == len(signer.namespaces) {
+		return true
+	}
+
+	for _, allowed := range signer.namespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sshSignature is the body of an SSH signature.
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// parseAllowedSigners parses an allowed signers file, as described in the
+// ALLOWED SIGNERS section of ssh-keygen(1). Each line lists the principals
+// of a key, its options and the key. Only the "namespaces" option is
+// supported, lines with other options are rejected.
+func parseAllowedSigners(r io.Reader) ([]allowedSigner, error) {
+	signers := make([]allowedSigner, 0)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if "" == line || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		if 2 != len(fields) {
+			return nil, fmt.Errorf("line %d: missing key", n)
+		}
+
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(fields[1]))
+		if nil != err {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		signer := allowedSigner{
+			principals: strings.Split(fields[0], ","),
+			key:        key,
+		}
+
+		for _, option := range options {
+			name, value, _ := strings.Cut(option, "=")
+			if "namespaces" != strings.ToLower(name) {
+				return nil, fmt.Errorf("line %d: unsupported option %s", n, name)
+			}
+			signer.namespaces = strings.Split(strings.Trim(value, `"`), ",")
+		}
+
+		signers = append(signers, signer)
+	}
+
+	if err := scanner.Err(); nil != err {
+		return nil, err
+	}
+
+	return signers, nil
+}
+
+// verifySSHSignature returns nil if the passed armored SSH signature over
+// the passed payload was made in the git namespace by one of the passed
+// signers.
+func verifySSHSignature(signers []allowedSigner, payload []byte, armored string) error {
+	sig, err := parseSSHSignature(armored)
+	if nil != err {
+		return err
+	}
+
+	if sshSigNamespace != sig.Namespace {
+		return fmt.Errorf("signature is in the %q namespace, not %q", sig.Namespace, sshSigNamespace)
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported signature hash algorithm %s", sig.HashAlgorithm)
+	}
+	h.Write(payload)
+
+	var wireSignature ssh.Signature
+	if err = ssh.Unmarshal(sig.Signature, &wireSignature); nil != err {
+		return fmt.Errorf("parsing signature: %w", err)
+	}
+
+	signed := []byte(sshSigMagic)
+	signed = appendSSHString(signed, []byte(sig.Namespace))
+	signed = appendSSHString(signed, []byte(sig.Reserved))
+	signed = appendSSHString(signed, []byte(sig.HashAlgorithm))
+	signed = appendSSHString(signed, h.Sum(nil))
+
+	for _, signer := range signers {
+		if !bytes.Equal(signer.key.Marshal(), sig.PublicKey) || !signer.allows(sshSigNamespace) {
+			continue
+		}
+
+		return signer.key.Verify(signed, &wireSignature)
+	}
+
+	return errors.New("signature was not made by an allowed signer")
+}
+
+// parseSSHSignature parses an armored SSH signature.
+func parseSSHSignature(armored string) (sshSignature, error) {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, sshSignatureHeader)
+	body = strings.TrimSuffix(body, "-----END SSH SIGNATURE-----")
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if nil != err {
+		return sshSignature{}, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	if !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		return sshSignature{}, errors.New("not an SSH signature")
+	}
+
+	var sig sshSignature
+	if err = ssh.Unmarshal(blob[len(sshSigMagic):], &sig); nil != err {
+		return sshSignature{}, fmt.Errorf("parsing signature: %w", err)
+	}
+
+	if 1 != sig.Version {
+		return sshSignature{}, fmt.Errorf("unsupported signature version %d", sig.Version)
+	}
+
+	return sig, nil
+}
+
+// appendSSHString appends the passed value to the passed buffer in the SSH
+// wire format, prefixed by its length.
+func appendSSHString(buf []byte, value []byte) []byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(value)))
+	return append(append(buf, length[:]...), value...)
+}
@@ -0,0 +1,140 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// pageLimit is the maximum number of pages of results requested from
+// endpoints that return lists.
+const pageLimit = 3
+
+// perPage is the number of results requested in each page.
+const perPage = 100
+
+// errNotFound is the error returned when the GitLab API responds with a 404.
+var errNotFound = errors.New("not found")
+
+// project is a GitLab project, as returned by the projects API.
+type project struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+	Namespace         struct {
+		FullPath string `json:"full_path"`
+		WebURL   string `json:"web_url"`
+	} `json:"namespace"`
+}
+
+// commit is a commit, as returned by the commits API.
+type commit struct {
+	ID           string `json:"id"`
+	WebURL       string `json:"web_url"`
+	LastPipeline *struct {
+		Status string `json:"status"`
+	} `json:"last_pipeline"`
+}
+
+// signature is a commit signature, as returned by the commits API.
+type signature struct {
+	SignatureType      string `json:"signature_type"`
+	VerificationStatus string `json:"verification_status"`
+}
+
+// mergeRequest is a merge request, as returned by the merge requests API.
+type mergeRequest struct {
+	IID             int    `json:"iid"`
+	State           string `json:"state"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	SHA             string `json:"sha"`
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+}
+
+// mergedCommitSHA returns the SHA of the commit that the merge request was
+// merged as. Fast-forward merges have no merge or squash commit, so the head
+// of the merge request is merged as is.
+func (mr mergeRequest) mergedCommitSHA() string {
+	switch {
+	case "" != mr.MergeCommitSHA:
+		return mr.MergeCommitSHA
+	case "" != mr.SquashCommitSHA:
+		return mr.SquashCommitSHA
+	}
+	return mr.SHA
+}
+
+// approvals is the approval state of a merge request, as returned by the
+// merge request approvals API.
+type approvals struct {
+	Approved      bool `json:"approved"`
+	ApprovalsLeft int  `json:"approvals_left"`
+}
+
+// get requests the GitLab API endpoint at the passed path, and decodes the
+// JSON response into v.
+func (glc *client) get(ctx context.Context, path string, query url.Values, v interface{}) (http.Header, error) {
+	u := glc.baseURL + path
+	if 0 < len(query) {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if nil != err {
+		return nil, err
+	}
+
+	resp, err := glc.httpClient.Do(req)
+	if nil != err {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if http.StatusNotFound == resp.StatusCode {
+		return nil, errNotFound
+	}
+
+	if http.StatusOK != resp.StatusCode {
+		return nil, fmt.Errorf("GitLab API request to %s failed with status %s", path, resp.Status)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); nil != err {
+		return nil, fmt.Errorf("could not decode GitLab API response: %w", err)
+	}
+
+	return resp.Header, nil
+}
+
+// getPages requests each page of the GitLab API endpoint at the passed path,
+// up to the pageLimit, and returns the items in all of the pages.
+func (glc *client) getPages(ctx context.Context, path string, query url.Values) ([]json.RawMessage, error) {
+	if nil == query {
+		query = url.Values{}
+	}
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	results := make([]json.RawMessage, 0)
+	for page := 1; 0 < page && page <= pageLimit; {
+		query.Set("page", strconv.Itoa(page))
+
+		var items []json.RawMessage
+		header, err := glc.get(ctx, path, query, &items)
+		if nil != err {
+			return nil, err
+		}
+
+		results = append(results, items...)
+
+		// X-Next-Page is empty on the last page.
+		page, _ = strconv.Atoi(header.Get("X-Next-Page"))
+	}
+
+	return results, nil
+}
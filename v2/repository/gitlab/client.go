@@ -0,0 +1,293 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+// errCreatingRepositoryMetadata is the error returned when we fail to create
+// repository metadata.
+var errCreatingRepositoryMetadata = errors.New("failed to create repository metadata")
+
+// client represents the GitLab implementation of repository.Client
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient creates a new GitLab client, which connects to the GitLab REST API
+// at the passed base URL (eg. "https://gitlab.com/api/v4"). Self-managed
+// GitLab instances are supported by passing their API URL.
+func NewClient(ctx context.Context, auth *repository.Auth, baseURL string) (repository.Client, error) {
+	if auth == nil {
+		return nil, fmt.Errorf("must provide authentication")
+	}
+
+	if auth.Type() != repository.TokenAuthType {
+		return nil, fmt.Errorf("unsupported auth type: %s", auth.Type())
+	}
+
+	if _, err := url.Parse(baseURL); nil != err {
+		return nil, fmt.Errorf("invalid GitLab API URL: %v", err)
+	}
+
+	sts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: auth.Token},
+	)
+
+	return &client{
+		httpClient: oauth2.NewClient(ctx, sts),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// GetOrganization retrieves the group (or nested subgroup) that the
+// repository belongs to.
+func (glc *client) GetOrganization(ctx context.Context, details repository.BuildDetail) (repository.Organization, error) {
+	p, err := glc.getProject(ctx, details)
+	if nil != err {
+		return repository.Organization{}, err
+	}
+
+	groupURL, err := url.Parse(p.Namespace.WebURL)
+	if nil != err {
+		return repository.Organization{}, fmt.Errorf("error parsing group url %s", p.Namespace.WebURL)
+	}
+
+	return repository.Organization{
+		Alias: p.Namespace.FullPath,
+		VCS:   groupURL.Host,
+		Name:  p.Namespace.FullPath,
+	}, nil
+}
+
+// GetCommit retrieves the pipeline status, signature and merge requests of
+// the commit the image was built from.
+func (glc *client) GetCommit(ctx context.Context, details repository.BuildDetail) (repository.Commit, error) {
+	if "" == details.Commit {
+		return repository.Commit{}, errors.New("error creating a commit url. Error: no commit in build details")
+	}
+
+	p, err := glc.getProject(ctx, details)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	projectPath := projectAPIPath(p)
+
+	var c commit
+	if _, err = glc.get(ctx, projectPath+"/repository/commits/"+url.PathEscape(details.Commit), nil, &c); nil != err {
+		return repository.Commit{}, fmt.Errorf("GetCommit request could not be completed. Error: %s", err)
+	}
+
+	isSigned, err := glc.isSigned(ctx, projectPath, c.ID)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	pullRequests, err := glc.getMergeRequests(ctx, p, c.ID)
+	if nil != err {
+		return repository.Commit{}, err
+	}
+
+	status := repository.CommitStatusExpected
+	if nil != c.LastPipeline {
+		status = pipelineStatus(c.LastPipeline.Status)
+	}
+
+	return repository.NewCommit(commitURL(p, c.ID), []repository.Check{}, status, isSigned, pullRequests), nil
+}
+
+// GetDefaultBranch retrieves the default branch of the repository.
+func (glc *client) GetDefaultBranch(ctx context.Context, details repository.BuildDetail) (repository.Branch, error) {
+	p, err := glc.getProject(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	return glc.getBranch(ctx, p, p.DefaultBranch, details.Commit)
+}
+
+// GetBranch retrieves the branch with the passed name.
+func (glc *client) GetBranch(ctx context.Context, details repository.BuildDetail, name string) (repository.Branch, error) {
+	p, err := glc.getProject(ctx, details)
+	if nil != err {
+		return repository.Branch{}, err
+	}
+
+	return glc.getBranch(ctx, p, name, details.Commit)
+}
+
+// getProject requests the project of the repository in the passed
+// BuildDetail.
+func (glc *client) getProject(ctx context.Context, details repository.BuildDetail) (project, error) {
+	repo := repository.NewRepositoryMetadata(details.RepositoryURL)
+	if nil == repo || "" == repo.Name {
+		return project{}, errCreatingRepositoryMetadata
+	}
+
+	var p project
+	if _, err := glc.get(ctx, "/projects/"+url.PathEscape(repo.Organization+"/"+repo.Name), nil, &p); nil != err {
+		return project{}, fmt.Errorf("error getting project %s/%s: %s", repo.Organization, repo.Name, err)
+	}
+
+	return p, nil
+}
+
+// getBranch requests the branch with the passed name. Rather than the
+// branch's whole history, its commits are its head, followed by the commit
+// with the passed SHA if the branch contains it.
+func (glc *client) getBranch(ctx context.Context, p project, name, sha string) (repository.Branch, error) {
+	projectPath := projectAPIPath(p)
+
+	var b struct {
+		Name   string `json:"name"`
+		Commit commit `json:"commit"`
+	}
+	if _, err := glc.get(ctx, projectPath+"/repository/branches/"+url.PathEscape(name), nil, &b); nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	commits := []repository.CommitRef{repository.NewCommitRef(commitURL(p, b.Commit.ID))}
+	if "" == sha || sha == b.Commit.ID {
+		return repository.NewBranch(b.Name, commits), nil
+	}
+
+	contains, err := glc.branchContains(ctx, projectPath, b.Name, sha)
+	if nil != err {
+		return repository.Branch{}, fmt.Errorf("GetBranch request could not be completed. Error: %s", err)
+	}
+
+	if contains {
+		commits = append(commits, repository.NewCommitRef(commitURL(p, sha)))
+	}
+
+	return repository.NewBranch(b.Name, commits), nil
+}
+
+// branchContains returns true if the branch with the passed name contains the
+// commit with the passed SHA, by requesting the branches which contain the
+// commit until it is found.
+func (glc *client) branchContains(ctx context.Context, projectPath, name, sha string) (bool, error) {
+	query := url.Values{
+		"type":     {"branch"},
+		"per_page": {strconv.Itoa(perPage)},
+	}
+
+	for page := 1; 0 < page; {
+		query.Set("page", strconv.Itoa(page))
+
+		var refs []struct {
+			Name string `json:"name"`
+		}
+		header, err := glc.get(ctx, projectPath+"/repository/commits/"+url.PathEscape(sha)+"/refs", query, &refs)
+		if nil != err {
+			return false, err
+		}
+
+		for _, ref := range refs {
+			if name == ref.Name {
+				return true, nil
+			}
+		}
+
+		// X-Next-Page is empty on the last page.
+		page, _ = strconv.Atoi(header.Get("X-Next-Page"))
+	}
+
+	return false, nil
+}
+
+// isSigned returns true if the commit with the passed SHA has a signature
+// which GitLab verified.
+func (glc *client) isSigned(ctx context.Context, projectPath, sha string) (bool, error) {
+	var s signature
+	_, err := glc.get(ctx, projectPath+"/repository/commits/"+url.PathEscape(sha)+"/signature", nil, &s)
+	if errNotFound == err {
+		return false, nil
+	}
+	if nil != err {
+		return false, fmt.Errorf("GetCommit signature request could not be completed. Error: %s", err)
+	}
+
+	return "verified" == s.VerificationStatus || "verified_system" == s.VerificationStatus, nil
+}
+
+// getMergeRequests requests the merge requests associated with the commit
+// with the passed SHA, and the approval state of the merged ones.
+func (glc *client) getMergeRequests(ctx context.Context, p project, sha string) ([]repository.PullRequest, error) {
+	projectPath := projectAPIPath(p)
+
+	items, err := glc.getPages(ctx, projectPath+"/repository/commits/"+url.PathEscape(sha)+"/merge_requests", nil)
+	if nil != err {
+		return nil, fmt.Errorf("GetCommit merge requests request could not be completed. Error: %s", err)
+	}
+
+	pullRequests := make([]repository.PullRequest, 0, len(items))
+	for _, item := range items {
+		var mr mergeRequest
+		if err = json.Unmarshal(item, &mr); nil != err {
+			return nil, err
+		}
+
+		isMerged := "merged" == mr.State
+
+		var a approvals
+		if isMerged {
+			if _, err = glc.get(ctx, fmt.Sprintf("%s/merge_requests/%d/approvals", projectPath, mr.IID), nil, &a); nil != err {
+				return nil, fmt.Errorf("GetCommit approvals request could not be completed. Error: %s", err)
+			}
+		}
+
+		pullRequests = append(pullRequests, repository.NewPullRequest(
+			mr.TargetBranch,
+			mr.SourceBranch,
+			isMerged,
+			repository.NewCommitRef(commitURL(p, mr.mergedCommitSHA())),
+			a.Approved && 0 == a.ApprovalsLeft,
+		))
+	}
+
+	return pullRequests, nil
+}
+
+// projectAPIPath returns the API path of the passed project.
+func projectAPIPath(p project) string {
+	return fmt.Sprintf("/projects/%d", p.ID)
+}
+
+// commitURL returns the URL of the commit with the passed SHA in the passed
+// project.
+func commitURL(p project, sha string) string {
+	return p.WebURL + "/-/commit/" + sha
+}
+
+// pipelineStatus converts the status of a GitLab pipeline to a commit status.
+func pipelineStatus(status string) string {
+	switch status {
+	case "success":
+		return repository.CommitStatusSuccess
+	case "failed", "canceled", "skipped":
+		return repository.CommitStatusFAilure
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled", "manual":
+		return repository.CommitStatusPending
+	}
+	return repository.CommitStatusError
+}
+
+// IsGitlabRepoClient returns true if the passed repository.Client is a GitLab
+// client.
+func IsGitlabRepoClient(repositoryClient repository.Client) bool {
+	_, ok := repositoryClient.(*client)
+	return ok
+}
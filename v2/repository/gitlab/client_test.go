@@ -0,0 +1,229 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const (
+	testCommit      = "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"
+	testMergeCommit = "97db2bc359ccc94d3b2d6f5daa4173e9e91c513b"
+	testProjectURL  = "https://gitlab.example.com/group/subgroup/my-app"
+)
+
+// newTestServer creates a fake GitLab API, serving a project in a nested
+// subgroup with a signed merge commit on its default branch.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]interface{}{
+		"/api/v4/projects/group%2Fsubgroup%2Fmy-app": map[string]interface{}{
+			"id":                  42,
+			"path_with_namespace": "group/subgroup/my-app",
+			"web_url":             testProjectURL,
+			"default_branch":      "main",
+			"namespace": map[string]interface{}{
+				"full_path": "group/subgroup",
+				"web_url":   "https://gitlab.example.com/groups/group/subgroup",
+			},
+		},
+		"/api/v4/projects/42/repository/commits/" + testMergeCommit: map[string]interface{}{
+			"id":            testMergeCommit,
+			"web_url":       testProjectURL + "/-/commit/" + testMergeCommit,
+			"last_pipeline": map[string]interface{}{"status": "success"},
+		},
+		"/api/v4/projects/42/repository/commits/" + testCommit: map[string]interface{}{
+			"id":            testCommit,
+			"web_url":       testProjectURL + "/-/commit/" + testCommit,
+			"last_pipeline": map[string]interface{}{"status": "running"},
+		},
+		"/api/v4/projects/42/repository/commits/" + testMergeCommit + "/signature": map[string]interface{}{
+			"signature_type":      "PGP",
+			"verification_status": "verified",
+		},
+		"/api/v4/projects/42/repository/commits/" + testMergeCommit + "/merge_requests": []map[string]interface{}{
+			{"iid": 7, "state": "merged", "source_branch": "feature", "target_branch": "main", "sha": testCommit, "merge_commit_sha": testMergeCommit},
+		},
+		"/api/v4/projects/42/repository/commits/" + testCommit + "/merge_requests": []map[string]interface{}{
+			{"iid": 8, "state": "opened", "source_branch": "feature", "target_branch": "main", "sha": testCommit},
+		},
+		"/api/v4/projects/42/merge_requests/7/approvals": map[string]interface{}{
+			"approved":       true,
+			"approvals_left": 0,
+		},
+		"/api/v4/projects/42/repository/branches/main": map[string]interface{}{
+			"name":   "main",
+			"commit": map[string]interface{}{"id": testMergeCommit},
+		},
+		"/api/v4/projects/42/repository/branches/feature": map[string]interface{}{
+			"name":   "feature",
+			"commit": map[string]interface{}{"id": testCommit},
+		},
+		"/api/v4/projects/42/repository/commits/" + testCommit + "/refs": []map[string]interface{}{
+			{"type": "branch", "name": "feature"},
+			{"type": "branch", "name": "main"},
+		},
+		"/api/v4/projects/42/repository/commits/" + testMergeCommit + "/refs": []map[string]interface{}{
+			{"type": "branch", "name": "main"},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "Bearer token" != r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		response, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// the refs are split into pages of one ref.
+		if strings.HasSuffix(r.URL.Path, "/refs") {
+			refs := response.([]map[string]interface{})
+			assert.Equal(t, "branch", r.URL.Query().Get("type"))
+
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if page < len(refs) {
+				w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+			}
+			response = refs[page-1 : page]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestClient(t *testing.T) repository.Client {
+	t.Helper()
+
+	server := newTestServer(t)
+
+	c, err := NewClient(context.Background(), &repository.Auth{Token: "token"}, server.URL+"/api/v4/")
+	require.NoError(t, err)
+	assert.True(t, IsGitlabRepoClient(c))
+
+	return c
+}
+
+func TestGetCommit(t *testing.T) {
+	c := newTestClient(t)
+
+	commit, err := c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/subgroup/my-app.git",
+		Commit:        testMergeCommit,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, repository.NewCommit(
+		testProjectURL+"/-/commit/"+testMergeCommit,
+		[]repository.Check{},
+		repository.CommitStatusSuccess,
+		true,
+		[]repository.PullRequest{
+			repository.NewPullRequest("main", "feature", true, repository.NewCommitRef(testProjectURL+"/-/commit/"+testMergeCommit), true),
+		},
+	), commit)
+
+	// the feature branch commit is unsigned, and in an open merge request.
+	commit, err = c.GetCommit(context.Background(), repository.BuildDetail{
+		RepositoryURL: "git@gitlab.example.com:group/subgroup/my-app.git",
+		Commit:        testCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.CommitStatusPending, commit.Status)
+	assert.False(t, commit.IsSigned)
+	require.Len(t, commit.AssociatedPullRequests, 1)
+	assert.False(t, commit.AssociatedPullRequests[0].IsMerged)
+	assert.False(t, commit.AssociatedPullRequests[0].HasRequiredApprovals)
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	c := newTestClient(t)
+
+	branch, err := c.GetDefaultBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/subgroup/my-app",
+		Commit:        testMergeCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testProjectURL + "/-/commit/" + testMergeCommit),
+	}), branch)
+
+	// the merged feature branch commit is on the default branch, behind its
+	// head.
+	branch, err = c.GetDefaultBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/subgroup/my-app",
+		Commit:        testCommit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("main", []repository.CommitRef{
+		repository.NewCommitRef(testProjectURL + "/-/commit/" + testMergeCommit),
+		repository.NewCommitRef(testProjectURL + "/-/commit/" + testCommit),
+	}), branch)
+
+	// the merge commit isn't on the feature branch.
+	branch, err = c.GetBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/subgroup/my-app",
+		Commit:        testMergeCommit,
+	}, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, repository.NewBranch("feature", []repository.CommitRef{
+		repository.NewCommitRef(testProjectURL + "/-/commit/" + testCommit),
+	}), branch)
+
+	_, err = c.GetBranch(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/subgroup/my-app",
+	}, "production")
+	assert.EqualError(t, err, "GetBranch request could not be completed. Error: not found")
+}
+
+func TestGetOrganization(t *testing.T) {
+	c := newTestClient(t)
+
+	org, err := c.GetOrganization(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/subgroup/my-app",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.Organization{
+		Alias: "group/subgroup",
+		VCS:   "gitlab.example.com",
+		Name:  "group/subgroup",
+	}, org)
+
+	_, err = c.GetOrganization(context.Background(), repository.BuildDetail{
+		RepositoryURL: "https://gitlab.example.com/group/other-app",
+	})
+	assert.EqualError(t, err, "error getting project group/other-app: not found")
+}
+
+func TestNewClientAuth(t *testing.T) {
+	_, err := NewClient(context.Background(), nil, "https://gitlab.com/api/v4")
+	assert.EqualError(t, err, "must provide authentication")
+
+	_, err = NewClient(context.Background(), &repository.Auth{Username: "user", Password: "pass"}, "https://gitlab.com/api/v4")
+	assert.EqualError(t, err, "unsupported auth type: userpassword")
+}
+
+func TestPipelineStatus(t *testing.T) {
+	assert.Equal(t, repository.CommitStatusSuccess, pipelineStatus("success"))
+	assert.Equal(t, repository.CommitStatusFAilure, pipelineStatus("failed"))
+	assert.Equal(t, repository.CommitStatusPending, pipelineStatus("manual"))
+	assert.Equal(t, repository.CommitStatusError, pipelineStatus("unknown"))
+}
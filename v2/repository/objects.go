@@ -4,12 +4,13 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"strings"
 )
 
 const (
 	Protocol      = "((?P<protocol>https?|git)(?:://|@))?"
 	VCSName       = "(?P<vcs>[^/:]+)"
-	OrgName       = "(?P<org>[^/.]+)"
+	OrgName       = "(?P<org>[^/.]+(?:/[^/.]+)*)" // Organizations may be nested, such as GitLab subgroups.
 	RepoName      = "(?P<repo>[^/.]+)"
 	RepoExtension = "(?:.git)?"
 
@@ -48,6 +49,12 @@ func NewOrganization(alias string, url string) *Organization {
 	return nil
 }
 
+// Includes returns true if the organization with the passed name is this
+// Organization, or is nested within it (such as a GitLab subgroup).
+func (o Organization) Includes(name string) bool {
+	return o.Name == name || strings.HasPrefix(name, o.Name+"/")
+}
+
 const (
 	CommitStatusError    = "ERROR"
 	CommitStatusExpected = "EXPECTED"
@@ -39,6 +39,24 @@ func TestNewOrganization(t *testing.T) {
 				Name:  "Org",
 			},
 		},
+		{
+			alias: "MyOrg4",
+			url:   "https://gitlab.example.com/group/subgroup/repo.git",
+			expected: Organization{
+				Alias: "MyOrg4",
+				VCS:   "gitlab.example.com",
+				Name:  "group/subgroup",
+			},
+		},
+		{
+			alias: "MyOrg5",
+			url:   "gitlab.example.com/group/subgroup/",
+			expected: Organization{
+				Alias: "MyOrg5",
+				VCS:   "gitlab.example.com",
+				Name:  "group/subgroup",
+			},
+		},
 	}
 
 	for _, testCase := range cases {
@@ -86,6 +104,22 @@ func TestNewRepositoryMetadata(t *testing.T) {
 				VCS:          "github.com",
 			},
 		},
+		{
+			url: "https://gitlab.example.com/group/subgroup/my-repo",
+			expected: Metadata{
+				Name:         "my-repo",
+				Organization: "group/subgroup",
+				VCS:          "gitlab.example.com",
+			},
+		},
+		{
+			url: "git@gitlab.example.com:group/subgroup/my-repo.git",
+			expected: Metadata{
+				Name:         "my-repo",
+				Organization: "group/subgroup",
+				VCS:          "gitlab.example.com",
+			},
+		},
 	}
 
 	for _, testCase := range cases {
@@ -6,21 +6,28 @@ import "github.com/docker/distribution/reference"
 type Response struct {
 	Image   string        `json:"image"`
 	Success bool          `json:"success"`
+	Err     string        `json:"error,omitempty"`
 	Results []CheckResult `json:"results"`
 }
 
 // NewResponse creates a new Response for the passed ImageData,
-// with the passed results.
-func NewResponse(reference reference.Reference, results []CheckResult) (checkResponse Response) {
+// with the passed results. The Response is only successful if every
+// result was successful.
+func NewResponse(reference reference.Reference, results []CheckResult) Response {
+	return NewPolicyResponse(reference, results, AllChecksPolicy)
+}
+
+// NewPolicyResponse creates a new Response for the passed ImageData, with the
+// passed results. The Response is successful if the results satisfy the passed
+// Policy.
+func NewPolicyResponse(reference reference.Reference, results []CheckResult, policy Policy) (checkResponse Response) {
 	checkResponse.Image = reference.String()
 	checkResponse.Results = results
 	checkResponse.Success = true
 
-	for _, check := range checkResponse.Results {
-		if !check.Success {
-			checkResponse.Success = false
-			break
-		}
+	if err := policy.Evaluate(reference, results); nil != err {
+		checkResponse.Success = false
+		checkResponse.Err = err.Error()
 	}
 
 	return checkResponse
@@ -3,8 +3,10 @@ package voucher
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/docker/distribution/reference"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,3 +35,44 @@ func TestUnmarshalResponse(t *testing.T) {
 	err := json.NewDecoder(buf).Decode(&response)
 	assert.NoErrorf(t, err, "failed to unmarshal valid data: %s", err)
 }
+
+type testPolicy struct {
+	err error
+}
+
+func (p testPolicy) Evaluate(_ reference.Reference, _ []CheckResult) error {
+	return p.err
+}
+
+func TestNewPolicyResponse(t *testing.T) {
+	imageData := newTestImageData(t)
+	results := []CheckResult{
+		{Name: "diy", Success: true},
+		{Name: "nobody", Success: false},
+	}
+
+	response := NewResponse(imageData, results)
+	assert.False(t, response.Success)
+	assert.Equal(t, "check(s) failed: nobody", response.Err)
+
+	response = NewPolicyResponse(imageData, results, testPolicy{})
+	assert.True(t, response.Success)
+	assert.Empty(t, response.Err)
+
+	response = NewPolicyResponse(imageData, results[:1], testPolicy{err: errors.New("not allowed")})
+	assert.False(t, response.Success)
+	assert.Equal(t, "not allowed", response.Err)
+}
+
+func TestNewBatchResponse(t *testing.T) {
+	passed := Response{Image: "gcr.io/voucher/app@sha256:1", Success: true}
+	failed := Response{Image: "gcr.io/voucher/db@sha256:2", Success: false}
+
+	batchResponse := NewBatchResponse([]Response{passed, passed})
+	assert.True(t, batchResponse.Success)
+	assert.Len(t, batchResponse.Responses, 2)
+
+	batchResponse = NewBatchResponse([]Response{passed, failed})
+	assert.False(t, batchResponse.Success)
+	assert.Equal(t, []Response{passed, failed}, batchResponse.Responses)
+}
@@ -3,12 +3,17 @@ package voucher
 // CheckResult describes the result of a Check. If a check failed, it will have a
 // status of false. If a check succeeded, but its Attestation creation failed,
 // Success will be true, Attested will be false. Err will contain the first error to
-// occur.
+// occur. Details holds the attestation created for a successful check, and
+// CheckDetails holds what a DetailedCheck reported about how it reached its
+// result. Cached is true if the result was read from the decision cache rather
+// than by running the Check.
 type CheckResult struct {
-	ImageData ImageData   `json:"-"`
-	Name      string      `json:"name"`
-	Err       string      `json:"error,omitempty"`
-	Success   bool        `json:"success"`
-	Attested  bool        `json:"attested"`
-	Details   interface{} `json:"details,omitempty"`
+	ImageData    ImageData   `json:"-"`
+	Name         string      `json:"name"`
+	Err          string      `json:"error,omitempty"`
+	Success      bool        `json:"success"`
+	Attested     bool        `json:"attested"`
+	Details      interface{} `json:"details,omitempty"`
+	CheckDetails interface{} `json:"check_details,omitempty"`
+	Cached       bool        `json:"cached,omitempty"`
 }
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	log "github.com/sirupsen/logrus"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/admission"
+	"github.com/grafeas/voucher/v2/cmd/config"
+	"github.com/grafeas/voucher/v2/docker"
+)
+
+// maxAdmissionReviewSize is the largest AdmissionReview that is read. A
+// review holds the object and, for updates, its old version, each of which
+// the API server limits to a few MiB.
+const maxAdmissionReviewSize = 8 << 20
+
+var errNoAdmissionRequest = errors.New("admission review has no request")
+
+var errNoDigest = errors.New("image is not referenced by digest")
+
+// handleAdmissionInput reads an AdmissionReview from the request.
+func handleAdmissionInput(w http.ResponseWriter, r *http.Request) (*admission.Request, error) {
+	var review admission.Review
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdmissionReviewSize)).Decode(&review); nil != err {
+		return nil, err
+	}
+
+	if nil == review.Request {
+		return nil, errNoAdmissionRequest
+	}
+
+	return review.Request, nil
+}
+
+// handleAdmission verifies the images of the workload in the AdmissionReview
+// in the request, and responds with an AdmissionReview which admits the
+// workload if every image passes.
+func (s *Server) handleAdmission(w http.ResponseWriter, r *http.Request, policy voucher.Policy, names ...string) {
+	defer r.Body.Close()
+
+	w.Header().Set("content-type", "application/json")
+
+	LogRequests(r)
+
+	request, err := handleAdmissionInput(w, r)
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		LogError(err.Error(), err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.serverConfig.TimeoutDuration())
+	defer cancel()
+
+	response := s.admit(ctx, request, policy, names)
+
+	err = json.NewEncoder(w).Encode(admission.NewReview(response))
+	if nil != err {
+		// if all else fails
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LogError("failed to encode response as JSON", err)
+		return
+	}
+}
+
+// admit decides whether to admit the object in the passed admission Request.
+// Objects are admitted if they don't run containers, are in an exempt
+// namespace, have the break-glass annotation, or if every one of their images
+// passes the named checks' verification. If the Server requires digests,
+// images referenced only by tag are rejected.
+func (s *Server) admit(ctx context.Context, request *admission.Request, policy voucher.Policy, names []string) admission.Response {
+	fields := log.Fields{
+		"uid":       request.UID,
+		"kind":      request.Kind.Kind,
+		"namespace": request.Namespace,
+		"name":      request.Name,
+	}
+
+	if s.serverConfig.IsExemptNamespace(request.Namespace) {
+		log.WithFields(fields).Info("admitted workload in exempt namespace")
+		return admission.Allow(request.UID)
+	}
+
+	workload, ok, err := admission.ParseWorkload(request.Kind.Kind, request.Object)
+	if nil != err {
+		return s.admissionFailure(request.UID, fields, err)
+	}
+
+	if !ok {
+		return admission.Allow(request.UID)
+	}
+
+	if annotation, reason := s.serverConfig.BreakGlass(workload.Annotations); "" != reason {
+		log.WithFields(fields).WithField("reason", reason).Warning("admitted workload with break-glass annotation")
+
+		response := admission.Allow(request.UID)
+		response.Warnings = []string{fmt.Sprintf("images were not verified by voucher, as %s is set", annotation)}
+		response.AuditAnnotations = map[string]string{"break-glass": reason}
+		return response
+	}
+
+	rejections := make([]string, 0)
+	for _, image := range workload.Images {
+		if s.serverConfig.AdmissionRequireDigest && !hasDigest(image) {
+			rejections = append(rejections, fmt.Sprintf("%s (%s)", image, errNoDigest))
+			continue
+		}
+
+		imageData, err := s.resolveImage(ctx, image)
+		if nil != err {
+			return s.admissionFailure(request.UID, fields, fmt.Errorf("could not resolve %s: %w", image, err))
+		}
+
+		checkResponse, err := s.verifyImage(ctx, imageData, policy, names)
+		if nil != err {
+			return s.admissionFailure(request.UID, fields, err)
+		}
+
+		LogResult(checkResponse)
+
+		if !checkResponse.Success {
+			rejections = append(rejections, describeRejection(image, checkResponse))
+		}
+	}
+
+	if 0 < len(rejections) {
+		log.WithFields(fields).Info("denied workload")
+		return admission.Deny(request.UID, "voucher rejected "+strings.Join(rejections, "; "))
+	}
+
+	return admission.Allow(request.UID)
+}
+
+// admissionFailure returns the Response for an object whose images couldn't be
+// verified because of the passed error. The object is admitted if the Server
+// fails open, and rejected otherwise.
+func (s *Server) admissionFailure(uid string, fields log.Fields, err error) admission.Response {
+	message := fmt.Sprintf("voucher could not verify images: %s", err)
+
+	if s.serverConfig.AdmissionFailOpen {
+		log.WithFields(fields).WithError(err).Warning("admitted workload which could not be verified")
+
+		response := admission.Allow(uid)
+		response.Warnings = []string{message}
+		return response
+	}
+
+	log.WithFields(fields).WithError(err).Error("denied workload which could not be verified")
+	return admission.Deny(uid, message)
+}
+
+// hasDigest returns true if the passed image reference has a digest. Images
+// referenced by tag are verified by the digest the tag has when they are
+// admitted, but the kubelet pulls the tag later, when it may have been moved.
+func hasDigest(image string) bool {
+	named, err := reference.ParseNormalizedNamed(image)
+	if nil != err {
+		return false
+	}
+
+	_, ok := named.(reference.Canonical)
+	return ok
+}
+
+// resolveImage returns the ImageData for the passed image reference. If the
+// reference doesn't have a digest, the digest of its tag is read from the
+// registry.
+func (s *Server) resolveImage(ctx context.Context, image string) (voucher.ImageData, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if nil != err {
+		return nil, err
+	}
+
+	if canonical, ok := named.(reference.Canonical); ok {
+		return reference.WithDigest(reference.TrimNamed(canonical), canonical.Digest())
+	}
+
+	tagged, ok := reference.TagNameOnly(named).(reference.NamedTagged)
+	if !ok {
+		return nil, fmt.Errorf("reference cannot be converted to a canonical reference")
+	}
+
+	client, err := config.NewRegistryClient(ctx, s.secrets, tagged)
+	if nil != err {
+		return nil, err
+	}
+
+	imageDigest, err := docker.GetDigestFromTagged(client, tagged)
+	if nil != err {
+		return nil, err
+	}
+
+	return reference.WithDigest(reference.TrimNamed(tagged), imageDigest)
+}
+
+// describeRejection describes why the passed image failed verification.
+func describeRejection(image string, response voucher.Response) string {
+	failures := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		if result.Success {
+			continue
+		}
+
+		if "" == result.Err {
+			failures = append(failures, result.Name+" is not attested")
+			continue
+		}
+		failures = append(failures, result.Name+": "+result.Err)
+	}
+
+	if 0 == len(failures) && "" != response.Err {
+		failures = append(failures, response.Err)
+	}
+
+	return fmt.Sprintf("%s (%s)", image, strings.Join(failures, ", "))
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/admission"
+)
+
+const testAdmissionImage = "gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2"
+
+func admissionReview(t *testing.T, namespace string, kind string, object string) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(admission.Review{
+		APIVersion: admission.APIVersion,
+		Kind:       "AdmissionReview",
+		Request: &admission.Request{
+			UID:       "705ab4f5-6393-11e8-b7cc-42010a800002",
+			Kind:      admission.Kind{Version: "v1", Kind: kind},
+			Namespace: namespace,
+			Name:      "app",
+			Operation: "CREATE",
+			Object:    json.RawMessage(object),
+		},
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func TestAdmission(t *testing.T) {
+	metadataClient := viper.GetString("metadata_client")
+	viper.Set("metadata_client", "local")
+	defer viper.Set("metadata_client", metadataClient)
+
+	serverConfig := *server.serverConfig
+	serverConfig.AdmissionExemptNamespaces = []string{"kube-system"}
+
+	s := NewServer(&serverConfig, server.secrets, server.metrics)
+	router := NewRouter(s)
+
+	admit := func(body []byte) admission.Response {
+		req, err := http.NewRequest(http.MethodPost, "/admission/diy", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.SetBasicAuth(testUsername, testPassword)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var review admission.Review
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&review))
+		assert.Equal(t, admission.APIVersion, review.APIVersion)
+		assert.Equal(t, "AdmissionReview", review.Kind)
+		require.NotNil(t, review.Response)
+		assert.Equal(t, "705ab4f5-6393-11e8-b7cc-42010a800002", review.Response.UID)
+		return *review.Response
+	}
+
+	pod := `{"spec": {"containers": [{"name": "app", "image": "` + testAdmissionImage + `"}]}}`
+
+	// the image has no attestations.
+	response := admit(admissionReview(t, "default", "Pod", pod))
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Equal(t, int32(http.StatusForbidden), response.Result.Code)
+	assert.Equal(t, "voucher rejected "+testAdmissionImage+" (diy is not attested)", response.Result.Message)
+
+	response = admit(admissionReview(t, "kube-system", "Pod", pod))
+	assert.True(t, response.Allowed)
+
+	response = admit(admissionReview(t, "default", "ConfigMap", `{"data": {"key": "value"}}`))
+	assert.True(t, response.Allowed)
+
+	brokenGlass := admissionReview(t, "default", "Deployment", `{
+		"metadata": {"annotations": {"voucher.grafeas.io/break-glass": "INC-1234"}},
+		"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "`+testAdmissionImage+`"}]}}}
+	}`)
+
+	// the break-glass annotation is ignored unless it is configured.
+	response = admit(brokenGlass)
+	assert.False(t, response.Allowed)
+
+	serverConfig.AdmissionBreakGlass = "voucher.grafeas.io/break-glass"
+
+	response = admit(brokenGlass)
+	assert.True(t, response.Allowed)
+	assert.Len(t, response.Warnings, 1)
+	assert.Equal(t, map[string]string{"break-glass": "INC-1234"}, response.AuditAnnotations)
+
+	// images whose tags can't be resolved are rejected, unless the server
+	// fails open.
+	unresolvable := admissionReview(t, "default", "Pod", `{"spec": {"containers": [{"name": "app", "image": "registry.invalid/app:1.0"}]}}`)
+
+	response = admit(unresolvable)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Contains(t, response.Result.Message, "voucher could not verify images: could not resolve registry.invalid/app:1.0")
+
+	serverConfig.AdmissionFailOpen = true
+
+	response = admit(unresolvable)
+	assert.True(t, response.Allowed)
+	require.Len(t, response.Warnings, 1)
+	assert.Contains(t, response.Warnings[0], "could not resolve registry.invalid/app:1.0")
+
+	// images without digests are rejected if digests are required, even if
+	// the server fails open.
+	serverConfig.AdmissionRequireDigest = true
+
+	response = admit(unresolvable)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Equal(t, "voucher rejected registry.invalid/app:1.0 (image is not referenced by digest)", response.Result.Message)
+
+	response = admit(admissionReview(t, "default", "Pod", pod))
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Equal(t, "voucher rejected "+testAdmissionImage+" (diy is not attested)", response.Result.Message)
+}
+
+func TestAdmissionErrors(t *testing.T) {
+	router := NewRouter(server)
+
+	req, err := http.NewRequest(http.MethodPost, "/admission/diy", bytes.NewReader([]byte(`{"apiVersion": "admission.k8s.io/v1", "kind": "AdmissionReview"}`)))
+	require.NoError(t, err)
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	// reviews larger than maxAdmissionReviewSize aren't read.
+	oversized := admissionReview(t, "default", "ConfigMap", `{"data": {"key": "`+strings.Repeat("a", maxAdmissionReviewSize)+`"}}`)
+	req, err = http.NewRequest(http.MethodPost, "/admission/diy", bytes.NewReader(oversized))
+	require.NoError(t, err)
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	req, err = http.NewRequest(http.MethodPost, "/admission/missing", bytes.NewReader(admissionReview(t, "default", "Pod", `{}`)))
+	require.NoError(t, err)
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestDescribeRejection(t *testing.T) {
+	assert.Equal(t, "app:1.0 (diy: attestation signed by \"key\" rejected: bad signature, nobody is not attested)", describeRejection("app:1.0", voucher.Response{
+		Results: []voucher.CheckResult{
+			{Name: "diy", Err: "attestation signed by \"key\" rejected: bad signature"},
+			{Name: "snakeoil", Success: true},
+			{Name: "nobody"},
+		},
+	}))
+
+	assert.Equal(t, "app:1.0 (check diy failed)", describeRejection("app:1.0", voucher.Response{Err: "check diy failed"}))
+}
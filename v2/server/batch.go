@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+const (
+	// DefaultBatchConcurrency is the number of images in a batch that are
+	// checked at once if the Server's Config doesn't set one.
+	DefaultBatchConcurrency = 4
+
+	// DefaultBatchSize is the number of images a batch can hold if the
+	// Server's Config doesn't set one.
+	DefaultBatchSize = 50
+)
+
+var errEmptyBatch = errors.New("no images in batch")
+
+// handleBatchInput reads a BatchRequest from the request, and returns the
+// ImageData of each of its images.
+func handleBatchInput(r *http.Request) ([]voucher.ImageData, error) {
+	var request voucher.BatchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); nil != err {
+		return nil, err
+	}
+
+	if 0 == len(request.ImageURLs) {
+		return nil, errEmptyBatch
+	}
+
+	images := make([]voucher.ImageData, 0, len(request.ImageURLs))
+	for _, imageURL := range request.ImageURLs {
+		imageData, err := voucher.NewImageData(imageURL)
+		if nil != err {
+			return nil, err
+		}
+		images = append(images, imageData)
+	}
+
+	return images, nil
+}
+
+// handleBatchChecks runs the named checks against each of the images in the
+// request, several images at a time, and responds with a BatchResponse. Each
+// image is given the Server's timeout.
+func (s *Server) handleBatchChecks(w http.ResponseWriter, r *http.Request, checkName string, policy voucher.Policy, name ...string) {
+	defer r.Body.Close()
+
+	w.Header().Set("content-type", "application/json")
+
+	LogRequests(r)
+
+	images, err := handleBatchInput(r)
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		LogError(err.Error(), err)
+		return
+	}
+
+	if len(images) > s.serverConfig.BatchLimit() {
+		http.Error(w, fmt.Sprintf("batch has %d images, the limit is %d", len(images), s.serverConfig.BatchLimit()), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	force := isForced(r)
+	responses := make([]voucher.Response, len(images))
+	limit := make(chan struct{}, s.serverConfig.BatchWorkers())
+
+	var wg sync.WaitGroup
+	for i, imageData := range images {
+		wg.Add(1)
+		go func(i int, imageData voucher.ImageData) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			// each image has the whole timeout, from when it starts being
+			// checked rather than from when the batch was received.
+			ctx, cancel := context.WithTimeout(context.Background(), s.serverConfig.TimeoutDuration())
+			defer cancel()
+
+			response, err := s.runChecks(ctx, imageData, policy, nil, force, name...)
+			if nil != err {
+				LogError("failed to run checks", err)
+				response = voucher.Response{Image: imageData.String(), Err: "server has been misconfigured"}
+			} else {
+				s.notify(checkName, imageData, response)
+			}
+			responses[i] = response
+		}(i, imageData)
+	}
+	wg.Wait()
+
+	for _, response := range responses {
+		LogResult(response)
+	}
+
+	err = json.NewEncoder(w).Encode(voucher.NewBatchResponse(responses))
+	if nil != err {
+		// if all else fails
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LogError("failed to encode response as JSON", err)
+		return
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cache"
+	"github.com/grafeas/voucher/v2/cache/memory"
+	"github.com/grafeas/voucher/v2/metrics"
+)
+
+const (
+	cachedImage   = "gcr.io/somewhere/cached@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2"
+	uncachedImage = "gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2"
+)
+
+func batchCheck(t *testing.T, router http.Handler, path string, request voucher.BatchRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestBatchCheck(t *testing.T) {
+	s := NewServer(&Config{
+		Timeout:          server.serverConfig.Timeout,
+		RequireAuth:      true,
+		Username:         testUsername,
+		PassHash:         testPasswordHash,
+		BatchConcurrency: 2,
+	}, server.secrets, &metrics.NoopClient{})
+
+	imageData, err := voucher.NewImageData(cachedImage)
+	require.NoError(t, err)
+
+	decisions := cache.NewDecisions(memory.NewCache(0), "test", time.Minute, 0, nil)
+	decisions.Put(context.Background(), voucher.CheckResult{Name: "diy", ImageData: imageData, Success: true, Attested: true})
+	s.SetDecisionCache(decisions)
+
+	router := NewRouter(s)
+
+	recorder := batchCheck(t, router, "/batch/diy", voucher.BatchRequest{ImageURLs: []string{cachedImage, uncachedImage, cachedImage}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var batchResponse voucher.BatchResponse
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&batchResponse))
+
+	assert.False(t, batchResponse.Success)
+	require.Len(t, batchResponse.Responses, 3)
+
+	// responses are in the same order as the images.
+	for i, image := range []string{cachedImage, uncachedImage, cachedImage} {
+		assert.Equal(t, image, batchResponse.Responses[i].Image)
+	}
+	assert.True(t, batchResponse.Responses[0].Success)
+	assert.False(t, batchResponse.Responses[1].Success)
+	assert.True(t, batchResponse.Responses[2].Success)
+
+	recorder = batchCheck(t, router, "/batch/diy", voucher.BatchRequest{ImageURLs: []string{cachedImage}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&batchResponse))
+	assert.True(t, batchResponse.Success)
+}
+
+// deadlineCheck is a Check which records how long it had until its deadline
+// when it started, and then takes a while to run.
+type deadlineCheck struct {
+	mu        sync.Mutex
+	remaining []time.Duration
+}
+
+func (c *deadlineCheck) Check(ctx context.Context, _ voucher.ImageData) (bool, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return false, errors.New("check has no deadline")
+	}
+
+	c.mu.Lock()
+	c.remaining = append(c.remaining, time.Until(deadline))
+	c.mu.Unlock()
+
+	time.Sleep(300 * time.Millisecond)
+	return true, nil
+}
+
+func TestBatchCheckTimeoutPerImage(t *testing.T) {
+	check := &deadlineCheck{}
+	voucher.RegisterCheckFactory("deadline", func() voucher.Check { return check })
+
+	s := NewServer(&Config{
+		Timeout:          1,
+		RequireAuth:      true,
+		Username:         testUsername,
+		PassHash:         testPasswordHash,
+		BatchConcurrency: 1,
+	}, server.secrets, &metrics.NoopClient{})
+
+	recorder := batchCheck(t, NewRouter(s), "/batch/deadline", voucher.BatchRequest{ImageURLs: []string{uncachedImage, cachedImage, uncachedImage}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// images which waited for the one before them still have the whole
+	// timeout.
+	require.Len(t, check.remaining, 3)
+	for _, remaining := range check.remaining {
+		assert.Greater(t, remaining, 800*time.Millisecond)
+	}
+}
+
+func TestBatchCheckErrors(t *testing.T) {
+	s := NewServer(&Config{BatchSize: 2}, nil, &metrics.NoopClient{})
+	router := NewRouter(s)
+
+	cases := []struct {
+		name    string
+		path    string
+		request voucher.BatchRequest
+		code    int
+	}{
+		{
+			name: "no images",
+			path: "/batch/diy",
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "image without digest",
+			path:    "/batch/diy",
+			request: voucher.BatchRequest{ImageURLs: []string{cachedImage, "gcr.io/somewhere/image:latest"}},
+			code:    http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "too many images",
+			path:    "/batch/diy",
+			request: voucher.BatchRequest{ImageURLs: []string{cachedImage, uncachedImage, cachedImage}},
+			code:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:    "unknown check",
+			path:    "/batch/unknown",
+			request: voucher.BatchRequest{ImageURLs: []string{cachedImage}},
+			code:    http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.code, batchCheck(t, router, testCase.path, testCase.request).Code)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+// isForced returns true if the request asks for checks to be run even if
+// their results are cached, with `force=true`.
+func isForced(r *http.Request) bool {
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	return force
+}
+
+// cachedResults returns the cached results of the named checks for the passed
+// image, and the names of the checks that have no cached result and must be
+// run. If force is true, or there is no decision cache, every check must be
+// run.
+func (s *Server) cachedResults(ctx context.Context, imageData voucher.ImageData, force bool, names []string) ([]voucher.CheckResult, []string) {
+	if nil == s.decisions || force {
+		return []voucher.CheckResult{}, names
+	}
+
+	results := make([]voucher.CheckResult, 0, len(names))
+	uncached := make([]string, 0, len(names))
+	for _, name := range names {
+		if result, ok := s.decisions.Get(ctx, imageData, name); ok {
+			s.metrics.CheckCacheHit(name)
+			results = append(results, result)
+			continue
+		}
+
+		s.metrics.CheckCacheMiss(name)
+		uncached = append(uncached, name)
+	}
+
+	return results, uncached
+}
+
+// cacheResults stores the passed results in the decision cache, if there is
+// one.
+func (s *Server) cacheResults(ctx context.Context, results []voucher.CheckResult) {
+	if nil == s.decisions {
+		return
+	}
+
+	for _, result := range results {
+		s.decisions.Put(ctx, result)
+	}
+}
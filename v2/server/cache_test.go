@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cache"
+	"github.com/grafeas/voucher/v2/cache/memory"
+	"github.com/grafeas/voucher/v2/metrics"
+)
+
+// cacheMetrics is a metrics.Client which counts cache hits and misses.
+type cacheMetrics struct {
+	metrics.NoopClient
+	hits   []string
+	misses []string
+}
+
+func (m *cacheMetrics) CheckCacheHit(check string) {
+	m.hits = append(m.hits, check)
+}
+
+func (m *cacheMetrics) CheckCacheMiss(check string) {
+	m.misses = append(m.misses, check)
+}
+
+func TestDecisionCache(t *testing.T) {
+	metricsClient := &cacheMetrics{}
+	s := NewServer(server.serverConfig, server.secrets, metricsClient)
+	s.SetCheckGroup("env", []string{"diy", "nobody"})
+
+	decisions := cache.NewDecisions(memory.NewCache(0), "test", time.Minute, time.Minute, nil)
+	s.SetDecisionCache(decisions)
+
+	imageData, err := voucher.NewImageData("gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	decisions.Put(context.Background(), voucher.CheckResult{Name: "diy", ImageData: imageData, Success: true, Attested: true})
+	decisions.Put(context.Background(), voucher.CheckResult{Name: "nobody", ImageData: imageData, Success: true, Attested: true})
+
+	router := NewRouter(s)
+
+	check := func(path string) voucher.Response {
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(testParams))
+		require.NoError(t, err)
+		req.SetBasicAuth(testUsername, testPassword)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response voucher.Response
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+		return response
+	}
+
+	response := check("/env")
+	assert.True(t, response.Success)
+	assert.ElementsMatch(t, []voucher.CheckResult{
+		{Name: "diy", Success: true, Attested: true, Cached: true},
+		{Name: "nobody", Success: true, Attested: true, Cached: true},
+	}, response.Results)
+	assert.ElementsMatch(t, []string{"diy", "nobody"}, metricsClient.hits)
+	assert.Empty(t, metricsClient.misses)
+
+	// forced checks are run, and not read from the cache.
+	response = check("/diy?force=true")
+	require.Len(t, response.Results, 1)
+	assert.False(t, response.Results[0].Cached)
+	assert.False(t, response.Results[0].Success)
+	assert.Len(t, metricsClient.hits, 2)
+
+	// the failing result replaces the cached one.
+	response = check("/diy")
+	require.Len(t, response.Results, 1)
+	assert.True(t, response.Results[0].Cached)
+	assert.Equal(t, "image is not from a valid repo", response.Results[0].Err)
+}
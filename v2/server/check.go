@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -14,9 +15,8 @@ import (
 	"github.com/spf13/viper"
 )
 
-func (s *Server) handleChecks(w http.ResponseWriter, r *http.Request, name ...string) {
+func (s *Server) handleChecks(w http.ResponseWriter, r *http.Request, checkName string, policy voucher.Policy, name ...string) {
 	var imageData voucher.ImageData
-	var repositoryClient repository.Client
 	var err error
 
 	defer r.Body.Close()
@@ -35,12 +35,106 @@ func (s *Server) handleChecks(w http.ResponseWriter, r *http.Request, name ...st
 	ctx, cancel := context.WithTimeout(context.Background(), s.serverConfig.TimeoutDuration())
 	defer cancel()
 
-	metadataClient, err := config.NewMetadataClient(ctx, s.secrets)
+	checkResponse, err := s.runChecks(ctx, imageData, policy, nil, isForced(r), name...)
 	if nil != err {
 		http.Error(w, "server has been misconfigured", http.StatusInternalServerError)
-		LogError("failed to create MetadataClient", err)
+		LogError("failed to run checks", err)
+		return
+	}
+
+	LogResult(checkResponse)
+	s.notify(checkName, imageData, checkResponse)
+
+	err = json.NewEncoder(w).Encode(checkResponse)
+	if nil != err {
+		// if all else fails
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LogError("failed to encode respoonse as JSON", err)
+		return
+	}
+}
+
+// handleAsyncChecks queues a job which runs the named checks against the image
+// in the request, and responds with the job and its location.
+func (s *Server) handleAsyncChecks(w http.ResponseWriter, r *http.Request, checkName string, policy voucher.Policy, name ...string) {
+	defer r.Body.Close()
+
+	w.Header().Set("content-type", "application/json")
+
+	LogRequests(r)
+
+	imageData, err := handleInput(r)
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		LogError(err.Error(), err)
+		return
+	}
+
+	j, err := newJob(checkName, imageData, policy, isForced(r), name)
+	if nil != err {
+		http.Error(w, "failed to create job", http.StatusInternalServerError)
+		LogError("failed to create job", err)
+		return
+	}
+
+	if err = s.jobs.submit(j); nil != err {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		LogError("failed to queue job", err)
 		return
 	}
+
+	job := j.snapshot()
+
+	w.Header().Set("location", jobsPath+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+
+	if err = json.NewEncoder(w).Encode(job); nil != err {
+		LogError("failed to encode job as JSON", err)
+	}
+}
+
+// runJob runs the passed job's checks and records the results.
+func (s *Server) runJob(j *job) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.serverConfig.TimeoutDuration())
+	defer cancel()
+
+	checkResponse, err := s.runChecks(ctx, j.imageData, j.policy, j.addResult, j.force, j.state.Checks...)
+	if nil != err {
+		LogError("failed to run checks", err)
+		j.finish(checkResponse, errors.New("server has been misconfigured"))
+		return
+	}
+
+	LogResult(checkResponse)
+	s.notify(j.state.Check, j.imageData, checkResponse)
+
+	j.finish(checkResponse, nil)
+}
+
+// runChecks runs the named checks against the passed image, attesting the
+// results unless voucher is in dry run mode, and returns a Response for the
+// results evaluated against the passed Policy. If progress is not nil, it is
+// called with each CheckResult as it becomes available. Checks with results in
+// the decision cache aren't run, unless force is true.
+func (s *Server) runChecks(ctx context.Context, imageData voucher.ImageData, policy voucher.Policy, progress voucher.ProgressFunc, force bool, name ...string) (voucher.Response, error) {
+	var repositoryClient repository.Client
+	var err error
+
+	cached, name := s.cachedResults(ctx, imageData, force, name)
+	for _, result := range cached {
+		if nil != progress {
+			progress(result)
+		}
+	}
+
+	if 0 == len(name) {
+		return voucher.NewPolicyResponse(imageData, cached, policy), nil
+	}
+
+	metadataClient, err := config.NewMetadataClient(ctx, s.secrets)
+	if nil != err {
+		return voucher.Response{}, fmt.Errorf("failed to create MetadataClient: %w", err)
+	}
 	defer metadataClient.Close()
 
 	// Initialize repository client if and only if we have a secrets that represents the org repo
@@ -60,12 +154,11 @@ func (s *Server) handleChecks(w http.ResponseWriter, r *http.Request, name ...st
 		log.Warning("failed to create repository client, no secrets configured")
 	}
 
-	checksuite, err := config.NewCheckSuite(metadataClient, repositoryClient, name...)
+	checksuite, err := config.NewCheckSuite(s.secrets, metadataClient, repositoryClient, name...)
 	if nil != err {
-		http.Error(w, "server has been misconfigured", http.StatusInternalServerError)
-		LogError("failed to create CheckSuite", err)
-		return
+		return voucher.Response{}, fmt.Errorf("failed to create CheckSuite: %w", err)
 	}
+	checksuite.SetProgressFunc(progress)
 
 	var results []voucher.CheckResult
 
@@ -75,15 +168,7 @@ func (s *Server) handleChecks(w http.ResponseWriter, r *http.Request, name ...st
 		results = checksuite.RunAndAttest(ctx, metadataClient, s.metrics, imageData)
 	}
 
-	checkResponse := voucher.NewResponse(imageData, results)
+	s.cacheResults(ctx, results)
 
-	LogResult(checkResponse)
-
-	err = json.NewEncoder(w).Encode(checkResponse)
-	if nil != err {
-		// if all else fails
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		LogError("failed to encode respoonse as JSON", err)
-		return
-	}
+	return voucher.NewPolicyResponse(imageData, append(cached, results...), policy), nil
 }
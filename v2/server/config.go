@@ -12,6 +12,18 @@ type Config struct {
 	RequireAuth bool
 	Username    string
 	PassHash    string
+
+	JobWorkers   int
+	JobQueueSize int
+	JobTTL       int
+
+	BatchConcurrency int
+	BatchSize        int
+
+	AdmissionFailOpen         bool
+	AdmissionExemptNamespaces []string
+	AdmissionBreakGlass       string
+	AdmissionRequireDigest    bool
 }
 
 // Address is the address of the Server.
@@ -23,3 +35,42 @@ func (config *Config) Address() string {
 func (config *Config) TimeoutDuration() time.Duration {
 	return time.Duration(config.Timeout) * time.Second
 }
+
+// JobTTLDuration returns how long finished asynchronous jobs are kept for.
+func (config *Config) JobTTLDuration() time.Duration {
+	return time.Duration(config.JobTTL) * time.Second
+}
+
+// BatchWorkers returns the number of images in a batch that are checked at
+// once.
+func (config *Config) BatchWorkers() int {
+	if config.BatchConcurrency <= 0 {
+		return DefaultBatchConcurrency
+	}
+	return config.BatchConcurrency
+}
+
+// BatchLimit returns the number of images a batch can hold.
+func (config *Config) BatchLimit() int {
+	if config.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return config.BatchSize
+}
+
+// IsExemptNamespace returns true if workloads in the passed namespace are
+// admitted without verifying their images.
+func (config *Config) IsExemptNamespace(namespace string) bool {
+	return contains(config.AdmissionExemptNamespaces, namespace)
+}
+
+// BreakGlass returns the break-glass annotation, and the reason it gives in
+// the passed workload annotations, if it is set. A workload with a reason is
+// admitted without verifying its images. The reason is always empty if no
+// break-glass annotation is configured.
+func (config *Config) BreakGlass(annotations map[string]string) (string, string) {
+	if "" == config.AdmissionBreakGlass {
+		return "", ""
+	}
+	return config.AdmissionBreakGlass, annotations[config.AdmissionBreakGlass]
+}
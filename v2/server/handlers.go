@@ -1,8 +1,10 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 )
@@ -38,7 +40,46 @@ func (s *Server) HandleCheckImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.handleChecks(w, r, requiredChecks...)
+	if async, _ := strconv.ParseBool(r.URL.Query().Get("async")); async {
+		s.handleAsyncChecks(w, r, checkName, s.GetPolicy(checkName), requiredChecks...)
+		return
+	}
+
+	s.handleChecks(w, r, checkName, s.GetPolicy(checkName), requiredChecks...)
+}
+
+// HandleBatchCheck is a request handler that executes an individual Check or
+// all of the Checks in one CheckGroup against each of a list of images, and
+// creates any attestations if applicable.
+func (s *Server) HandleBatchCheck(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	if err = s.isAuthorized(r); nil != err {
+		http.Error(w, "username or password is incorrect", http.StatusUnauthorized)
+		LogError("username or password is incorrect", err)
+		return
+	}
+
+	variables := mux.Vars(r)
+	checkName := variables["check"]
+
+	if "" == checkName {
+		http.Error(w, "failure", http.StatusInternalServerError)
+		return
+	}
+
+	requiredChecks := []string{checkName}
+
+	if s.HasCheckGroup(checkName) {
+		requiredChecks = s.GetCheckGroup(checkName)
+	}
+
+	if err = verifiedRequiredChecksAreRegistered(requiredChecks...); err != nil {
+		http.Error(w, fmt.Sprintf("check or group \"%s\" is not active: %s", checkName, err), http.StatusNotFound)
+		return
+	}
+
+	s.handleBatchChecks(w, r, checkName, s.GetPolicy(checkName), requiredChecks...)
 }
 
 // HandleVerifyImage is a request handler that verifies an individual
@@ -72,7 +113,65 @@ func (s *Server) HandleVerifyImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.handleVerify(w, r, requiredChecks...)
+	s.handleVerify(w, r, s.GetPolicy(checkName), requiredChecks...)
+}
+
+// HandleAdmission is a request handler for a Kubernetes validating admission
+// webhook, which admits workloads if each of their images passes the
+// verification of an individual attestation or all of the attestations which
+// would be created by one CheckGroup.
+func (s *Server) HandleAdmission(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	if err = s.isAuthorized(r); nil != err {
+		http.Error(w, "username or password is incorrect", http.StatusUnauthorized)
+		LogError("username or password is incorrect", err)
+		return
+	}
+
+	variables := mux.Vars(r)
+	checkName := variables["check"]
+
+	if "" == checkName {
+		http.Error(w, "failure", http.StatusInternalServerError)
+		return
+	}
+
+	requiredChecks := []string{checkName}
+
+	if s.HasCheckGroup(checkName) {
+		requiredChecks = s.GetCheckGroup(checkName)
+	}
+
+	if err = verifiedRequiredChecksAreRegistered(requiredChecks...); err != nil {
+		http.Error(w, fmt.Sprintf("check or group \"%s\" is not active: %s", checkName, err), http.StatusNotFound)
+		return
+	}
+
+	s.handleAdmission(w, r, s.GetPolicy(checkName), requiredChecks...)
+}
+
+// HandleGetJob is a request handler that returns the status and results so far
+// of an asynchronous check job.
+func (s *Server) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	if err := s.isAuthorized(r); nil != err {
+		http.Error(w, "username or password is incorrect", http.StatusUnauthorized)
+		LogError("username or password is incorrect", err)
+		return
+	}
+
+	job, ok := s.jobs.get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(job); nil != err {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		LogError("failed to encode job as JSON", err)
+	}
 }
 
 // HandleHealthCheck is a request handler that returns HTTP Status Code 200
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+const (
+	// DefaultJobWorkers is the number of asynchronous jobs that are run at
+	// once if the Server's Config doesn't set one.
+	DefaultJobWorkers = 4
+
+	// DefaultJobQueueSize is the number of asynchronous jobs that can wait
+	// for a worker if the Server's Config doesn't set one.
+	DefaultJobQueueSize = 100
+
+	// DefaultJobTTL is how long a finished job can be fetched for if the
+	// Server's Config doesn't set a TTL.
+	DefaultJobTTL = time.Hour
+)
+
+var errJobQueueFull = errors.New("too many jobs are queued, try again later")
+
+// job is an asynchronous run of a check or check group.
+type job struct {
+	mu        sync.Mutex
+	state     voucher.Job
+	imageData voucher.ImageData
+	policy    voucher.Policy
+	force     bool
+}
+
+// newJob creates a queued job which runs the passed checks against the passed
+// image, on behalf of the passed check or check group. If force is true, the
+// checks are run even if their results are cached.
+func newJob(checkName string, imageData voucher.ImageData, policy voucher.Policy, force bool, checks []string) (*job, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); nil != err {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &job{
+		state: voucher.Job{
+			ID:      hex.EncodeToString(id),
+			Check:   checkName,
+			Image:   imageData.String(),
+			Status:  voucher.JobQueued,
+			Checks:  checks,
+			Results: []voucher.CheckResult{},
+			Created: now,
+			Updated: now,
+		},
+		imageData: imageData,
+		policy:    policy,
+		force:     force,
+	}, nil
+}
+
+// snapshot returns a copy of the job's current state.
+func (j *job) snapshot() voucher.Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	state := j.state
+	state.Results = append([]voucher.CheckResult{}, j.state.Results...)
+	return state
+}
+
+// start marks the job as running.
+func (j *job) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.state.Status = voucher.JobRunning
+	j.state.Updated = time.Now().UTC()
+}
+
+// addResult records the passed CheckResult, replacing any earlier result
+// for the same check.
+func (j *job) addResult(result voucher.CheckResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.state.Updated = time.Now().UTC()
+	for i := range j.state.Results {
+		if j.state.Results[i].Name == result.Name {
+			j.state.Results[i] = result
+			return
+		}
+	}
+	j.state.Results = append(j.state.Results, result)
+	j.state.Completed = len(j.state.Results)
+}
+
+// finish marks the job as done with the passed Response, or as failed if err
+// is not nil.
+func (j *job) finish(response voucher.Response, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.state.Updated = time.Now().UTC()
+	if nil != err {
+		j.state.Status = voucher.JobFailed
+		j.state.Err = err.Error()
+		return
+	}
+
+	j.state.Status = voucher.JobDone
+	j.state.Results = response.Results
+	j.state.Completed = len(response.Results)
+	j.state.Response = &response
+}
+
+// expired returns true if the job finished before the passed time.
+func (j *job) expired(before time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.state.Status.Finished() && j.state.Updated.Before(before)
+}
+
+// jobQueue runs jobs on a fixed number of workers, and keeps finished jobs
+// until their TTL has passed.
+type jobQueue struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	pending chan *job
+	workers int
+	ttl     time.Duration
+	run     func(*job)
+	once    sync.Once
+}
+
+// newJobQueue creates a jobQueue which runs jobs with the passed function.
+// The workers are started when the first job is submitted.
+func newJobQueue(workers, size int, ttl time.Duration, run func(*job)) *jobQueue {
+	if workers <= 0 {
+		workers = DefaultJobWorkers
+	}
+
+	if size <= 0 {
+		size = DefaultJobQueueSize
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultJobTTL
+	}
+
+	return &jobQueue{
+		jobs:    make(map[string]*job),
+		pending: make(chan *job, size),
+		workers: workers,
+		ttl:     ttl,
+		run:     run,
+	}
+}
+
+// work runs pending jobs as they are submitted.
+func (q *jobQueue) work() {
+	for j := range q.pending {
+		j.start()
+		q.run(j)
+	}
+}
+
+// submit queues the passed job, or returns errJobQueueFull if too many jobs
+// are already waiting for a worker.
+func (q *jobQueue) submit(j *job) error {
+	q.once.Do(func() {
+		for i := 0; i < q.workers; i++ {
+			go q.work()
+		}
+	})
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.prune()
+
+	select {
+	case q.pending <- j:
+		q.jobs[j.state.ID] = j
+		return nil
+	default:
+		return errJobQueueFull
+	}
+}
+
+// get returns the state of the job with the passed ID, and false if there is
+// no such job or it has expired.
+func (q *jobQueue) get(id string) (voucher.Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.prune()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return voucher.Job{}, false
+	}
+	return j.snapshot(), true
+}
+
+// prune removes the jobs that finished more than the queue's TTL ago. The
+// caller must hold the queue's lock.
+func (q *jobQueue) prune() {
+	before := time.Now().UTC().Add(-q.ttl)
+	for id, j := range q.jobs {
+		if j.expired(before) {
+			delete(q.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+)
+
+func newTestJob(t *testing.T) *job {
+	t.Helper()
+
+	imageData, err := voucher.NewImageData("gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	j, err := newJob("all", imageData, voucher.AllChecksPolicy, false, []string{"diy", "nobody"})
+	require.NoError(t, err)
+	return j
+}
+
+func TestJobQueue(t *testing.T) {
+	release := make(chan struct{})
+	queue := newJobQueue(1, 1, time.Minute, func(j *job) {
+		j.addResult(voucher.CheckResult{Name: "diy", Success: true})
+		<-release
+		j.addResult(voucher.CheckResult{Name: "diy", Success: true, Attested: true})
+		j.finish(voucher.Response{Success: true, Results: []voucher.CheckResult{
+			{Name: "diy", Success: true, Attested: true},
+			{Name: "nobody", Success: true},
+		}}, nil)
+	})
+
+	running := newTestJob(t)
+	require.NoError(t, queue.submit(running))
+
+	// wait for the worker to pick up the first job, so the second one fills
+	// the queue.
+	require.Eventually(t, func() bool {
+		state, ok := queue.get(running.state.ID)
+		return ok && 1 == state.Completed
+	}, time.Second, time.Millisecond)
+
+	state, _ := queue.get(running.state.ID)
+	assert.Equal(t, voucher.JobRunning, state.Status)
+	assert.Equal(t, []voucher.CheckResult{{Name: "diy", Success: true}}, state.Results)
+	assert.Nil(t, state.Response)
+
+	queued := newTestJob(t)
+	require.NoError(t, queue.submit(queued))
+	assert.Equal(t, errJobQueueFull, queue.submit(newTestJob(t)))
+
+	state, ok := queue.get(queued.state.ID)
+	require.True(t, ok)
+	assert.Equal(t, voucher.JobQueued, state.Status)
+
+	close(release)
+
+	for _, j := range []*job{running, queued} {
+		require.Eventually(t, func() bool {
+			state, _ := queue.get(j.state.ID)
+			return state.Status.Finished()
+		}, time.Second, time.Millisecond)
+
+		state, _ := queue.get(j.state.ID)
+		assert.Equal(t, voucher.JobDone, state.Status)
+		assert.Equal(t, 2, state.Completed)
+		require.NotNil(t, state.Response)
+		assert.True(t, state.Response.Success)
+	}
+
+	_, ok = queue.get("missing")
+	assert.False(t, ok)
+}
+
+func TestJobQueueExpiry(t *testing.T) {
+	queue := newJobQueue(1, 1, time.Minute, func(j *job) {
+		j.finish(voucher.Response{}, assert.AnError)
+	})
+
+	j := newTestJob(t)
+	require.NoError(t, queue.submit(j))
+
+	require.Eventually(t, func() bool {
+		state, _ := queue.get(j.state.ID)
+		return state.Status.Finished()
+	}, time.Second, time.Millisecond)
+
+	state, ok := queue.get(j.state.ID)
+	require.True(t, ok)
+	assert.Equal(t, voucher.JobFailed, state.Status)
+	assert.Equal(t, assert.AnError.Error(), state.Err)
+
+	// finished jobs are removed once their TTL has passed.
+	j.mu.Lock()
+	j.state.Updated = time.Now().Add(-2 * time.Minute)
+	j.mu.Unlock()
+
+	_, ok = queue.get(j.state.ID)
+	assert.False(t, ok)
+}
+
+func TestAsyncCheck(t *testing.T) {
+	router := NewRouter(server)
+
+	req, err := http.NewRequest(http.MethodPost, "/diy?async=true", bytes.NewReader(testParams))
+	require.NoError(t, err)
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var job voucher.Job
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&job))
+	assert.Equal(t, "diy", job.Check)
+	assert.Equal(t, []string{"diy"}, job.Checks)
+	assert.Equal(t, jobsPath+job.ID, recorder.Header().Get("Location"))
+
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequest(http.MethodGet, recorder.Header().Get("Location"), nil)
+		require.NoError(t, err)
+		req.SetBasicAuth(testUsername, testPassword)
+
+		jobRecorder := httptest.NewRecorder()
+		router.ServeHTTP(jobRecorder, req)
+		require.Equal(t, http.StatusOK, jobRecorder.Code)
+		require.NoError(t, json.NewDecoder(jobRecorder.Body).Decode(&job))
+		return job.Status.Finished()
+	}, 30*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, voucher.JobDone, job.Status)
+	require.NotNil(t, job.Response)
+	assert.Equal(t, job.Results, job.Response.Results)
+}
+
+func TestGetJobErrors(t *testing.T) {
+	router := NewRouter(server)
+
+	req, err := http.NewRequest(http.MethodGet, jobsPath+"missing", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/notifier"
+)
+
+// notify sends a notification that the passed image failed the check group
+// with the passed name, if the Response wasn't successful and the Server has a
+// notifier.
+func (s *Server) notify(checkName string, imageData voucher.ImageData, response voucher.Response) {
+	if nil == s.notifier || response.Success {
+		return
+	}
+
+	s.notifier.Notify(notifier.NewNotification(checkName, imageData, response))
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cache"
+	"github.com/grafeas/voucher/v2/cache/memory"
+	"github.com/grafeas/voucher/v2/notifier"
+)
+
+func TestNotify(t *testing.T) {
+	notifications := make(chan notifier.Notification, 2)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification notifier.Notification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		notifications <- notification
+	}))
+	defer hook.Close()
+
+	target, err := notifier.NewTarget("hook", hook.URL, notifier.Webhook, []string{"env"}, "")
+	require.NoError(t, err)
+
+	s := NewServer(server.serverConfig, server.secrets, server.metrics)
+	s.SetCheckGroup("env", []string{"diy", "nobody"})
+
+	dispatcher := notifier.NewDispatcher(notifier.Config{}, []*notifier.Target{target}, nil)
+	s.SetNotifier(dispatcher)
+
+	decisions := cache.NewDecisions(memory.NewCache(0), "test", time.Minute, time.Minute, nil)
+	s.SetDecisionCache(decisions)
+
+	imageData, err := voucher.NewImageData("gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	decisions.Put(context.Background(), voucher.CheckResult{Name: "diy", ImageData: imageData, Success: false, Err: "image is not from a valid repo"})
+	decisions.Put(context.Background(), voucher.CheckResult{Name: "nobody", ImageData: imageData, Success: true, Attested: true})
+
+	router := NewRouter(s)
+
+	req, err := http.NewRequest(http.MethodPost, "/env", bytes.NewReader(testParams))
+	require.NoError(t, err)
+	req.SetBasicAuth(testUsername, testPassword)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	require.NoError(t, dispatcher.Close())
+	require.Len(t, notifications, 1)
+
+	notification := <-notifications
+	assert.Equal(t, "env", notification.Group)
+	assert.Equal(t, imageData.String(), notification.Image)
+	require.Len(t, notification.Failures, 1)
+	assert.Equal(t, "diy", notification.Failures[0].Name)
+	assert.Equal(t, "image is not from a valid repo", notification.Failures[0].Err)
+}
@@ -10,6 +10,10 @@ const (
 	healthCheckPath     = "/services/ping"
 	individualCheckPath = "/{check}"
 	verifyCheckPath     = individualCheckPath + "/verify"
+	batchCheckPath      = "/batch" + individualCheckPath
+	admissionPath       = "/admission" + individualCheckPath
+	jobsPath            = "/jobs/"
+	jobPath             = jobsPath + "{id}"
 )
 
 // Route stores metadata about a particular endpoint
@@ -20,10 +24,11 @@ type Route struct {
 	HandlerFunc http.HandlerFunc
 }
 
-// NewRouter creates a mux router with the specified routes and handlers
+// NewRouter creates a mux router with the specified routes and handlers, and
+// any routes added to the Server, which take precedence over its own
 func NewRouter(s *Server) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
-	for _, route := range getRoutes(s) {
+	for _, route := range append(append([]Route{}, s.routes...), getRoutes(s)...) {
 		router.
 			Methods(route.Method).
 			Path(route.Path).
@@ -41,12 +46,30 @@ func getRoutes(s *Server) []Route {
 			individualCheckPath,
 			s.HandleCheckImage,
 		},
+		{
+			"Check Images",
+			"POST",
+			batchCheckPath,
+			s.HandleBatchCheck,
+		},
+		{
+			"Admit Workload",
+			"POST",
+			admissionPath,
+			s.HandleAdmission,
+		},
 		{
 			"Verify Image",
 			"POST",
 			verifyCheckPath,
 			s.HandleVerifyImage,
 		},
+		{
+			"Get Job",
+			"GET",
+			jobPath,
+			s.HandleGetJob,
+		},
 		{
 			"healthcheck: /services/ping",
 			"GET",
@@ -4,26 +4,41 @@ import (
 	"net/http"
 	"strings"
 
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/cache"
 	"github.com/grafeas/voucher/v2/cmd/config"
 	"github.com/grafeas/voucher/v2/metrics"
+	"github.com/grafeas/voucher/v2/notifier"
+	"github.com/grafeas/voucher/v2/policy"
+	"github.com/grafeas/voucher/v2/signer"
 	log "github.com/sirupsen/logrus"
 )
 
 type Server struct {
 	serverConfig *Config
 	checkGroups  map[string][]string
+	policies     map[string]voucher.Policy
 	secrets      *config.Secrets
+	verifier     signer.AttestationVerifier
 	metrics      metrics.Client
+	jobs         *jobQueue
+	decisions    *cache.Decisions
+	notifier     *notifier.Dispatcher
+	routes       []Route
 }
 
 // NewServer creates a server on the specified port
-func NewServer(config *Config, secrets *config.Secrets, metrics metrics.Client) *Server {
-	return &Server{
-		serverConfig: config,
+func NewServer(serverConfig *Config, secrets *config.Secrets, metrics metrics.Client) *Server {
+	server := &Server{
+		serverConfig: serverConfig,
 		secrets:      secrets,
+		verifier:     config.NewAttestationVerifier(secrets),
 		metrics:      metrics,
 		checkGroups:  make(map[string][]string),
+		policies:     make(map[string]voucher.Policy),
 	}
+	server.jobs = newJobQueue(serverConfig.JobWorkers, serverConfig.JobQueueSize, serverConfig.JobTTLDuration(), server.runJob)
+	return server
 }
 
 // Serve runs the Server on the specified port
@@ -32,6 +47,24 @@ func (server *Server) Serve() {
 	log.Fatal(http.ListenAndServe(server.serverConfig.Address(), router))
 }
 
+// SetDecisionCache sets the cache that check results are read from and stored
+// in, so checks that were recently run against an image aren't run again.
+func (server *Server) SetDecisionCache(decisions *cache.Decisions) {
+	server.decisions = decisions
+}
+
+// SetNotifier sets the Dispatcher that is notified when an image fails a
+// check group.
+func (server *Server) SetNotifier(dispatcher *notifier.Dispatcher) {
+	server.notifier = dispatcher
+}
+
+// AddRoute adds a route to the Server, so other handlers, such as a Pub/Sub
+// push subscription, can be served alongside the Server's own.
+func (server *Server) AddRoute(route Route) {
+	server.routes = append(server.routes, route)
+}
+
 // SetCheckGroup adds a list of checks as a group with the passed name.
 func (server *Server) SetCheckGroup(name string, checkNames []string) {
 	log.Infof("registering check group \"%s\": %s", name, strings.Join(checkNames, ", "))
@@ -51,3 +84,38 @@ func (server *Server) GetCheckGroup(name string) []string {
 	checks := server.checkGroups[name]
 	return checks
 }
+
+// SetPolicy sets the Policy that the results of the check group with the
+// passed name must satisfy. Any checks the Policy refers to are added to the
+// check group, but images which the Policy doesn't apply to only have to pass
+// the checks that were in the group already.
+func (server *Server) SetPolicy(name string, groupPolicy policy.Policies) {
+	groupChecks := server.checkGroups[name]
+
+	checks := append([]string{}, groupChecks...)
+	for _, check := range groupPolicy.Checks() {
+		if !contains(checks, check) {
+			checks = append(checks, check)
+		}
+	}
+	server.SetCheckGroup(name, checks)
+	server.policies[name] = groupPolicy.ForChecks(groupChecks)
+}
+
+// GetPolicy returns the Policy for the check group with the passed name. If
+// the group has no Policy, every check must pass.
+func (server *Server) GetPolicy(name string) voucher.Policy {
+	if groupPolicy, ok := server.policies[name]; ok {
+		return groupPolicy
+	}
+	return voucher.AllChecksPolicy
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -11,8 +11,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	voucher "github.com/grafeas/voucher/v2"
 	"github.com/grafeas/voucher/v2/cmd/config"
 	"github.com/grafeas/voucher/v2/metrics"
+	"github.com/grafeas/voucher/v2/policy"
 )
 
 var testParams = []byte(`
@@ -96,9 +98,13 @@ func TestInvalidJSON(t *testing.T) {
 
 		if individualCheckPath == path {
 			path = "/diy"
+		} else if batchCheckPath == path {
+			path = "/batch/diy"
+		} else if admissionPath == path {
+			path = "/admission/diy"
 		} else if verifyCheckPath == path {
 			path = "/diy/verify"
-		} else if healthCheckPath == path {
+		} else if healthCheckPath == path || jobPath == path {
 			continue
 		}
 
@@ -126,3 +132,59 @@ func TestHandlerStatus(t *testing.T) {
 	// Check the status code is what we expect
 	assert.Equal(t, http.StatusOK, recorder.Code, "handler for health check failed")
 }
+
+func TestSetPolicy(t *testing.T) {
+	s := NewServer(&Config{}, nil, &metrics.NoopClient{})
+	s.SetCheckGroup("production", []string{"diy"})
+
+	assert.Equal(t, voucher.AllChecksPolicy, s.GetPolicy("production"))
+
+	productionPolicy := policy.Policies{
+		{
+			Images:  []string{"gcr.io/prod-*"},
+			Require: policy.Rule{AnyOf: []policy.Rule{{Check: "diy"}, {Check: "nobody"}}},
+		},
+	}
+	s.SetPolicy("production", productionPolicy)
+
+	assert.Equal(t, []string{"diy", "nobody"}, s.GetCheckGroup("production"))
+
+	results := []voucher.CheckResult{
+		{Name: "diy", Success: false},
+		{Name: "nobody", Success: true},
+	}
+
+	prodImage, err := voucher.NewImageData("gcr.io/prod-team/app@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+	assert.NoError(t, s.GetPolicy("production").Evaluate(prodImage, results))
+
+	// images the policy doesn't apply to only require the group's own checks,
+	// and not the checks the policy added to it.
+	otherImage, err := voucher.NewImageData("gcr.io/staging/app@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+	assert.EqualError(t, s.GetPolicy("production").Evaluate(otherImage, results), "check(s) failed: diy")
+
+	results[0].Success = true
+	results[1].Success = false
+	assert.NoError(t, s.GetPolicy("production").Evaluate(otherImage, results))
+}
+
+func TestAddRoute(t *testing.T) {
+	s := NewServer(&Config{}, nil, &metrics.NoopClient{})
+	s.AddRoute(Route{
+		Name:   "Teapot",
+		Method: http.MethodPost,
+		Path:   "/teapot",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		},
+	})
+
+	req, err := http.NewRequest(http.MethodPost, "/teapot", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	NewRouter(s).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusTeapot, recorder.Code)
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	voucher "github.com/grafeas/voucher/v2"
 	"github.com/grafeas/voucher/v2/cmd/config"
+	"github.com/grafeas/voucher/v2/signer"
 )
 
-func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request, names ...string) {
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request, policy voucher.Policy, names ...string) {
 	var imageData voucher.ImageData
 	var err error
 
@@ -30,23 +32,12 @@ func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request, names ...s
 	ctx, cancel := context.WithTimeout(context.Background(), s.serverConfig.TimeoutDuration())
 	defer cancel()
 
-	metadataClient, err := config.NewMetadataClient(ctx, s.secrets)
+	checkResponse, err := s.verifyImage(ctx, imageData, policy, names)
 	if nil != err {
 		http.Error(w, "server has been misconfigured", 500)
-		LogError("failed to create MetadataClient", err)
+		LogError("failed to verify attestations", err)
 		return
 	}
-	defer metadataClient.Close()
-
-	attestations, err := metadataClient.GetAttestations(ctx, imageData)
-	if nil != err {
-		LogWarning(fmt.Sprintf("could not get image attestations for %s", imageData), err)
-	}
-
-	checkResponse := voucher.NewResponse(
-		imageData,
-		attestationsToResults(attestations, names),
-	)
 
 	LogResult(checkResponse)
 
@@ -59,24 +50,58 @@ func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request, names ...s
 	}
 }
 
-func attestationsToResults(attestations []voucher.SignedAttestation, names []string) []voucher.CheckResult {
+// verifyImage verifies the passed image's attestations for the named checks,
+// and returns a Response for the results evaluated against the passed Policy.
+func (s *Server) verifyImage(ctx context.Context, imageData voucher.ImageData, policy voucher.Policy, names []string) (voucher.Response, error) {
+	metadataClient, err := config.NewMetadataClient(ctx, s.secrets)
+	if nil != err {
+		return voucher.Response{}, fmt.Errorf("failed to create MetadataClient: %w", err)
+	}
+	defer metadataClient.Close()
+
+	attestations, err := metadataClient.GetAttestations(ctx, imageData)
+	if nil != err {
+		LogWarning(fmt.Sprintf("could not get image attestations for %s", imageData), err)
+	}
+
+	return voucher.NewPolicyResponse(
+		imageData,
+		attestationsToResults(s.verifier, imageData, attestations, names),
+		policy,
+	), nil
+}
+
+// attestationsToResults converts the passed attestations into a CheckResult
+// for each of the passed check names. A check passes if at least one of its
+// attestations is signed by the key configured for that check and was created
+// for the passed image. If every attestation for a check is rejected, the
+// reasons are reported in the CheckResult's error.
+func attestationsToResults(verifier signer.AttestationVerifier, imageData voucher.ImageData, attestations []voucher.SignedAttestation, names []string) []voucher.CheckResult {
 	results := make([]voucher.CheckResult, 0, len(names))
 
 	for _, name := range names {
 		failed := true
+		rejections := make([]string, 0)
 		for _, attestation := range attestations {
-			if attestation.CheckName == name {
-				failed = false
-				results = append(results, voucher.SignedAttestationToResult(attestation))
-				break
+			if attestation.CheckName != name {
+				continue
 			}
+
+			if err := voucher.VerifyAttestation(verifier, imageData, attestation); nil != err {
+				rejections = append(rejections, fmt.Sprintf("attestation signed by %q rejected: %s", attestation.KeyID, err))
+				continue
+			}
+
+			failed = false
+			results = append(results, voucher.SignedAttestationToResult(attestation))
+			break
 		}
 		if failed {
 			results = append(
 				results,
 				voucher.CheckResult{
 					Name:     name,
-					Err:      "",
+					Err:      strings.Join(rejections, "; "),
 					Success:  false,
 					Attested: false,
 					Details:  nil,
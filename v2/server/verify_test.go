@@ -0,0 +1,49 @@
+package server
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	voucher "github.com/grafeas/voucher/v2"
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/signer/pgp"
+)
+
+func TestAttestationsToResults(t *testing.T) {
+	keyring := pgp.NewKeyRing()
+	keyFile, err := os.Open("../../testdata/testkey.asc")
+	require.NoError(t, err)
+	defer keyFile.Close()
+	require.NoError(t, pgp.AddKeyToKeyRingFromReader(keyring, "snakeoil", keyFile))
+
+	imageData, err := voucher.NewImageData("gcr.io/somewhere/image@sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2")
+	require.NoError(t, err)
+
+	payload, err := attestation.NewPayload(imageData).ToString()
+	require.NoError(t, err)
+
+	signed, err := voucher.SignAttestation(keyring, voucher.NewAttestation("snakeoil", payload))
+	require.NoError(t, err)
+
+	forged := voucher.SignedAttestation{
+		Attestation: voucher.NewAttestation("diy", payload),
+		Signature:   "not a signature",
+		KeyID:       signed.KeyID,
+	}
+
+	results := attestationsToResults(keyring, imageData, []voucher.SignedAttestation{signed, forged}, []string{"snakeoil", "diy", "nobody"})
+	require.Len(t, results, 3)
+
+	assert.Equal(t, voucher.SignedAttestationToResult(signed), results[0])
+
+	assert.Equal(t, "diy", results[1].Name)
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Err, "rejected: no signing entity exists for check")
+
+	assert.Equal(t, "nobody", results[2].Name)
+	assert.False(t, results[2].Success)
+	assert.Empty(t, results[2].Err)
+}
@@ -7,3 +7,11 @@ import (
 // ErrNoKeyForCheck is the error returned when Voucher does not have a key
 // for the Check in question.
 var ErrNoKeyForCheck = errors.New("no signing entity exists for check")
+
+// ErrKeyMismatch is the error returned when an attestation claims to be signed
+// by a key other than the one Voucher has configured for the Check in question.
+var ErrKeyMismatch = errors.New("attestation was not signed by the key configured for check")
+
+// ErrInvalidSignature is the error returned when an attestation's signature
+// does not match its payload.
+var ErrInvalidSignature = errors.New("signature is not valid for attestation payload")
@@ -2,10 +2,17 @@ package kms
 
 import (
 	"context"
-	"crypto/sha256"
-	"crypto/sha512"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA256 for crypto.Hash
+	_ "crypto/sha512" // register SHA384 and SHA512 for crypto.Hash
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
-	"hash"
+	"strings"
+	"sync"
 
 	apiv1 "cloud.google.com/go/kms/apiv1"
 	"github.com/googleapis/gax-go/v2"
@@ -23,6 +30,7 @@ const (
 // kmsClient is a subset of cloud.google.com/go/kms/apiv1.KeyManagementClient
 type kmsClient interface {
 	AsymmetricSign(ctx context.Context, req *kms_pb.AsymmetricSignRequest, opts ...gax.CallOption) (*kms_pb.AsymmetricSignResponse, error)
+	GetPublicKey(ctx context.Context, req *kms_pb.GetPublicKeyRequest, opts ...gax.CallOption) (*kms_pb.PublicKey, error)
 	Close() error
 }
 
@@ -38,6 +46,9 @@ type Key struct {
 type Signer struct {
 	keys   map[string]Key
 	client kmsClient
+
+	publicKeysMu sync.Mutex
+	publicKeys   map[string]*kms_pb.PublicKey
 }
 
 func NewSigner(keys map[string]Key, opts ...SignerOpt) (*Signer, error) {
@@ -50,7 +61,7 @@ func NewSigner(keys map[string]Key, opts ...SignerOpt) (*Signer, error) {
 		}
 	}
 
-	s := &Signer{keys: keys}
+	s := &Signer{keys: keys, publicKeys: make(map[string]*kms_pb.PublicKey)}
 	for _, o := range opts {
 		o(s)
 	}
@@ -79,37 +90,25 @@ func (s *Signer) Sign(checkName, body string) (string, string, error) {
 		return "", "", signer.ErrNoKeyForCheck
 	}
 
-	digest := func(h hash.Hash) ([]byte, error) {
-		if _, err := h.Write([]byte(body)); err != nil {
-			return nil, err
-		}
-		return h.Sum(nil), nil
+	_, digested, err := digest(key.Algo, body)
+	if err != nil {
+		return "", "", err
 	}
 
 	var d kms_pb.Digest
-	var digested []byte
-	var digestErr error
 	switch key.Algo {
 	case AlgoSHA256:
-		digested, digestErr = digest(sha256.New())
 		d.Digest = &kms_pb.Digest_Sha256{
 			Sha256: digested,
 		}
 	case AlgoSHA384:
-		digested, digestErr = digest(sha512.New384())
 		d.Digest = &kms_pb.Digest_Sha384{
 			Sha384: digested,
 		}
 	case AlgoSHA512:
-		digested, digestErr = digest(sha512.New())
 		d.Digest = &kms_pb.Digest_Sha512{
 			Sha512: digested,
 		}
-	default:
-		return "", "", fmt.Errorf("unsupported digest algorithm %v", key.Algo)
-	}
-	if digestErr != nil {
-		return "", "", digestErr
 	}
 
 	resp, err := s.client.AsymmetricSign(context.Background(), &kms_pb.AsymmetricSignRequest{
@@ -121,7 +120,115 @@ func (s *Signer) Sign(checkName, body string) (string, string, error) {
 		return "", "", err
 	}
 
-	return string(resp.Signature), fmt.Sprintf(APIPath+"/%v", key.Path), nil
+	return string(resp.Signature), keyID(key), nil
+}
+
+// Verify verifies that the passed signature was created over the body by the
+// KMS key configured for the check. The public half of the key is fetched from
+// KMS and cached for the lifetime of the Signer.
+func (s *Signer) Verify(checkName, body, signature, id string) error {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return signer.ErrNoKeyForCheck
+	}
+
+	if id != keyID(key) {
+		return signer.ErrKeyMismatch
+	}
+
+	publicKey, err := s.getPublicKey(key.Path)
+	if err != nil {
+		return err
+	}
+
+	hashAlgo, digested, err := digest(key.Algo, body)
+	if err != nil {
+		return err
+	}
+
+	return verifySignature(publicKey, hashAlgo, digested, []byte(signature))
+}
+
+// getPublicKey returns the public key for the KMS key version at the passed
+// path, fetching it from KMS if it hasn't been seen before.
+func (s *Signer) getPublicKey(path string) (*kms_pb.PublicKey, error) {
+	s.publicKeysMu.Lock()
+	defer s.publicKeysMu.Unlock()
+
+	if publicKey, ok := s.publicKeys[path]; ok {
+		return publicKey, nil
+	}
+
+	publicKey, err := s.client.GetPublicKey(context.Background(), &kms_pb.GetPublicKeyRequest{
+		Name: path,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key for %s: %w", path, err)
+	}
+
+	s.publicKeys[path] = publicKey
+	return publicKey, nil
+}
+
+// verifySignature verifies the signature of the digest against the PEM encoded
+// public key returned by KMS.
+func verifySignature(publicKey *kms_pb.PublicKey, hashAlgo crypto.Hash, digested, signature []byte) error {
+	block, _ := pem.Decode([]byte(publicKey.GetPem()))
+	if block == nil {
+		return errors.New("public key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	switch pub := parsed.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digested, signature) {
+			return signer.ErrInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		if strings.Contains(publicKey.GetAlgorithm().String(), "PSS") {
+			err = rsa.VerifyPSS(pub, hashAlgo, digested, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, hashAlgo, digested, signature)
+		}
+		if err != nil {
+			return signer.ErrInvalidSignature
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported public key type %T", parsed)
+}
+
+// digest hashes the body with the passed digest algorithm.
+func digest(algo, body string) (crypto.Hash, []byte, error) {
+	var hashAlgo crypto.Hash
+	switch algo {
+	case AlgoSHA256:
+		hashAlgo = crypto.SHA256
+	case AlgoSHA384:
+		hashAlgo = crypto.SHA384
+	case AlgoSHA512:
+		hashAlgo = crypto.SHA512
+	default:
+		return 0, nil, fmt.Errorf("unsupported digest algorithm %v", algo)
+	}
+
+	h := hashAlgo.New()
+	if _, err := h.Write([]byte(body)); err != nil {
+		return 0, nil, err
+	}
+	return hashAlgo, h.Sum(nil), nil
+}
+
+// keyID returns the identifier that is recorded alongside signatures made
+// with the passed key.
+func keyID(key Key) string {
+	return fmt.Sprintf(APIPath+"/%v", key.Path)
 }
 
 // Close closes the KMS signer's connections.
@@ -2,10 +2,17 @@ package kms_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"testing"
 
 	"github.com/googleapis/gax-go/v2"
+	vsigner "github.com/grafeas/voucher/v2/signer"
 	"github.com/grafeas/voucher/v2/signer/kms"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -76,8 +83,46 @@ func TestSigner_Sign(t *testing.T) {
 	}
 }
 
+func TestSigner_Verify(t *testing.T) {
+	const checkBody = "pass"
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	k := &mockKMS{
+		publicKey: &kms_pb.PublicKey{
+			Pem:       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			Algorithm: kms_pb.CryptoKeyVersion_EC_SIGN_P256_SHA256,
+		},
+	}
+	signer, err := kms.NewSigner(map[string]kms.Key{
+		checkName: {
+			Path: keyPath,
+			Algo: kms.AlgoSHA256,
+		},
+	}, kms.WithKMSClient(k))
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte(checkBody))
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	require.NoError(t, err)
+	keyID := kms.APIPath + "/" + keyPath
+
+	assert.NoError(t, signer.Verify(checkName, checkBody, string(signature), keyID))
+	assert.ErrorIs(t, signer.Verify(checkName, "fail", string(signature), keyID), vsigner.ErrInvalidSignature)
+	assert.ErrorIs(t, signer.Verify(checkName, checkBody, string(signature), "some-other-key"), vsigner.ErrKeyMismatch)
+	assert.ErrorIs(t, signer.Verify("other-check", checkBody, string(signature), keyID), vsigner.ErrNoKeyForCheck)
+
+	// the public key should only be fetched once
+	assert.Equal(t, 1, k.publicKeyReqs)
+}
+
 type mockKMS struct {
-	reqs []*kms_pb.AsymmetricSignRequest
+	reqs          []*kms_pb.AsymmetricSignRequest
+	publicKey     *kms_pb.PublicKey
+	publicKeyReqs int
 }
 
 func (k *mockKMS) AsymmetricSign(_ context.Context, req *kms_pb.AsymmetricSignRequest, _ ...gax.CallOption) (*kms_pb.AsymmetricSignResponse, error) {
@@ -86,4 +131,10 @@ func (k *mockKMS) AsymmetricSign(_ context.Context, req *kms_pb.AsymmetricSignRe
 		Signature: []byte(mockSignature),
 	}, nil
 }
+
+func (k *mockKMS) GetPublicKey(_ context.Context, _ *kms_pb.GetPublicKeyRequest, _ ...gax.CallOption) (*kms_pb.PublicKey, error) {
+	k.publicKeyReqs++
+	return k.publicKey, nil
+}
+
 func (k *mockKMS) Close() error { return nil }
@@ -0,0 +1,212 @@
+package local
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // register SHA384 and SHA512 for crypto.Hash
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/grafeas/voucher/v2/signer"
+)
+
+// Signer is an AttestationSigner which signs attestations with PKCS#8 private
+// keys held in memory, such as keys loaded from files or secrets. Signatures
+// are PKIX signatures, which can be verified with the keys' public halves.
+type Signer struct {
+	keys map[string]crypto.Signer
+}
+
+// NewSigner creates a new Signer with no keys.
+func NewSigner() *Signer {
+	return &Signer{
+		keys: make(map[string]crypto.Signer),
+	}
+}
+
+// AddKey adds the PEM encoded PKCS#8 private key to the Signer, as the key
+// for the check with the passed name. Ed25519, ECDSA and RSA keys are
+// supported.
+func (s *Signer) AddKey(checkName string, pemBytes []byte) error {
+	block, _ := pem.Decode(pemBytes)
+	if nil == block {
+		return fmt.Errorf("key for %s is not PEM encoded", checkName)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if nil != err {
+		return fmt.Errorf("failed to parse key for %s: %w", checkName, err)
+	}
+
+	switch key.(type) {
+	case ed25519.PrivateKey, *ecdsa.PrivateKey, *rsa.PrivateKey:
+		s.keys[checkName] = key.(crypto.Signer)
+	default:
+		return fmt.Errorf("unsupported key type %T for %s", key, checkName)
+	}
+
+	return nil
+}
+
+// AddKeyFromFile adds the PEM encoded PKCS#8 private key in the file at the
+// passed path to the Signer, as the key for the check with the passed name.
+func (s *Signer) AddKeyFromFile(checkName, path string) error {
+	pemBytes, err := os.ReadFile(path)
+	if nil != err {
+		return fmt.Errorf("failed to read key for %s: %w", checkName, err)
+	}
+
+	return s.AddKey(checkName, pemBytes)
+}
+
+// Sign signs the body with the key for the check with the passed name, and
+// returns the signature and the ID of the key.
+func (s *Signer) Sign(checkName, body string) (string, string, error) {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return "", "", signer.ErrNoKeyForCheck
+	}
+
+	id, err := KeyID(key.Public())
+	if nil != err {
+		return "", "", err
+	}
+
+	hashAlgo, err := hashFor(key.Public())
+	if nil != err {
+		return "", "", err
+	}
+
+	digested := []byte(body)
+	if 0 != hashAlgo {
+		h := hashAlgo.New()
+		h.Write(digested)
+		digested = h.Sum(nil)
+	}
+
+	signature, err := key.Sign(rand.Reader, digested, hashAlgo)
+	if nil != err {
+		return "", "", err
+	}
+
+	return string(signature), id, nil
+}
+
+// Verify verifies that the passed signature was created over the body by the
+// key for the check with the passed name, and that the key ID is that key's.
+func (s *Signer) Verify(checkName, body, signature, keyID string) error {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return signer.ErrNoKeyForCheck
+	}
+
+	id, err := KeyID(key.Public())
+	if nil != err {
+		return err
+	}
+
+	if id != keyID {
+		return signer.ErrKeyMismatch
+	}
+
+	return VerifySignature(key.Public(), []byte(body), []byte(signature))
+}
+
+// Close does nothing, as the Signer holds no connections.
+func (s *Signer) Close() error {
+	return nil
+}
+
+// KeyID returns the ID of the passed public key, which is the SHA-256 digest
+// of its PKIX encoding, as an RFC 6920 "ni" URI. This is the ID Binary
+// Authorization gives PKIX keys by default.
+func KeyID(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if nil != err {
+		return "", err
+	}
+
+	digest := sha256.Sum256(der)
+	return "ni:///sha-256;" + base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX public key, for verifying
+// signatures made by a Signer.
+func ParsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if nil == block {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if nil != err {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return publicKey, nil
+}
+
+// VerifySignature verifies that the passed signature was created over the
+// body by the private half of the passed public key. ECDSA signatures are
+// ASN.1 encoded, and RSA signatures are PKCS #1 v1.5 signatures.
+func VerifySignature(publicKey crypto.PublicKey, body, signature []byte) error {
+	hashAlgo, err := hashFor(publicKey)
+	if nil != err {
+		return err
+	}
+
+	digested := body
+	if 0 != hashAlgo {
+		h := hashAlgo.New()
+		h.Write(body)
+		digested = h.Sum(nil)
+	}
+
+	valid := false
+	switch pub := publicKey.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(pub, body, signature)
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(pub, digested, signature)
+	case *rsa.PublicKey:
+		valid = nil == rsa.VerifyPKCS1v15(pub, hashAlgo, digested, signature)
+	}
+
+	if !valid {
+		return signer.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// hashFor returns the hash that bodies are digested with before they are
+// signed by the passed public key's private half. Ed25519 keys sign bodies
+// without digesting them first. ECDSA keys use the hash matching the size
+// of their curve.
+func hashFor(publicKey crypto.PublicKey) (crypto.Hash, error) {
+	switch pub := publicKey.(type) {
+	case ed25519.PublicKey:
+		return 0, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P384():
+			return crypto.SHA384, nil
+		case elliptic.P521():
+			return crypto.SHA512, nil
+		}
+		return crypto.SHA256, nil
+	case *rsa.PublicKey:
+		return crypto.SHA256, nil
+	}
+
+	return 0, fmt.Errorf("unsupported public key type %T", publicKey)
+}
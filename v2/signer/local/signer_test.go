@@ -0,0 +1,112 @@
+package local_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vsigner "github.com/grafeas/voucher/v2/signer"
+	"github.com/grafeas/voucher/v2/signer/local"
+)
+
+const (
+	checkName = "diy"
+	body      = `{"critical":{"identity":{"docker-reference":"gcr.io/voucher/app"}}}`
+)
+
+func encodePrivateKey(t *testing.T, key crypto.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestSignAndVerify(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{
+		"ed25519": ed25519Key,
+		"p256":    p256Key,
+		"p384":    p384Key,
+		"rsa":     rsaKey,
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := local.NewSigner()
+			require.NoError(t, s.AddKey(checkName, encodePrivateKey(t, key)))
+
+			signature, keyID, err := s.Sign(checkName, body)
+			require.NoError(t, err)
+
+			expectedID, err := local.KeyID(key.Public())
+			require.NoError(t, err)
+			assert.Equal(t, expectedID, keyID)
+
+			assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+			assert.Equal(t, vsigner.ErrInvalidSignature, s.Verify(checkName, body+" ", signature, keyID))
+			assert.Equal(t, vsigner.ErrKeyMismatch, s.Verify(checkName, body, signature, "ni:///sha-256;other"))
+			assert.Equal(t, vsigner.ErrNoKeyForCheck, s.Verify("nobody", body, signature, keyID))
+
+			// the signature can be verified with the public key alone.
+			publicKey, err := local.ParsePublicKey(encodePublicKey(t, key.Public()))
+			require.NoError(t, err)
+			assert.NoError(t, local.VerifySignature(publicKey, []byte(body), []byte(signature)))
+		})
+	}
+}
+
+func TestAddKeyFromFile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "diy.pem")
+	require.NoError(t, os.WriteFile(path, encodePrivateKey(t, key), 0600))
+
+	s := local.NewSigner()
+	require.NoError(t, s.AddKeyFromFile(checkName, path))
+
+	_, keyID, err := s.Sign(checkName, body)
+	require.NoError(t, err)
+	assert.Regexp(t, `^ni:///sha-256;[A-Za-z0-9_-]{43}$`, keyID)
+
+	_, _, err = s.Sign("nobody", body)
+	assert.Equal(t, vsigner.ErrNoKeyForCheck, err)
+
+	assert.Error(t, s.AddKeyFromFile(checkName, filepath.Join(t.TempDir(), "missing.pem")))
+
+	// keys must be PKCS#8 encoded.
+	sec1, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	assert.Error(t, s.AddKey(checkName, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})))
+	assert.EqualError(t, s.AddKey(checkName, []byte("not a key")), "key for diy is not PEM encoded")
+
+	assert.NoError(t, s.Close())
+}
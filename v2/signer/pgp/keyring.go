@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/grafeas/voucher/v2/signer"
 	"golang.org/x/crypto/openpgp"
@@ -31,6 +32,31 @@ func (keyring *KeyRing) Sign(checkName, body string) (string, string, error) {
 	return signature, fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint), err
 }
 
+// Verify verifies that the passed signature was created by the key associated
+// with the passed check name, that the signed message matches the body, and
+// that the keyID is the fingerprint of that key.
+func (keyring *KeyRing) Verify(checkName, body, signature, keyID string) error {
+	entity, err := keyring.GetSignerByName(checkName)
+	if nil != err {
+		return err
+	}
+
+	if !strings.EqualFold(keyID, fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)) {
+		return signer.ErrKeyMismatch
+	}
+
+	message, err := Verify(openpgp.EntityList{entity}, signature)
+	if nil != err {
+		return fmt.Errorf("%w: %s", signer.ErrInvalidSignature, err)
+	}
+
+	if message != body {
+		return signer.ErrInvalidSignature
+	}
+
+	return nil
+}
+
 // KeysById returns the set of keys that have the given key id.
 func (keyring *KeyRing) KeysById(id uint64) []openpgp.Key {
 	return keyring.entities.KeysById(id)
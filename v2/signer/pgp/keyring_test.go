@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/grafeas/voucher/v2/signer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -108,3 +109,18 @@ func TestAttestationPayload(t *testing.T) {
 		assert.Equalf(t, message, payloadMessage, "Failed to get correct message, was \"%s\" instead of \"%s\"", message, payloadMessage)
 	}
 }
+
+func TestKeyRingVerify(t *testing.T) {
+	payloadMessage := "test was successful"
+
+	keyring := newTestKeyRing(t)
+
+	result, fingerprint, err := keyring.Sign("snakeoil", payloadMessage)
+	require.NoError(t, err)
+
+	assert.NoError(t, keyring.Verify("snakeoil", payloadMessage, result, fingerprint))
+	assert.ErrorIs(t, keyring.Verify("snakeoil", "test was forged", result, fingerprint), signer.ErrInvalidSignature)
+	assert.ErrorIs(t, keyring.Verify("snakeoil", payloadMessage, result, "0123456789ABCDEF"), signer.ErrKeyMismatch)
+	assert.ErrorIs(t, keyring.Verify("diy", payloadMessage, result, fingerprint), signer.ErrNoKeyForCheck)
+	assert.ErrorIs(t, keyring.Verify("snakeoil", payloadMessage, "not a signature", fingerprint), signer.ErrInvalidSignature)
+}
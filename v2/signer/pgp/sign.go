@@ -87,11 +87,11 @@ func Verify(keyring openpgp.KeyRing, signed string) (string, error) {
 		return "", errNoSigner
 	}
 
+	// The signature is only checked once the body has been read in full, so
+	// the SignatureError must be consulted even if reading succeeded.
 	body, err := io.ReadAll(messageDetails.UnverifiedBody)
-	if nil != err {
-		if nil != messageDetails.SignatureError {
-			err = messageDetails.SignatureError
-		}
+	if nil != messageDetails.SignatureError {
+		err = messageDetails.SignatureError
 	}
 	return string(body), err
 }
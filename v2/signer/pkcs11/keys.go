@@ -0,0 +1,255 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/grafeas/voucher/v2/signer"
+	localsigner "github.com/grafeas/voucher/v2/signer/local"
+)
+
+// curves maps the OIDs of named curves in CKA_EC_PARAMS to the curves.
+var curves = map[string]elliptic.Curve{
+	"1.2.840.10045.3.1.7": elliptic.P256(),
+	"1.3.132.0.34":        elliptic.P384(),
+	"1.3.132.0.35":        elliptic.P521(),
+}
+
+// digestInfoPrefixes are the DER encoded DigestInfo prefixes that digests are
+// wrapped in before they're signed with RSA PKCS #1 v1.5 padding, as
+// CKM_RSA_PKCS signs data as it is passed.
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// pssParams are the CKM_RSA_PKCS_PSS hash and mask generation function for
+// each digest algorithm. The salt is as long as the digest.
+var pssParams = map[crypto.Hash][2]uint{
+	crypto.SHA256: {pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256},
+	crypto.SHA384: {pkcs11.CKM_SHA384, pkcs11.CKG_MGF1_SHA384},
+	crypto.SHA512: {pkcs11.CKM_SHA512, pkcs11.CKG_MGF1_SHA512},
+}
+
+// findKey finds the private key with the passed Key's label in the token,
+// and reads the public key with the same label.
+func findKey(m module, session pkcs11.SessionHandle, key Key) (tokenKey, error) {
+	handle, err := findObject(m, session, pkcs11.CKO_PRIVATE_KEY, key.Label)
+	if nil != err {
+		return tokenKey{}, err
+	}
+
+	pubHandle, err := findObject(m, session, pkcs11.CKO_PUBLIC_KEY, key.Label)
+	if nil != err {
+		return tokenKey{}, err
+	}
+
+	publicKey, err := readPublicKey(m, session, pubHandle)
+	if nil != err {
+		return tokenKey{}, err
+	}
+
+	if _, ok := publicKey.(*ecdsa.PublicKey); ok && PaddingPSS == key.Padding {
+		return tokenKey{}, fmt.Errorf("padding %v is not supported by EC key %s", key.Padding, key.Label)
+	}
+
+	id, err := localsigner.KeyID(publicKey)
+	if nil != err {
+		return tokenKey{}, err
+	}
+
+	return tokenKey{
+		Key:       key,
+		handle:    handle,
+		publicKey: publicKey,
+		id:        id,
+	}, nil
+}
+
+// findObject returns the handle of the only object of the passed class with
+// the passed label.
+func findObject(m module, session pkcs11.SessionHandle, class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+
+	if err := m.FindObjectsInit(session, template); nil != err {
+		return 0, err
+	}
+
+	handles, _, err := m.FindObjects(session, 2)
+	if finalErr := m.FindObjectsFinal(session); nil == err {
+		err = finalErr
+	}
+
+	if nil != err {
+		return 0, err
+	}
+
+	switch len(handles) {
+	case 0:
+		return 0, fmt.Errorf("no key with label %q", label)
+	case 1:
+		return handles[0], nil
+	}
+
+	return 0, fmt.Errorf("more than one key with label %q", label)
+}
+
+// readPublicKey reads the RSA or EC public key with the passed handle.
+func readPublicKey(m module, session pkcs11.SessionHandle, handle pkcs11.ObjectHandle) (crypto.PublicKey, error) {
+	attrs, err := m.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	keyType := attrs[0].Value
+	switch {
+	case isKeyType(keyType, pkcs11.CKK_RSA):
+		attrs, err = m.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if nil != err {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[0].Value),
+			E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+		}, nil
+	case isKeyType(keyType, pkcs11.CKK_EC):
+		attrs, err = m.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		})
+		if nil != err {
+			return nil, err
+		}
+
+		return parseECPublicKey(attrs[0].Value, attrs[1].Value)
+	}
+
+	return nil, fmt.Errorf("unsupported key type %x", keyType)
+}
+
+// parseECPublicKey parses an EC public key from its DER encoded named curve
+// OID and its DER encoded point.
+func parseECPublicKey(params, point []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); nil != err {
+		return nil, fmt.Errorf("failed to parse EC parameters: %w", err)
+	}
+
+	curve, ok := curves[oid.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve %s", oid)
+	}
+
+	var encoded []byte
+	if _, err := asn1.Unmarshal(point, &encoded); nil != err {
+		return nil, fmt.Errorf("failed to parse EC point: %w", err)
+	}
+
+	x, y := elliptic.Unmarshal(curve, encoded)
+	if nil == x {
+		return nil, errors.New("failed to parse EC point")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// isKeyType returns true if the passed CKA_KEY_TYPE value is the passed key
+// type. CK_ULONG values are encoded in the host's byte order, as they are
+// encoded by pkcs11.NewAttribute.
+func isKeyType(value []byte, keyType uint) bool {
+	return bytes.Equal(value, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType).Value)
+}
+
+// digest returns the hash for the passed digest algorithm, and the digest of
+// the body.
+func digest(algo, body string) (crypto.Hash, []byte, error) {
+	switch algo {
+	case AlgoSHA256:
+		d := sha256.Sum256([]byte(body))
+		return crypto.SHA256, d[:], nil
+	case AlgoSHA384:
+		d := sha512.Sum384([]byte(body))
+		return crypto.SHA384, d[:], nil
+	case AlgoSHA512:
+		d := sha512.Sum512([]byte(body))
+		return crypto.SHA512, d[:], nil
+	}
+
+	return 0, nil, fmt.Errorf("unsupported digest algorithm %v", algo)
+}
+
+// signingMechanism returns the mechanism which signs the passed digest with
+// the passed key, and the data to pass to it.
+func signingMechanism(key tokenKey, hashAlgo crypto.Hash, digested []byte) (*pkcs11.Mechanism, []byte) {
+	if _, ok := key.publicKey.(*ecdsa.PublicKey); ok {
+		return pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil), digested
+	}
+
+	if PaddingPSS == key.Padding {
+		params := pssParams[hashAlgo]
+		return pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_PSS, pkcs11.NewPSSParams(params[0], params[1], uint(hashAlgo.Size()))), digested
+	}
+
+	return pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil), append(append([]byte{}, digestInfoPrefixes[hashAlgo]...), digested...)
+}
+
+// ecdsaSignatureToASN1 converts a raw ECDSA signature, which is r followed by
+// s, to an ASN.1 encoded signature.
+func ecdsaSignatureToASN1(signature []byte) ([]byte, error) {
+	if 0 == len(signature) || 0 != len(signature)%2 {
+		return nil, errors.New("invalid ECDSA signature from PKCS#11 token")
+	}
+
+	half := len(signature) / 2
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(signature[:half]),
+		S: new(big.Int).SetBytes(signature[half:]),
+	})
+}
+
+// verifySignature verifies that the passed signature was created over the
+// digest by the private half of the passed key.
+func verifySignature(key tokenKey, hashAlgo crypto.Hash, digested, signature []byte) error {
+	valid := false
+	switch pub := key.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(pub, digested, signature)
+	case *rsa.PublicKey:
+		if PaddingPSS == key.Padding {
+			valid = nil == rsa.VerifyPSS(pub, hashAlgo, digested, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			valid = nil == rsa.VerifyPKCS1v15(pub, hashAlgo, digested, signature)
+		}
+	}
+
+	if !valid {
+		return signer.ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// fakeObject is a key in a fakeModule's token.
+type fakeObject struct {
+	class uint
+	label string
+	key   crypto.Signer
+}
+
+// fakeModule is a PKCS#11 module with one token, which signs with keys held
+// in memory. It fails if a session is used by two callers at once.
+type fakeModule struct {
+	sync.Mutex
+	tokenLabel string
+	pin        string
+	objects    []fakeObject
+
+	nextSession pkcs11.SessionHandle
+	open        map[pkcs11.SessionHandle]bool
+	inUse       map[pkcs11.SessionHandle]bool
+	found       map[pkcs11.SessionHandle][]pkcs11.ObjectHandle
+	signing     map[pkcs11.SessionHandle]signOperation
+	loggedIn    bool
+	finalized   bool
+	destroyed   bool
+}
+
+// signOperation is a signing operation started by SignInit.
+type signOperation struct {
+	mechanism *pkcs11.Mechanism
+	key       crypto.Signer
+}
+
+func newFakeModule(tokenLabel, pin string) *fakeModule {
+	return &fakeModule{
+		tokenLabel: tokenLabel,
+		pin:        pin,
+		open:       make(map[pkcs11.SessionHandle]bool),
+		inUse:      make(map[pkcs11.SessionHandle]bool),
+		found:      make(map[pkcs11.SessionHandle][]pkcs11.ObjectHandle),
+		signing:    make(map[pkcs11.SessionHandle]signOperation),
+	}
+}
+
+// addKey adds the passed key to the token, as a private key and a public key
+// with the passed label.
+func (m *fakeModule) addKey(label string, key crypto.Signer) {
+	m.objects = append(m.objects,
+		fakeObject{class: pkcs11.CKO_PRIVATE_KEY, label: label, key: key},
+		fakeObject{class: pkcs11.CKO_PUBLIC_KEY, label: label, key: key},
+	)
+}
+
+func (m *fakeModule) Initialize() error {
+	return nil
+}
+
+func (m *fakeModule) Finalize() error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.finalized = true
+	return nil
+}
+
+func (m *fakeModule) Destroy() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.destroyed = true
+}
+
+func (m *fakeModule) GetSlotList(tokenPresent bool) ([]uint, error) {
+	return []uint{0, 1}, nil
+}
+
+func (m *fakeModule) GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error) {
+	if 1 == slotID {
+		return pkcs11.TokenInfo{Label: m.tokenLabel}, nil
+	}
+	return pkcs11.TokenInfo{Label: "other"}, nil
+}
+
+func (m *fakeModule) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if 1 != slotID {
+		return 0, pkcs11.Error(pkcs11.CKR_SLOT_ID_INVALID)
+	}
+
+	m.nextSession++
+	m.open[m.nextSession] = true
+	return m.nextSession, nil
+}
+
+func (m *fakeModule) CloseSession(sh pkcs11.SessionHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if !m.open[sh] {
+		return pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)
+	}
+
+	delete(m.open, sh)
+	return nil
+}
+
+func (m *fakeModule) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.loggedIn {
+		return pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)
+	}
+
+	if m.pin != pin {
+		return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+	}
+
+	m.loggedIn = true
+	return nil
+}
+
+func (m *fakeModule) Logout(sh pkcs11.SessionHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.loggedIn = false
+	return nil
+}
+
+func (m *fakeModule) FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error {
+	m.Lock()
+	defer m.Unlock()
+
+	var found []pkcs11.ObjectHandle
+	for i, object := range m.objects {
+		matches := true
+		for _, attr := range temp {
+			switch attr.Type {
+			case pkcs11.CKA_CLASS:
+				matches = matches && bytes.Equal(attr.Value, pkcs11.NewAttribute(attr.Type, object.class).Value)
+			case pkcs11.CKA_LABEL:
+				matches = matches && object.label == string(attr.Value)
+			}
+		}
+
+		if matches {
+			found = append(found, pkcs11.ObjectHandle(i+1))
+		}
+	}
+
+	m.found[sh] = found
+	return nil
+}
+
+func (m *fakeModule) FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	found := m.found[sh]
+	if max < len(found) {
+		found = found[:max]
+	}
+	return found, false, nil
+}
+
+func (m *fakeModule) FindObjectsFinal(sh pkcs11.SessionHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.found, sh)
+	return nil
+}
+
+func (m *fakeModule) GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error) {
+	object := m.objects[o-1]
+
+	attrs := make([]*pkcs11.Attribute, 0, len(a))
+	for _, attr := range a {
+		switch pub := object.key.Public().(type) {
+		case *rsa.PublicKey:
+			switch attr.Type {
+			case pkcs11.CKA_KEY_TYPE:
+				attrs = append(attrs, pkcs11.NewAttribute(attr.Type, pkcs11.CKK_RSA))
+			case pkcs11.CKA_MODULUS:
+				attrs = append(attrs, pkcs11.NewAttribute(attr.Type, pub.N.Bytes()))
+			case pkcs11.CKA_PUBLIC_EXPONENT:
+				attrs = append(attrs, pkcs11.NewAttribute(attr.Type, big.NewInt(int64(pub.E)).Bytes()))
+			default:
+				return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_TYPE_INVALID)
+			}
+		case *ecdsa.PublicKey:
+			switch attr.Type {
+			case pkcs11.CKA_KEY_TYPE:
+				attrs = append(attrs, pkcs11.NewAttribute(attr.Type, pkcs11.CKK_EC))
+			case pkcs11.CKA_EC_PARAMS:
+				params, err := asn1.Marshal(curveOID(pub.Curve))
+				if nil != err {
+					return nil, err
+				}
+				attrs = append(attrs, pkcs11.NewAttribute(attr.Type, params))
+			case pkcs11.CKA_EC_POINT:
+				point, err := asn1.Marshal(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+				if nil != err {
+					return nil, err
+				}
+				attrs = append(attrs, pkcs11.NewAttribute(attr.Type, point))
+			default:
+				return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_TYPE_INVALID)
+			}
+		}
+	}
+
+	return attrs, nil
+}
+
+func (m *fakeModule) SignInit(sh pkcs11.SessionHandle, mechanisms []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if !m.open[sh] {
+		return pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)
+	}
+
+	if m.inUse[sh] {
+		return fmt.Errorf("session %d is already in use", sh)
+	}
+
+	if !m.loggedIn {
+		return pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+
+	object := m.objects[o-1]
+	if pkcs11.CKO_PRIVATE_KEY != object.class {
+		return pkcs11.Error(pkcs11.CKR_KEY_TYPE_INCONSISTENT)
+	}
+
+	m.inUse[sh] = true
+	m.signing[sh] = signOperation{mechanism: mechanisms[0], key: object.key}
+	return nil
+}
+
+func (m *fakeModule) Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error) {
+	m.Lock()
+	op := m.signing[sh]
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		defer m.Unlock()
+
+		delete(m.inUse, sh)
+		delete(m.signing, sh)
+	}()
+
+	switch op.mechanism.Mechanism {
+	case pkcs11.CKM_ECDSA:
+		key := op.key.(*ecdsa.PrivateKey)
+		r, s, err := ecdsa.Sign(rand.Reader, key, message)
+		if nil != err {
+			return nil, err
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	case pkcs11.CKM_RSA_PKCS:
+		return rsa.SignPKCS1v15(rand.Reader, op.key.(*rsa.PrivateKey), 0, message)
+	case pkcs11.CKM_RSA_PKCS_PSS:
+		if !bytes.Equal(op.mechanism.Parameter, pkcs11.NewPSSParams(pssHash(message), pssMGF(message), uint(len(message)))) {
+			return nil, pkcs11.Error(pkcs11.CKR_MECHANISM_PARAM_INVALID)
+		}
+
+		hashAlgo := map[int]crypto.Hash{32: crypto.SHA256, 48: crypto.SHA384, 64: crypto.SHA512}[len(message)]
+		return rsa.SignPSS(rand.Reader, op.key.(*rsa.PrivateKey), hashAlgo, message, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+
+	return nil, pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)
+}
+
+// reset closes every session and logs out, as a token does when it is reset
+// or reconnected.
+func (m *fakeModule) reset() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.open = make(map[pkcs11.SessionHandle]bool)
+	m.loggedIn = false
+}
+
+// sessions returns the number of open sessions.
+func (m *fakeModule) sessions() int {
+	m.Lock()
+	defer m.Unlock()
+
+	return len(m.open)
+}
+
+// curveOID returns the OID of the passed named curve.
+func curveOID(curve elliptic.Curve) asn1.ObjectIdentifier {
+	switch curve {
+	case elliptic.P384():
+		return asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	case elliptic.P521():
+		return asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+	}
+	return asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+}
+
+// pssHash returns the PSS hash mechanism for a digest of the passed length.
+func pssHash(digested []byte) uint {
+	return map[int]uint{32: pkcs11.CKM_SHA256, 48: pkcs11.CKM_SHA384, 64: pkcs11.CKM_SHA512}[len(digested)]
+}
+
+// pssMGF returns the PSS mask generation function for a digest of the passed
+// length.
+func pssMGF(digested []byte) uint {
+	return map[int]uint{32: pkcs11.CKG_MGF1_SHA256, 48: pkcs11.CKG_MGF1_SHA384, 64: pkcs11.CKG_MGF1_SHA512}[len(digested)]
+}
@@ -0,0 +1,399 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/grafeas/voucher/v2/signer"
+)
+
+const (
+	AlgoSHA256 = "SHA256"
+	AlgoSHA384 = "SHA384"
+	AlgoSHA512 = "SHA512"
+
+	PaddingPKCS1v15 = "PKCS1v15"
+	PaddingPSS      = "PSS"
+)
+
+// DefaultSessions is the number of sessions opened with the token if no
+// number is configured.
+const DefaultSessions = 4
+
+// errClosed is the error returned when the Signer is used after it was
+// closed.
+var errClosed = errors.New("pkcs11 signer is closed")
+
+// module is the subset of github.com/miekg/pkcs11.Ctx used by the Signer.
+type module interface {
+	Initialize() error
+	Finalize() error
+	Destroy()
+	GetSlotList(tokenPresent bool) ([]uint, error)
+	GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error)
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+	Logout(sh pkcs11.SessionHandle) error
+	FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error
+	FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh pkcs11.SessionHandle) error
+	GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error)
+	SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error
+	Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error)
+}
+
+var _ module = (*pkcs11.Ctx)(nil)
+
+// Config describes the PKCS#11 module and the token that keys are stored
+// in.
+type Config struct {
+	// Module is the path to the PKCS#11 module (eg.
+	// "/usr/lib/softhsm/libsofthsm2.so").
+	Module string
+
+	// TokenLabel is the label of the token that holds the keys.
+	TokenLabel string
+
+	// PIN is the user PIN of the token.
+	PIN string
+
+	// Sessions is the number of sessions opened with the token, which is
+	// the number of attestations that can be signed at once. Defaults to
+	// DefaultSessions.
+	Sessions int
+}
+
+// Key is a key pair in the token, which signs the attestations of a check.
+type Key struct {
+	// Label is the label (CKA_LABEL) of the private and public keys.
+	Label string
+
+	// Algo is the digest algorithm (SHA256, SHA384 or SHA512).
+	Algo string
+
+	// Padding is the signature padding of RSA keys (PKCS1v15 or PSS).
+	// Defaults to PKCS1v15.
+	Padding string
+}
+
+// tokenKey is a Key, and the handle and public half of its private key.
+type tokenKey struct {
+	Key
+	handle    pkcs11.ObjectHandle
+	publicKey crypto.PublicKey
+	id        string
+}
+
+// Signer is an AttestationSigner which signs attestations with keys stored
+// in a PKCS#11 token, such as an HSM. It holds a pool of sessions with the
+// token, so that several attestations can be signed at once.
+type Signer struct {
+	module   module
+	keys     map[string]tokenKey
+	sessions chan pkcs11.SessionHandle
+	size     int
+	opened   int
+	slot     uint
+	pin      string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// SignerOpt is an option for NewSigner.
+type SignerOpt func(*Signer)
+
+// WithModule makes the Signer use the passed module, rather than loading the
+// configured one.
+func WithModule(m module) SignerOpt {
+	return func(s *Signer) {
+		s.module = m
+	}
+}
+
+// NewSigner creates a new Signer, which logs in to the token configured in
+// the passed Config and finds the passed keys, by check name.
+func NewSigner(config Config, keys map[string]Key, opts ...SignerOpt) (*Signer, error) {
+	for checkName, key := range keys {
+		switch key.Algo {
+		case AlgoSHA256, AlgoSHA384, AlgoSHA512:
+			// supported
+		default:
+			return nil, fmt.Errorf("unsupported digest algorithm %v for check %v", key.Algo, checkName)
+		}
+
+		switch key.Padding {
+		case "", PaddingPKCS1v15, PaddingPSS:
+			// supported
+		default:
+			return nil, fmt.Errorf("unsupported padding %v for check %v", key.Padding, checkName)
+		}
+	}
+
+	s := &Signer{
+		keys: make(map[string]tokenKey, len(keys)),
+		size: config.Sessions,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+
+	if 0 >= s.size {
+		s.size = DefaultSessions
+	}
+
+	if nil == s.module {
+		ctx := pkcs11.New(config.Module)
+		if nil == ctx {
+			return nil, fmt.Errorf("failed to load PKCS#11 module %s", config.Module)
+		}
+		s.module = ctx
+	}
+
+	if err := s.module.Initialize(); nil != err {
+		s.module.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module: %w", err)
+	}
+
+	if err := s.openSessions(config); nil != err {
+		_ = s.Close()
+		return nil, err
+	}
+
+	if err := s.findKeys(keys); nil != err {
+		_ = s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// findKeys finds the passed keys in the token, by check name.
+func (s *Signer) findKeys(keys map[string]Key) error {
+	session := <-s.sessions
+	defer func() { s.sessions <- session }()
+
+	for checkName, key := range keys {
+		tk, err := findKey(s.module, session, key)
+		if nil != err {
+			return fmt.Errorf("failed to find key for %s: %w", checkName, err)
+		}
+		s.keys[checkName] = tk
+	}
+
+	return nil
+}
+
+// openSessions opens the pool of sessions with the configured token, and
+// logs in. PKCS#11 logins apply to every session an application has open
+// with a token, so the Signer only logs in once.
+func (s *Signer) openSessions(config Config) error {
+	slot, err := findSlot(s.module, config.TokenLabel)
+	if nil != err {
+		return err
+	}
+
+	s.slot = slot
+	s.pin = config.PIN
+
+	s.sessions = make(chan pkcs11.SessionHandle, s.size)
+	for i := 0; i < s.size; i++ {
+		session, err := s.openSession(0 == i)
+		if nil != err {
+			return err
+		}
+
+		s.sessions <- session
+		s.opened++
+	}
+
+	return nil
+}
+
+// openSession opens a session with the token, logging in if login is true.
+func (s *Signer) openSession(login bool) (pkcs11.SessionHandle, error) {
+	session, err := s.module.OpenSession(s.slot, pkcs11.CKF_SERIAL_SESSION)
+	if nil != err {
+		return 0, fmt.Errorf("failed to open PKCS#11 session: %w", err)
+	}
+
+	if login {
+		err = s.module.Login(session, pkcs11.CKU_USER, s.pin)
+		if nil != err && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+			_ = s.module.CloseSession(session)
+			return 0, fmt.Errorf("failed to log in to PKCS#11 token: %w", err)
+		}
+	}
+
+	return session, nil
+}
+
+// reopenSession closes the passed session, which the token no longer
+// accepts, and returns a new session in its place. The login is lost with
+// the token's sessions if the token was reset or removed, so the new session
+// logs in again. The passed session is returned if a new one can't be
+// opened, so that it is reopened the next time it is used.
+func (s *Signer) reopenSession(session pkcs11.SessionHandle) (pkcs11.SessionHandle, error) {
+	_ = s.module.CloseSession(session)
+
+	reopened, err := s.openSession(true)
+	if nil != err {
+		return session, err
+	}
+
+	return reopened, nil
+}
+
+// isSessionLost returns true if the passed error means that the session it
+// was returned for can't be used again, such as after the token was reset
+// or removed.
+func isSessionLost(err error) bool {
+	for _, code := range []uint{
+		pkcs11.CKR_SESSION_HANDLE_INVALID,
+		pkcs11.CKR_SESSION_CLOSED,
+		pkcs11.CKR_DEVICE_REMOVED,
+		pkcs11.CKR_TOKEN_NOT_PRESENT,
+		pkcs11.CKR_USER_NOT_LOGGED_IN,
+	} {
+		if errors.Is(err, pkcs11.Error(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSlot returns the slot holding the token with the passed label.
+func findSlot(m module, tokenLabel string) (uint, error) {
+	slots, err := m.GetSlotList(true)
+	if nil != err {
+		return 0, fmt.Errorf("failed to list PKCS#11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, err := m.GetTokenInfo(slot)
+		if nil != err {
+			return 0, fmt.Errorf("failed to get PKCS#11 token info: %w", err)
+		}
+
+		if tokenLabel == info.Label {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no PKCS#11 token with label %q", tokenLabel)
+}
+
+// Sign signs the body with the key for the check with the passed name, and
+// returns the signature and the ID of the key. ECDSA signatures are ASN.1
+// encoded, as they are by the other signers.
+func (s *Signer) Sign(checkName, body string) (string, string, error) {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return "", "", signer.ErrNoKeyForCheck
+	}
+
+	hashAlgo, digested, err := digest(key.Algo, body)
+	if nil != err {
+		return "", "", err
+	}
+
+	mechanism, data := signingMechanism(key, hashAlgo, digested)
+
+	session, ok := <-s.sessions
+	if !ok {
+		return "", "", errClosed
+	}
+	defer func() { s.sessions <- session }()
+
+	signature, err := s.sign(session, mechanism, key.handle, data)
+	if isSessionLost(err) {
+		// the session is replaced, rather than returned to the pool, and
+		// signing is tried again with its replacement.
+		var reopenErr error
+		if session, reopenErr = s.reopenSession(session); nil != reopenErr {
+			err = fmt.Errorf("%w (%s)", err, reopenErr)
+		} else {
+			signature, err = s.sign(session, mechanism, key.handle, data)
+		}
+	}
+
+	if nil != err {
+		return "", "", fmt.Errorf("failed to sign with PKCS#11 key %s: %w", key.Label, err)
+	}
+
+	if _, ok := key.publicKey.(*ecdsa.PublicKey); ok {
+		if signature, err = ecdsaSignatureToASN1(signature); nil != err {
+			return "", "", err
+		}
+	}
+
+	return string(signature), key.id, nil
+}
+
+// sign signs the passed data with the passed key and mechanism, in the passed
+// session.
+func (s *Signer) sign(session pkcs11.SessionHandle, mechanism *pkcs11.Mechanism, key pkcs11.ObjectHandle, data []byte) ([]byte, error) {
+	if err := s.module.SignInit(session, []*pkcs11.Mechanism{mechanism}, key); nil != err {
+		return nil, err
+	}
+
+	return s.module.Sign(session, data)
+}
+
+// Verify verifies that the passed signature was created over the body by the
+// key for the check with the passed name, and that the key ID is that key's.
+// Signatures are verified with the public key read from the token.
+func (s *Signer) Verify(checkName, body, signature, keyID string) error {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return signer.ErrNoKeyForCheck
+	}
+
+	if keyID != key.id {
+		return signer.ErrKeyMismatch
+	}
+
+	hashAlgo, digested, err := digest(key.Algo, body)
+	if nil != err {
+		return err
+	}
+
+	return verifySignature(key, hashAlgo, digested, []byte(signature))
+}
+
+// Close waits for signing to finish, closes the sessions with the token, and
+// unloads the module.
+func (s *Signer) Close() error {
+	s.closeOnce.Do(func() {
+		for i := 0; i < s.opened; i++ {
+			session := <-s.sessions
+			if 0 == i {
+				// logging out of one session logs out of all of them.
+				_ = s.module.Logout(session)
+			}
+
+			if err := s.module.CloseSession(session); nil != err && nil == s.closeErr {
+				s.closeErr = fmt.Errorf("failed to close PKCS#11 session: %w", err)
+			}
+		}
+
+		if nil != s.sessions {
+			close(s.sessions)
+		}
+
+		if err := s.module.Finalize(); nil != err && nil == s.closeErr {
+			s.closeErr = fmt.Errorf("failed to finalize PKCS#11 module: %w", err)
+		}
+		s.module.Destroy()
+	})
+
+	return s.closeErr
+}
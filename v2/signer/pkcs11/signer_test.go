@@ -0,0 +1,267 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vsigner "github.com/grafeas/voucher/v2/signer"
+	localsigner "github.com/grafeas/voucher/v2/signer/local"
+)
+
+const (
+	checkName  = "diy"
+	body       = `{"critical":{"identity":{"docker-reference":"gcr.io/voucher/app"}}}`
+	tokenLabel = "voucher"
+	pin        = "1234"
+)
+
+func newTestModule(t *testing.T) (*fakeModule, map[string]crypto.Signer) {
+	t.Helper()
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys := map[string]crypto.Signer{
+		"p256": p256Key,
+		"p384": p384Key,
+		"rsa":  rsaKey,
+	}
+
+	m := newFakeModule(tokenLabel, pin)
+	for label, key := range keys {
+		m.addKey(label, key)
+	}
+
+	return m, keys
+}
+
+func TestSignAndVerify(t *testing.T) {
+	m, keys := newTestModule(t)
+
+	for _, key := range []Key{
+		{Label: "p256", Algo: AlgoSHA256},
+		{Label: "p384", Algo: AlgoSHA384},
+		{Label: "rsa", Algo: AlgoSHA256},
+		{Label: "rsa", Algo: AlgoSHA512, Padding: PaddingPKCS1v15},
+		{Label: "rsa", Algo: AlgoSHA256, Padding: PaddingPSS},
+		{Label: "rsa", Algo: AlgoSHA384, Padding: PaddingPSS},
+	} {
+		t.Run(fmt.Sprintf("%s %s %s", key.Label, key.Algo, key.Padding), func(t *testing.T) {
+			s, err := NewSigner(Config{TokenLabel: tokenLabel, PIN: pin}, map[string]Key{checkName: key}, WithModule(m))
+			require.NoError(t, err)
+			defer s.Close()
+
+			signature, keyID, err := s.Sign(checkName, body)
+			require.NoError(t, err)
+
+			expectedID, err := localsigner.KeyID(keys[key.Label].Public())
+			require.NoError(t, err)
+			assert.Equal(t, expectedID, keyID)
+
+			assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+			assert.Equal(t, vsigner.ErrInvalidSignature, s.Verify(checkName, body+" ", signature, keyID))
+			assert.Equal(t, vsigner.ErrKeyMismatch, s.Verify(checkName, body, signature, "ni:///sha-256;other"))
+			assert.Equal(t, vsigner.ErrNoKeyForCheck, s.Verify("nobody", body, signature, keyID))
+
+			_, _, err = s.Sign("nobody", body)
+			assert.Equal(t, vsigner.ErrNoKeyForCheck, err)
+		})
+	}
+}
+
+func TestSignatureFormats(t *testing.T) {
+	m, keys := newTestModule(t)
+
+	s, err := NewSigner(Config{TokenLabel: tokenLabel, PIN: pin}, map[string]Key{
+		"ecdsa": {Label: "p256", Algo: AlgoSHA256},
+		"rsa":   {Label: "rsa", Algo: AlgoSHA256},
+		"pss":   {Label: "rsa", Algo: AlgoSHA256, Padding: PaddingPSS},
+	}, WithModule(m))
+	require.NoError(t, err)
+	defer s.Close()
+
+	digest := sha256.Sum256([]byte(body))
+
+	// ECDSA signatures are ASN.1 encoded, and RSA signatures are PKCS #1
+	// v1.5 signatures, as they are by the local signer.
+	for _, checkName := range []string{"ecdsa", "rsa"} {
+		signature, _, err := s.Sign(checkName, body)
+		require.NoError(t, err)
+		assert.NoError(t, localsigner.VerifySignature(keys[map[string]string{"ecdsa": "p256", "rsa": "rsa"}[checkName]].Public(), []byte(body), []byte(signature)))
+	}
+
+	signature, _, err := s.Sign("pss", body)
+	require.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPSS(keys["rsa"].Public().(*rsa.PublicKey), crypto.SHA256, digest[:], []byte(signature), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}))
+}
+
+func TestConcurrentSigning(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	s, err := NewSigner(Config{TokenLabel: tokenLabel, PIN: pin, Sessions: 2}, map[string]Key{
+		checkName: {Label: "p256", Algo: AlgoSHA256},
+	}, WithModule(m))
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.sessions())
+
+	// the fake module fails if a session is used by two signers at once.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			signature, keyID, err := s.Sign(checkName, body)
+			if nil == err {
+				err = s.Verify(checkName, body, signature, keyID)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, 0, m.sessions())
+	assert.False(t, m.loggedIn)
+	assert.True(t, m.finalized)
+	assert.True(t, m.destroyed)
+
+	_, _, err = s.Sign(checkName, body)
+	assert.Equal(t, errClosed, err)
+
+	// closing the signer again does nothing.
+	assert.NoError(t, s.Close())
+}
+
+func TestSigningAfterTokenReset(t *testing.T) {
+	m, _ := newTestModule(t)
+
+	s, err := NewSigner(Config{TokenLabel: tokenLabel, PIN: pin, Sessions: 2}, map[string]Key{
+		checkName: {Label: "p256", Algo: AlgoSHA256},
+	}, WithModule(m))
+	require.NoError(t, err)
+	defer s.Close()
+
+	// the token closes the signer's sessions and forgets its login.
+	m.reset()
+
+	// each session is reopened, and logged in again, as it is used.
+	for i := 0; i < 4; i++ {
+		signature, keyID, err := s.Sign(checkName, body)
+		require.NoError(t, err)
+		assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+	}
+
+	assert.Equal(t, 2, m.sessions())
+	assert.True(t, m.loggedIn)
+
+	// sessions which can't be reopened are kept, to be reopened later.
+	m.reset()
+	m.pin = "4321"
+
+	_, _, err = s.Sign(checkName, body)
+	assert.Error(t, err)
+	assert.Len(t, s.sessions, 2)
+
+	m.pin = pin
+
+	_, _, err = s.Sign(checkName, body)
+	assert.NoError(t, err)
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	m, _ := newTestModule(t)
+	m.addKey("duplicate", m.objects[0].key)
+	m.addKey("duplicate", m.objects[0].key)
+
+	cases := []struct {
+		name   string
+		config Config
+		key    Key
+		err    string
+	}{
+		{
+			name:   "unsupported algorithm",
+			config: Config{TokenLabel: tokenLabel, PIN: pin},
+			key:    Key{Label: "rsa", Algo: "MD5"},
+			err:    "unsupported digest algorithm MD5 for check diy",
+		},
+		{
+			name:   "unsupported padding",
+			config: Config{TokenLabel: tokenLabel, PIN: pin},
+			key:    Key{Label: "rsa", Algo: AlgoSHA256, Padding: "OAEP"},
+			err:    "unsupported padding OAEP for check diy",
+		},
+		{
+			name:   "missing token",
+			config: Config{TokenLabel: "missing", PIN: pin},
+			key:    Key{Label: "rsa", Algo: AlgoSHA256},
+			err:    `no PKCS#11 token with label "missing"`,
+		},
+		{
+			name:   "wrong PIN",
+			config: Config{TokenLabel: tokenLabel, PIN: "0000"},
+			key:    Key{Label: "rsa", Algo: AlgoSHA256},
+			err:    "failed to log in to PKCS#11 token: pkcs11: 0xA0: CKR_PIN_INCORRECT",
+		},
+		{
+			name:   "missing key",
+			config: Config{TokenLabel: tokenLabel, PIN: pin},
+			key:    Key{Label: "missing", Algo: AlgoSHA256},
+			err:    `failed to find key for diy: no key with label "missing"`,
+		},
+		{
+			name:   "duplicate key",
+			config: Config{TokenLabel: tokenLabel, PIN: pin},
+			key:    Key{Label: "duplicate", Algo: AlgoSHA256},
+			err:    `failed to find key for diy: more than one key with label "duplicate"`,
+		},
+		{
+			name:   "PSS with EC key",
+			config: Config{TokenLabel: tokenLabel, PIN: pin},
+			key:    Key{Label: "p256", Algo: AlgoSHA256, Padding: PaddingPSS},
+			err:    "failed to find key for diy: padding PSS is not supported by EC key p256",
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewSigner(testCase.config, map[string]Key{checkName: testCase.key}, WithModule(m))
+			assert.EqualError(t, err, testCase.err)
+
+			// sessions opened before the error are closed.
+			assert.Equal(t, 0, m.sessions())
+			assert.False(t, m.loggedIn)
+		})
+	}
+}
+
+func TestECDSASignatureToASN1(t *testing.T) {
+	_, err := ecdsaSignatureToASN1([]byte{1, 2, 3})
+	assert.Error(t, err)
+
+	_, err = ecdsaSignatureToASN1(nil)
+	assert.Error(t, err)
+}
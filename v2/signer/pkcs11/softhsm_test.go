@@ -0,0 +1,123 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"encoding/asn1"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// softHSMModuleEnv is the environment variable holding the path to the
+// SoftHSM2 module, such as "/usr/lib/softhsm/libsofthsm2.so". The SoftHSM2
+// tests are skipped if it's not set.
+const softHSMModuleEnv = "VOUCHER_PKCS11_MODULE"
+
+// newSoftHSMToken creates a SoftHSM2 token in a temporary directory, with an
+// RSA key labelled "rsa" and an EC P-256 key labelled "p256", and returns the
+// path to the module.
+func newSoftHSMToken(t *testing.T) string {
+	t.Helper()
+
+	module := os.Getenv(softHSMModuleEnv)
+	if "" == module {
+		t.Skip(softHSMModuleEnv + " is not set")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "tokens"), 0700))
+
+	conf := filepath.Join(dir, "softhsm2.conf")
+	require.NoError(t, os.WriteFile(conf, []byte("directories.tokendir = "+filepath.Join(dir, "tokens")+"\nobjectstore.backend = file\n"), 0600))
+	t.Setenv("SOFTHSM2_CONF", conf)
+
+	ctx := pkcs11.New(module)
+	require.NotNil(t, ctx, "failed to load %s", module)
+	require.NoError(t, ctx.Initialize())
+	defer ctx.Destroy()
+	defer ctx.Finalize()
+
+	slots, err := ctx.GetSlotList(false)
+	require.NoError(t, err)
+	require.NotEmpty(t, slots)
+	require.NoError(t, ctx.InitToken(slots[0], "so-"+pin, tokenLabel))
+
+	// SoftHSM2 moves initialized tokens to new slots.
+	slot, err := findSlot(ctx, tokenLabel)
+	require.NoError(t, err)
+
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	require.NoError(t, err)
+	defer ctx.CloseSession(session)
+
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_SO, "so-"+pin))
+	require.NoError(t, ctx.InitPIN(session, pin))
+	require.NoError(t, ctx.Logout(session))
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_USER, pin))
+	defer ctx.Logout(session)
+
+	private := func(label string) []*pkcs11.Attribute {
+		return []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		}
+	}
+
+	_, _, err = ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, 2048),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, "rsa"),
+		},
+		private("rsa"),
+	)
+	require.NoError(t, err)
+
+	p256, err := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	require.NoError(t, err)
+
+	_, _, err = ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, p256),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, "p256"),
+		},
+		private("p256"),
+	)
+	require.NoError(t, err)
+
+	return module
+}
+
+func TestSoftHSM(t *testing.T) {
+	module := newSoftHSMToken(t)
+
+	s, err := NewSigner(Config{Module: module, TokenLabel: tokenLabel, PIN: pin}, map[string]Key{
+		"ecdsa":  {Label: "p256", Algo: AlgoSHA256},
+		"pkcs1":  {Label: "rsa", Algo: AlgoSHA384},
+		"pss":    {Label: "rsa", Algo: AlgoSHA512, Padding: PaddingPSS},
+		"pss256": {Label: "rsa", Algo: AlgoSHA256, Padding: PaddingPSS},
+	})
+	require.NoError(t, err)
+
+	for _, checkName := range []string{"ecdsa", "pkcs1", "pss", "pss256"} {
+		signature, keyID, err := s.Sign(checkName, body)
+		require.NoError(t, err, checkName)
+		assert.NoError(t, s.Verify(checkName, body, signature, keyID), checkName)
+	}
+
+	assert.NoError(t, s.Close())
+}
@@ -1,7 +1,18 @@
 package signer
 
+// AttestationSigner signs the attestations of checks, with the key configured
+// for each check.
 type AttestationSigner interface {
 	// Sign finds the key for a given check, signs the body and returns the signature and the key identifier
 	Sign(checkName, body string) (string, string, error)
 	Close() error
 }
+
+// AttestationVerifier verifies the signatures of attestations, against the key
+// configured for each check.
+type AttestationVerifier interface {
+	// Verify finds the key for a given check and returns an error if the signature was not created
+	// by that key over the body, or if the key identifier does not belong to that key
+	Verify(checkName, body, signature, keyID string) error
+	Close() error
+}
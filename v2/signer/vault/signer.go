@@ -0,0 +1,486 @@
+package vault
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/grafeas/voucher/v2/signer"
+)
+
+const (
+	AlgoSHA256 = "SHA256"
+	AlgoSHA384 = "SHA384"
+	AlgoSHA512 = "SHA512"
+
+	PaddingPKCS1v15 = "PKCS1v15"
+	PaddingPSS      = "PSS"
+)
+
+const (
+	AuthToken      = "token"
+	AuthAppRole    = "approle"
+	AuthKubernetes = "kubernetes"
+)
+
+// DefaultMount is the path that the Transit secrets engine is mounted at if
+// no mount is configured.
+const DefaultMount = "transit"
+
+// DefaultKubernetesTokenPath is the path that the service account token is
+// read from when logging in with Kubernetes auth, if no path is configured.
+const DefaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// reloginInterval is how long the Signer waits before logging in again, after
+// a login fails.
+var reloginInterval = 10 * time.Second
+
+// errClosed is the error returned when the Signer is closed while logging in.
+var errClosed = errors.New("vault signer is closed")
+
+// hashAlgorithms maps digest algorithms to Transit hash algorithms.
+var hashAlgorithms = map[string]string{
+	AlgoSHA256: "sha2-256",
+	AlgoSHA384: "sha2-384",
+	AlgoSHA512: "sha2-512",
+}
+
+// signatureAlgorithms maps RSA paddings to Transit signature algorithms.
+var signatureAlgorithms = map[string]string{
+	PaddingPKCS1v15: "pkcs1v15",
+	PaddingPSS:      "pss",
+}
+
+// Key is a Transit key, which signs the attestations of a check.
+type Key struct {
+	// Name is the name of the Transit key.
+	Name string
+
+	// Algo is the digest algorithm (SHA256, SHA384 or SHA512). Defaults to
+	// the Transit default for the key's type.
+	Algo string
+
+	// Padding is the signature padding of RSA keys (PKCS1v15 or PSS).
+	// Defaults to the Transit default, which is PSS.
+	Padding string
+}
+
+// Auth describes how the Signer logs in to Vault.
+type Auth struct {
+	// Method is the auth method (token, approle or kubernetes).
+	Method string
+
+	// Mount is the path the auth method is mounted at. Defaults to the
+	// method's name.
+	Mount string
+
+	// Token is the token used by the token method. Defaults to the token in
+	// the VAULT_TOKEN environment variable.
+	Token string
+
+	// RoleID and SecretID are the credentials used by the approle method.
+	RoleID   string
+	SecretID string
+
+	// Role is the role used by the kubernetes method.
+	Role string
+
+	// JWTPath is the path to the service account token used by the
+	// kubernetes method. Defaults to DefaultKubernetesTokenPath.
+	JWTPath string
+}
+
+// Config describes the Vault server and Transit mount that keys are in.
+type Config struct {
+	// Address is the address of the Vault server. Defaults to the address in
+	// the VAULT_ADDR environment variable.
+	Address string
+
+	// Namespace is the Vault Enterprise namespace that the Transit mount is
+	// in.
+	Namespace string
+
+	// Mount is the path the Transit secrets engine is mounted at. Defaults to
+	// DefaultMount.
+	Mount string
+
+	// Auth describes how to log in to Vault.
+	Auth Auth
+}
+
+// Signer is an AttestationSigner which signs attestations with keys in
+// Vault's Transit secrets engine. The Signer renews its token until it's
+// closed, and logs in again if the token can no longer be renewed.
+type Signer struct {
+	client *api.Client
+	mount  string
+	keys   map[string]Key
+	auth   Auth
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSigner creates a new Signer, which logs in to the Vault server
+// described in the passed Config, and signs with the passed keys, by check
+// name.
+func NewSigner(config Config, keys map[string]Key) (*Signer, error) {
+	for checkName, key := range keys {
+		if _, ok := hashAlgorithms[key.Algo]; !ok && "" != key.Algo {
+			return nil, fmt.Errorf("unsupported digest algorithm %v for check %v", key.Algo, checkName)
+		}
+
+		if _, ok := signatureAlgorithms[key.Padding]; !ok && "" != key.Padding {
+			return nil, fmt.Errorf("unsupported padding %v for check %v", key.Padding, checkName)
+		}
+	}
+
+	clientConfig := api.DefaultConfig()
+	if "" != config.Address {
+		clientConfig.Address = config.Address
+	}
+
+	client, err := api.NewClient(clientConfig)
+	if nil != err {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+
+	if "" != config.Namespace {
+		client.SetNamespace(config.Namespace)
+	}
+
+	s := &Signer{
+		client: client,
+		mount:  strings.Trim(config.Mount, "/"),
+		keys:   keys,
+		auth:   config.Auth,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if "" == s.mount {
+		s.mount = DefaultMount
+	}
+
+	if "" == s.auth.Method {
+		s.auth.Method = AuthToken
+	}
+
+	if "" == s.auth.Mount {
+		s.auth.Mount = s.auth.Method
+	}
+
+	secret, err := s.login()
+	if nil != err {
+		return nil, err
+	}
+
+	go s.renew(secret)
+
+	return s, nil
+}
+
+// login logs in to Vault with the configured auth method, and returns the
+// secret holding the token. Tokens passed to the token method are looked up,
+// to find if they're renewable.
+func (s *Signer) login() (*api.Secret, error) {
+	var data map[string]interface{}
+
+	switch s.auth.Method {
+	case AuthToken:
+		if "" != s.auth.Token {
+			s.client.SetToken(s.auth.Token)
+		}
+
+		lookup, err := s.client.Auth().Token().LookupSelf()
+		if nil != err {
+			return nil, fmt.Errorf("failed to look up vault token: %w", err)
+		}
+
+		renewable, _ := lookup.TokenIsRenewable()
+		ttl, _ := lookup.TokenTTL()
+
+		return &api.Secret{
+			Auth: &api.SecretAuth{
+				ClientToken:   s.client.Token(),
+				Renewable:     renewable,
+				LeaseDuration: int(ttl.Seconds()),
+			},
+		}, nil
+	case AuthAppRole:
+		data = map[string]interface{}{
+			"role_id":   s.auth.RoleID,
+			"secret_id": s.auth.SecretID,
+		}
+	case AuthKubernetes:
+		path := s.auth.JWTPath
+		if "" == path {
+			path = DefaultKubernetesTokenPath
+		}
+
+		jwt, err := os.ReadFile(path)
+		if nil != err {
+			return nil, fmt.Errorf("failed to read kubernetes service account token: %w", err)
+		}
+
+		data = map[string]interface{}{
+			"role": s.auth.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported vault auth method %s", s.auth.Method)
+	}
+
+	// log in without the previous token, which may have expired.
+	client, err := s.client.Clone()
+	if nil != err {
+		return nil, err
+	}
+	client.SetHeaders(s.client.Headers())
+	client.ClearToken()
+
+	secret, err := client.Logical().Write("auth/"+strings.Trim(s.auth.Mount, "/")+"/login", data)
+	if nil != err {
+		return nil, fmt.Errorf("failed to log in to vault: %w", err)
+	}
+
+	if nil == secret || nil == secret.Auth {
+		return nil, errors.New("failed to log in to vault: no token returned")
+	}
+
+	s.client.SetToken(secret.Auth.ClientToken)
+
+	return secret, nil
+}
+
+// renew keeps the token in the passed secret renewed until the Signer is
+// closed. Once the token can no longer be renewed, or is close to expiring if
+// it's not renewable, the Signer logs in again, and renews the new token.
+// Tokens passed to the token method are used until they expire, as the
+// Signer cannot log in again.
+func (s *Signer) renew(secret *api.Secret) {
+	defer close(s.done)
+
+	for {
+		if secret.Auth.Renewable {
+			renewer, err := s.client.NewRenewer(&api.RenewerInput{Secret: secret})
+			if nil != err {
+				log.Errorf("failed to renew vault token: %s", err)
+				return
+			}
+
+			go renewer.Renew()
+			if !s.watch(renewer) {
+				return
+			}
+		} else if !s.waitForExpiry(secret) {
+			return
+		}
+
+		if AuthToken == s.auth.Method {
+			log.Warning("vault token can no longer be renewed, attestations will fail once it expires")
+			<-s.stop
+			return
+		}
+
+		var err error
+		if secret, err = s.relogin(); nil != err {
+			return
+		}
+	}
+}
+
+// waitForExpiry waits until the token in the passed secret, which cannot be
+// renewed, is two thirds of the way through its TTL, and returns true, or
+// returns false if the Signer is closed first. Tokens without a TTL never
+// expire, so this waits for the Signer to be closed.
+func (s *Signer) waitForExpiry(secret *api.Secret) bool {
+	if 0 == secret.Auth.LeaseDuration {
+		<-s.stop
+		return false
+	}
+
+	select {
+	case <-s.stop:
+		return false
+	case <-time.After(time.Duration(secret.Auth.LeaseDuration) * time.Second * 2 / 3):
+		return true
+	}
+}
+
+// watch waits for the renewer to stop renewing the token, and returns true,
+// or returns false if the Signer is closed first.
+func (s *Signer) watch(renewer *api.Renewer) bool {
+	defer renewer.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return false
+		case <-renewer.RenewCh():
+			log.Debug("renewed vault token")
+		case err := <-renewer.DoneCh():
+			if nil != err {
+				log.Warningf("failed to renew vault token: %s", err)
+			}
+			return true
+		}
+	}
+}
+
+// relogin logs in to Vault again, retrying until it succeeds or the Signer is
+// closed.
+func (s *Signer) relogin() (*api.Secret, error) {
+	for {
+		secret, err := s.login()
+		if nil == err {
+			return secret, nil
+		}
+
+		log.Errorf("%s, retrying in %s", err, reloginInterval)
+
+		select {
+		case <-s.stop:
+			return nil, errClosed
+		case <-time.After(reloginInterval):
+		}
+	}
+}
+
+// Sign signs the body with the Transit key for the check with the passed
+// name, and returns the signature and the ID of the key version that signed
+// it.
+func (s *Signer) Sign(checkName, body string) (string, string, error) {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return "", "", signer.ErrNoKeyForCheck
+	}
+
+	data := map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString([]byte(body)),
+	}
+	if "" != key.Padding {
+		data["signature_algorithm"] = signatureAlgorithms[key.Padding]
+	}
+
+	secret, err := s.client.Logical().Write(s.path("sign", key), data)
+	if nil != err {
+		return "", "", fmt.Errorf("failed to sign with vault key %s: %w", key.Name, err)
+	}
+
+	if nil == secret || nil == secret.Data {
+		return "", "", fmt.Errorf("failed to sign with vault key %s: no signature returned", key.Name)
+	}
+
+	encoded, _ := secret.Data["signature"].(string)
+	version, signature, err := parseSignature(encoded)
+	if nil != err {
+		return "", "", fmt.Errorf("failed to sign with vault key %s: %w", key.Name, err)
+	}
+
+	return string(signature), s.keyID(key, version), nil
+}
+
+// Verify verifies that the passed signature was created over the body by the
+// version of the check's Transit key with the passed ID.
+func (s *Signer) Verify(checkName, body, signature, keyID string) error {
+	key, ok := s.keys[checkName]
+	if !ok {
+		return signer.ErrNoKeyForCheck
+	}
+
+	prefix := s.keyID(key, "")
+	if !strings.HasPrefix(keyID, prefix) {
+		return signer.ErrKeyMismatch
+	}
+
+	version := strings.TrimPrefix(keyID, prefix)
+	if _, err := strconv.Atoi(version); nil != err {
+		return signer.ErrKeyMismatch
+	}
+
+	data := map[string]interface{}{
+		"input":     base64.StdEncoding.EncodeToString([]byte(body)),
+		"signature": "vault:v" + version + ":" + base64.StdEncoding.EncodeToString([]byte(signature)),
+	}
+	if "" != key.Padding {
+		data["signature_algorithm"] = signatureAlgorithms[key.Padding]
+	}
+
+	secret, err := s.client.Logical().Write(s.path("verify", key), data)
+	if nil != err {
+		return fmt.Errorf("failed to verify with vault key %s: %w", key.Name, err)
+	}
+
+	if nil == secret || nil == secret.Data {
+		return signer.ErrInvalidSignature
+	}
+
+	if valid, _ := secret.Data["valid"].(bool); !valid {
+		return signer.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Close stops renewing the Signer's token, and revokes it if the Signer
+// logged in to get it.
+func (s *Signer) Close() error {
+	var err error
+
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+
+		if AuthToken != s.auth.Method {
+			err = s.client.Auth().Token().RevokeSelf("")
+		}
+	})
+
+	return err
+}
+
+// path returns the Transit path of the passed operation with the passed key.
+func (s *Signer) path(operation string, key Key) string {
+	path := s.mount + "/" + operation + "/" + key.Name
+	if "" != key.Algo {
+		path += "/" + hashAlgorithms[key.Algo]
+	}
+	return path
+}
+
+// keyID returns the ID of the passed version of the passed key, such as
+// "vault://transit/keys/diy/versions/1".
+func (s *Signer) keyID(key Key, version string) string {
+	return "vault://" + s.mount + "/keys/" + key.Name + "/versions/" + version
+}
+
+// parseSignature parses a Transit signature, such as "vault:v1:MEUCIQ...",
+// and returns the version of the key that created it, and the signature.
+func parseSignature(encoded string) (string, []byte, error) {
+	parts := strings.SplitN(encoded, ":", 3)
+	if 3 != len(parts) || "vault" != parts[0] || !strings.HasPrefix(parts[1], "v") {
+		return "", nil, fmt.Errorf("invalid signature %q", encoded)
+	}
+
+	version := strings.TrimPrefix(parts[1], "v")
+	if _, err := strconv.Atoi(version); nil != err {
+		return "", nil, fmt.Errorf("invalid signature %q", encoded)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(parts[2])
+	if nil != err {
+		return "", nil, fmt.Errorf("invalid signature %q", encoded)
+	}
+
+	return version, signature, nil
+}
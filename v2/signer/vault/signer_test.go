@@ -0,0 +1,463 @@
+package vault
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vsigner "github.com/grafeas/voucher/v2/signer"
+	localsigner "github.com/grafeas/voucher/v2/signer/local"
+)
+
+const (
+	checkName = "diy"
+	body      = `{"critical":{"identity":{"docker-reference":"gcr.io/voucher/app"}}}`
+	rootToken = "root-token"
+)
+
+// fakeVault is a Vault server with the Transit secrets engine mounted at
+// "transit", and the approle and kubernetes auth methods enabled.
+type fakeVault struct {
+	sync.Mutex
+	t *testing.T
+
+	// keys holds the versions of each Transit key, oldest first.
+	keys map[string][]crypto.Signer
+
+	// tokens holds the tokens that are valid.
+	tokens map[string]bool
+
+	// unrenewable is the number of renewals which fail, before renewals
+	// succeed.
+	unrenewable int
+
+	// loginTTL is the TTL of tokens issued by logins, which aren't renewable
+	// if it's set.
+	loginTTL int
+
+	logins   int
+	renewals int
+	revoked  []string
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	t.Helper()
+
+	v := &fakeVault{
+		t:      t,
+		keys:   make(map[string][]crypto.Signer),
+		tokens: map[string]bool{rootToken: true},
+	}
+	v.rotate("ecdsa", "p256")
+	v.rotate("rsa", "rsa")
+
+	server := httptest.NewServer(v)
+	t.Cleanup(server.Close)
+
+	return v, server
+}
+
+// rotate adds a new version of the key with the passed name.
+func (v *fakeVault) rotate(name, keyType string) {
+	v.Lock()
+	defer v.Unlock()
+
+	var key crypto.Signer
+	var err error
+	if "rsa" == keyType {
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	} else {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	require.NoError(v.t, err)
+
+	v.keys[name] = append(v.keys[name], key)
+}
+
+// publicKey returns the public half of the passed version of the key with
+// the passed name.
+func (v *fakeVault) publicKey(name string, version int) crypto.PublicKey {
+	v.Lock()
+	defer v.Unlock()
+
+	return v.keys[name][version-1].Public()
+}
+
+// counts returns the number of logins and renewals.
+func (v *fakeVault) counts() (int, int) {
+	v.Lock()
+	defer v.Unlock()
+
+	return v.logins, v.renewals
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.Lock()
+	defer v.Unlock()
+
+	var req map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&req); nil != err && io.EOF != err {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	token := r.Header.Get("X-Vault-Token")
+
+	switch path {
+	case "auth/approle/login":
+		if "role" != req["role_id"] || "secret" != req["secret_id"] {
+			writeError(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		v.login(w)
+		return
+	case "auth/kubernetes/login":
+		if "voucher" != req["role"] || "service-account-jwt" != req["jwt"] {
+			writeError(w, http.StatusForbidden, "permission denied")
+			return
+		}
+		v.login(w)
+		return
+	}
+
+	if !v.tokens[token] {
+		writeError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch {
+	case "auth/token/lookup-self" == path:
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"renewable": false, "ttl": 0}})
+	case "auth/token/renew-self" == path:
+		v.renewals++
+		renewable := v.renewals > v.unrenewable
+		writeJSON(w, map[string]interface{}{"auth": map[string]interface{}{"client_token": token, "renewable": renewable, "lease_duration": 3600}})
+	case "auth/token/revoke-self" == path:
+		delete(v.tokens, token)
+		v.revoked = append(v.revoked, token)
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(path, "transit/sign/"):
+		v.sign(w, strings.TrimPrefix(path, "transit/sign/"), req)
+	case strings.HasPrefix(path, "transit/verify/"):
+		v.verify(w, strings.TrimPrefix(path, "transit/verify/"), req)
+	default:
+		writeError(w, http.StatusNotFound, "no handler for route")
+	}
+}
+
+// login issues a new renewable token.
+func (v *fakeVault) login(w http.ResponseWriter) {
+	v.logins++
+	token := "token-" + strconv.Itoa(v.logins)
+	v.tokens[token] = true
+
+	if 0 != v.loginTTL {
+		writeJSON(w, map[string]interface{}{"auth": map[string]interface{}{"client_token": token, "renewable": false, "lease_duration": v.loginTTL}})
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"auth": map[string]interface{}{"client_token": token, "renewable": true, "lease_duration": 3600}})
+}
+
+// digest returns the hash and digest of the request's input, for the key
+// name and hash algorithm in the passed path.
+func (v *fakeVault) digest(path string, req map[string]interface{}) (string, crypto.Hash, []byte) {
+	name, hashAlgorithm, _ := strings.Cut(path, "/")
+
+	input, err := base64.StdEncoding.DecodeString(req["input"].(string))
+	require.NoError(v.t, err)
+
+	switch hashAlgorithm {
+	case "", "sha2-256":
+		d := sha256.Sum256(input)
+		return name, crypto.SHA256, d[:]
+	case "sha2-384":
+		d := sha512.Sum384(input)
+		return name, crypto.SHA384, d[:]
+	}
+
+	d := sha512.Sum512(input)
+	return name, crypto.SHA512, d[:]
+}
+
+func (v *fakeVault) sign(w http.ResponseWriter, path string, req map[string]interface{}) {
+	name, hashAlgo, digested := v.digest(path, req)
+
+	versions := v.keys[name]
+	if 0 == len(versions) {
+		writeError(w, http.StatusBadRequest, "encryption key not found")
+		return
+	}
+
+	key := versions[len(versions)-1]
+	var opts crypto.SignerOpts = hashAlgo
+	if _, ok := key.(*rsa.PrivateKey); ok && "pkcs1v15" != req["signature_algorithm"] {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: hashAlgo}
+	}
+
+	signature, err := key.Sign(rand.Reader, digested, opts)
+	require.NoError(v.t, err)
+
+	writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+		"signature": fmt.Sprintf("vault:v%d:%s", len(versions), base64.StdEncoding.EncodeToString(signature)),
+	}})
+}
+
+func (v *fakeVault) verify(w http.ResponseWriter, path string, req map[string]interface{}) {
+	name, hashAlgo, digested := v.digest(path, req)
+
+	parts := strings.SplitN(req["signature"].(string), ":", 3)
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+	if nil != err || 0 == version || len(v.keys[name]) < version {
+		writeError(w, http.StatusBadRequest, "invalid key version")
+		return
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(parts[2])
+	require.NoError(v.t, err)
+
+	valid := false
+	switch pub := v.keys[name][version-1].Public().(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(pub, digested, signature)
+	case *rsa.PublicKey:
+		if "pkcs1v15" == req["signature_algorithm"] {
+			valid = nil == rsa.VerifyPKCS1v15(pub, hashAlgo, digested, signature)
+		} else {
+			valid = nil == rsa.VerifyPSS(pub, hashAlgo, digested, signature, nil)
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"valid": valid}})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{message}})
+}
+
+func TestSignAndVerify(t *testing.T) {
+	v, server := newFakeVault(t)
+
+	s, err := NewSigner(Config{
+		Address: server.URL,
+		Auth:    Auth{Token: rootToken},
+	}, map[string]Key{
+		checkName: {Name: "ecdsa", Algo: AlgoSHA256},
+		"pkcs1":   {Name: "rsa", Padding: PaddingPKCS1v15},
+		"pss":     {Name: "rsa", Algo: AlgoSHA512, Padding: PaddingPSS},
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	signature, keyID, err := s.Sign(checkName, body)
+	require.NoError(t, err)
+	assert.Equal(t, "vault://transit/keys/ecdsa/versions/1", keyID)
+
+	assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+	assert.Equal(t, vsigner.ErrInvalidSignature, s.Verify(checkName, body+" ", signature, keyID))
+	assert.Equal(t, vsigner.ErrKeyMismatch, s.Verify(checkName, body, signature, "vault://transit/keys/rsa/versions/1"))
+	assert.Equal(t, vsigner.ErrKeyMismatch, s.Verify(checkName, body, signature, "vault://transit/keys/ecdsa/versions/latest"))
+	assert.Equal(t, vsigner.ErrNoKeyForCheck, s.Verify("nobody", body, signature, keyID))
+
+	_, _, err = s.Sign("nobody", body)
+	assert.Equal(t, vsigner.ErrNoKeyForCheck, err)
+
+	// Transit signatures are PKIX signatures, which can be verified with the
+	// public key alone.
+	assert.NoError(t, localsigner.VerifySignature(v.publicKey("ecdsa", 1), []byte(body), []byte(signature)))
+
+	// once the key is rotated, the new version signs attestations, and
+	// attestations signed by the old version can still be verified.
+	v.rotate("ecdsa", "p256")
+
+	rotatedSignature, rotatedKeyID, err := s.Sign(checkName, body)
+	require.NoError(t, err)
+	assert.Equal(t, "vault://transit/keys/ecdsa/versions/2", rotatedKeyID)
+	assert.NoError(t, s.Verify(checkName, body, rotatedSignature, rotatedKeyID))
+	assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+	assert.Equal(t, vsigner.ErrInvalidSignature, s.Verify(checkName, body, signature, rotatedKeyID))
+
+	for _, checkName := range []string{"pkcs1", "pss"} {
+		signature, keyID, err := s.Sign(checkName, body)
+		require.NoError(t, err)
+		assert.Equal(t, "vault://transit/keys/rsa/versions/1", keyID)
+		assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+	}
+
+	signature, _, err = s.Sign("pkcs1", body)
+	require.NoError(t, err)
+	assert.NoError(t, localsigner.VerifySignature(v.publicKey("rsa", 1), []byte(body), []byte(signature)))
+
+	// tokens passed to the signer are not revoked.
+	require.NoError(t, s.Close())
+	assert.Empty(t, v.revoked)
+}
+
+func TestAppRoleRenewal(t *testing.T) {
+	v, server := newFakeVault(t)
+	v.unrenewable = 1
+
+	reloginInterval = time.Millisecond
+	defer func() { reloginInterval = 10 * time.Second }()
+
+	s, err := NewSigner(Config{
+		Address: server.URL,
+		Auth:    Auth{Method: AuthAppRole, RoleID: "role", SecretID: "secret"},
+	}, map[string]Key{checkName: {Name: "ecdsa"}})
+	require.NoError(t, err)
+
+	// the first token can't be renewed, so the signer logs in again, and
+	// renews the second token.
+	assert.Eventually(t, func() bool {
+		logins, renewals := v.counts()
+		return 2 == logins && 2 == renewals
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "token-2", s.client.Token())
+
+	signature, keyID, err := s.Sign(checkName, body)
+	require.NoError(t, err)
+	assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, []string{"token-2"}, v.revoked)
+
+	// closing the signer again does nothing.
+	assert.NoError(t, s.Close())
+}
+
+func TestUnrenewableLogin(t *testing.T) {
+	v, server := newFakeVault(t)
+	v.loginTTL = 1
+
+	s, err := NewSigner(Config{
+		Address: server.URL,
+		Auth:    Auth{Method: AuthAppRole, RoleID: "role", SecretID: "secret"},
+	}, map[string]Key{checkName: {Name: "ecdsa"}})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// tokens which can't be renewed are replaced before they expire.
+	assert.Eventually(t, func() bool {
+		logins, renewals := v.counts()
+		return 2 <= logins && 0 == renewals
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, _, err = s.Sign(checkName, body)
+	assert.NoError(t, err)
+}
+
+func TestKubernetesLogin(t *testing.T) {
+	v, server := newFakeVault(t)
+
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwtPath, []byte("service-account-jwt\n"), 0600))
+
+	s, err := NewSigner(Config{
+		Address: server.URL,
+		Auth:    Auth{Method: AuthKubernetes, Role: "voucher", JWTPath: jwtPath},
+	}, map[string]Key{checkName: {Name: "ecdsa"}})
+	require.NoError(t, err)
+
+	signature, keyID, err := s.Sign(checkName, body)
+	require.NoError(t, err)
+	assert.NoError(t, s.Verify(checkName, body, signature, keyID))
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, []string{"token-1"}, v.revoked)
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	cases := []struct {
+		name   string
+		config Config
+		key    Key
+		err    string
+	}{
+		{
+			name:   "unsupported algorithm",
+			config: Config{Address: server.URL, Auth: Auth{Token: rootToken}},
+			key:    Key{Name: "ecdsa", Algo: "MD5"},
+			err:    "unsupported digest algorithm MD5 for check diy",
+		},
+		{
+			name:   "unsupported padding",
+			config: Config{Address: server.URL, Auth: Auth{Token: rootToken}},
+			key:    Key{Name: "rsa", Padding: "OAEP"},
+			err:    "unsupported padding OAEP for check diy",
+		},
+		{
+			name:   "unsupported auth method",
+			config: Config{Address: server.URL, Auth: Auth{Method: "userpass"}},
+			key:    Key{Name: "ecdsa"},
+			err:    "unsupported vault auth method userpass",
+		},
+		{
+			name:   "invalid token",
+			config: Config{Address: server.URL, Auth: Auth{Token: "invalid"}},
+			key:    Key{Name: "ecdsa"},
+			err:    "failed to look up vault token",
+		},
+		{
+			name:   "invalid secret ID",
+			config: Config{Address: server.URL, Auth: Auth{Method: AuthAppRole, RoleID: "role", SecretID: "invalid"}},
+			key:    Key{Name: "ecdsa"},
+			err:    "failed to log in to vault",
+		},
+		{
+			name:   "missing service account token",
+			config: Config{Address: server.URL, Auth: Auth{Method: AuthKubernetes, Role: "voucher", JWTPath: filepath.Join(t.TempDir(), "missing")}},
+			key:    Key{Name: "ecdsa"},
+			err:    "failed to read kubernetes service account token",
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewSigner(testCase.config, map[string]Key{checkName: testCase.key})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	version, signature, err := parseSignature("vault:v12:" + base64.StdEncoding.EncodeToString([]byte("signature")))
+	require.NoError(t, err)
+	assert.Equal(t, "12", version)
+	assert.Equal(t, []byte("signature"), signature)
+
+	for _, encoded := range []string{"", "vault:v1", "vault:latest:c2ln", "other:v1:c2ln", "vault:v1:!!!"} {
+		_, _, err = parseSignature(encoded)
+		assert.Error(t, err, encoded)
+	}
+}
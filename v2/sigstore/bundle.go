@@ -0,0 +1,226 @@
+package sigstore
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafeas/voucher/v2/attestation"
+)
+
+// BundleMediaTypePrefix is the prefix of the media types (and OCI artifact
+// types) of Sigstore bundles.
+const BundleMediaTypePrefix = "application/vnd.dev.sigstore.bundle"
+
+// ErrUnsupportedBundle is the error returned when a Sigstore bundle does not
+// hold a DSSE envelope.
+var ErrUnsupportedBundle = errors.New("only Sigstore bundles holding a DSSE envelope are supported")
+
+// int64String is an int64 which may be JSON encoded as a string, as the
+// protobuf JSON encoding used by Sigstore bundles does.
+type int64String int64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *int64String) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	if nil != err {
+		return err
+	}
+
+	*i = int64String(v)
+	return nil
+}
+
+// rawCertificate is a DER encoded certificate in a Sigstore bundle.
+type rawCertificate struct {
+	RawBytes []byte `json:"rawBytes"`
+}
+
+// bundle is the JSON format of a Sigstore bundle.
+type bundle struct {
+	MediaType            string `json:"mediaType"`
+	VerificationMaterial struct {
+		Certificate          *rawCertificate `json:"certificate"`
+		X509CertificateChain *struct {
+			Certificates []rawCertificate `json:"certificates"`
+		} `json:"x509CertificateChain"`
+		TlogEntries []struct {
+			LogIndex int64String `json:"logIndex"`
+			LogID    struct {
+				KeyID []byte `json:"keyId"`
+			} `json:"logId"`
+			IntegratedTime   int64String `json:"integratedTime"`
+			InclusionPromise *struct {
+				SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+			} `json:"inclusionPromise"`
+			InclusionProof *struct {
+				LogIndex   int64String `json:"logIndex"`
+				RootHash   []byte      `json:"rootHash"`
+				TreeSize   int64String `json:"treeSize"`
+				Hashes     [][]byte    `json:"hashes"`
+				Checkpoint struct {
+					Envelope string `json:"envelope"`
+				} `json:"checkpoint"`
+			} `json:"inclusionProof"`
+			CanonicalizedBody []byte `json:"canonicalizedBody"`
+		} `json:"tlogEntries"`
+	} `json:"verificationMaterial"`
+	DSSEEnvelope *struct {
+		PayloadType string `json:"payloadType"`
+		Payload     []byte `json:"payload"`
+		Signatures  []struct {
+			Sig []byte `json:"sig"`
+		} `json:"signatures"`
+	} `json:"dsseEnvelope"`
+}
+
+// ParseBundle parses a JSON encoded Sigstore bundle, returning a Signature
+// for each of the signatures on the DSSE envelope it holds.
+func ParseBundle(b []byte) ([]*Signature, error) {
+	var parsed bundle
+	if err := json.Unmarshal(b, &parsed); nil != err {
+		return nil, fmt.Errorf("parsing bundle: %w", err)
+	}
+
+	if !strings.HasPrefix(parsed.MediaType, BundleMediaTypePrefix) {
+		return nil, fmt.Errorf("not a Sigstore bundle: %q", parsed.MediaType)
+	}
+
+	if nil == parsed.DSSEEnvelope {
+		return nil, ErrUnsupportedBundle
+	}
+
+	material := parsed.VerificationMaterial
+
+	var chain []*x509.Certificate
+	switch {
+	case nil != material.Certificate:
+		cert, err := x509.ParseCertificate(material.Certificate.RawBytes)
+		if nil != err {
+			return nil, fmt.Errorf("parsing bundle certificate: %w", err)
+		}
+		chain = append(chain, cert)
+	case nil != material.X509CertificateChain:
+		for _, raw := range material.X509CertificateChain.Certificates {
+			cert, err := x509.ParseCertificate(raw.RawBytes)
+			if nil != err {
+				return nil, fmt.Errorf("parsing bundle certificate: %w", err)
+			}
+			chain = append(chain, cert)
+		}
+	}
+
+	var entry *TlogEntry
+	if 0 < len(material.TlogEntries) {
+		raw := material.TlogEntries[0]
+		entry = &TlogEntry{
+			LogIndex:       int64(raw.LogIndex),
+			LogID:          raw.LogID.KeyID,
+			IntegratedTime: int64(raw.IntegratedTime),
+			Body:           raw.CanonicalizedBody,
+		}
+
+		if nil != raw.InclusionPromise {
+			entry.SignedEntryTimestamp = raw.InclusionPromise.SignedEntryTimestamp
+		}
+
+		if nil != raw.InclusionProof {
+			entry.InclusionProof = &InclusionProof{
+				LogIndex:   int64(raw.InclusionProof.LogIndex),
+				TreeSize:   int64(raw.InclusionProof.TreeSize),
+				RootHash:   raw.InclusionProof.RootHash,
+				Hashes:     raw.InclusionProof.Hashes,
+				Checkpoint: raw.InclusionProof.Checkpoint.Envelope,
+			}
+		}
+	}
+
+	signatures := make([]*Signature, 0, len(parsed.DSSEEnvelope.Signatures))
+	for _, sig := range parsed.DSSEEnvelope.Signatures {
+		signature := &Signature{
+			Payload:     parsed.DSSEEnvelope.Payload,
+			PayloadType: parsed.DSSEEnvelope.PayloadType,
+			Signature:   sig.Sig,
+			TlogEntry:   entry,
+		}
+
+		if 0 < len(chain) {
+			signature.Certificate = chain[0]
+			signature.Chain = chain[1:]
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	return signatures, nil
+}
+
+// cosignBundle is the JSON format of the bundle that cosign stores in the
+// "dev.sigstore.cosign/bundle" annotation of signature layers. It holds a
+// transparency log entry and its signed entry timestamp.
+type cosignBundle struct {
+	SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+	Payload              struct {
+		Body           []byte `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogIndex       int64  `json:"logIndex"`
+		LogID          string `json:"logID"`
+	} `json:"Payload"`
+}
+
+// parseCosignBundle parses the JSON encoded bundle from a cosign signature
+// layer annotation.
+func parseCosignBundle(b []byte) (*TlogEntry, error) {
+	var parsed cosignBundle
+	if err := json.Unmarshal(b, &parsed); nil != err {
+		return nil, fmt.Errorf("parsing cosign bundle: %w", err)
+	}
+
+	logID, err := hex.DecodeString(parsed.Payload.LogID)
+	if nil != err {
+		return nil, fmt.Errorf("parsing cosign bundle log ID: %w", err)
+	}
+
+	return &TlogEntry{
+		LogIndex:             parsed.Payload.LogIndex,
+		LogID:                logID,
+		IntegratedTime:       parsed.Payload.IntegratedTime,
+		Body:                 parsed.Payload.Body,
+		SignedEntryTimestamp: parsed.SignedEntryTimestamp,
+	}, nil
+}
+
+// parseEnvelopeSignatures returns a Signature for each of the signatures on
+// the passed JSON encoded DSSE envelope.
+func parseEnvelopeSignatures(b []byte) ([]*Signature, error) {
+	envelope, err := attestation.ParseEnvelope(b)
+	if nil != err {
+		return nil, fmt.Errorf("parsing envelope: %w", err)
+	}
+
+	payload, err := envelope.DecodePayload()
+	if nil != err {
+		return nil, fmt.Errorf("decoding envelope payload: %w", err)
+	}
+
+	signatures := make([]*Signature, 0, len(envelope.Signatures))
+	for _, sig := range envelope.Signatures {
+		decoded, err := base64.StdEncoding.DecodeString(sig.Sig)
+		if nil != err {
+			return nil, fmt.Errorf("decoding envelope signature: %w", err)
+		}
+
+		signatures = append(signatures, &Signature{
+			Payload:     payload,
+			PayloadType: envelope.PayloadType,
+			Signature:   decoded,
+		})
+	}
+
+	return signatures, nil
+}
@@ -0,0 +1,209 @@
+package sigstore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/docker"
+)
+
+const (
+	// SimpleSigningMediaType is the media type of the layers holding the
+	// payloads signed by `cosign sign`.
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+	// DSSEMediaType is the media type of the layers holding the DSSE
+	// envelopes made by `cosign attest`.
+	DSSEMediaType = "application/vnd.dsse.envelope.v1+json"
+
+	// SignPredicateType is the predicate type of the in-toto statements that
+	// cosign signs when it stores image signatures as Sigstore bundles.
+	SignPredicateType = "https://sigstore.dev/cosign/sign/v1"
+
+	signatureAnnotation   = "dev.cosignproject.cosign/signature"
+	certificateAnnotation = "dev.sigstore.cosign/certificate"
+	chainAnnotation       = "dev.sigstore.cosign/chain"
+	bundleAnnotation      = "dev.sigstore.cosign/bundle"
+)
+
+// RequestSignatures requests the signatures of the passed image. Signatures
+// are read from the layers of the "sha256-<digest>.sig" tag that `cosign
+// sign` pushes, and from Sigstore bundles attached to the image as OCI
+// referrers.
+func RequestSignatures(client *http.Client, ref reference.Canonical) ([]*Signature, error) {
+	layers, err := docker.RequestLayers(client, ref, cosignTag(ref, "sig"))
+	if nil != err {
+		return nil, err
+	}
+
+	signatures := make([]*Signature, 0, len(layers))
+	for _, layer := range layers {
+		if SimpleSigningMediaType != layer.MediaType {
+			continue
+		}
+
+		payload, err := docker.RequestBlob(client, ref, layer.Digest)
+		if nil != err {
+			return nil, err
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[signatureAnnotation])
+		if nil != err {
+			return nil, fmt.Errorf("decoding signature: %w", err)
+		}
+
+		signature := &Signature{
+			Payload:   payload,
+			Signature: sig,
+		}
+
+		if err = addLayerMaterial(signature, layer); nil != err {
+			return nil, err
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	bundled, err := requestBundles(client, ref)
+	if nil != err {
+		return nil, err
+	}
+
+	for _, signature := range bundled {
+		if isSignStatement(signature) {
+			signatures = append(signatures, signature)
+		}
+	}
+
+	return signatures, nil
+}
+
+// RequestAttestations requests the in-toto attestations of the passed image.
+// Attestations are read from the layers of the "sha256-<digest>.att" tag
+// that `cosign attest` pushes, and from Sigstore bundles attached to the
+// image as OCI referrers.
+func RequestAttestations(client *http.Client, ref reference.Canonical) ([]*Signature, error) {
+	layers, err := docker.RequestLayers(client, ref, cosignTag(ref, "att"))
+	if nil != err {
+		return nil, err
+	}
+
+	attestations := make([]*Signature, 0, len(layers))
+	for _, layer := range layers {
+		if DSSEMediaType != layer.MediaType {
+			continue
+		}
+
+		envelope, err := docker.RequestBlob(client, ref, layer.Digest)
+		if nil != err {
+			return nil, err
+		}
+
+		signatures, err := parseEnvelopeSignatures(envelope)
+		if nil != err {
+			return nil, err
+		}
+
+		for _, signature := range signatures {
+			if err = addLayerMaterial(signature, layer); nil != err {
+				return nil, err
+			}
+		}
+
+		attestations = append(attestations, signatures...)
+	}
+
+	bundled, err := requestBundles(client, ref)
+	if nil != err {
+		return nil, err
+	}
+
+	for _, signature := range bundled {
+		if attestation.InTotoPayloadType == signature.PayloadType && !isSignStatement(signature) {
+			attestations = append(attestations, signature)
+		}
+	}
+
+	return attestations, nil
+}
+
+// requestBundles requests the Sigstore bundles attached to the passed image
+// as OCI referrers. Bundles which do not hold a DSSE envelope are skipped.
+func requestBundles(client *http.Client, ref reference.Canonical) ([]*Signature, error) {
+	referrers, err := docker.RequestReferrers(client, ref, "")
+	if nil != err {
+		return nil, err
+	}
+
+	signatures := make([]*Signature, 0, len(referrers))
+	for _, referrer := range referrers {
+		if !strings.HasPrefix(referrer.ArtifactType, BundleMediaTypePrefix) {
+			continue
+		}
+
+		b, err := docker.RequestArtifact(client, ref, referrer)
+		if nil != err {
+			return nil, err
+		}
+
+		bundled, err := ParseBundle(b)
+		if ErrUnsupportedBundle == err {
+			continue
+		}
+		if nil != err {
+			return nil, err
+		}
+
+		signatures = append(signatures, bundled...)
+	}
+
+	return signatures, nil
+}
+
+// addLayerMaterial adds the certificates and transparency log entry stored
+// in the annotations of a cosign layer to the passed Signature.
+func addLayerMaterial(signature *Signature, layer docker.Descriptor) error {
+	if cert := layer.Annotations[certificateAnnotation]; "" != cert {
+		certs, err := parseCertificates([]byte(cert))
+		if nil != err {
+			return err
+		}
+		signature.Certificate = certs[0]
+	}
+
+	if chain := layer.Annotations[chainAnnotation]; "" != chain {
+		certs, err := parseCertificates([]byte(chain))
+		if nil != err {
+			return err
+		}
+		signature.Chain = certs
+	}
+
+	if bundle := layer.Annotations[bundleAnnotation]; "" != bundle {
+		entry, err := parseCosignBundle([]byte(bundle))
+		if nil != err {
+			return err
+		}
+		signature.TlogEntry = entry
+	}
+
+	return nil
+}
+
+// isSignStatement returns true if the passed Signature is over the in-toto
+// statement that cosign signs in place of a simple signing payload.
+func isSignStatement(signature *Signature) bool {
+	statement, err := signature.Statement()
+	return nil == err && SignPredicateType == statement.PredicateType
+}
+
+// cosignTag returns the tag that cosign stores the passed image's artifacts
+// of the passed kind (eg. "sig" or "att") under.
+func cosignTag(ref reference.Canonical, kind string) string {
+	return strings.Replace(string(ref.Digest()), ":", "-", 1) + "." + kind
+}
@@ -0,0 +1,111 @@
+package sigstore
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// oidIssuer is the deprecated Fulcio extension holding the OIDC issuer as
+	// a raw string.
+	oidIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+
+	// oidIssuerV2 is the Fulcio extension holding the OIDC issuer as a DER
+	// encoded UTF8String.
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// ErrIdentityNoIssuer is the error returned when an Identity has no issuer.
+var ErrIdentityNoIssuer = errors.New("identity must have an issuer")
+
+// ErrIdentityNoSubject is the error returned when an Identity has neither a
+// subject nor a subject regular expression.
+var ErrIdentityNoSubject = errors.New("identity must have a subject or subject_regexp")
+
+// Identity is a signer identity which is trusted to sign images without a
+// key. Keyless signing certificates must be issued to a subject (an email
+// address or URI, such as a CI workflow) by the OIDC issuer of one of the
+// trusted Identities.
+type Identity struct {
+	Issuer        string `mapstructure:"issuer"`
+	Subject       string `mapstructure:"subject"`
+	SubjectRegexp string `mapstructure:"subject_regexp"`
+}
+
+// Validate returns an error if the Identity is incomplete, or if its
+// subject regular expression is invalid.
+func (id Identity) Validate() error {
+	if "" == id.Issuer {
+		return ErrIdentityNoIssuer
+	}
+
+	if "" == id.Subject && "" == id.SubjectRegexp {
+		return ErrIdentityNoSubject
+	}
+
+	if "" != id.SubjectRegexp {
+		if _, err := regexp.Compile(id.SubjectRegexp); nil != err {
+			return fmt.Errorf("invalid subject_regexp: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Matches returns true if the passed certificate was issued to this
+// Identity. Subject regular expressions must match the whole subject.
+func (id Identity) Matches(cert *x509.Certificate) bool {
+	if id.Issuer != certificateIssuer(cert) {
+		return false
+	}
+
+	for _, subject := range certificateSubjects(cert) {
+		if "" != id.Subject && subject == id.Subject {
+			return true
+		}
+
+		if "" != id.SubjectRegexp {
+			if matched, _ := regexp.MatchString("^(?:"+id.SubjectRegexp+")$", subject); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// certificateIssuer returns the OIDC issuer recorded in the passed Fulcio
+// certificate, or an empty string if there isn't one.
+func certificateIssuer(cert *x509.Certificate) string {
+	var deprecated string
+
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); nil == err {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuer):
+			deprecated = string(ext.Value)
+		}
+	}
+
+	return deprecated
+}
+
+// certificateSubjects returns the subjects (email addresses and URIs) in the
+// passed certificate's subject alternative names.
+func certificateSubjects(cert *x509.Certificate) []string {
+	subjects := make([]string, 0, len(cert.EmailAddresses)+len(cert.URIs))
+	subjects = append(subjects, cert.EmailAddresses...)
+
+	for _, uri := range cert.URIs {
+		subjects = append(subjects, uri.String())
+	}
+
+	return subjects
+}
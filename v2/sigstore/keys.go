@@ -0,0 +1,112 @@
+package sigstore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+)
+
+// ErrInvalidSignature is the error returned when a signature does not match
+// the signed message.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ParsePublicKey parses a PEM encoded public key (a "PUBLIC KEY" block, as
+// written by `cosign generate-key-pair`).
+func ParsePublicKey(b []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if nil == block {
+		return nil, errors.New("no PEM block found in public key")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if nil != err {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// LoadPublicKeys reads the PEM encoded public keys in the passed files.
+func LoadPublicKeys(paths ...string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if nil != err {
+			return nil, err
+		}
+
+		key, err := ParsePublicKey(b)
+		if nil != err {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// verifySignature verifies that the passed signature was made over the
+// passed message by the private key matching the passed public key. ECDSA
+// and RSA signatures are expected to be made over the SHA-256 digest of the
+// message (SHA-384 and SHA-512 for ECDSA keys on the P-384 and P-521 curves).
+func verifySignature(key crypto.PublicKey, message, sig []byte) error {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		h := ecdsaHash(k)
+		h.Write(message)
+		if !ecdsa.VerifyASN1(k, h.Sum(nil), sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(message)
+		if nil == rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) {
+			return nil
+		}
+		if nil == rsa.VerifyPSS(k, crypto.SHA256, sum[:], sig, nil) {
+			return nil
+		}
+		return ErrInvalidSignature
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, message, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// ecdsaHash returns the hash used with ECDSA keys on the passed key's curve.
+func ecdsaHash(key *ecdsa.PublicKey) hash.Hash {
+	switch key.Curve {
+	case elliptic.P384():
+		return sha512.New384()
+	case elliptic.P521():
+		return sha512.New()
+	default:
+		return sha256.New()
+	}
+}
+
+// equalKeys returns true if the passed public keys are the same.
+func equalKeys(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
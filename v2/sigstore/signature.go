@@ -0,0 +1,99 @@
+package sigstore
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/grafeas/voucher/v2/attestation"
+)
+
+// Signature is a signature over a payload, along with the material needed
+// to verify it. Signatures made with a key have no Certificate, while
+// keyless signatures carry the certificate they were made with.
+type Signature struct {
+	// Payload is the signed content. For signatures from DSSE envelopes,
+	// this is the envelope's decoded payload.
+	Payload []byte
+
+	// PayloadType is the DSSE payload type. It is empty for signatures that
+	// were made directly over the payload.
+	PayloadType string
+
+	// Signature is the raw signature.
+	Signature []byte
+
+	// Certificate is the certificate the signature was made with, if it is
+	// a keyless signature.
+	Certificate *x509.Certificate
+
+	// Chain holds any intermediate certificates between the Certificate and
+	// its certificate authority.
+	Chain []*x509.Certificate
+
+	// TlogEntry is the transparency log entry recording the signature, if
+	// there is one.
+	TlogEntry *TlogEntry
+}
+
+// message returns the bytes that were signed.
+func (s *Signature) message() []byte {
+	if "" != s.PayloadType {
+		return attestation.PAE(s.PayloadType, s.Payload)
+	}
+
+	return s.Payload
+}
+
+// Statement returns the in-toto statement in the Signature's payload.
+// Returns an error if the payload is not an in-toto statement.
+func (s *Signature) Statement() (attestation.Statement, error) {
+	if attestation.InTotoPayloadType != s.PayloadType {
+		return attestation.Statement{}, fmt.Errorf("payload type %q is not an in-toto statement", s.PayloadType)
+	}
+
+	return attestation.ParseStatement(s.Payload)
+}
+
+// IsFor returns true if the Signature's payload is about the image with the
+// passed digest. Payloads are either simple signing payloads (as made by
+// `cosign sign`) or in-toto statements.
+func (s *Signature) IsFor(d digest.Digest) bool {
+	if "" != s.PayloadType {
+		statement, err := s.Statement()
+		return nil == err && statement.HasSubject(d)
+	}
+
+	payload, err := attestation.ParsePayload(string(s.Payload))
+	return nil == err && payload.Critical.Image.DockerManifestDigest == d
+}
+
+// parseCertificates parses the PEM encoded certificates in the passed
+// bytes.
+func parseCertificates(b []byte) ([]*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0, 1)
+
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if nil == block {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if nil != err {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if 0 == len(certs) {
+		return nil, errors.New("no PEM encoded certificates found")
+	}
+
+	return certs, nil
+}
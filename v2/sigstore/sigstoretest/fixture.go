@@ -0,0 +1,627 @@
+// Package sigstoretest provides a test Sigstore deployment, and a registry
+// serving the signatures it makes, for testing code which verifies cosign
+// signatures.
+package sigstoretest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/sigstore"
+)
+
+// sigstoreLogName is the name of the test transparency log.
+const sigstoreLogName = "voucher-test-log"
+
+// Fixture is a test Sigstore deployment, with a certificate
+// authority, a transparency log and a signing key.
+type Fixture struct {
+	t      *testing.T
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+	logKey *ecdsa.PrivateKey
+	logID  []byte
+	leaves [][]byte
+
+	// Key is the key that signatures made by SignWithKey are made with.
+	Key *ecdsa.PrivateKey
+
+	// InTotoEntries records DSSE signatures as "intoto" entries in the
+	// transparency log, as older versions of cosign did, rather than as
+	// "dsse" entries.
+	InTotoEntries bool
+}
+
+// NewFixture creates a new Fixture with freshly generated keys.
+func NewFixture(t *testing.T) *Fixture {
+	t.Helper()
+
+	f := &Fixture{
+		t:      t,
+		caKey:  newECDSAKey(t),
+		logKey: newECDSAKey(t),
+		Key:    newECDSAKey(t),
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "voucher-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, f.caKey.Public(), f.caKey)
+	require.NoError(t, err)
+
+	f.caCert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	logKey, err := x509.MarshalPKIXPublicKey(f.logKey.Public())
+	require.NoError(t, err)
+
+	logID := sha256.Sum256(logKey)
+	f.logID = logID[:]
+
+	// Start the log with some other entries, so inclusion proofs have more
+	// than one level.
+	for i := 0; i < 5; i++ {
+		f.leaves = append(f.leaves, []byte(fmt.Sprintf("entry %d", i)))
+	}
+
+	return f
+}
+
+// newECDSAKey generates a new P-256 ECDSA key.
+func newECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+// TrustedRootJSON returns the fixture's Sigstore trusted root, as JSON.
+func (f *Fixture) TrustedRootJSON() []byte {
+	logKey, err := x509.MarshalPKIXPublicKey(f.logKey.Public())
+	require.NoError(f.t, err)
+
+	start := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	root := map[string]interface{}{
+		"mediaType": "application/vnd.dev.sigstore.trustedroot+json;version=0.1",
+		"tlogs": []interface{}{
+			map[string]interface{}{
+				"baseUrl":       "https://rekor.example.com",
+				"hashAlgorithm": "SHA2_256",
+				"publicKey": map[string]interface{}{
+					"rawBytes":   logKey,
+					"keyDetails": "PKIX_ECDSA_P256_SHA_256",
+					"validFor":   map[string]interface{}{"start": start},
+				},
+				"logId": map[string]interface{}{"keyId": f.logID},
+			},
+		},
+		"certificateAuthorities": []interface{}{
+			map[string]interface{}{
+				"uri": "https://fulcio.example.com",
+				"certChain": map[string]interface{}{
+					"certificates": []interface{}{
+						map[string]interface{}{"rawBytes": f.caCert.Raw},
+					},
+				},
+				"validFor": map[string]interface{}{"start": start},
+			},
+		},
+	}
+
+	b, err := json.Marshal(root)
+	require.NoError(f.t, err)
+
+	return b
+}
+
+// TrustedRoot returns the fixture's parsed Sigstore trusted root.
+func (f *Fixture) TrustedRoot() *sigstore.TrustedRoot {
+	root, err := sigstore.ParseTrustedRoot(f.TrustedRootJSON())
+	require.NoError(f.t, err)
+
+	return root
+}
+
+// PublicKey returns the public key matching the fixture's Key.
+func (f *Fixture) PublicKey() crypto.PublicKey {
+	return f.Key.Public()
+}
+
+// PublicKeyPEM returns the PEM encoded public key matching the fixture's
+// Key, as written by `cosign generate-key-pair`.
+func (f *Fixture) PublicKeyPEM() []byte {
+	return publicKeyPEM(f.t, f.Key.Public())
+}
+
+// publicKeyPEM PEM encodes the passed public key.
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// SimpleSigningPayload returns the payload that `cosign sign` signs for the
+// passed image.
+func SimpleSigningPayload(t *testing.T, ref reference.Canonical) []byte {
+	payload := attestation.NewPayload(ref)
+	payload.Critical.Type = "cosign container image signature"
+
+	b, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	return b
+}
+
+// SignWithKey signs the passed payload with the fixture's Key, and records
+// the signature in the transparency log with a signed entry timestamp. If
+// payloadType is not empty, the payload is signed as a DSSE envelope.
+func (f *Fixture) SignWithKey(payload []byte, payloadType string) *sigstore.Signature {
+	sig := &sigstore.Signature{
+		Payload:     payload,
+		PayloadType: payloadType,
+	}
+	sig.Signature = sign(f.t, f.Key, signedMessage(sig))
+
+	entry := f.newTlogEntry(sig, publicKeyPEM(f.t, f.Key.Public()))
+	entry.SignedEntryTimestamp = f.signEntryTimestamp(entry)
+	sig.TlogEntry = entry
+
+	return sig
+}
+
+// SignKeyless signs the passed payload with a short lived certificate issued
+// by the fixture's certificate authority to the passed subject (an email
+// address or URI) and OIDC issuer. The signature is recorded in the
+// transparency log with a signed entry timestamp and an inclusion proof. If
+// payloadType is not empty, the payload is signed as a DSSE envelope.
+func (f *Fixture) SignKeyless(payload []byte, payloadType, subject, issuer string) *sigstore.Signature {
+	key := newECDSAKey(f.t)
+
+	issuerValue, err := asn1.MarshalWithParams(issuer, "utf8")
+	require.NoError(f.t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}, Value: issuerValue},
+		},
+	}
+
+	if strings.Contains(subject, "://") {
+		uri, err := url.Parse(subject)
+		require.NoError(f.t, err)
+		template.URIs = append(template.URIs, uri)
+	} else {
+		template.EmailAddresses = []string{subject}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, f.caCert, key.Public(), f.caKey)
+	require.NoError(f.t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(f.t, err)
+
+	sig := &sigstore.Signature{
+		Payload:     payload,
+		PayloadType: payloadType,
+		Certificate: cert,
+	}
+	sig.Signature = sign(f.t, key, signedMessage(sig))
+
+	entry := f.newTlogEntry(sig, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	entry.SignedEntryTimestamp = f.signEntryTimestamp(entry)
+	entry.InclusionProof = f.proveInclusion(entry)
+	sig.TlogEntry = entry
+
+	return sig
+}
+
+// signedMessage returns the bytes that the passed Signature signs.
+func signedMessage(sig *sigstore.Signature) []byte {
+	if "" != sig.PayloadType {
+		return attestation.PAE(sig.PayloadType, sig.Payload)
+	}
+
+	return sig.Payload
+}
+
+// sign signs the SHA-256 digest of the passed message with the passed key.
+func sign(t *testing.T, key *ecdsa.PrivateKey, message []byte) []byte {
+	sum := sha256.Sum256(message)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(t, err)
+
+	return sig
+}
+
+// newTlogEntry adds an entry recording the passed Signature, made by the
+// PEM encoded verifier (a certificate or public key), to the fixture's
+// transparency log.
+func (f *Fixture) newTlogEntry(sig *sigstore.Signature, verifier []byte) *sigstore.TlogEntry {
+	var body interface{}
+
+	if "" == sig.PayloadType {
+		sum := sha256.Sum256(sig.Payload)
+		body = map[string]interface{}{
+			"apiVersion": "0.0.1",
+			"kind":       "hashedrekord",
+			"spec": map[string]interface{}{
+				"data": map[string]interface{}{
+					"hash": map[string]interface{}{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])},
+				},
+				"signature": map[string]interface{}{
+					"content":   sig.Signature,
+					"publicKey": map[string]interface{}{"content": verifier},
+				},
+			},
+		}
+	} else if f.InTotoEntries {
+		sum := sha256.Sum256(sig.Payload)
+		body = map[string]interface{}{
+			"apiVersion": "0.0.2",
+			"kind":       "intoto",
+			"spec": map[string]interface{}{
+				"content": map[string]interface{}{
+					"envelope": map[string]interface{}{
+						"payloadType": sig.PayloadType,
+						"signatures": []interface{}{
+							map[string]interface{}{
+								"sig":       []byte(base64.StdEncoding.EncodeToString(sig.Signature)),
+								"publicKey": verifier,
+							},
+						},
+					},
+					"payloadHash": map[string]interface{}{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])},
+				},
+			},
+		}
+	} else {
+		sum := sha256.Sum256(sig.Payload)
+		body = map[string]interface{}{
+			"apiVersion": "0.0.1",
+			"kind":       "dsse",
+			"spec": map[string]interface{}{
+				"payloadHash": map[string]interface{}{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])},
+				"signatures": []interface{}{
+					map[string]interface{}{"signature": sig.Signature, "verifier": verifier},
+				},
+			},
+		}
+	}
+
+	b, err := json.Marshal(body)
+	require.NoError(f.t, err)
+
+	f.leaves = append(f.leaves, b)
+
+	return &sigstore.TlogEntry{
+		LogIndex:       int64(len(f.leaves) - 1),
+		LogID:          f.logID,
+		IntegratedTime: time.Now().Unix(),
+		Body:           b,
+	}
+}
+
+// signEntryTimestamp returns the log's signed entry timestamp for the
+// passed entry.
+func (f *Fixture) signEntryTimestamp(entry *sigstore.TlogEntry) []byte {
+	b, err := json.Marshal(map[string]interface{}{
+		"body":           base64.StdEncoding.EncodeToString(entry.Body),
+		"integratedTime": entry.IntegratedTime,
+		"logID":          hex.EncodeToString(entry.LogID),
+		"logIndex":       entry.LogIndex,
+	})
+	require.NoError(f.t, err)
+
+	return sign(f.t, f.logKey, b)
+}
+
+// proveInclusion returns an inclusion proof of the passed entry in the
+// fixture's transparency log, with a checkpoint signed by the log.
+func (f *Fixture) proveInclusion(entry *sigstore.TlogEntry) *sigstore.InclusionProof {
+	root := merkleRoot(f.leaves)
+	size := int64(len(f.leaves))
+
+	text := fmt.Sprintf("%s\n%d\n%s\n", sigstoreLogName, size, base64.StdEncoding.EncodeToString(root))
+	sig := append([]byte{0, 0, 0, 0}, sign(f.t, f.logKey, []byte(text))...)
+
+	return &sigstore.InclusionProof{
+		LogIndex:   entry.LogIndex,
+		TreeSize:   size,
+		RootHash:   root,
+		Hashes:     merklePath(int(entry.LogIndex), f.leaves),
+		Checkpoint: fmt.Sprintf("%s\n— %s %s\n", text, sigstoreLogName, base64.StdEncoding.EncodeToString(sig)),
+	}
+}
+
+// merkleRoot returns the RFC 6962 Merkle tree hash of the passed leaves.
+func merkleRoot(leaves [][]byte) []byte {
+	if 1 == len(leaves) {
+		return merkleHash(0, leaves[0])
+	}
+
+	k := merkleSplit(len(leaves))
+	return merkleHash(1, merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+}
+
+// merklePath returns the RFC 6962 inclusion proof of the leaf at the passed
+// index.
+func merklePath(index int, leaves [][]byte) [][]byte {
+	if 1 == len(leaves) {
+		return [][]byte{}
+	}
+
+	k := merkleSplit(len(leaves))
+	if index < k {
+		return append(merklePath(index, leaves[:k]), merkleRoot(leaves[k:]))
+	}
+
+	return append(merklePath(index-k, leaves[k:]), merkleRoot(leaves[:k]))
+}
+
+// merkleSplit returns the largest power of two smaller than n.
+func merkleSplit(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// merkleHash hashes the passed prefix and values.
+func merkleHash(prefix byte, values ...[]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	for _, value := range values {
+		h.Write(value)
+	}
+	return h.Sum(nil)
+}
+
+// NewRegistry creates a registry which serves the passed signatures
+// for the passed image the way cosign stores them. Signatures over simple
+// signing payloads are served from the "sha256-<digest>.sig" tag, keyed
+// DSSE signatures from the "sha256-<digest>.att" tag, and keyless DSSE
+// signatures as Sigstore bundles attached with the referrers API.
+func (f *Fixture) NewRegistry(ref reference.Canonical, signatures ...*sigstore.Signature) *httptest.Server {
+	registry := &sigstoreRegistry{
+		repo:    "/v2/" + reference.Path(ref),
+		content: map[string]sigstoreContent{},
+	}
+
+	sigLayers := []map[string]interface{}{}
+	attLayers := []map[string]interface{}{}
+	referrers := []map[string]interface{}{}
+
+	for _, sig := range signatures {
+		switch {
+		case "" == sig.PayloadType:
+			annotations := cosignAnnotations(f.t, sig)
+			annotations["dev.cosignproject.cosign/signature"] = base64.StdEncoding.EncodeToString(sig.Signature)
+			sigLayers = append(sigLayers, registry.blob(sigstore.SimpleSigningMediaType, sig.Payload, annotations))
+		case nil == sig.Certificate:
+			envelope, err := json.Marshal(attestation.Envelope{
+				PayloadType: sig.PayloadType,
+				Payload:     base64.StdEncoding.EncodeToString(sig.Payload),
+				Signatures:  []attestation.EnvelopeSignature{{Sig: base64.StdEncoding.EncodeToString(sig.Signature)}},
+			})
+			require.NoError(f.t, err)
+
+			attLayers = append(attLayers, registry.blob(sigstore.DSSEMediaType, envelope, cosignAnnotations(f.t, sig)))
+		default:
+			bundleType := sigstore.BundleMediaTypePrefix + ".v0.3+json"
+			layer := registry.blob(bundleType, sigstoreBundle(f.t, sig), nil)
+			descriptor, _ := registry.manifest(f.t, bundleType, layer)
+			referrers = append(referrers, descriptor)
+		}
+	}
+
+	tag := strings.Replace(string(ref.Digest()), ":", "-", 1)
+
+	_, sigManifest := registry.manifest(f.t, "", sigLayers...)
+	registry.serve("/manifests/"+tag+".sig", "application/vnd.oci.image.manifest.v1+json", sigManifest)
+
+	_, attManifest := registry.manifest(f.t, "", attLayers...)
+	registry.serve("/manifests/"+tag+".att", "application/vnd.oci.image.manifest.v1+json", attManifest)
+
+	index, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests":     referrers,
+	})
+	require.NoError(f.t, err)
+	registry.serve("/referrers/"+string(ref.Digest()), "application/vnd.oci.image.index.v1+json", index)
+
+	server := httptest.NewTLSServer(registry)
+	f.t.Cleanup(server.Close)
+
+	return server
+}
+
+// cosignAnnotations returns the annotations that cosign stores alongside a
+// signature layer.
+func cosignAnnotations(t *testing.T, sig *sigstore.Signature) map[string]string {
+	annotations := map[string]string{}
+
+	if nil != sig.Certificate {
+		annotations["dev.sigstore.cosign/certificate"] = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sig.Certificate.Raw}))
+	}
+
+	if nil != sig.TlogEntry {
+		bundle, err := json.Marshal(map[string]interface{}{
+			"SignedEntryTimestamp": sig.TlogEntry.SignedEntryTimestamp,
+			"Payload": map[string]interface{}{
+				"body":           sig.TlogEntry.Body,
+				"integratedTime": sig.TlogEntry.IntegratedTime,
+				"logIndex":       sig.TlogEntry.LogIndex,
+				"logID":          hex.EncodeToString(sig.TlogEntry.LogID),
+			},
+		})
+		require.NoError(t, err)
+		annotations["dev.sigstore.cosign/bundle"] = string(bundle)
+	}
+
+	return annotations
+}
+
+// sigstoreBundle returns the passed keyless DSSE Signature as a JSON encoded
+// Sigstore bundle.
+func sigstoreBundle(t *testing.T, sig *sigstore.Signature) []byte {
+	entry := sig.TlogEntry
+	proof := entry.InclusionProof
+
+	b, err := json.Marshal(map[string]interface{}{
+		"mediaType": sigstore.BundleMediaTypePrefix + ".v0.3+json",
+		"verificationMaterial": map[string]interface{}{
+			"certificate": map[string]interface{}{"rawBytes": sig.Certificate.Raw},
+			"tlogEntries": []interface{}{
+				map[string]interface{}{
+					"logIndex":       fmt.Sprint(entry.LogIndex),
+					"logId":          map[string]interface{}{"keyId": entry.LogID},
+					"kindVersion":    map[string]interface{}{"kind": "dsse", "version": "0.0.1"},
+					"integratedTime": fmt.Sprint(entry.IntegratedTime),
+					"inclusionPromise": map[string]interface{}{
+						"signedEntryTimestamp": entry.SignedEntryTimestamp,
+					},
+					"inclusionProof": map[string]interface{}{
+						"logIndex":   fmt.Sprint(proof.LogIndex),
+						"rootHash":   proof.RootHash,
+						"treeSize":   fmt.Sprint(proof.TreeSize),
+						"hashes":     proof.Hashes,
+						"checkpoint": map[string]interface{}{"envelope": proof.Checkpoint},
+					},
+					"canonicalizedBody": entry.Body,
+				},
+			},
+		},
+		"dsseEnvelope": map[string]interface{}{
+			"payloadType": sig.PayloadType,
+			"payload":     sig.Payload,
+			"signatures":  []interface{}{map[string]interface{}{"sig": sig.Signature}},
+		},
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+// sigstoreContent is a blob or manifest served by a sigstoreRegistry.
+type sigstoreContent struct {
+	mediaType string
+	body      []byte
+}
+
+// sigstoreRegistry is a registry serving the content of a single repository
+// from a map of paths.
+type sigstoreRegistry struct {
+	repo    string
+	content map[string]sigstoreContent
+}
+
+// ServeHTTP implements http.Handler.
+func (r *sigstoreRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	content, ok := r.content[req.URL.Path]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", content.mediaType)
+	_, _ = w.Write(content.body)
+}
+
+// serve serves the passed content under the passed path in the repository.
+func (r *sigstoreRegistry) serve(path, mediaType string, body []byte) {
+	r.content[r.repo+path] = sigstoreContent{mediaType, body}
+}
+
+// blob stores the passed blob and returns a descriptor for it.
+func (r *sigstoreRegistry) blob(mediaType string, blob []byte, annotations map[string]string) map[string]interface{} {
+	d := digest.FromBytes(blob)
+	r.serve("/blobs/"+string(d), "application/octet-stream", blob)
+
+	descriptor := map[string]interface{}{
+		"mediaType": mediaType,
+		"digest":    d,
+		"size":      len(blob),
+	}
+	if 0 < len(annotations) {
+		descriptor["annotations"] = annotations
+	}
+
+	return descriptor
+}
+
+// manifest stores an OCI manifest with the passed layers, and returns a
+// descriptor for it along with its content.
+func (r *sigstoreRegistry) manifest(t *testing.T, artifactType string, layers ...map[string]interface{}) (map[string]interface{}, []byte) {
+	if nil == layers {
+		layers = []map[string]interface{}{}
+	}
+
+	manifest := map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        r.blob("application/vnd.oci.empty.v1+json", []byte("{}"), nil),
+		"layers":        layers,
+	}
+	if "" != artifactType {
+		manifest["artifactType"] = artifactType
+	}
+
+	b, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	d := digest.FromBytes(b)
+	r.serve("/manifests/"+string(d), "application/vnd.oci.image.manifest.v1+json", b)
+
+	descriptor := map[string]interface{}{
+		"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"digest":    d,
+		"size":      len(b),
+	}
+	if "" != artifactType {
+		descriptor["artifactType"] = artifactType
+	}
+
+	return descriptor, b
+}
@@ -0,0 +1,390 @@
+package sigstore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownLog is the error returned when a transparency log entry comes
+// from a log which is not in the trusted root.
+var ErrUnknownLog = errors.New("transparency log entry is from an untrusted log")
+
+// ErrNoLogProof is the error returned when a transparency log entry has
+// neither an inclusion proof nor a signed entry timestamp.
+var ErrNoLogProof = errors.New("transparency log entry has no inclusion proof or signed entry timestamp")
+
+// TlogEntry is an entry in a transparency log (such as Rekor), proving that
+// a signature was recorded in the log at IntegratedTime.
+type TlogEntry struct {
+	LogIndex       int64
+	LogID          []byte
+	IntegratedTime int64
+
+	// Body is the canonicalized body of the entry, as stored in the log.
+	Body []byte
+
+	// SignedEntryTimestamp is the log's signature over the entry, promising
+	// that it will be included in the log.
+	SignedEntryTimestamp []byte
+
+	// InclusionProof proves that the entry is included in the log.
+	InclusionProof *InclusionProof
+}
+
+// InclusionProof is a Merkle tree inclusion proof of a transparency log
+// entry, along with the signed checkpoint of the tree it is included in.
+type InclusionProof struct {
+	LogIndex   int64
+	TreeSize   int64
+	RootHash   []byte
+	Hashes     [][]byte
+	Checkpoint string
+}
+
+// verify verifies that the entry was recorded by one of the trusted
+// transparency logs, and that it records the passed Signature, made with the
+// passed public key. An inclusion proof is verified against the log's signed
+// checkpoint, and a signed entry timestamp against the log's key; an entry
+// must have at least one of them. Returns the time the entry was added to
+// the log, which is only known if the signed entry timestamp was verified,
+// since nothing else signs it. Otherwise the returned time is zero.
+func (e *TlogEntry) verify(root *TrustedRoot, sig *Signature, key crypto.PublicKey) (time.Time, error) {
+	log := root.findLog(e.LogID)
+	if nil == log {
+		return time.Time{}, ErrUnknownLog
+	}
+
+	if nil == e.InclusionProof && 0 == len(e.SignedEntryTimestamp) {
+		return time.Time{}, ErrNoLogProof
+	}
+
+	if nil != e.InclusionProof {
+		if err := e.verifyInclusionProof(log); nil != err {
+			return time.Time{}, err
+		}
+	}
+
+	var signedAt time.Time
+	if 0 < len(e.SignedEntryTimestamp) {
+		if err := e.verifySignedEntryTimestamp(log); nil != err {
+			return time.Time{}, err
+		}
+
+		signedAt = time.Unix(e.IntegratedTime, 0)
+		if !log.validFor.contains(signedAt) {
+			return time.Time{}, errors.New("transparency log entry was made outside of the log's validity period")
+		}
+	}
+
+	if err := verifyEntryBody(e.Body, sig, key); nil != err {
+		return time.Time{}, err
+	}
+
+	return signedAt, nil
+}
+
+// verifySignedEntryTimestamp verifies the log's signature over the entry.
+func (e *TlogEntry) verifySignedEntryTimestamp(log *transparencyLog) error {
+	// The signed entry timestamp is made over the canonical JSON encoding of
+	// these fields, which is the same as their encoding in this order.
+	payload, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{
+		Body:           base64.StdEncoding.EncodeToString(e.Body),
+		IntegratedTime: e.IntegratedTime,
+		LogID:          hex.EncodeToString(e.LogID),
+		LogIndex:       e.LogIndex,
+	})
+	if nil != err {
+		return err
+	}
+
+	if err = verifySignature(log.key, payload, e.SignedEntryTimestamp); nil != err {
+		return fmt.Errorf("invalid signed entry timestamp: %w", err)
+	}
+
+	return nil
+}
+
+// verifyInclusionProof verifies that the entry is included in the tree
+// described by the proof's checkpoint, and that the checkpoint was signed
+// by the log.
+func (e *TlogEntry) verifyInclusionProof(log *transparencyLog) error {
+	proof := e.InclusionProof
+
+	leaf := hashLeaf(e.Body)
+	root, err := rootFromInclusionProof(proof.LogIndex, proof.TreeSize, leaf, proof.Hashes)
+	if nil != err {
+		return err
+	}
+
+	if !bytes.Equal(root, proof.RootHash) {
+		return errors.New("inclusion proof does not match the root hash")
+	}
+
+	return verifyCheckpoint(log, proof.Checkpoint, proof.TreeSize, proof.RootHash)
+}
+
+// hashLeaf returns the RFC 6962 hash of a Merkle tree leaf.
+func hashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+// hashChildren returns the RFC 6962 hash of a Merkle tree node.
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// rootFromInclusionProof calculates the root hash of a Merkle tree of the
+// passed size from the hash of the leaf at the passed index and its
+// inclusion proof, as described in RFC 9162 section 2.1.3.2.
+func rootFromInclusionProof(index, size int64, leaf []byte, proof [][]byte) ([]byte, error) {
+	if index < 0 || index >= size {
+		return nil, fmt.Errorf("inclusion proof index %d is outside of the tree of size %d", index, size)
+	}
+
+	fn, sn := index, size-1
+	hash := leaf
+
+	for _, p := range proof {
+		if 0 == sn {
+			return nil, errors.New("inclusion proof is too long")
+		}
+
+		if 1 == fn&1 || fn == sn {
+			hash = hashChildren(p, hash)
+			for 0 == fn&1 && 0 != fn {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = hashChildren(hash, p)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if 0 != sn {
+		return nil, errors.New("inclusion proof is too short")
+	}
+
+	return hash, nil
+}
+
+// verifyCheckpoint verifies that the passed checkpoint (a signed note
+// describing the log's tree) was signed by the log and describes a tree
+// with the passed size and root hash.
+func verifyCheckpoint(log *transparencyLog, checkpoint string, size int64, root []byte) error {
+	separator := strings.Index(checkpoint, "\n\n")
+	if -1 == separator {
+		return errors.New("malformed checkpoint")
+	}
+
+	text := checkpoint[:separator+1]
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) < 3 {
+		return errors.New("malformed checkpoint")
+	}
+
+	if lines[1] != strconv.FormatInt(size, 10) {
+		return errors.New("checkpoint does not match the inclusion proof's tree size")
+	}
+
+	if lines[2] != base64.StdEncoding.EncodeToString(root) {
+		return errors.New("checkpoint does not match the inclusion proof's root hash")
+	}
+
+	for _, line := range strings.Split(checkpoint[separator+2:], "\n") {
+		// Signature lines are formatted as "— <name> <signature>", where the
+		// signature is prefixed with a four byte key hint.
+		if !strings.HasPrefix(line, "— ") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if 3 != len(fields) {
+			continue
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(fields[2])
+		if nil != err || len(sig) <= 4 {
+			continue
+		}
+
+		if nil == verifySignature(log.key, []byte(text), sig[4:]) {
+			return nil
+		}
+	}
+
+	return errors.New("checkpoint is not signed by the transparency log")
+}
+
+// rekorEntry is the body of a Rekor transparency log entry.
+type rekorEntry struct {
+	Kind string          `json:"kind"`
+	Spec json.RawMessage `json:"spec"`
+}
+
+// rekorHash is a hash recorded in a Rekor entry.
+type rekorHash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// hashedRekordSpec is the spec of a "hashedrekord" entry, which records a
+// signature over the hash of an artifact.
+type hashedRekordSpec struct {
+	Data struct {
+		Hash rekorHash `json:"hash"`
+	} `json:"data"`
+	Signature struct {
+		Content   []byte `json:"content"`
+		PublicKey struct {
+			Content []byte `json:"content"`
+		} `json:"publicKey"`
+	} `json:"signature"`
+}
+
+// dsseSpec is the spec of a "dsse" entry, which records a DSSE envelope.
+type dsseSpec struct {
+	PayloadHash rekorHash `json:"payloadHash"`
+	Signatures  []struct {
+		Signature []byte `json:"signature"`
+		Verifier  []byte `json:"verifier"`
+	} `json:"signatures"`
+}
+
+// intotoSpec is the spec of an "intoto" entry, which records an in-toto
+// attestation in a DSSE envelope. The envelope's signatures are base64
+// encoded again, on top of the encoding of the JSON bytes.
+type intotoSpec struct {
+	Content struct {
+		Envelope struct {
+			Signatures []struct {
+				Sig       []byte `json:"sig"`
+				PublicKey []byte `json:"publicKey"`
+			} `json:"signatures"`
+		} `json:"envelope"`
+		PayloadHash rekorHash `json:"payloadHash"`
+	} `json:"content"`
+}
+
+// verifyEntryBody verifies that the passed Rekor entry body records the
+// passed Signature, made with the passed public key.
+func verifyEntryBody(body []byte, sig *Signature, key crypto.PublicKey) error {
+	var entry rekorEntry
+	if err := json.Unmarshal(body, &entry); nil != err {
+		return fmt.Errorf("parsing transparency log entry: %w", err)
+	}
+
+	switch entry.Kind {
+	case "hashedrekord":
+		var spec hashedRekordSpec
+		if err := json.Unmarshal(entry.Spec, &spec); nil != err {
+			return fmt.Errorf("parsing transparency log entry: %w", err)
+		}
+
+		if !matchesHash(spec.Data.Hash, sig.message()) || !bytes.Equal(spec.Signature.Content, sig.Signature) {
+			return errors.New("transparency log entry does not record the signature")
+		}
+
+		return verifyEntryVerifier(spec.Signature.PublicKey.Content, key)
+	case "dsse":
+		var spec dsseSpec
+		if err := json.Unmarshal(entry.Spec, &spec); nil != err {
+			return fmt.Errorf("parsing transparency log entry: %w", err)
+		}
+
+		if !matchesHash(spec.PayloadHash, sig.Payload) {
+			return errors.New("transparency log entry does not record the signature")
+		}
+
+		for _, s := range spec.Signatures {
+			if bytes.Equal(s.Signature, sig.Signature) {
+				return verifyEntryVerifier(s.Verifier, key)
+			}
+		}
+
+		return errors.New("transparency log entry does not record the signature")
+	case "intoto":
+		var spec intotoSpec
+		if err := json.Unmarshal(entry.Spec, &spec); nil != err {
+			return fmt.Errorf("parsing transparency log entry: %w", err)
+		}
+
+		if !matchesHash(spec.Content.PayloadHash, sig.Payload) {
+			return errors.New("transparency log entry does not record the signature")
+		}
+
+		for _, s := range spec.Content.Envelope.Signatures {
+			signature, err := base64.StdEncoding.DecodeString(string(s.Sig))
+			if nil == err && bytes.Equal(signature, sig.Signature) {
+				return verifyEntryVerifier(s.PublicKey, key)
+			}
+		}
+
+		return errors.New("transparency log entry does not record the signature")
+	default:
+		return fmt.Errorf("unsupported transparency log entry kind %q", entry.Kind)
+	}
+}
+
+// verifyEntryVerifier verifies that the PEM encoded certificate or public
+// key recorded in a transparency log entry has the passed public key, which
+// made the signature.
+func verifyEntryVerifier(verifier []byte, key crypto.PublicKey) error {
+	block, _ := pem.Decode(verifier)
+	if nil == block {
+		return errors.New("transparency log entry has no verifier")
+	}
+
+	var entryKey crypto.PublicKey
+	if "CERTIFICATE" == block.Type {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if nil != err {
+			return fmt.Errorf("parsing transparency log entry verifier: %w", err)
+		}
+		entryKey = cert.PublicKey
+	} else {
+		var err error
+		entryKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if nil != err {
+			return fmt.Errorf("parsing transparency log entry verifier: %w", err)
+		}
+	}
+
+	if !equalKeys(entryKey, key) {
+		return errors.New("transparency log entry was made with a different key")
+	}
+
+	return nil
+}
+
+// matchesHash returns true if the passed Rekor hash is the SHA-256 hash of
+// the passed content.
+func matchesHash(hash rekorHash, content []byte) bool {
+	sum := sha256.Sum256(content)
+	return "sha256" == hash.Algorithm && hash.Value == hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,181 @@
+package sigstore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// timeRange is the period that a certificate authority or transparency log
+// is trusted for. A range without an end is open ended.
+type timeRange struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// contains returns true if the passed time is in the range.
+func (r timeRange) contains(t time.Time) bool {
+	if t.Before(r.Start) {
+		return false
+	}
+
+	return nil == r.End || !t.After(*r.End)
+}
+
+// certificateAuthority is a certificate authority (such as Fulcio) which
+// issues the certificates used for keyless signing.
+type certificateAuthority struct {
+	root          *x509.Certificate
+	intermediates []*x509.Certificate
+	validFor      timeRange
+}
+
+// transparencyLog is a transparency log (such as Rekor) which records
+// signatures.
+type transparencyLog struct {
+	id       []byte
+	key      crypto.PublicKey
+	validFor timeRange
+}
+
+// TrustedRoot holds the certificate authorities and transparency logs that
+// signatures are verified against. It is read from a Sigstore trusted root
+// file (trusted_root.json), so no network access is needed to verify
+// signatures.
+type TrustedRoot struct {
+	authorities []certificateAuthority
+	logs        []transparencyLog
+}
+
+// trustedRootFile is the JSON format of a Sigstore trusted root file.
+type trustedRootFile struct {
+	MediaType string `json:"mediaType"`
+	Tlogs     []struct {
+		PublicKey struct {
+			RawBytes []byte    `json:"rawBytes"`
+			ValidFor timeRange `json:"validFor"`
+		} `json:"publicKey"`
+		LogID struct {
+			KeyID []byte `json:"keyId"`
+		} `json:"logId"`
+	} `json:"tlogs"`
+	CertificateAuthorities []struct {
+		CertChain struct {
+			Certificates []struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"certChain"`
+		ValidFor timeRange `json:"validFor"`
+	} `json:"certificateAuthorities"`
+}
+
+// ParseTrustedRoot parses a JSON encoded Sigstore trusted root.
+func ParseTrustedRoot(b []byte) (*TrustedRoot, error) {
+	var file trustedRootFile
+	if err := json.Unmarshal(b, &file); nil != err {
+		return nil, fmt.Errorf("parsing trusted root: %w", err)
+	}
+
+	root := new(TrustedRoot)
+
+	for _, tlog := range file.Tlogs {
+		key, err := x509.ParsePKIXPublicKey(tlog.PublicKey.RawBytes)
+		if nil != err {
+			return nil, fmt.Errorf("parsing transparency log key: %w", err)
+		}
+
+		root.logs = append(root.logs, transparencyLog{
+			id:       tlog.LogID.KeyID,
+			key:      key,
+			validFor: tlog.PublicKey.ValidFor,
+		})
+	}
+
+	for _, ca := range file.CertificateAuthorities {
+		chain := make([]*x509.Certificate, 0, len(ca.CertChain.Certificates))
+		for _, raw := range ca.CertChain.Certificates {
+			cert, err := x509.ParseCertificate(raw.RawBytes)
+			if nil != err {
+				return nil, fmt.Errorf("parsing certificate authority: %w", err)
+			}
+			chain = append(chain, cert)
+		}
+
+		if 0 == len(chain) {
+			return nil, errors.New("certificate authority has no certificates")
+		}
+
+		// The chain is ordered from the issuing certificate to the root.
+		root.authorities = append(root.authorities, certificateAuthority{
+			root:          chain[len(chain)-1],
+			intermediates: chain[:len(chain)-1],
+			validFor:      ca.ValidFor,
+		})
+	}
+
+	return root, nil
+}
+
+// LoadTrustedRoot reads the Sigstore trusted root in the passed file.
+func LoadTrustedRoot(path string) (*TrustedRoot, error) {
+	b, err := os.ReadFile(path)
+	if nil != err {
+		return nil, err
+	}
+
+	return ParseTrustedRoot(b)
+}
+
+// findLog returns the transparency log with the passed ID, or nil if the
+// log is not trusted.
+func (r *TrustedRoot) findLog(id []byte) *transparencyLog {
+	for i := range r.logs {
+		if bytes.Equal(r.logs[i].id, id) {
+			return &r.logs[i]
+		}
+	}
+
+	return nil
+}
+
+// verifyCertificate verifies that the passed certificate was issued by one
+// of the trusted certificate authorities, and was valid at the passed time.
+func (r *TrustedRoot) verifyCertificate(cert *x509.Certificate, chain []*x509.Certificate, at time.Time) error {
+	var lastErr error = errors.New("no trusted certificate authorities")
+
+	for _, ca := range r.authorities {
+		if !ca.validFor.contains(at) {
+			continue
+		}
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.root)
+
+		intermediates := x509.NewCertPool()
+		for _, intermediate := range ca.intermediates {
+			intermediates.AddCert(intermediate)
+		}
+		for _, intermediate := range chain {
+			intermediates.AddCert(intermediate)
+		}
+
+		_, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   at,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		})
+		if nil == err {
+			return nil
+		}
+
+		lastErr = err
+	}
+
+	return fmt.Errorf("certificate is not trusted: %w", lastErr)
+}
@@ -0,0 +1,166 @@
+package sigstore
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// ErrNoSignatures is the error returned when an image has no signatures
+// (or attestations) to verify.
+var ErrNoSignatures = errors.New("image has no signatures")
+
+// ErrNoTrustedRoot is the error returned when a keyless signature or a
+// transparency log entry is verified without a trusted root.
+var ErrNoTrustedRoot = errors.New("no trusted root configured")
+
+// ErrNoTlogEntry is the error returned when a signature that must be
+// recorded in a transparency log has no log entry.
+var ErrNoTlogEntry = errors.New("signature has no transparency log entry")
+
+// ErrNoSignedTimestamp is the error returned when a keyless signature's
+// transparency log entry has no signed entry timestamp, so the time it was
+// recorded (which its certificate must have been valid at) is not signed.
+var ErrNoSignedTimestamp = errors.New("keyless signature's transparency log entry has no signed entry timestamp")
+
+// ErrUntrustedKey is the error returned when a signature was not made by
+// any of the trusted public keys.
+var ErrUntrustedKey = errors.New("signature was not made by a trusted key")
+
+// ErrUntrustedIdentity is the error returned when a keyless signature's
+// certificate was not issued to a trusted identity.
+var ErrUntrustedIdentity = errors.New("certificate was not issued to a trusted identity")
+
+// Verifier verifies signatures against a set of trusted public keys, and
+// keyless signatures against a trusted root and a set of trusted identities.
+type Verifier struct {
+	keys        []crypto.PublicKey
+	root        *TrustedRoot
+	identities  []Identity
+	requireTlog bool
+}
+
+// NewVerifier creates a new Verifier. Signatures made with one of the passed
+// keys are trusted. Keyless signatures are trusted if their certificate was
+// issued by a certificate authority in the passed TrustedRoot to one of the
+// passed Identities, and they are recorded in one of its transparency logs.
+// If requireTlog is true, signatures made with keys must also be recorded in
+// a transparency log.
+func NewVerifier(keys []crypto.PublicKey, root *TrustedRoot, identities []Identity, requireTlog bool) *Verifier {
+	return &Verifier{
+		keys:        keys,
+		root:        root,
+		identities:  identities,
+		requireTlog: requireTlog,
+	}
+}
+
+// Verify verifies the passed Signature. Returns nil if the Signature is
+// trusted.
+func (v *Verifier) Verify(sig *Signature) error {
+	if nil != sig.Certificate {
+		return v.verifyKeyless(sig)
+	}
+
+	for _, key := range v.keys {
+		if nil != verifySignature(key, sig.message(), sig.Signature) {
+			continue
+		}
+
+		if v.requireTlog {
+			if _, err := v.verifyTlogEntry(sig, key); nil != err {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return ErrUntrustedKey
+}
+
+// verifyKeyless verifies a keyless Signature. The certificate must have been
+// valid when the signature was recorded in the transparency log, as signed by
+// the log's signed entry timestamp.
+func (v *Verifier) verifyKeyless(sig *Signature) error {
+	if nil == v.root {
+		return ErrNoTrustedRoot
+	}
+
+	key := sig.Certificate.PublicKey
+	if err := verifySignature(key, sig.message(), sig.Signature); nil != err {
+		return err
+	}
+
+	signedAt, err := v.verifyTlogEntry(sig, key)
+	if nil != err {
+		return err
+	}
+
+	if signedAt.IsZero() {
+		return ErrNoSignedTimestamp
+	}
+
+	if err = v.root.verifyCertificate(sig.Certificate, sig.Chain, signedAt); nil != err {
+		return err
+	}
+
+	for _, identity := range v.identities {
+		if identity.Matches(sig.Certificate) {
+			return nil
+		}
+	}
+
+	return ErrUntrustedIdentity
+}
+
+// verifyTlogEntry verifies the Signature's transparency log entry, returning
+// the time the Signature was recorded, or zero if the entry has no signed
+// entry timestamp.
+func (v *Verifier) verifyTlogEntry(sig *Signature, key crypto.PublicKey) (time.Time, error) {
+	if nil == v.root {
+		return time.Time{}, ErrNoTrustedRoot
+	}
+
+	if nil == sig.TlogEntry {
+		return time.Time{}, ErrNoTlogEntry
+	}
+
+	return sig.TlogEntry.verify(v.root, sig, key)
+}
+
+// VerifyImage returns the passed Signatures which are trusted and are about
+// the image with the passed digest. Returns an error describing why each
+// Signature was rejected if none of them are trusted.
+func (v *Verifier) VerifyImage(signatures []*Signature, d digest.Digest) ([]*Signature, error) {
+	if 0 == len(signatures) {
+		return nil, ErrNoSignatures
+	}
+
+	verified := make([]*Signature, 0, len(signatures))
+	errs := make([]string, 0, len(signatures))
+
+	for _, sig := range signatures {
+		if !sig.IsFor(d) {
+			errs = append(errs, fmt.Sprintf("signature is not for %s", d))
+			continue
+		}
+
+		if err := v.Verify(sig); nil != err {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		verified = append(verified, sig)
+	}
+
+	if 0 == len(verified) {
+		return nil, fmt.Errorf("no trusted signatures: %s", strings.Join(errs, "; "))
+	}
+
+	return verified, nil
+}
@@ -0,0 +1,266 @@
+package sigstore_test
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/sigstore"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+const (
+	testSubject = "https://github.com/grafeas/voucher/.github/workflows/release.yml@refs/heads/main"
+	testIssuer  = "https://token.actions.githubusercontent.com"
+)
+
+// newTestStatement returns an in-toto statement about the test image.
+func newTestStatement(t *testing.T, predicateType string) []byte {
+	ref := vtesting.NewTestReference(t)
+
+	b, err := json.Marshal(attestation.Statement{
+		Type: attestation.StatementType,
+		Subject: []attestation.Subject{
+			{Name: ref.Name(), Digest: map[string]string{"sha256": ref.Digest().Encoded()}},
+		},
+		PredicateType: predicateType,
+		Predicate:     json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestVerifyKeySignature(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	sig := fixture.SignWithKey(sigstoretest.SimpleSigningPayload(t, ref), "")
+
+	verifier := sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false)
+	verified, err := verifier.VerifyImage([]*sigstore.Signature{sig}, ref.Digest())
+	require.NoError(t, err)
+	assert.Equal(t, []*sigstore.Signature{sig}, verified)
+
+	// another key isn't trusted.
+	other := sigstoretest.NewFixture(t)
+	verifier = sigstore.NewVerifier([]crypto.PublicKey{other.PublicKey()}, nil, nil, false)
+	assert.Equal(t, sigstore.ErrUntrustedKey, verifier.Verify(sig))
+
+	// the signature must be for the image.
+	verifier = sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false)
+	_, err = verifier.VerifyImage([]*sigstore.Signature{sig}, vtesting.NewNobodyBadTestReference(t).Digest())
+	assert.EqualError(t, err, "no trusted signatures: signature is not for sha256:b248c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da")
+}
+
+func TestVerifyKeySignatureRequiringTlog(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	sig := fixture.SignWithKey(sigstoretest.SimpleSigningPayload(t, ref), "")
+
+	verifier := sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, fixture.TrustedRoot(), nil, true)
+	assert.NoError(t, verifier.Verify(sig))
+
+	// a tampered signed entry timestamp is rejected.
+	tampered := *sig.TlogEntry
+	tampered.IntegratedTime++
+	sig.TlogEntry = &tampered
+	assert.Error(t, verifier.Verify(sig))
+
+	sig.TlogEntry = nil
+	assert.Equal(t, sigstore.ErrNoTlogEntry, verifier.Verify(sig))
+
+	// a log that isn't in the trusted root isn't trusted.
+	other := sigstoretest.NewFixture(t)
+	verifier = sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, other.TrustedRoot(), nil, true)
+	assert.Equal(t, sigstore.ErrUnknownLog, verifier.Verify(fixture.SignWithKey(sigstoretest.SimpleSigningPayload(t, ref), "")))
+}
+
+func TestVerifyKeylessSignature(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	sig := fixture.SignKeyless(newTestStatement(t, sigstore.SignPredicateType), attestation.InTotoPayloadType, testSubject, testIssuer)
+
+	identities := []sigstore.Identity{{Issuer: testIssuer, SubjectRegexp: `https://github\.com/grafeas/voucher/.*`}}
+	verifier := sigstore.NewVerifier(nil, fixture.TrustedRoot(), identities, false)
+
+	verified, err := verifier.VerifyImage([]*sigstore.Signature{sig}, ref.Digest())
+	require.NoError(t, err)
+	assert.Len(t, verified, 1)
+
+	// the inclusion proof is verified, as well as the signed entry timestamp.
+	entry := *sig.TlogEntry
+	proof := *entry.InclusionProof
+	proof.Hashes = proof.Hashes[1:]
+	sig.TlogEntry = &sigstore.TlogEntry{
+		LogIndex:             entry.LogIndex,
+		LogID:                entry.LogID,
+		IntegratedTime:       entry.IntegratedTime,
+		Body:                 entry.Body,
+		SignedEntryTimestamp: entry.SignedEntryTimestamp,
+		InclusionProof:       &proof,
+	}
+	assert.Error(t, verifier.Verify(sig))
+
+	// the integrated time is only trusted if it is signed, since the
+	// certificate must have been valid at that time.
+	sig.TlogEntry = &entry
+	sig.TlogEntry.SignedEntryTimestamp = nil
+	assert.Equal(t, sigstore.ErrNoSignedTimestamp, verifier.Verify(sig))
+}
+
+func TestVerifyKeylessSignatureTamperedTime(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+
+	sig := fixture.SignKeyless(sigstoretest.SimpleSigningPayload(t, vtesting.NewTestReference(t)), "", "release@example.com", testIssuer)
+	require.NotNil(t, sig.TlogEntry.InclusionProof)
+	require.NotEmpty(t, sig.TlogEntry.SignedEntryTimestamp)
+
+	identities := []sigstore.Identity{{Issuer: testIssuer, Subject: "release@example.com"}}
+	verifier := sigstore.NewVerifier(nil, fixture.TrustedRoot(), identities, false)
+	require.NoError(t, verifier.Verify(sig))
+
+	// the inclusion proof doesn't cover the integrated time, which the
+	// certificate's validity is checked at, so the signed entry timestamp
+	// must still be verified.
+	sig.TlogEntry.IntegratedTime--
+	assert.Error(t, verifier.Verify(sig))
+}
+
+func TestVerifyInTotoTlogEntry(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	fixture.InTotoEntries = true
+
+	statement := newTestStatement(t, sigstore.SignPredicateType)
+	sig := fixture.SignKeyless(statement, attestation.InTotoPayloadType, testSubject, testIssuer)
+	other := fixture.SignKeyless(statement, attestation.InTotoPayloadType, testSubject, testIssuer)
+
+	identities := []sigstore.Identity{{Issuer: testIssuer, Subject: testSubject}}
+	verifier := sigstore.NewVerifier(nil, fixture.TrustedRoot(), identities, false)
+	require.NoError(t, verifier.Verify(sig))
+
+	// an entry recording the same payload, signed by another key, doesn't
+	// record the signature.
+	sig.TlogEntry = other.TlogEntry
+	assert.EqualError(t, verifier.Verify(sig), "transparency log entry does not record the signature")
+}
+
+func TestVerifyKeylessSignatureIdentity(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+
+	sig := fixture.SignKeyless(sigstoretest.SimpleSigningPayload(t, vtesting.NewTestReference(t)), "", "release@example.com", testIssuer)
+
+	cases := []struct {
+		name     string
+		identity sigstore.Identity
+		trusted  bool
+	}{
+		{name: "subject", identity: sigstore.Identity{Issuer: testIssuer, Subject: "release@example.com"}, trusted: true},
+		{name: "subject regexp", identity: sigstore.Identity{Issuer: testIssuer, SubjectRegexp: ".*@example.com"}, trusted: true},
+		{name: "partial subject regexp", identity: sigstore.Identity{Issuer: testIssuer, SubjectRegexp: "release"}},
+		{name: "other subject", identity: sigstore.Identity{Issuer: testIssuer, Subject: "someone@example.com"}},
+		{name: "other issuer", identity: sigstore.Identity{Issuer: "https://accounts.google.com", Subject: "release@example.com"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verifier := sigstore.NewVerifier(nil, fixture.TrustedRoot(), []sigstore.Identity{c.identity}, false)
+
+			err := verifier.Verify(sig)
+			if c.trusted {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, sigstore.ErrUntrustedIdentity, err)
+			}
+		})
+	}
+}
+
+func TestVerifyKeylessSignatureFromOtherAuthority(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	other := sigstoretest.NewFixture(t)
+
+	sig := other.SignKeyless(sigstoretest.SimpleSigningPayload(t, vtesting.NewTestReference(t)), "", "release@example.com", testIssuer)
+
+	identities := []sigstore.Identity{{Issuer: testIssuer, Subject: "release@example.com"}}
+
+	verifier := sigstore.NewVerifier(nil, fixture.TrustedRoot(), identities, false)
+	assert.Equal(t, sigstore.ErrUnknownLog, verifier.Verify(sig))
+
+	verifier = sigstore.NewVerifier(nil, nil, identities, false)
+	assert.Equal(t, sigstore.ErrNoTrustedRoot, verifier.Verify(sig))
+}
+
+func TestIdentityValidate(t *testing.T) {
+	assert.Equal(t, sigstore.ErrIdentityNoIssuer, sigstore.Identity{Subject: "release@example.com"}.Validate())
+	assert.Equal(t, sigstore.ErrIdentityNoSubject, sigstore.Identity{Issuer: testIssuer}.Validate())
+	assert.Error(t, sigstore.Identity{Issuer: testIssuer, SubjectRegexp: "("}.Validate())
+	assert.NoError(t, sigstore.Identity{Issuer: testIssuer, Subject: "release@example.com"}.Validate())
+}
+
+func TestRequestSignaturesAndAttestations(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	keySig := fixture.SignWithKey(sigstoretest.SimpleSigningPayload(t, ref), "")
+	keylessSig := fixture.SignKeyless(sigstoretest.SimpleSigningPayload(t, ref), "", "release@example.com", testIssuer)
+	bundledSig := fixture.SignKeyless(newTestStatement(t, sigstore.SignPredicateType), attestation.InTotoPayloadType, testSubject, testIssuer)
+	keyAtt := fixture.SignWithKey(newTestStatement(t, "https://slsa.dev/provenance/v1"), attestation.InTotoPayloadType)
+	bundledAtt := fixture.SignKeyless(newTestStatement(t, "https://slsa.dev/provenance/v1"), attestation.InTotoPayloadType, testSubject, testIssuer)
+
+	server := fixture.NewRegistry(ref, keySig, keylessSig, bundledSig, keyAtt, bundledAtt)
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, server))
+
+	signatures, err := sigstore.RequestSignatures(client, ref)
+	require.NoError(t, err)
+	require.Len(t, signatures, 3)
+	assert.Equal(t, keySig.Signature, signatures[0].Signature)
+	assert.Equal(t, keylessSig.Certificate.Raw, signatures[1].Certificate.Raw)
+	assert.Equal(t, bundledSig.Signature, signatures[2].Signature)
+
+	attestations, err := sigstore.RequestAttestations(client, ref)
+	require.NoError(t, err)
+	require.Len(t, attestations, 2)
+	assert.Equal(t, keyAtt.Signature, attestations[0].Signature)
+	assert.Equal(t, bundledAtt.Signature, attestations[1].Signature)
+
+	// every signature read from the registry can be verified.
+	verifier := sigstore.NewVerifier(
+		[]crypto.PublicKey{fixture.PublicKey()},
+		fixture.TrustedRoot(),
+		[]sigstore.Identity{
+			{Issuer: testIssuer, Subject: "release@example.com"},
+			{Issuer: testIssuer, Subject: testSubject},
+		},
+		true,
+	)
+
+	for _, sig := range append(signatures, attestations...) {
+		assert.NoError(t, verifier.Verify(sig))
+	}
+}
+
+func TestRequestSignaturesUnsigned(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, fixture.NewRegistry(vtesting.NewNobodyBadTestReference(t))))
+
+	signatures, err := sigstore.RequestSignatures(client, ref)
+	require.NoError(t, err)
+	assert.Empty(t, signatures)
+
+	_, err = sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false).VerifyImage(signatures, ref.Digest())
+	assert.Equal(t, sigstore.ErrNoSignatures, err)
+}
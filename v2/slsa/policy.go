@@ -0,0 +1,147 @@
+package slsa
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxLevel is the highest SLSA build level.
+const MaxLevel = 3
+
+// ErrNoBuilders is the error returned when a Policy trusts no builders.
+var ErrNoBuilders = errors.New("slsa policy must trust at least one builder")
+
+// ErrUntrustedBuilder is the error returned when provenance was produced by a
+// builder which is not trusted.
+var ErrUntrustedBuilder = errors.New("provenance was not produced by a trusted builder")
+
+// ErrUntrustedSource is the error returned when provenance describes a build
+// from a source repository which is not trusted.
+var ErrUntrustedSource = errors.New("image was not built from a trusted source repository")
+
+// ErrUntrustedSourceRef is the error returned when provenance describes a
+// build from a git ref which is not trusted.
+var ErrUntrustedSourceRef = errors.New("image was not built from a trusted source ref")
+
+// ErrUntrustedBuildType is the error returned when provenance describes a
+// build of a type which is not trusted.
+var ErrUntrustedBuildType = errors.New("image was not built with a trusted build type")
+
+// ErrLevelTooLow is the error returned when provenance was produced by a
+// builder which does not meet the minimum SLSA build level.
+var ErrLevelTooLow = errors.New("builder does not meet the minimum SLSA level")
+
+// Builder is a trusted builder, and the SLSA build level it meets.
+type Builder struct {
+	ID    string `mapstructure:"id"`
+	Level int    `mapstructure:"level"`
+}
+
+// Matches returns true if the passed builder ID is this Builder's. Builder
+// IDs are compared without their version (the part after the "@") if this
+// Builder's ID has no version.
+func (b Builder) Matches(id string) bool {
+	if !strings.Contains(b.ID, "@") {
+		id = strings.SplitN(id, "@", 2)[0]
+	}
+
+	return b.ID == id
+}
+
+// level returns the SLSA build level that this Builder meets. Builders which
+// don't have a level meet level 1, as they produce provenance.
+func (b Builder) level() int {
+	if 0 == b.Level {
+		return 1
+	}
+	return b.Level
+}
+
+// Policy describes the provenance which is trusted. Source URIs, source refs
+// and build types are only checked if the Policy lists some.
+type Policy struct {
+	Builders   []Builder `mapstructure:"builders"`
+	SourceURIs []string  `mapstructure:"source_uris"`
+	SourceRefs []string  `mapstructure:"source_refs"`
+	BuildTypes []string  `mapstructure:"build_types"`
+	MinLevel   int       `mapstructure:"min_level"`
+}
+
+// Validate returns an error if the Policy is invalid.
+func (p Policy) Validate() error {
+	if 0 == len(p.Builders) {
+		return ErrNoBuilders
+	}
+
+	for _, builder := range p.Builders {
+		if "" == builder.ID {
+			return errors.New("slsa builder must have an id")
+		}
+
+		if 0 > builder.Level || MaxLevel < builder.Level {
+			return fmt.Errorf("invalid SLSA level %d for builder %s", builder.Level, builder.ID)
+		}
+	}
+
+	if 0 > p.MinLevel || MaxLevel < p.MinLevel {
+		return fmt.Errorf("invalid minimum SLSA level %d", p.MinLevel)
+	}
+
+	return nil
+}
+
+// Verify returns an error describing why the passed Provenance is not
+// trusted by the Policy, or nil if it is.
+func (p Policy) Verify(provenance Provenance) error {
+	builder, ok := p.findBuilder(provenance.BuilderID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUntrustedBuilder, provenance.BuilderID)
+	}
+
+	if builder.level() < p.MinLevel {
+		return fmt.Errorf("%w: %s is level %d", ErrLevelTooLow, builder.ID, builder.level())
+	}
+
+	if 0 < len(p.BuildTypes) && !contains(p.BuildTypes, provenance.BuildType, identity) {
+		return fmt.Errorf("%w: %s", ErrUntrustedBuildType, provenance.BuildType)
+	}
+
+	if 0 < len(p.SourceURIs) && !contains(p.SourceURIs, provenance.SourceURI, normalizeSourceURI) {
+		return fmt.Errorf("%w: %s", ErrUntrustedSource, provenance.SourceURI)
+	}
+
+	if 0 < len(p.SourceRefs) && !contains(p.SourceRefs, provenance.SourceRef, identity) {
+		return fmt.Errorf("%w: %s", ErrUntrustedSourceRef, provenance.SourceRef)
+	}
+
+	return nil
+}
+
+// findBuilder returns the trusted Builder with the passed ID.
+func (p Policy) findBuilder(id string) (Builder, bool) {
+	for _, builder := range p.Builders {
+		if builder.Matches(id) {
+			return builder, true
+		}
+	}
+
+	return Builder{}, false
+}
+
+// contains returns true if the passed value is in the passed list, comparing
+// values after they are normalized with the passed function.
+func contains(list []string, value string, normalize func(string) string) bool {
+	for _, item := range list {
+		if normalize(item) == normalize(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// identity returns the passed string.
+func identity(s string) string {
+	return s
+}
@@ -0,0 +1,89 @@
+package slsa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyVerify(t *testing.T) {
+	provenance := Provenance{
+		BuilderID: testBuilderID,
+		BuildType: testBuildType,
+		SourceURI: "git+https://github.com/grafeas/voucher",
+		SourceRef: "refs/heads/main",
+	}
+
+	cases := []struct {
+		name   string
+		policy Policy
+		err    error
+	}{
+		{
+			name:   "builder",
+			policy: Policy{Builders: []Builder{{ID: testBuilderID}}},
+		},
+		{
+			name:   "builder without version",
+			policy: Policy{Builders: []Builder{{ID: "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml", Level: 3}}, MinLevel: 3},
+		},
+		{
+			name:   "other builder version",
+			policy: Policy{Builders: []Builder{{ID: "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0"}}},
+			err:    ErrUntrustedBuilder,
+		},
+		{
+			name:   "level too low",
+			policy: Policy{Builders: []Builder{{ID: testBuilderID, Level: 2}}, MinLevel: 3},
+			err:    ErrLevelTooLow,
+		},
+		{
+			name:   "builders meet level 1",
+			policy: Policy{Builders: []Builder{{ID: testBuilderID}}, MinLevel: 2},
+			err:    ErrLevelTooLow,
+		},
+		{
+			name: "source, ref and build type",
+			policy: Policy{
+				Builders:   []Builder{{ID: testBuilderID}},
+				SourceURIs: []string{"https://github.com/grafeas/voucher.git"},
+				SourceRefs: []string{"refs/heads/main"},
+				BuildTypes: []string{testBuildType},
+			},
+		},
+		{
+			name:   "other source",
+			policy: Policy{Builders: []Builder{{ID: testBuilderID}}, SourceURIs: []string{"https://github.com/grafeas/grafeas"}},
+			err:    ErrUntrustedSource,
+		},
+		{
+			name:   "other ref",
+			policy: Policy{Builders: []Builder{{ID: testBuilderID}}, SourceRefs: []string{"refs/heads/production"}},
+			err:    ErrUntrustedSourceRef,
+		},
+		{
+			name:   "other build type",
+			policy: Policy{Builders: []Builder{{ID: testBuilderID}}, BuildTypes: []string{"https://tekton.dev/chains/v2/slsa"}},
+			err:    ErrUntrustedBuildType,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.policy.Verify(provenance)
+			if nil == c.err {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.Equal(t, ErrNoBuilders, Policy{}.Validate())
+	assert.EqualError(t, Policy{Builders: []Builder{{Level: 3}}}.Validate(), "slsa builder must have an id")
+	assert.EqualError(t, Policy{Builders: []Builder{{ID: testBuilderID, Level: 4}}}.Validate(), "invalid SLSA level 4 for builder "+testBuilderID)
+	assert.EqualError(t, Policy{Builders: []Builder{{ID: testBuilderID}}, MinLevel: -1}.Validate(), "invalid minimum SLSA level -1")
+	assert.NoError(t, Policy{Builders: []Builder{{ID: testBuilderID, Level: 3}}, MinLevel: 3}.Validate())
+}
@@ -0,0 +1,190 @@
+package slsa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/repository"
+)
+
+const (
+	// ProvenanceV02PredicateType is the predicate type of SLSA v0.2
+	// provenance.
+	ProvenanceV02PredicateType = "https://slsa.dev/provenance/v0.2"
+
+	// ProvenanceV1PredicateType is the predicate type of SLSA v1 provenance.
+	ProvenanceV1PredicateType = "https://slsa.dev/provenance/v1"
+)
+
+// ErrNotProvenance is the error returned when an in-toto statement does not
+// hold SLSA provenance.
+var ErrNotProvenance = errors.New("statement is not SLSA provenance")
+
+// Provenance describes how an image was built. It holds the parts of SLSA
+// v0.2 and v1 provenance that Voucher verifies.
+type Provenance struct {
+	PredicateType string                `json:"predicateType"`
+	BuilderID     string                `json:"builderId"`
+	BuildType     string                `json:"buildType"`
+	SourceURI     string                `json:"sourceUri,omitempty"`
+	SourceRef     string                `json:"sourceRef,omitempty"`
+	SourceDigest  string                `json:"sourceDigest,omitempty"`
+	InvocationID  string                `json:"invocationId,omitempty"`
+	Subjects      []attestation.Subject `json:"-"`
+}
+
+// provenanceV02 is the JSON format of SLSA v0.2 provenance.
+type provenanceV02 struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		ConfigSource resourceDescriptor `json:"configSource"`
+	} `json:"invocation"`
+	Metadata struct {
+		BuildInvocationID string `json:"buildInvocationId"`
+	} `json:"metadata"`
+	Materials []resourceDescriptor `json:"materials"`
+}
+
+// provenanceV1 is the JSON format of SLSA v1 provenance.
+type provenanceV1 struct {
+	BuildDefinition struct {
+		BuildType            string               `json:"buildType"`
+		ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Metadata struct {
+			InvocationID string `json:"invocationId"`
+		} `json:"metadata"`
+	} `json:"runDetails"`
+}
+
+// resourceDescriptor is an artifact referred to by provenance, such as the
+// source repository.
+type resourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ParseProvenance returns the Provenance held in the passed in-toto
+// statement. Returns ErrNotProvenance if the statement's predicate is not
+// SLSA v0.2 or v1 provenance.
+func ParseProvenance(statement attestation.Statement) (Provenance, error) {
+	provenance := Provenance{
+		PredicateType: statement.PredicateType,
+		Subjects:      statement.Subject,
+	}
+
+	var source resourceDescriptor
+
+	switch statement.PredicateType {
+	case ProvenanceV02PredicateType:
+		var predicate provenanceV02
+		if err := json.Unmarshal(statement.Predicate, &predicate); nil != err {
+			return Provenance{}, fmt.Errorf("parsing provenance: %w", err)
+		}
+
+		provenance.BuilderID = predicate.Builder.ID
+		provenance.BuildType = predicate.BuildType
+		provenance.InvocationID = predicate.Metadata.BuildInvocationID
+
+		source = predicate.Invocation.ConfigSource
+		if "" == source.URI {
+			source = findSource(predicate.Materials)
+		}
+	case ProvenanceV1PredicateType:
+		var predicate provenanceV1
+		if err := json.Unmarshal(statement.Predicate, &predicate); nil != err {
+			return Provenance{}, fmt.Errorf("parsing provenance: %w", err)
+		}
+
+		provenance.BuilderID = predicate.RunDetails.Builder.ID
+		provenance.BuildType = predicate.BuildDefinition.BuildType
+		provenance.InvocationID = predicate.RunDetails.Metadata.InvocationID
+
+		source = findSource(predicate.BuildDefinition.ResolvedDependencies)
+	default:
+		return Provenance{}, ErrNotProvenance
+	}
+
+	if "" == provenance.BuilderID {
+		return Provenance{}, errors.New("provenance has no builder ID")
+	}
+
+	provenance.SourceURI, provenance.SourceRef = splitSourceURI(source.URI)
+	provenance.SourceDigest = sourceDigest(source.Digest)
+
+	return provenance, nil
+}
+
+// findSource returns the first of the passed resources which is a git
+// repository, which builders list as the source of the build.
+func findSource(resources []resourceDescriptor) resourceDescriptor {
+	for _, resource := range resources {
+		if strings.HasPrefix(resource.URI, "git+") {
+			return resource
+		}
+	}
+
+	return resourceDescriptor{}
+}
+
+// splitSourceURI splits a source URI such as
+// "git+https://github.com/grafeas/voucher@refs/heads/main" into the
+// repository URI and the git ref.
+func splitSourceURI(uri string) (string, string) {
+	if i := strings.LastIndex(uri, "@"); -1 != i && strings.HasPrefix(uri[i+1:], "refs/") {
+		return uri[:i], uri[i+1:]
+	}
+
+	return uri, ""
+}
+
+// sourceDigest returns the commit in the passed source digest.
+func sourceDigest(digest map[string]string) string {
+	for _, algorithm := range []string{"gitCommit", "sha1", "sha256"} {
+		if value := digest[algorithm]; "" != value {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// normalizeSourceURI returns the passed source URI without the "git+" scheme
+// prefix or ".git" suffix, so that URIs of the same repository compare equal.
+func normalizeSourceURI(uri string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(uri, "git+"), ".git")
+}
+
+// BuildDetail returns a repository.BuildDetail describing the build in the
+// Provenance, for checks which look up build details from the metadata
+// service.
+func (p Provenance) BuildDetail() repository.BuildDetail {
+	buildDetail := repository.BuildDetail{
+		RepositoryURL: normalizeSourceURI(p.SourceURI),
+		Commit:        p.SourceDigest,
+		BuildCreator:  p.BuilderID,
+		BuildURL:      p.InvocationID,
+		Artifacts:     make([]repository.BuildArtifact, 0, len(p.Subjects)),
+	}
+
+	for _, subject := range p.Subjects {
+		for algorithm, value := range subject.Digest {
+			buildDetail.Artifacts = append(buildDetail.Artifacts, repository.BuildArtifact{
+				ID:       subject.Name,
+				Checksum: algorithm + ":" + value,
+			})
+		}
+	}
+
+	return buildDetail
+}
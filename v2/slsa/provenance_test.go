@@ -0,0 +1,110 @@
+package slsa
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/repository"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+const (
+	testBuilderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0"
+	testBuildType = "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"
+	testCommit    = "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"
+)
+
+const testProvenanceV1 = `{
+  "buildDefinition": {
+    "buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+    "externalParameters": {
+      "workflow": {"ref": "refs/heads/main", "repository": "https://github.com/grafeas/voucher", "path": ".github/workflows/release.yml"}
+    },
+    "resolvedDependencies": [
+      {"uri": "git+https://github.com/grafeas/voucher@refs/heads/main", "digest": {"gitCommit": "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"}}
+    ]
+  },
+  "runDetails": {
+    "builder": {"id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0"},
+    "metadata": {"invocationId": "https://github.com/grafeas/voucher/actions/runs/1/attempts/1"}
+  }
+}`
+
+const testProvenanceV02 = `{
+  "builder": {"id": "https://tekton.dev/chains/v2"},
+  "buildType": "tekton.dev/v1beta1/TaskRun",
+  "invocation": {
+    "configSource": {}
+  },
+  "metadata": {"buildInvocationId": "build-1234"},
+  "materials": [
+    {"uri": "oci://gcr.io/tekton-releases/git-init", "digest": {"sha256": "b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da"}},
+    {"uri": "git+https://github.com/grafeas/voucher.git", "digest": {"sha1": "2bd1c7a4a5bbf6ac12c8ba8cc6e0e9b4ee5e3d59"}}
+  ]
+}`
+
+// newTestStatement returns an in-toto statement about the test image, with
+// the passed predicate.
+func newTestStatement(t *testing.T, predicateType, predicate string) attestation.Statement {
+	t.Helper()
+
+	statement, err := attestation.NewStatement(vtesting.NewTestReference(t), predicateType, json.RawMessage(predicate))
+	require.NoError(t, err)
+
+	return statement
+}
+
+func TestParseProvenanceV1(t *testing.T) {
+	statement := newTestStatement(t, ProvenanceV1PredicateType, testProvenanceV1)
+
+	provenance, err := ParseProvenance(statement)
+	require.NoError(t, err)
+	assert.Equal(t, Provenance{
+		PredicateType: ProvenanceV1PredicateType,
+		BuilderID:     testBuilderID,
+		BuildType:     testBuildType,
+		SourceURI:     "git+https://github.com/grafeas/voucher",
+		SourceRef:     "refs/heads/main",
+		SourceDigest:  testCommit,
+		InvocationID:  "https://github.com/grafeas/voucher/actions/runs/1/attempts/1",
+		Subjects:      statement.Subject,
+	}, provenance)
+
+	assert.Equal(t, repository.BuildDetail{
+		RepositoryURL: "https://github.com/grafeas/voucher",
+		Commit:        testCommit,
+		BuildCreator:  testBuilderID,
+		BuildURL:      "https://github.com/grafeas/voucher/actions/runs/1/attempts/1",
+		Artifacts: []repository.BuildArtifact{
+			{ID: "localhost/path/to/image", Checksum: "sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da"},
+		},
+	}, provenance.BuildDetail())
+}
+
+func TestParseProvenanceV02(t *testing.T) {
+	provenance, err := ParseProvenance(newTestStatement(t, ProvenanceV02PredicateType, testProvenanceV02))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://tekton.dev/chains/v2", provenance.BuilderID)
+	assert.Equal(t, "tekton.dev/v1beta1/TaskRun", provenance.BuildType)
+	assert.Equal(t, "git+https://github.com/grafeas/voucher.git", provenance.SourceURI)
+	assert.Equal(t, "", provenance.SourceRef)
+	assert.Equal(t, testCommit, provenance.SourceDigest)
+	assert.Equal(t, "build-1234", provenance.InvocationID)
+	assert.Equal(t, "https://github.com/grafeas/voucher", provenance.BuildDetail().RepositoryURL)
+}
+
+func TestParseProvenanceErrors(t *testing.T) {
+	_, err := ParseProvenance(newTestStatement(t, "https://cyclonedx.org/bom", `{}`))
+	assert.Equal(t, ErrNotProvenance, err)
+
+	_, err = ParseProvenance(newTestStatement(t, ProvenanceV1PredicateType, `{}`))
+	assert.EqualError(t, err, "provenance has no builder ID")
+
+	_, err = ParseProvenance(newTestStatement(t, ProvenanceV1PredicateType, `{"runDetails": []}`))
+	assert.Error(t, err)
+}
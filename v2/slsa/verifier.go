@@ -0,0 +1,88 @@
+package slsa
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+
+	"github.com/grafeas/voucher/v2/sigstore"
+)
+
+// ErrNoProvenance is the error returned when an image has no signed SLSA
+// provenance.
+var ErrNoProvenance = errors.New("image has no SLSA provenance")
+
+// Verifier verifies the SLSA provenance attached to images.
+type Verifier struct {
+	signatures *sigstore.Verifier
+	policy     Policy
+}
+
+// NewVerifier creates a new Verifier, which trusts provenance that is signed
+// by a key or identity trusted by the passed sigstore.Verifier and satisfies
+// the passed Policy.
+func NewVerifier(signatures *sigstore.Verifier, policy Policy) *Verifier {
+	return &Verifier{
+		signatures: signatures,
+		policy:     policy,
+	}
+}
+
+// VerifyImage requests the attestations of the passed image, and returns the
+// first SLSA provenance which is trusted. Returns an error describing why the
+// provenance was rejected if none of it is trusted.
+func (v *Verifier) VerifyImage(client *http.Client, ref reference.Canonical) (Provenance, error) {
+	attestations, err := sigstore.RequestAttestations(client, ref)
+	if nil != err {
+		return Provenance{}, err
+	}
+
+	provenances := make([]*sigstore.Signature, 0, len(attestations))
+	for _, att := range attestations {
+		if statement, err := att.Statement(); nil == err && isProvenance(statement.PredicateType) {
+			provenances = append(provenances, att)
+		}
+	}
+
+	if 0 == len(provenances) {
+		return Provenance{}, ErrNoProvenance
+	}
+
+	verified, err := v.signatures.VerifyImage(provenances, ref.Digest())
+	if nil != err {
+		return Provenance{}, err
+	}
+
+	errs := make([]string, 0, len(verified))
+	for _, att := range verified {
+		statement, err := att.Statement()
+		if nil != err {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		provenance, err := ParseProvenance(statement)
+		if nil != err {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		if err = v.policy.Verify(provenance); nil != err {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		return provenance, nil
+	}
+
+	return Provenance{}, fmt.Errorf("no trusted provenance: %s", strings.Join(errs, "; "))
+}
+
+// isProvenance returns true if the passed predicate type is a SLSA
+// provenance predicate type.
+func isProvenance(predicateType string) bool {
+	return ProvenanceV02PredicateType == predicateType || ProvenanceV1PredicateType == predicateType
+}
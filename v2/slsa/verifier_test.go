@@ -0,0 +1,76 @@
+package slsa
+
+import (
+	"crypto"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafeas/voucher/v2/attestation"
+	"github.com/grafeas/voucher/v2/sigstore"
+	"github.com/grafeas/voucher/v2/sigstore/sigstoretest"
+	vtesting "github.com/grafeas/voucher/v2/testing"
+)
+
+// signTestProvenance returns the passed provenance, signed with the
+// fixture's key.
+func signTestProvenance(t *testing.T, fixture *sigstoretest.Fixture, predicateType, predicate string) *sigstore.Signature {
+	t.Helper()
+
+	statement, err := newTestStatement(t, predicateType, predicate).ToString()
+	require.NoError(t, err)
+
+	return fixture.SignWithKey([]byte(statement), attestation.InTotoPayloadType)
+}
+
+func TestVerifyImage(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	other := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	server := fixture.NewRegistry(
+		ref,
+		other.SignWithKey([]byte(`{}`), attestation.InTotoPayloadType),
+		signTestProvenance(t, fixture, ProvenanceV02PredicateType, testProvenanceV02),
+		signTestProvenance(t, fixture, ProvenanceV1PredicateType, testProvenanceV1),
+	)
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, server))
+
+	signatures := sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false)
+
+	verifier := NewVerifier(signatures, Policy{Builders: []Builder{{ID: testBuilderID, Level: 3}}, MinLevel: 3})
+	provenance, err := verifier.VerifyImage(client, ref)
+	require.NoError(t, err)
+	assert.Equal(t, testBuilderID, provenance.BuilderID)
+
+	verifier = NewVerifier(signatures, Policy{Builders: []Builder{{ID: "https://github.com/grafeas/builder"}}})
+	_, err = verifier.VerifyImage(client, ref)
+	assert.EqualError(t, err, "no trusted provenance: "+
+		"provenance was not produced by a trusted builder: https://tekton.dev/chains/v2; "+
+		"provenance was not produced by a trusted builder: "+testBuilderID)
+}
+
+func TestVerifyImageWithUntrustedProvenance(t *testing.T) {
+	fixture := sigstoretest.NewFixture(t)
+	other := sigstoretest.NewFixture(t)
+	ref := vtesting.NewTestReference(t)
+
+	client := &http.Client{}
+	require.NoError(t, vtesting.UpdateClient(client, fixture.NewRegistry(ref, signTestProvenance(t, other, ProvenanceV1PredicateType, testProvenanceV1))))
+
+	verifier := NewVerifier(
+		sigstore.NewVerifier([]crypto.PublicKey{fixture.PublicKey()}, nil, nil, false),
+		Policy{Builders: []Builder{{ID: testBuilderID}}},
+	)
+
+	_, err := verifier.VerifyImage(client, ref)
+	assert.EqualError(t, err, "no trusted signatures: "+sigstore.ErrUntrustedKey.Error())
+
+	require.NoError(t, vtesting.UpdateClient(client, fixture.NewRegistry(ref)))
+	_, err = verifier.VerifyImage(client, ref)
+	assert.Equal(t, ErrNoProvenance, err)
+}
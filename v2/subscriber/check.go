@@ -2,16 +2,18 @@ package subscriber
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/docker/distribution/reference"
 	voucher "github.com/grafeas/voucher/v2"
 	"github.com/grafeas/voucher/v2/cmd/config"
+	"github.com/grafeas/voucher/v2/notifier"
 	"github.com/grafeas/voucher/v2/repository"
 )
 
 // check runs all checks for a given image.
-// Returns true if the required check(s) have passed and true if the check run needs to be retried.
-func (s *Subscriber) check(canonicalImageReference reference.Canonical) (bool, bool) {
+// Returns true if the required check(s) have passed, and an error if the check run couldn't start.
+func (s *Subscriber) check(canonicalImageReference reference.Canonical) (bool, error) {
 	var repositoryClient repository.Client
 	var err error
 
@@ -20,14 +22,13 @@ func (s *Subscriber) check(canonicalImageReference reference.Canonical) (bool, b
 
 	metadataClient, err := config.NewMetadataClient(ctx, s.secrets)
 	if nil != err {
-		s.log.Errorf("failed to create MetadataClient: %s", err)
-		return false, true
+		return false, transient(fmt.Errorf("failed to create MetadataClient: %w", err))
 	}
 	defer metadataClient.Close()
 
-	buildDetail, err := metadataClient.GetBuildDetail(ctx, canonicalImageReference)
-	if nil != err {
-		s.log.Warningf("could not get image metadata for %s: %s", canonicalImageReference, err)
+	buildDetail, buildErr := metadataClient.GetBuildDetail(ctx, canonicalImageReference)
+	if nil != buildErr {
+		s.log.Warningf("could not get image metadata for %s: %s", canonicalImageReference, buildErr)
 	} else {
 		if s.secrets != nil {
 			repositoryClient, err = config.NewRepositoryClient(ctx, s.secrets.RepositoryAuthentication, buildDetail.RepositoryURL)
@@ -39,10 +40,11 @@ func (s *Subscriber) check(canonicalImageReference reference.Canonical) (bool, b
 		}
 	}
 
-	checksuite, err := config.NewCheckSuite(metadataClient, repositoryClient, s.cfg.RequiredChecks...)
+	checksuite, err := config.NewCheckSuite(s.secrets, metadataClient, repositoryClient, s.cfg.RequiredChecks...)
 	if nil != err {
-		s.log.Errorf("failed to create CheckSuite: %s", err)
-		return false, true
+		// the check suite is only misconfigured, which won't change if the
+		// message is delivered again.
+		return false, permanent(fmt.Errorf("failed to create CheckSuite: %w", err))
 	}
 
 	var results []voucher.CheckResult
@@ -55,5 +57,14 @@ func (s *Subscriber) check(canonicalImageReference reference.Canonical) (bool, b
 
 	checkResponse := voucher.NewResponse(canonicalImageReference, results)
 
-	return checkResponse.Success, false
+	if nil != s.cfg.Notifier && !checkResponse.Success {
+		// the subscriber runs the checks in the "all" group.
+		notification := notifier.NewNotification("all", canonicalImageReference, checkResponse)
+		if nil == buildErr {
+			notification.Build = &buildDetail
+		}
+		s.cfg.Notifier.Notify(notification)
+	}
+
+	return checkResponse.Success, nil
 }
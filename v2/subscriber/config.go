@@ -3,6 +3,9 @@ package subscriber
 import (
 	"time"
 
+	"cloud.google.com/go/pubsub"
+
+	"github.com/grafeas/voucher/v2/notifier"
 	"github.com/grafeas/voucher/v2/server"
 )
 
@@ -14,9 +17,99 @@ type Config struct {
 	RequiredChecks []string
 	DryRun         bool
 	Timeout        int
+	Notifier       *notifier.Dispatcher
+
+	MaxAttempts     int
+	MinBackoff      time.Duration
+	MaxBackoff      time.Duration
+	DeadLetterTopic string
+
+	WebhookAddress   string
+	WebhookSecret    string
+	WebhookWorkers   int
+	WebhookQueueSize int
+
+	PushAudience       string
+	PushServiceAccount string
 }
 
 // TimeoutDuration returns the configured timeout for this Server.
 func (c *Config) TimeoutDuration() time.Duration {
 	return time.Duration(c.Timeout) * time.Second
 }
+
+// WebhookWorkerCount returns the number of images pushed to registries that
+// are checked at once.
+func (c *Config) WebhookWorkerCount() int {
+	if c.WebhookWorkers <= 0 {
+		return DefaultWebhookWorkers
+	}
+	return c.WebhookWorkers
+}
+
+// WebhookQueueLimit returns the number of images pushed to registries that
+// can wait to be checked.
+func (c *Config) WebhookQueueLimit() int {
+	if c.WebhookQueueSize <= 0 {
+		return DefaultWebhookQueueSize
+	}
+	return c.WebhookQueueSize
+}
+
+// MaxAttemptCount returns the number of times a pulled message is delivered
+// before the Subscriber gives up on it.
+func (c *Config) MaxAttemptCount() int {
+	if c.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// Backoff returns how long to wait before a message which failed on the
+// passed delivery attempt is delivered again. The wait starts at MinBackoff
+// and doubles with each attempt, up to MaxBackoff.
+func (c *Config) Backoff(attempt int) time.Duration {
+	backoff, maxBackoff := c.minBackoff(), c.maxBackoff()
+
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// RetryPolicy returns the retry policy that pulled messages are delivered
+// again with, which backs off exponentially from MinBackoff to MaxBackoff.
+func (c *Config) RetryPolicy() *pubsub.RetryPolicy {
+	return &pubsub.RetryPolicy{
+		MinimumBackoff: c.minBackoff(),
+		MaximumBackoff: c.maxBackoff(),
+	}
+}
+
+func (c *Config) minBackoff() time.Duration {
+	if c.MinBackoff <= 0 {
+		return DefaultMinBackoff
+	}
+	return c.MinBackoff
+}
+
+func (c *Config) maxBackoff() time.Duration {
+	if c.MaxBackoff <= 0 {
+		return DefaultMaxBackoff
+	}
+	return c.MaxBackoff
+}
+
+// ValidatePush returns an error if Pub/Sub push is only partly configured.
+// Push requests can't be authenticated without both the audience and the
+// push service account.
+func (c *Config) ValidatePush() error {
+	if ("" == c.PushAudience) != ("" == c.PushServiceAccount) {
+		return errNoPushAudience
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/api/idtoken"
+)
+
+// PushPath is the path that the subscriber receives Pub/Sub push messages
+// on.
+const PushPath = "/pubsub/push"
+
+// maxPushSize is the largest push request that is read.
+const maxPushSize = 1 << 20
+
+var (
+	errNoPushAudience       = errors.New("pub/sub push requires an audience and a service account")
+	errNoBearerToken        = errors.New("request has no bearer token")
+	errWrongIssuer          = errors.New("token was not issued by Google")
+	errWrongServiceAccount  = errors.New("token is not for the push service account")
+	errUnverifiedTokenEmail = errors.New("token's email is not verified")
+)
+
+// tokenValidator validates an OIDC token for the passed audience.
+type tokenValidator func(ctx context.Context, token string, audience string) (*idtoken.Payload, error)
+
+// pushEnvelope is the body of a Pub/Sub push request.
+type pushEnvelope struct {
+	Message struct {
+		Data       []byte            `json:"data"`
+		Attributes map[string]string `json:"attributes"`
+		MessageID  string            `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// HandlePush is a request handler for a Pub/Sub push subscription. It checks
+// and attests the image in the pushed message, as Subscribe does for pulled
+// messages. It responds with a 2xx status once the message has been handled,
+// which acknowledges it, or a 5xx status if it should be delivered again.
+func (s *Subscriber) HandlePush(w http.ResponseWriter, r *http.Request) {
+	s.handlePush(w, r, idtoken.Validate)
+}
+
+func (s *Subscriber) handlePush(w http.ResponseWriter, r *http.Request, validate tokenValidator) {
+	defer r.Body.Close()
+
+	processStart := time.Now()
+	defer func(startTime time.Time) {
+		s.metrics.PubSubTotalLatency(time.Since(startTime))
+	}(processStart)
+
+	if err := s.authenticatePush(r, validate); nil != err {
+		http.Error(w, "push request is not authorized", http.StatusUnauthorized)
+		s.log.WithField("reason", err).Error("received unauthorized pub/sub push request")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPushSize)
+
+	var envelope pushEnvelope
+	if err := json.NewDecoder(r.Body).Decode(&envelope); nil != err {
+		// a message that can't be read won't be readable when it's
+		// delivered again.
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		s.log.WithField("reason", err).Error("couldn't parse pub/sub push request")
+		return
+	}
+
+	s.metrics.PubSubMessageReceived()
+
+	l := s.log.WithField("message", envelope.Message.MessageID)
+
+	if err := s.handle(l, envelope.Message.Data); isTransient(err) {
+		// pub/sub delivers the message again with the subscription's
+		// retry policy, and dead letters it with its dead letter policy.
+		s.metrics.PubSubMessageRetried()
+		http.Error(w, "the vouch needs to be retried", http.StatusServiceUnavailable)
+		return
+	} else if nil != err {
+		s.metrics.PubSubMessageDropped()
+		l.WithError(err).WithField("payload", string(envelope.Message.Data)).Error("dropping message, it can't be handled")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.metrics.PubSubMessageAcked()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authenticatePush checks that the request has an OIDC token, signed by
+// Google, for the configured audience and push service account. Anyone can
+// get a token signed by Google for any audience, so the service account must
+// be checked too.
+func (s *Subscriber) authenticatePush(r *http.Request, validate tokenValidator) error {
+	if "" == s.cfg.PushAudience || "" == s.cfg.PushServiceAccount {
+		return errNoPushAudience
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if "" == token || r.Header.Get("Authorization") == token {
+		return errNoBearerToken
+	}
+
+	payload, err := validate(r.Context(), token, s.cfg.PushAudience)
+	if nil != err {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+
+	if "accounts.google.com" != payload.Issuer && "https://accounts.google.com" != payload.Issuer {
+		return errWrongIssuer
+	}
+
+	if email, _ := payload.Claims["email"].(string); email != s.cfg.PushServiceAccount {
+		return errWrongServiceAccount
+	}
+
+	if verified, _ := payload.Claims["email_verified"].(bool); !verified {
+		return errUnverifiedTokenEmail
+	}
+
+	return nil
+}
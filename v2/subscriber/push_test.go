@@ -0,0 +1,116 @@
+package subscriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/idtoken"
+
+	"github.com/grafeas/voucher/v2/metrics"
+)
+
+const (
+	testAudience       = "https://voucher.example.com/pubsub/push"
+	testServiceAccount = "pubsub-push@my-project.iam.gserviceaccount.com"
+)
+
+// testValidator accepts "good-token" as a token for the passed claims.
+func testValidator(claims map[string]interface{}) tokenValidator {
+	return func(_ context.Context, token string, audience string) (*idtoken.Payload, error) {
+		if "good-token" != token || testAudience != audience {
+			return nil, errors.New("idtoken: invalid token")
+		}
+
+		return &idtoken.Payload{
+			Issuer:   "https://accounts.google.com",
+			Audience: audience,
+			Claims:   claims,
+		}, nil
+	}
+}
+
+func pushRequest(t *testing.T, token string, data []byte) *http.Request {
+	t.Helper()
+
+	var envelope pushEnvelope
+	envelope.Message.Data = data
+	envelope.Message.MessageID = "2070443601311540"
+	envelope.Subscription = "projects/my-project/subscriptions/voucher"
+
+	body, err := json.Marshal(envelope)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, PushPath, bytes.NewReader(body))
+	require.NoError(t, err)
+	if "" != token {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestHandlePush(t *testing.T) {
+	s := NewSubscriber(&Config{
+		PushAudience:       testAudience,
+		PushServiceAccount: testServiceAccount,
+	}, nil, &metrics.NoopClient{}, logrus.New())
+
+	validator := testValidator(map[string]interface{}{
+		"email":          testServiceAccount,
+		"email_verified": true,
+	})
+
+	push := func(req *http.Request, validate tokenValidator) int {
+		recorder := httptest.NewRecorder()
+		s.handlePush(recorder, req, validate)
+		return recorder.Code
+	}
+
+	deleted := []byte(`{"action": "DELETE", "tag": "gcr.io/my-project/app:1.0"}`)
+
+	// messages which aren't inserts are acknowledged.
+	assert.Equal(t, http.StatusNoContent, push(pushRequest(t, "good-token", deleted), validator))
+
+	assert.Equal(t, http.StatusUnauthorized, push(pushRequest(t, "", deleted), validator))
+	assert.Equal(t, http.StatusUnauthorized, push(pushRequest(t, "bad-token", deleted), validator))
+
+	otherAccount := testValidator(map[string]interface{}{
+		"email":          "someone@my-project.iam.gserviceaccount.com",
+		"email_verified": true,
+	})
+	assert.Equal(t, http.StatusUnauthorized, push(pushRequest(t, "good-token", deleted), otherAccount))
+
+	unverified := testValidator(map[string]interface{}{
+		"email": testServiceAccount,
+	})
+	assert.Equal(t, http.StatusUnauthorized, push(pushRequest(t, "good-token", deleted), unverified))
+
+	req, err := http.NewRequest(http.MethodPost, PushPath, bytes.NewReader([]byte(`{"message": `)))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer good-token")
+	assert.Equal(t, http.StatusBadRequest, push(req, validator))
+
+	// requests larger than maxPushSize aren't read.
+	assert.Equal(t, http.StatusBadRequest, push(pushRequest(t, "good-token", make([]byte, maxPushSize)), validator))
+}
+
+func TestAuthenticatePushRequiresConfig(t *testing.T) {
+	s := NewSubscriber(&Config{PushAudience: testAudience}, nil, &metrics.NoopClient{}, logrus.New())
+
+	err := s.authenticatePush(pushRequest(t, "good-token", nil), testValidator(nil))
+	assert.Equal(t, errNoPushAudience, err)
+}
+
+func TestValidatePush(t *testing.T) {
+	assert.NoError(t, (&Config{}).ValidatePush())
+	assert.NoError(t, (&Config{PushAudience: testAudience, PushServiceAccount: testServiceAccount}).ValidatePush())
+	assert.Equal(t, errNoPushAudience, (&Config{PushAudience: testAudience}).ValidatePush())
+	assert.Equal(t, errNoPushAudience, (&Config{PushServiceAccount: testServiceAccount}).ValidatePush())
+}
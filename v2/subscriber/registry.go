@@ -0,0 +1,196 @@
+package subscriber
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+)
+
+// registryParser reads the images that were pushed from a registry's
+// notification.
+type registryParser func(notification []byte) ([]reference.Canonical, error)
+
+// registryParsers are the parsers for the notifications of each registry that
+// the subscriber accepts webhooks from.
+var registryParsers = map[string]registryParser{
+	"distribution": parseDistributionNotification,
+	"harbor":       parseHarborNotification,
+	"artifactory":  parseArtifactoryNotification,
+}
+
+// distributionNotification is a Docker Distribution notification envelope.
+type distributionNotification struct {
+	Events []struct {
+		Action string `json:"action"`
+		Target struct {
+			Digest     string `json:"digest"`
+			Repository string `json:"repository"`
+			URL        string `json:"url"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	} `json:"events"`
+}
+
+// parseDistributionNotification returns the manifests pushed in a Docker
+// Distribution notification. Pushed blobs are ignored.
+func parseDistributionNotification(notification []byte) ([]reference.Canonical, error) {
+	var envelope distributionNotification
+	if err := json.Unmarshal(notification, &envelope); nil != err {
+		return nil, err
+	}
+
+	images := make([]reference.Canonical, 0, len(envelope.Events))
+	for _, event := range envelope.Events {
+		if "push" != event.Action || !strings.Contains(event.Target.URL, "/manifests/") {
+			continue
+		}
+
+		host := event.Request.Host
+		if targetURL, err := url.Parse(event.Target.URL); nil == err && "" != targetURL.Host {
+			host = targetURL.Host
+		}
+
+		image, err := canonicalReference(host+"/"+event.Target.Repository, event.Target.Digest)
+		if nil != err {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// harborNotification is a Harbor webhook payload.
+type harborNotification struct {
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Digest      string `json:"digest"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+}
+
+// parseHarborNotification returns the artifacts pushed in a Harbor
+// PUSH_ARTIFACT notification. Other notifications are ignored.
+func parseHarborNotification(notification []byte) ([]reference.Canonical, error) {
+	var payload harborNotification
+	if err := json.Unmarshal(notification, &payload); nil != err {
+		return nil, err
+	}
+
+	if "PUSH_ARTIFACT" != payload.Type {
+		return []reference.Canonical{}, nil
+	}
+
+	images := make([]reference.Canonical, 0, len(payload.EventData.Resources))
+	for _, resource := range payload.EventData.Resources {
+		image, err := canonicalReference(resource.ResourceURL, resource.Digest)
+		if nil != err {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// artifactoryNotification is an Artifactory webhook payload.
+type artifactoryNotification struct {
+	Domain    string `json:"domain"`
+	EventType string `json:"event_type"`
+	Origin    string `json:"jpd_origin"`
+	Data      struct {
+		RepoKey   string `json:"repo_key"`
+		ImageName string `json:"image_name"`
+		SHA256    string `json:"sha256"`
+	} `json:"data"`
+}
+
+// parseArtifactoryNotification returns the image pushed in an Artifactory
+// docker "pushed" notification. Other notifications are ignored. The image is
+// named for the repository path access method, as
+// "<host>/<repository key>/<image name>".
+func parseArtifactoryNotification(notification []byte) ([]reference.Canonical, error) {
+	var payload artifactoryNotification
+	if err := json.Unmarshal(notification, &payload); nil != err {
+		return nil, err
+	}
+
+	if "docker" != payload.Domain || "pushed" != payload.EventType {
+		return []reference.Canonical{}, nil
+	}
+
+	origin, err := url.Parse(payload.Origin)
+	if nil != err {
+		return nil, err
+	}
+
+	// the sha256 is the checksum of the manifest file, which is the
+	// image's digest.
+	image, err := canonicalReference(
+		origin.Host+"/"+payload.Data.RepoKey+"/"+payload.Data.ImageName,
+		string(digest.SHA256)+":"+payload.Data.SHA256,
+	)
+	if nil != err {
+		return nil, err
+	}
+
+	return []reference.Canonical{image}, nil
+}
+
+// canonicalReference returns the canonical reference for the image with the
+// passed name, ignoring any tag or digest it has, and the passed digest.
+func canonicalReference(name string, imageDigest string) (reference.Canonical, error) {
+	named, err := reference.ParseNormalizedNamed(name)
+	if nil != err {
+		return nil, err
+	}
+
+	parsedDigest, err := digest.Parse(imageDigest)
+	if nil != err {
+		return nil, err
+	}
+
+	return reference.WithDigest(reference.TrimNamed(named), parsedDigest)
+}
+
+// isAuthenticWebhook returns true if the request was sent by a registry which
+// knows the secret. The registry can send the secret in the Authorization
+// header, with or without the "Bearer" scheme, or in the X-JFrog-Event-Auth
+// header, or sign the body with an HMAC-SHA256 in the X-JFrog-Event-Auth
+// header or in the X-Hub-Signature-256 header.
+func isAuthenticWebhook(r *http.Request, body []byte, secret string) bool {
+	if "" == secret {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	authorization := r.Header.Get("Authorization")
+	jfrog := r.Header.Get("X-JFrog-Event-Auth")
+	hub := r.Header.Get("X-Hub-Signature-256")
+
+	return equal(authorization, secret) ||
+		equal(authorization, "Bearer "+secret) ||
+		equal(jfrog, secret) ||
+		equal(jfrog, signature) ||
+		equal(hub, "sha256="+signature)
+}
+
+// equal compares the header value to the expected value in constant time.
+func equal(value string, expected string) bool {
+	return "" != value && 1 == subtle.ConstantTimeCompare([]byte(value), []byte(expected))
+}
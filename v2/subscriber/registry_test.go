@@ -0,0 +1,169 @@
+package subscriber
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2"
+
+var distributionNotificationBody = []byte(`{
+	"events": [
+		{
+			"id": "asdf-asdf-asdf-asdf-0",
+			"action": "push",
+			"target": {
+				"mediaType": "application/octet-stream",
+				"digest": "sha256:fea8895f450959fa676bcc1df0611ea93823a735a01205fd8622846041d0c7cf",
+				"repository": "team/app",
+				"url": "https://registry.example.com/v2/team/app/blobs/sha256:fea8895f450959fa676bcc1df0611ea93823a735a01205fd8622846041d0c7cf"
+			},
+			"request": {"host": "registry.example.com", "method": "PUT"}
+		},
+		{
+			"id": "asdf-asdf-asdf-asdf-1",
+			"action": "push",
+			"target": {
+				"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+				"digest": "` + testDigest + `",
+				"repository": "team/app",
+				"url": "https://registry.example.com/v2/team/app/manifests/` + testDigest + `",
+				"tag": "1.0"
+			},
+			"request": {"host": "registry.example.com", "method": "PUT"}
+		},
+		{
+			"id": "asdf-asdf-asdf-asdf-2",
+			"action": "pull",
+			"target": {
+				"digest": "` + testDigest + `",
+				"repository": "team/app",
+				"url": "https://registry.example.com/v2/team/app/manifests/` + testDigest + `"
+			},
+			"request": {"host": "registry.example.com", "method": "GET"}
+		}
+	]
+}`)
+
+var harborNotificationBody = []byte(`{
+	"type": "PUSH_ARTIFACT",
+	"occur_at": 1680000000,
+	"operator": "admin",
+	"event_data": {
+		"resources": [
+			{
+				"digest": "` + testDigest + `",
+				"tag": "1.0",
+				"resource_url": "harbor.example.com/library/app:1.0"
+			}
+		],
+		"repository": {"name": "app", "namespace": "library", "repo_full_name": "library/app", "repo_type": "private"}
+	}
+}`)
+
+var artifactoryNotificationBody = []byte(`{
+	"domain": "docker",
+	"event_type": "pushed",
+	"data": {
+		"repo_key": "docker-local",
+		"event_type": "pushed",
+		"path": "app/1.0/manifest.json",
+		"name": "manifest.json",
+		"sha256": "cb749360c5198a55859a7f335de3cf4e2f64b60886a2098684a2f9c7ffca81f2",
+		"size": 1201,
+		"image_name": "app",
+		"tag": "1.0"
+	},
+	"subscription_key": "voucher",
+	"jpd_origin": "https://example.jfrog.io",
+	"source": "jfrog/user@example.com"
+}`)
+
+func TestRegistryParsers(t *testing.T) {
+	testCases := []struct {
+		registry     string
+		notification []byte
+		expected     string
+	}{
+		{"distribution", distributionNotificationBody, "registry.example.com/team/app@" + testDigest},
+		{"harbor", harborNotificationBody, "harbor.example.com/library/app@" + testDigest},
+		{"artifactory", artifactoryNotificationBody, "example.jfrog.io/docker-local/app@" + testDigest},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.registry, func(t *testing.T) {
+			images, err := registryParsers[testCase.registry](testCase.notification)
+			require.NoError(t, err)
+			require.Len(t, images, 1)
+			assert.Equal(t, testCase.expected, images[0].String())
+		})
+	}
+}
+
+func TestRegistryParsersIgnoreOtherEvents(t *testing.T) {
+	images, err := parseHarborNotification([]byte(`{"type": "DELETE_ARTIFACT", "event_data": {"resources": [{"digest": "` + testDigest + `", "resource_url": "harbor.example.com/library/app:1.0"}]}}`))
+	require.NoError(t, err)
+	assert.Empty(t, images)
+
+	images, err = parseArtifactoryNotification([]byte(`{"domain": "docker", "event_type": "deleted"}`))
+	require.NoError(t, err)
+	assert.Empty(t, images)
+
+	images, err = parseDistributionNotification([]byte(`{"events": []}`))
+	require.NoError(t, err)
+	assert.Empty(t, images)
+
+	_, err = parseHarborNotification([]byte(`{"type": "PUSH_ARTIFACT", "event_data": {"resources": [{"digest": "not a digest", "resource_url": "harbor.example.com/library/app:1.0"}]}}`))
+	assert.Error(t, err)
+}
+
+func TestCanonicalReference(t *testing.T) {
+	image, err := canonicalReference("app:1.0", testDigest)
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io/library/app@"+testDigest, image.String())
+}
+
+func TestIsAuthenticWebhook(t *testing.T) {
+	body := []byte(`{"events": []}`)
+	secret := "hunter2"
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	testCases := []struct {
+		name    string
+		header  string
+		value   string
+		secret  string
+		success bool
+	}{
+		{"authorization", "Authorization", secret, secret, true},
+		{"bearer", "Authorization", "Bearer " + secret, secret, true},
+		{"jfrog secret", "X-JFrog-Event-Auth", secret, secret, true},
+		{"jfrog signature", "X-JFrog-Event-Auth", signature, secret, true},
+		{"hub signature", "X-Hub-Signature-256", "sha256=" + signature, secret, true},
+		{"wrong secret", "Authorization", "Bearer hunter3", secret, false},
+		{"unsigned hub", "X-Hub-Signature-256", secret, secret, false},
+		{"no header", "", "", secret, false},
+		{"no secret", "Authorization", "", "", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPost, "/registry/distribution", nil)
+			require.NoError(t, err)
+			if "" != testCase.header {
+				r.Header.Set(testCase.header, testCase.value)
+			}
+
+			assert.Equal(t, testCase.success, isAuthenticWebhook(r, body, testCase.secret))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times a pulled message is delivered
+	// before the Subscriber gives up on it, if the number isn't configured.
+	DefaultMaxAttempts = 5
+
+	// DefaultMinBackoff is how long to wait before a message which failed
+	// for the first time is delivered again, if the wait isn't configured.
+	DefaultMinBackoff = 10 * time.Second
+
+	// DefaultMaxBackoff is the longest to wait before a failed message is
+	// delivered again, if the wait isn't configured. This is the longest
+	// backoff pub/sub allows.
+	DefaultMaxBackoff = 10 * time.Minute
+)
+
+// The attributes added to messages published to the dead letter topic.
+const (
+	FailureReasonAttribute    = "voucher_failure_reason"
+	DeliveryAttemptsAttribute = "voucher_delivery_attempts"
+	MessageIDAttribute        = "voucher_message_id"
+	SubscriptionAttribute     = "voucher_subscription"
+)
+
+// deliveryError is an error handling a message, which is transient if the
+// message may be handled when it is delivered again.
+type deliveryError struct {
+	err       error
+	transient bool
+}
+
+func (e *deliveryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *deliveryError) Unwrap() error {
+	return e.err
+}
+
+// transient marks the passed error as one which may not happen if the message
+// is delivered again.
+func transient(err error) error {
+	return &deliveryError{err: err, transient: true}
+}
+
+// permanent marks the passed error as one which will happen every time the
+// message is delivered.
+func permanent(err error) error {
+	return &deliveryError{err: err}
+}
+
+// isTransient returns true if the passed error may not happen if the message
+// is delivered again.
+func isTransient(err error) bool {
+	var deliveryErr *deliveryError
+	return errors.As(err, &deliveryErr) && deliveryErr.transient
+}
+
+// publisher publishes messages to a topic.
+type publisher interface {
+	Publish(ctx context.Context, msg *pubsub.Message) error
+}
+
+// topicPublisher is a publisher which waits for each message to be published
+// to a pub/sub topic.
+type topicPublisher struct {
+	topic *pubsub.Topic
+}
+
+func (t *topicPublisher) Publish(ctx context.Context, msg *pubsub.Message) error {
+	_, err := t.topic.Publish(ctx, msg).Get(ctx)
+	return err
+}
+
+// attempts counts the deliveries of pulled messages, for subscriptions which
+// don't have a dead letter policy, where pub/sub doesn't count them.
+type attempts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newAttempts() *attempts {
+	return &attempts{counts: make(map[string]int)}
+}
+
+// next returns the number of times the message has been delivered, including
+// this time.
+func (a *attempts) next(msg *pubsub.Message) int {
+	if nil != msg.DeliveryAttempt {
+		return *msg.DeliveryAttempt
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counts[msg.ID]++
+	return a.counts[msg.ID]
+}
+
+// forget stops counting the deliveries of the message, once it won't be
+// delivered again.
+func (a *attempts) forget(msg *pubsub.Message) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.counts, msg.ID)
+}
+
+// process handles a pulled message, and returns true if it should be acked,
+// or false if it should be delivered again. Messages which fail with a
+// transient error are nacked, and delivered again after the backoff of the
+// subscription's retry policy, which Subscribe sets from the configured
+// backoff, until they reach the maximum number of attempts. Messages which can't be handled are published
+// to the dead letter topic, if there is one, with the reason they failed.
+func (s *Subscriber) process(ctx context.Context, msg *pubsub.Message) bool {
+	l := s.log.WithField("message", msg.ID)
+
+	err := s.handle(l, msg.Data)
+	if nil == err {
+		s.attempts.forget(msg)
+		s.metrics.PubSubMessageAcked()
+		return true
+	}
+
+	attempt := s.attempts.next(msg)
+	l = l.WithError(err).WithField("attempt", attempt)
+
+	if isTransient(err) && attempt < s.cfg.MaxAttemptCount() {
+		l.Warning("message failed, it will be delivered again")
+		s.metrics.PubSubMessageRetried()
+		return false
+	}
+
+	if isTransient(err) {
+		err = fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+	}
+
+	if nil == s.deadLetters {
+		s.attempts.forget(msg)
+		s.metrics.PubSubMessageDropped()
+		l.WithField("payload", string(msg.Data)).Error("dropping message, there is no dead letter topic")
+		return true
+	}
+
+	if publishErr := s.deadLetter(ctx, msg, attempt, err); nil != publishErr {
+		// keep the message, rather than lose it.
+		s.metrics.PubSubMessageRetried()
+		l.WithField("reason", publishErr).Error("couldn't publish message to the dead letter topic, it will be delivered again")
+		return false
+	}
+
+	s.attempts.forget(msg)
+	s.metrics.PubSubMessageDeadLettered()
+	l.Error("published message to the dead letter topic")
+	return true
+}
+
+// deadLetter publishes the message to the dead letter topic, with the reason
+// it failed.
+func (s *Subscriber) deadLetter(ctx context.Context, msg *pubsub.Message, attempt int, reason error) error {
+	attributes := make(map[string]string, len(msg.Attributes)+4)
+	for key, value := range msg.Attributes {
+		attributes[key] = value
+	}
+
+	attributes[FailureReasonAttribute] = reason.Error()
+	attributes[DeliveryAttemptsAttribute] = strconv.Itoa(attempt)
+	if "" != msg.ID {
+		attributes[MessageIDAttribute] = msg.ID
+	}
+	if "" != s.cfg.Subscription {
+		attributes[SubscriptionAttribute] = s.cfg.Subscription
+	}
+
+	return s.deadLetters.Publish(ctx, &pubsub.Message{
+		Data:       msg.Data,
+		Attributes: attributes,
+	})
+}
+
+// handle checks and attests the image in the data of a pub/sub message. It
+// returns nil once the message has been handled, even if the image failed its
+// checks, or if the message isn't about a pushed image.
+func (s *Subscriber) handle(l *logrus.Entry, data []byte) error {
+	pl, err := parsePayload(data)
+	if errNotInsertAction == err || errNoDigest == err {
+		l.WithField("reason", err).Debug("ignoring pub/sub message")
+		return nil
+	}
+
+	if nil != err {
+		return permanent(fmt.Errorf("couldn't parse pub/sub payload: %w", err))
+	}
+
+	cir, err := pl.asCanonicalImage()
+	if nil != err {
+		return permanent(fmt.Errorf("couldn't make canonical image: %w", err))
+	}
+
+	return s.vouch(l.WithField("payload", pl), cir)
+}
@@ -0,0 +1,186 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+
+	"github.com/grafeas/voucher/v2/cmd/config"
+	"github.com/grafeas/voucher/v2/metrics"
+)
+
+// outcomeMetrics is a metrics.Client which counts the outcomes of messages.
+type outcomeMetrics struct {
+	metrics.NoopClient
+	acked, retried, deadLettered, dropped int
+}
+
+func (m *outcomeMetrics) PubSubMessageAcked()        { m.acked++ }
+func (m *outcomeMetrics) PubSubMessageRetried()      { m.retried++ }
+func (m *outcomeMetrics) PubSubMessageDeadLettered() { m.deadLettered++ }
+func (m *outcomeMetrics) PubSubMessageDropped()      { m.dropped++ }
+
+// testPublisher is a publisher which keeps the messages it publishes.
+type testPublisher struct {
+	messages []*pubsub.Message
+	err      error
+}
+
+func (p *testPublisher) Publish(_ context.Context, msg *pubsub.Message) error {
+	if nil != p.err {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func TestProcessDeadLetters(t *testing.T) {
+	metricsClient := &outcomeMetrics{}
+	deadLetters := &testPublisher{}
+
+	s := NewSubscriber(&Config{Subscription: "voucher"}, nil, metricsClient, logrus.New())
+	s.deadLetters = deadLetters
+
+	// messages which aren't about pushed images are acked.
+	ignored := &pubsub.Message{ID: "1", Data: []byte(`{"action": "DELETE", "tag": "gcr.io/my-project/app:1.0"}`)}
+	assert.True(t, s.process(context.Background(), ignored))
+	assert.Equal(t, 1, metricsClient.acked)
+
+	broken := &pubsub.Message{
+		ID:         "2",
+		Data:       []byte(`{"action": "INSERT", "digest": "not an image"}`),
+		Attributes: map[string]string{"origin": "gcr"},
+	}
+	assert.True(t, s.process(context.Background(), broken))
+	assert.Equal(t, 1, metricsClient.deadLettered)
+
+	require.Len(t, deadLetters.messages, 1)
+	published := deadLetters.messages[0]
+	assert.Equal(t, broken.Data, published.Data)
+	assert.Equal(t, "gcr", published.Attributes["origin"])
+	assert.Equal(t, "1", published.Attributes[DeliveryAttemptsAttribute])
+	assert.Equal(t, "2", published.Attributes[MessageIDAttribute])
+	assert.Equal(t, "voucher", published.Attributes[SubscriptionAttribute])
+	assert.Contains(t, published.Attributes[FailureReasonAttribute], "couldn't make canonical image")
+
+	// a message which can't be published to the dead letter topic is kept.
+	deadLetters.err = errors.New("topic not found")
+	assert.False(t, s.process(context.Background(), broken))
+	assert.Equal(t, 1, metricsClient.retried)
+
+	// without a dead letter topic, the message is dropped.
+	s.deadLetters = nil
+	assert.True(t, s.process(context.Background(), &pubsub.Message{ID: "3", Data: []byte(`{"action": `)}))
+	assert.Equal(t, 1, metricsClient.dropped)
+}
+
+func TestAttempts(t *testing.T) {
+	a := newAttempts()
+
+	msg := &pubsub.Message{ID: "1"}
+	assert.Equal(t, 1, a.next(msg))
+	assert.Equal(t, 2, a.next(msg))
+
+	a.forget(msg)
+	assert.Equal(t, 1, a.next(msg))
+
+	// pub/sub's count is used when the subscription has a dead letter policy.
+	attempt := 4
+	assert.Equal(t, 4, a.next(&pubsub.Message{ID: "2", DeliveryAttempt: &attempt}))
+}
+
+func TestProcessUnknownCheck(t *testing.T) {
+	settings := map[string]string{"metadata_client": "local", "scanner": "metadata", "failon": "high"}
+	for key, value := range settings {
+		previous := viper.GetString(key)
+		viper.Set(key, value)
+		defer viper.Set(key, previous)
+	}
+	defer config.CloseMetadataClients()
+
+	metricsClient := &outcomeMetrics{}
+	deadLetters := &testPublisher{}
+
+	s := NewSubscriber(&Config{RequiredChecks: []string{"no-such-check"}}, nil, metricsClient, logrus.New())
+	s.deadLetters = deadLetters
+
+	// a misconfigured check suite won't be fixed by delivering the message
+	// again, so it is dead lettered at once.
+	msg := &pubsub.Message{ID: "1", Data: []byte(`{"action": "INSERT", "digest": "gcr.io/my-project/app@` + testDigest + `"}`)}
+	assert.True(t, s.process(context.Background(), msg))
+	assert.Equal(t, 0, metricsClient.retried)
+	assert.Equal(t, 1, metricsClient.deadLettered)
+
+	require.Len(t, deadLetters.messages, 1)
+	assert.Contains(t, deadLetters.messages[0].Attributes[FailureReasonAttribute], "failed to create CheckSuite")
+}
+
+func TestBackoff(t *testing.T) {
+	c := &Config{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, c.Backoff(1))
+	assert.Equal(t, 2*time.Second, c.Backoff(2))
+	assert.Equal(t, 4*time.Second, c.Backoff(3))
+	assert.Equal(t, 5*time.Second, c.Backoff(4))
+	assert.Equal(t, 5*time.Second, c.Backoff(100))
+	assert.Equal(t, 3, c.MaxAttemptCount())
+
+	defaults := &Config{}
+	assert.Equal(t, DefaultMinBackoff, defaults.Backoff(1))
+	assert.Equal(t, DefaultMaxBackoff, defaults.Backoff(100))
+	assert.Equal(t, DefaultMaxAttempts, defaults.MaxAttemptCount())
+}
+
+func TestSetRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	srv := pstest.NewServer()
+	defer srv.Close()
+
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := pubsub.NewClient(ctx, "my-project", option.WithGRPCConn(conn))
+	require.NoError(t, err)
+	defer client.Close()
+
+	topic, err := client.CreateTopic(ctx, "gcr")
+	require.NoError(t, err)
+
+	sub, err := client.CreateSubscription(ctx, "voucher", pubsub.SubscriptionConfig{Topic: topic})
+	require.NoError(t, err)
+
+	s := NewSubscriber(&Config{MinBackoff: 30 * time.Second}, nil, &metrics.NoopClient{}, logrus.New())
+	require.NoError(t, s.setRetryPolicy(ctx, sub))
+
+	cfg, err := sub.Config(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.RetryPolicy)
+	assert.Equal(t, 30*time.Second, cfg.RetryPolicy.MinimumBackoff)
+	assert.Equal(t, DefaultMaxBackoff, cfg.RetryPolicy.MaximumBackoff)
+
+	// the subscriber won't start if the retry policy can't be set.
+	assert.Error(t, s.setRetryPolicy(ctx, client.Subscription("missing")))
+}
+
+func TestIsTransient(t *testing.T) {
+	err := errors.New("metadata server unavailable")
+
+	assert.True(t, isTransient(transient(err)))
+	assert.True(t, isTransient(fmt.Errorf("gave up: %w", transient(err))))
+	assert.False(t, isTransient(permanent(err)))
+	assert.False(t, isTransient(err))
+	assert.False(t, isTransient(nil))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/pubsub"
+	"github.com/docker/distribution/reference"
 	"github.com/grafeas/voucher/v2/cmd/config"
 	"github.com/grafeas/voucher/v2/metrics"
 	"github.com/sirupsen/logrus"
@@ -17,6 +18,9 @@ type Subscriber struct {
 	secrets *config.Secrets
 	metrics metrics.Client
 	log     *logrus.Logger
+
+	attempts    *attempts
+	deadLetters publisher
 }
 
 // NewSubscriber creates a new subscription topic puller for a subscription.
@@ -26,6 +30,8 @@ func NewSubscriber(config *Config, secrets *config.Secrets, metrics metrics.Clie
 		secrets: secrets,
 		metrics: metrics,
 		log:     log,
+
+		attempts: newAttempts(),
 	}
 }
 
@@ -41,6 +47,17 @@ func (s *Subscriber) Subscribe(ctx context.Context) error {
 	sub.ReceiveSettings.Synchronous = true
 	sub.ReceiveSettings.MaxOutstandingMessages = 10
 
+	if err = s.setRetryPolicy(ctx, sub); nil != err {
+		return err
+	}
+
+	if "" != s.cfg.DeadLetterTopic {
+		topic := client.Topic(s.cfg.DeadLetterTopic)
+		defer topic.Stop()
+
+		s.deadLetters = &topicPublisher{topic: topic}
+	}
+
 	cctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -52,41 +69,11 @@ func (s *Subscriber) Subscribe(ctx context.Context) error {
 
 		s.metrics.PubSubMessageReceived()
 
-		pl, err := parsePayload(msg.Data)
-		if err != nil {
-			if err != errNotInsertAction {
-				s.log.WithField("reason", err).WithField("payload", string(msg.Data)).Error("couldn't parse pub/sub payload")
-			}
-
-			msg.Ack()
-			return
-		}
-
-		l := s.log.WithField("payload", pl)
-
-		cir, err := pl.asCanonicalImage()
-		if err != nil {
+		if s.process(ctx, msg) {
 			msg.Ack()
-			l.WithField("reason", err).Error("couldn't make canonical image")
-			return
-		}
-
-		l.WithField("status", "pending").Info("the vouch started")
-		vouchStatus, shouldRetry := s.check(cir)
-
-		// Nack if we want to retry, otherwise the catch-all Ack below will
-		// ensure this message doesn't get retried
-		if vouchStatus {
-			l.WithField("status", "success").Info("the vouch succeeded")
 		} else {
-			l.WithField("status", "failure").Info("the vouch failed")
-
-			if shouldRetry {
-				msg.Nack()
-			}
+			msg.Nack()
 		}
-
-		msg.Ack()
 	})
 
 	if msgErr != nil {
@@ -95,3 +82,31 @@ func (s *Subscriber) Subscribe(ctx context.Context) error {
 
 	return nil
 }
+
+// setRetryPolicy sets the subscription's retry policy to the configured
+// backoff, so that messages which are nacked aren't delivered again at once.
+func (s *Subscriber) setRetryPolicy(ctx context.Context, sub *pubsub.Subscription) error {
+	_, err := sub.Update(ctx, pubsub.SubscriptionConfigToUpdate{RetryPolicy: s.cfg.RetryPolicy()})
+	if nil != err {
+		return fmt.Errorf("failed to set the subscription's retry policy: %s", err)
+	}
+	return nil
+}
+
+// vouch checks the image, logging whether the vouch succeeded, and returns an
+// error if the checks couldn't be run. The error is transient if the checks
+// may run if they are tried again.
+func (s *Subscriber) vouch(l *logrus.Entry, image reference.Canonical) error {
+	l.WithField("status", "pending").Info("the vouch started")
+	vouchStatus, err := s.check(image)
+
+	if nil != err {
+		l.WithField("status", "error").WithError(err).Error("the vouch couldn't run")
+	} else if vouchStatus {
+		l.WithField("status", "success").Info("the vouch succeeded")
+	} else {
+		l.WithField("status", "failure").Info("the vouch failed")
+	}
+
+	return err
+}
@@ -0,0 +1,268 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/docker/distribution/reference"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultWebhookWorkers is the number of pushed images that are checked at
+	// once if the Config doesn't set a number.
+	DefaultWebhookWorkers = 2
+
+	// DefaultWebhookQueueSize is the number of pushed images that can wait to
+	// be checked if the Config doesn't set a size.
+	DefaultWebhookQueueSize = 100
+
+	// maxWebhookSize is the largest notification that is read.
+	maxWebhookSize = 1 << 20
+
+	registryWebhookPath = "/registry/{registry}"
+)
+
+var (
+	errNoWebhookSecret  = errors.New("registry webhooks require a secret, or pub/sub push an audience")
+	errWebhookQueueFull = errors.New("too many images are waiting to be checked")
+)
+
+// webhookHandler accepts registry notifications, and queues the pushed images
+// to be checked.
+type webhookHandler struct {
+	subscriber *Subscriber
+	secret     string
+	queue      chan reference.Canonical
+
+	// vouch checks and attests an image, returning an error if the checks
+	// couldn't run.
+	vouch func(*logrus.Entry, reference.Canonical) error
+}
+
+// ServeWebhooks listens for registry notifications on the configured address,
+// and checks and attests each image that was pushed, until the context is
+// canceled. If a push audience is configured, it also receives Pub/Sub push
+// messages on PushPath.
+func (s *Subscriber) ServeWebhooks(ctx context.Context) error {
+	if err := s.cfg.ValidatePush(); nil != err {
+		return err
+	}
+
+	if "" == s.cfg.WebhookSecret && "" == s.cfg.PushAudience {
+		return errNoWebhookSecret
+	}
+
+	if "" != s.cfg.DeadLetterTopic {
+		client, err := pubsub.NewClient(ctx, s.cfg.Project)
+		if nil != err {
+			return fmt.Errorf("failed to create new pubsub client: %s", err)
+		}
+		defer client.Close()
+
+		topic := client.Topic(s.cfg.DeadLetterTopic)
+		defer topic.Stop()
+
+		s.deadLetters = &topicPublisher{topic: topic}
+	}
+
+	handler := &webhookHandler{
+		subscriber: s,
+		secret:     s.cfg.WebhookSecret,
+		queue:      make(chan reference.Canonical, s.cfg.WebhookQueueLimit()),
+		vouch:      s.vouch,
+	}
+
+	// workers stop backing off once the server is shutting down, but still
+	// check the images left in the queue.
+	workCtx, stopWork := context.WithCancel(ctx)
+	defer stopWork()
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.WebhookWorkerCount(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.work(workCtx)
+		}()
+	}
+
+	server := &http.Server{
+		Addr:              s.cfg.WebhookAddress,
+		Handler:           handler.router(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// the queue can't be closed until Shutdown returns, as handlers may still
+	// be queueing images until then.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	s.log.WithField("address", s.cfg.WebhookAddress).Info("listening for registry webhooks")
+
+	err := server.ListenAndServe()
+
+	// stop the server if it failed, rather than waiting for the context.
+	cancel()
+	<-shutdown
+
+	stopWork()
+	close(handler.queue)
+	wg.Wait()
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// router routes notifications to the handler for each registry, and Pub/Sub
+// push messages to the Subscriber.
+func (h *webhookHandler) router() *mux.Router {
+	router := mux.NewRouter().StrictSlash(true)
+	if "" != h.secret {
+		router.Methods(http.MethodPost).Path(registryWebhookPath).HandlerFunc(h.handleNotification)
+	}
+	if "" != h.subscriber.cfg.PushAudience {
+		router.Methods(http.MethodPost).Path(PushPath).HandlerFunc(h.subscriber.HandlePush)
+	}
+	return router
+}
+
+// handleNotification verifies and parses a registry notification, and queues
+// the images in it.
+func (h *webhookHandler) handleNotification(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	registry := mux.Vars(r)["registry"]
+	parse, ok := registryParsers[registry]
+	if !ok {
+		http.Error(w, fmt.Sprintf("registry %q is not supported", registry), http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l := h.subscriber.log.WithField("registry", registry)
+
+	if !isAuthenticWebhook(r, body, h.secret) {
+		http.Error(w, "webhook secret is incorrect", http.StatusUnauthorized)
+		l.Error("received registry webhook with incorrect secret")
+		return
+	}
+
+	images, err := parse(body)
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		l.WithField("reason", err).WithField("payload", string(body)).Error("couldn't parse registry webhook")
+		return
+	}
+
+	for _, image := range images {
+		select {
+		case h.queue <- image:
+		default:
+			// the registry retries the notification, including images that
+			// were already queued.
+			http.Error(w, errWebhookQueueFull.Error(), http.StatusServiceUnavailable)
+			l.WithField("image", image.String()).Error(errWebhookQueueFull.Error())
+			return
+		}
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	response := struct {
+		Images []string `json:"images"`
+	}{Images: make([]string, 0, len(images))}
+	for _, image := range images {
+		response.Images = append(response.Images, image.String())
+	}
+
+	if err = json.NewEncoder(w).Encode(response); nil != err {
+		l.WithError(err).Error("failed to encode response as JSON")
+	}
+}
+
+// work checks queued images until the queue is closed.
+func (h *webhookHandler) work(ctx context.Context) {
+	for image := range h.queue {
+		h.check(ctx, image)
+	}
+}
+
+// check checks and attests the passed image. The registry was answered once
+// the image was queued, so it won't send the image again: checks which fail
+// with a transient error are tried again after a backoff, until they have
+// been tried MaxAttemptCount times. Images which still can't be checked are
+// published to the dead letter topic, if there is one, as a pub/sub message.
+func (h *webhookHandler) check(ctx context.Context, image reference.Canonical) {
+	s := h.subscriber
+	l := s.log.WithField("image", image.String())
+
+	attempt := 1
+	err := h.vouch(l, image)
+	for isTransient(err) && attempt < s.cfg.MaxAttemptCount() {
+		backoff := s.cfg.Backoff(attempt)
+		l.WithError(err).WithField("attempt", attempt).WithField("backoff", backoff.String()).Warning("the vouch failed, it will be tried again")
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			// check the image once more, rather than waiting.
+		}
+
+		attempt++
+		err = h.vouch(l, image)
+	}
+
+	if nil == err {
+		return
+	}
+
+	if isTransient(err) {
+		err = fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+	}
+
+	l = l.WithError(err).WithField("attempt", attempt)
+
+	if nil == s.deadLetters {
+		l.Error("dropping image, there is no dead letter topic")
+		return
+	}
+
+	data, marshalErr := json.Marshal(Payload{Action: insertAction, Digest: image.String()})
+	if nil != marshalErr {
+		l.WithField("reason", marshalErr).Error("dropping image, couldn't make a pub/sub payload for it")
+		return
+	}
+
+	if publishErr := s.deadLetter(context.Background(), &pubsub.Message{Data: data}, attempt, err); nil != publishErr {
+		l.WithField("reason", publishErr).Error("dropping image, couldn't publish it to the dead letter topic")
+		return
+	}
+
+	l.Error("published image to the dead letter topic")
+}
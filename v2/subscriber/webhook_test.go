@@ -0,0 +1,158 @@
+package subscriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhookHandler(queueSize int) *webhookHandler {
+	return &webhookHandler{
+		subscriber: NewSubscriber(&Config{}, nil, nil, logrus.New()),
+		secret:     "hunter2",
+		queue:      make(chan reference.Canonical, queueSize),
+	}
+}
+
+func postNotification(t *testing.T, h *webhookHandler, path string, secret string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+secret)
+
+	recorder := httptest.NewRecorder()
+	h.router().ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestWebhookHandler(t *testing.T) {
+	h := newTestWebhookHandler(2)
+
+	recorder := postNotification(t, h, "/registry/harbor", "hunter2", harborNotificationBody)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var response struct {
+		Images []string `json:"images"`
+	}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.Equal(t, []string{"harbor.example.com/library/app@" + testDigest}, response.Images)
+
+	require.Len(t, h.queue, 1)
+	assert.Equal(t, "harbor.example.com/library/app@"+testDigest, (<-h.queue).String())
+}
+
+func TestWebhookHandlerErrors(t *testing.T) {
+	h := newTestWebhookHandler(1)
+
+	recorder := postNotification(t, h, "/registry/quay", "hunter2", harborNotificationBody)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = postNotification(t, h, "/registry/harbor", "hunter3", harborNotificationBody)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = postNotification(t, h, "/registry/harbor", "hunter2", []byte(`{"type": `))
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = postNotification(t, h, "/registry/harbor", "hunter2", harborNotificationBody)
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	// the queue is full.
+	recorder = postNotification(t, h, "/registry/harbor", "hunter2", harborNotificationBody)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Len(t, h.queue, 1)
+}
+
+func TestServeWebhooksRequiresSecret(t *testing.T) {
+	s := NewSubscriber(&Config{WebhookAddress: "127.0.0.1:0"}, nil, nil, logrus.New())
+	assert.Equal(t, errNoWebhookSecret, s.ServeWebhooks(context.Background()))
+}
+
+func TestServeWebhooksStops(t *testing.T) {
+	serve := func(ctx context.Context, address string) error {
+		s := NewSubscriber(&Config{WebhookAddress: address, WebhookSecret: "hunter2"}, nil, nil, logrus.New())
+
+		done := make(chan error, 1)
+		go func() {
+			done <- s.ServeWebhooks(ctx)
+		}()
+
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(10 * time.Second):
+			t.Fatal("ServeWebhooks didn't return")
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	assert.NoError(t, serve(ctx, "127.0.0.1:0"))
+
+	// a server which can't listen returns without its context being canceled.
+	assert.Error(t, serve(context.Background(), "127.0.0.1:-1"))
+}
+
+func TestWebhookHandlerRetries(t *testing.T) {
+	image, err := reference.ParseNamed("harbor.example.com/library/app@" + testDigest)
+	require.NoError(t, err)
+
+	h := newTestWebhookHandler(1)
+	h.subscriber.cfg.MaxAttempts = 3
+	h.subscriber.cfg.MinBackoff = time.Millisecond
+
+	deadLetters := &testPublisher{}
+	h.subscriber.deadLetters = deadLetters
+
+	failures := 0
+	h.vouch = func(_ *logrus.Entry, _ reference.Canonical) error {
+		if failures < 2 {
+			failures++
+			return transient(errors.New("metadata client unavailable"))
+		}
+		return nil
+	}
+
+	// the vouch succeeds on its third attempt.
+	h.check(context.Background(), image.(reference.Canonical))
+	assert.Equal(t, 2, failures)
+	assert.Empty(t, deadLetters.messages)
+
+	attempts := 0
+	h.vouch = func(_ *logrus.Entry, _ reference.Canonical) error {
+		attempts++
+		return transient(errors.New("metadata client unavailable"))
+	}
+
+	// images which still can't be checked are dead lettered.
+	h.check(context.Background(), image.(reference.Canonical))
+	assert.Equal(t, 3, attempts)
+
+	require.Len(t, deadLetters.messages, 1)
+	published := deadLetters.messages[0]
+	assert.JSONEq(t, `{"action": "INSERT", "digest": "harbor.example.com/library/app@`+testDigest+`"}`, string(published.Data))
+	assert.Equal(t, "3", published.Attributes[DeliveryAttemptsAttribute])
+	assert.Contains(t, published.Attributes[FailureReasonAttribute], "gave up after 3 attempts")
+
+	// errors which won't go away aren't retried.
+	attempts = 0
+	h.vouch = func(_ *logrus.Entry, _ reference.Canonical) error {
+		attempts++
+		return permanent(errors.New("failed to create CheckSuite"))
+	}
+
+	h.check(context.Background(), image.(reference.Canonical))
+	assert.Equal(t, 1, attempts)
+	assert.Len(t, deadLetters.messages, 2)
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"time"
 
+	"github.com/grafeas/voucher/v2/attestation"
 	"github.com/grafeas/voucher/v2/metrics"
 )
 
 // Suite is a suite of Checks, which
 type Suite struct {
-	checks map[string]Check
+	checks   map[string]Check
+	formats  map[string]PayloadFormat
+	progress ProgressFunc
 }
 
+// ProgressFunc is called by a Suite with each CheckResult as soon as it is
+// known, and again once Attest has processed that CheckResult.
+type ProgressFunc func(result CheckResult)
+
 // Add adds a Check to the checks that can be run. Once a Check is added,
 // it can be referenced by the name that was passed in when this function was called.
 func (cs *Suite) Add(name string, check Check) {
@@ -34,12 +41,50 @@ func (cs *Suite) Get(name string) (Check, error) {
 	return nil, ErrNoCheck
 }
 
+// SetPayloadFormat sets the format of the payload of the attestations created
+// for the Check with the passed name. Checks without a format use the
+// BinAuthzPayloadFormat.
+func (cs *Suite) SetPayloadFormat(name string, format PayloadFormat) {
+	cs.formats[name] = format
+}
+
+// payloadFormat returns the format of the payload of the attestations created
+// for the Check with the passed name.
+func (cs *Suite) payloadFormat(name string) PayloadFormat {
+	if format, ok := cs.formats[name]; ok {
+		return format
+	}
+	return BinAuthzPayloadFormat
+}
+
+// SetProgressFunc sets the function that is called with each CheckResult as
+// the Suite runs and attests its Checks. It is called from the goroutine that
+// called Run or Attest.
+func (cs *Suite) SetProgressFunc(progress ProgressFunc) {
+	cs.progress = progress
+}
+
+// reportProgress passes the CheckResult to the Suite's ProgressFunc, if one
+// is set.
+func (cs *Suite) reportProgress(result CheckResult) {
+	if nil != cs.progress {
+		cs.progress(result)
+	}
+}
+
 // runner runs the passed check against the passed ImageData, and pushes results to the
 // CheckResults channel.
 func runner(ctx context.Context, name string, check Check, imageData ImageData, resultsChan chan CheckResult, metricsClient metrics.Client) {
 	metricsClient.CheckRunStart(name)
 	checkStart := time.Now()
-	ok, err := check.Check(ctx, imageData)
+	var ok bool
+	var details interface{}
+	var err error
+	if detailedCheck, isDetailed := check.(DetailedCheck); isDetailed {
+		ok, details, err = detailedCheck.CheckWithDetails(ctx, imageData)
+	} else {
+		ok, err = check.Check(ctx, imageData)
+	}
 	metricsClient.CheckRunLatency(name, time.Since(checkStart))
 	if err == nil {
 		if ok {
@@ -47,10 +92,10 @@ func runner(ctx context.Context, name string, check Check, imageData ImageData,
 		} else {
 			metricsClient.CheckRunFailure(name)
 		}
-		resultsChan <- CheckResult{Name: name, Err: "", Success: ok, ImageData: imageData}
+		resultsChan <- CheckResult{Name: name, Err: "", Success: ok, ImageData: imageData, CheckDetails: details}
 	} else {
 		metricsClient.CheckRunError(name, err)
-		resultsChan <- CheckResult{Name: name, Err: err.Error(), Success: false, ImageData: imageData}
+		resultsChan <- CheckResult{Name: name, Err: err.Error(), Success: false, ImageData: imageData, CheckDetails: details}
 	}
 }
 
@@ -73,7 +118,9 @@ func (cs *Suite) Run(ctx context.Context, metricsClient metrics.Client, imageDat
 	}
 
 	for range cs.checks {
-		results = append(results, <-resultsChan)
+		result := <-resultsChan
+		cs.reportProgress(result)
+		results = append(results, result)
 	}
 
 	return results
@@ -88,7 +135,7 @@ func (cs *Suite) Attest(ctx context.Context, metricsClient metrics.Client, metad
 		checkStart := time.Now()
 		metricsClient.CheckAttestationStart(result.Name)
 		if result.Success {
-			details, err := createAttestation(ctx, metadataClient, result)
+			details, err := createAttestation(ctx, metadataClient, result, cs.payloadFormat(result.Name))
 			results[i].Details = details
 			if nil == err {
 				results[i].Attested = true
@@ -99,6 +146,7 @@ func (cs *Suite) Attest(ctx context.Context, metricsClient metrics.Client, metad
 			}
 		}
 		metricsClient.CheckAttestationLatency(result.Name, time.Since(checkStart))
+		cs.reportProgress(results[i])
 	}
 
 	return results
@@ -110,16 +158,25 @@ func (cs *Suite) RunAndAttest(ctx context.Context, metadataClient MetadataClient
 	return cs.Attest(ctx, metricsClient, metadataClient, results)
 }
 
-// createAttestation generates an attestation for the image Check described by CheckResult.
-// That attestation is then added to the metadata server the MetadataClient is connected to.
-func createAttestation(ctx context.Context, client MetadataClient, result CheckResult) (interface{}, error) {
-	payload, err := client.NewPayloadBody(result.ImageData)
+// createAttestation generates an attestation for the image Check described by CheckResult,
+// with a payload in the passed format. That attestation is then added to the metadata server
+// the MetadataClient is connected to.
+func createAttestation(ctx context.Context, client MetadataClient, result CheckResult, format PayloadFormat) (interface{}, error) {
+	payload, err := client.NewPayloadBody(ctx, result.ImageData, PayloadRequest{
+		Format:    format,
+		CheckName: result.Name,
+		Details:   result.CheckDetails,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	attestation := NewAttestation(result.Name, payload)
-	details, err := client.AddAttestationToImage(ctx, result.ImageData, attestation)
+	a := NewAttestation(result.Name, payload)
+	if InTotoPayloadFormat == format {
+		a.PayloadType = attestation.InTotoPayloadType
+	}
+
+	details, err := client.AddAttestationToImage(ctx, result.ImageData, a)
 	return details, err
 }
 
@@ -127,5 +184,6 @@ func createAttestation(ctx context.Context, client MetadataClient, result CheckR
 func NewSuite() *Suite {
 	suite := new(Suite)
 	suite.checks = make(map[string]Check)
+	suite.formats = make(map[string]PayloadFormat)
 	return suite
 }
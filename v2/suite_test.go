@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/grafeas/voucher/v2/attestation"
 	"github.com/grafeas/voucher/v2/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -134,7 +135,7 @@ func TestAttestSuite(t *testing.T) {
 
 	metadataClient := new(MockMetadataClient)
 	metadataClient.
-		On("NewPayloadBody", imageData).Return(imageData.String(), nil).
+		On("NewPayloadBody", mock.Anything, imageData, mock.Anything).Return(imageData.String(), nil).
 		On("AddAttestationToImage", mock.Anything, imageData, NewAttestation("snakeoil", imageData.String())).Return(SignedAttestation{
 		Attestation: Attestation{
 			CheckName: "snakeoil",
@@ -206,7 +207,7 @@ func TestNonattestingSuite(t *testing.T) {
 	errCreatingPayload := errors.New("cannot create payload body")
 
 	metadataClient := new(MockMetadataClient)
-	metadataClient.On("NewPayloadBody", imageData).Return("", errCreatingPayload)
+	metadataClient.On("NewPayloadBody", mock.Anything, imageData, mock.Anything).Return("", errCreatingPayload)
 
 	suite := NewSuite()
 	assert.NotNilf(t, suite, "could not make CheckSuite")
@@ -229,3 +230,102 @@ func TestNonattestingSuite(t *testing.T) {
 
 	assert.Contains(t, results, expectedResult)
 }
+
+func TestDetailedCheckSuite(t *testing.T) {
+	imageData := newTestImageData(t)
+	details := []string{"linux/amd64", "linux/arm64"}
+
+	signedAttestation := SignedAttestation{
+		Attestation: Attestation{
+			CheckName: "detailed",
+		},
+	}
+
+	metadataClient := new(MockMetadataClient)
+	metadataClient.
+		On("NewPayloadBody", mock.Anything, imageData, PayloadRequest{Format: BinAuthzPayloadFormat, CheckName: "detailed", Details: details}).Return(imageData.String(), nil).
+		On("AddAttestationToImage", mock.Anything, imageData, NewAttestation("detailed", imageData.String())).Return(signedAttestation, nil)
+
+	suite := NewSuite()
+	assert.NotNilf(t, suite, "could not make CheckSuite")
+
+	check := new(MockDetailedCheck)
+	check.On("CheckWithDetails", mock.Anything, imageData).Return(true, details, nil)
+	suite.Add("detailed", check)
+
+	results := suite.RunAndAttest(context.Background(), metadataClient, &metrics.NoopClient{}, imageData)
+
+	// the check's details are kept alongside the attestation.
+	expectedResult := CheckResult{
+		Name:         "detailed",
+		ImageData:    imageData,
+		Success:      true,
+		Attested:     true,
+		Details:      signedAttestation,
+		CheckDetails: details,
+	}
+
+	assert.Equal(t, []CheckResult{expectedResult}, results)
+	check.AssertNotCalled(t, "Check", mock.Anything, imageData)
+}
+
+func TestInTotoPayloadFormatSuite(t *testing.T) {
+	imageData := newTestImageData(t)
+
+	expectedAttestation := NewAttestation("intoto", "statement")
+	expectedAttestation.PayloadType = attestation.InTotoPayloadType
+
+	metadataClient := new(MockMetadataClient)
+	metadataClient.
+		On("NewPayloadBody", mock.Anything, imageData, PayloadRequest{Format: InTotoPayloadFormat, CheckName: "intoto"}).Return("statement", nil).
+		On("NewPayloadBody", mock.Anything, imageData, PayloadRequest{Format: BinAuthzPayloadFormat, CheckName: "binauthz"}).Return("payload", nil).
+		On("AddAttestationToImage", mock.Anything, imageData, expectedAttestation).Return(SignedAttestation{Attestation: expectedAttestation}, nil).
+		On("AddAttestationToImage", mock.Anything, imageData, NewAttestation("binauthz", "payload")).Return(SignedAttestation{}, nil)
+
+	suite := NewSuite()
+	suite.SetPayloadFormat("intoto", InTotoPayloadFormat)
+
+	for _, name := range []string{"intoto", "binauthz"} {
+		check := new(MockCheck)
+		check.On("Check", mock.Anything, imageData).Return(true, nil)
+		suite.Add(name, check)
+	}
+
+	results := suite.RunAndAttest(context.Background(), metadataClient, &metrics.NoopClient{}, imageData)
+
+	for _, result := range results {
+		assert.True(t, result.Attested, "%s was not attested: %s", result.Name, result.Err)
+	}
+	metadataClient.AssertExpectations(t)
+}
+
+func TestSuiteProgress(t *testing.T) {
+	suite := NewSuite()
+	imageData := newTestImageData(t)
+
+	metadataClient := new(MockMetadataClient)
+	metadataClient.
+		On("NewPayloadBody", mock.Anything, imageData, mock.Anything).Return(imageData.String(), nil).
+		On("AddAttestationToImage", mock.Anything, imageData, NewAttestation("passer", imageData.String())).Return(SignedAttestation{}, nil)
+
+	for name, pass := range map[string]bool{"passer": true, "failer": false} {
+		check := new(MockCheck)
+		check.On("Check", mock.Anything, imageData).Return(pass, nil)
+		suite.Add(name, check)
+	}
+
+	var progress []CheckResult
+	suite.SetProgressFunc(func(result CheckResult) {
+		progress = append(progress, result)
+	})
+
+	results := suite.RunAndAttest(context.Background(), metadataClient, &metrics.NoopClient{}, imageData)
+
+	// each result is reported once when its check has run, and once after
+	// attestation.
+	require.Len(t, progress, 4)
+	for _, result := range progress[:2] {
+		assert.False(t, result.Attested)
+	}
+	assert.ElementsMatch(t, results, progress[2:])
+}
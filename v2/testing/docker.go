@@ -67,6 +67,9 @@ func (mock *dockerAPIMock) ServeHTTP(writer http.ResponseWriter, req *http.Reque
 		mimeType, raw, _ := NewTestOCIManifest().Payload()
 		rawRespond(writer, mimeType, string(raw))
 		return
+	case "/v2/path/to/image-oci/manifests/sha256:0c10c10c52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da":
+		jsonRespond(writer, manifestlist.OCISchemaVersion.MediaType, NewTestOCIIndex())
+		return
 	case "/v2/path/to/image-oci/blobs/sha256:0fddd6ec43ab484d35772852bbeefbc825bc2b9846d121f1e87da42cfef62e00":
 		jsonRespond(writer, schema2.MediaTypeImageConfig, NewTestNobodyImageConfig())
 		return
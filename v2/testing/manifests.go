@@ -54,12 +54,15 @@ func NewTestManifestList() *manifestlist.ManifestList {
 			MediaType: manifestlist.MediaTypeManifestList,
 		},
 		Manifests: []manifestlist.ManifestDescriptor{
-			// Wrong arch
+			// Wrong arch, runs as root
 			{
 				Platform: manifestlist.PlatformSpec{
 					OS:           "linux",
 					Architecture: "arm64",
 				},
+				Descriptor: distribution.Descriptor{
+					Digest: "sha256:b248c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da",
+				},
 			},
 			// Wrong OS
 			{
@@ -67,6 +70,9 @@ func NewTestManifestList() *manifestlist.ManifestList {
 					OS:           "windows",
 					Architecture: "amd64",
 				},
+				Descriptor: distribution.Descriptor{
+					Digest: "sha256:b148c8af52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da",
+				},
 			},
 			// Matched manifest
 			{
@@ -82,6 +88,36 @@ func NewTestManifestList() *manifestlist.ManifestList {
 	}
 }
 
+// NewTestOCIIndex creates a test OCI image index for our mock Docker API. It
+// contains a single linux/amd64 image and a BuildKit attestation manifest.
+func NewTestOCIIndex() *manifestlist.ManifestList {
+	return &manifestlist.ManifestList{
+		Versioned: manifestlist.OCISchemaVersion,
+		Manifests: []manifestlist.ManifestDescriptor{
+			{
+				Platform: manifestlist.PlatformSpec{
+					OS:           "linux",
+					Architecture: "amd64",
+				},
+				Descriptor: distribution.Descriptor{
+					MediaType: ocischema.SchemaVersion.MediaType,
+					Digest:    "sha256:bbc57559ea5f6d7359f53c92bdfd386df0b1b0384591a24b7a6cf40b77343a4a",
+				},
+			},
+			{
+				Platform: manifestlist.PlatformSpec{
+					OS:           "unknown",
+					Architecture: "unknown",
+				},
+				Descriptor: distribution.Descriptor{
+					MediaType: ocischema.SchemaVersion.MediaType,
+					Digest:    "sha256:a77e57a7e52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da",
+				},
+			},
+		},
+	}
+}
+
 func NewTestOCIManifest() *ocischema.DeserializedManifest {
 	manifest := ocischema.Manifest{
 		Config: distribution.Descriptor{
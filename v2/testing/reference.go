@@ -33,6 +33,14 @@ func NewTestManifestListReference(t *testing.T) reference.Canonical {
 	return parseReference(t, "localhost/path/to/image@sha256:fefafefa52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da")
 }
 
+// NewTestOCIIndexReference creates a new reference to be used for testing OCI image indexes.
+// The returned reference is assumed to be an OCI index, with a single linux/amd64 image.
+func NewTestOCIIndexReference(t *testing.T) reference.Canonical {
+	t.Helper()
+
+	return parseReference(t, "localhost/path/to/image-oci@sha256:0c10c10c52ba402ed7dd98d73f5a41836ece508d1f4704b274562ac0c9b3b7da")
+}
+
 // NewBadTestReference creates a new reference to be used throughout the docker tests.
 // The returned reference is assumed to not, and does not have valid configuration
 // or layers.
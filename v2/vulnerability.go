@@ -7,6 +7,7 @@ type Vulnerability struct {
 	Description string   `json:"description"` // Description of the Vulnerability.
 	Severity    Severity `json:"severity"`    // Severity of the Vulnerability.
 	FixedBy     string   `json:"fixed_by"`    // If this vulnerability was fixed, what it was fixed by.
+	Package     string   `json:"package"`     // The package affected by the Vulnerability, if known.
 }
 
 // ShouldIncludeVulnerability returns true if the passed vulnerability should be included
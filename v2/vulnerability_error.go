@@ -3,8 +3,11 @@ package voucher
 import "fmt"
 
 // VulnerabilitiesError is an error that also contains a list of vulnerabilities.
+// Waived contains the vulnerabilities which were found but accepted due to a
+// VulnerabilityException, and did not cause the error.
 type VulnerabilitiesError struct {
 	Vulnerabilities []Vulnerability
+	Waived          []WaivedVulnerability
 }
 
 // Error returns the error message for the VulnerabilitiesError
@@ -17,6 +20,18 @@ func (err VulnerabilitiesError) Error() string {
 		}
 		output += fmt.Sprintf("%s (%s)", vulnerability.Name, vulnerability.Severity)
 	}
+
+	if 0 != len(err.Waived) {
+		output += fmt.Sprintf("; waived %d vulnerabilities: ", len(err.Waived))
+
+		for i, waived := range err.Waived {
+			if i != 0 {
+				output += ", "
+			}
+			output += fmt.Sprintf("%s (%s, expires %s)", waived.Name, waived.Severity, waived.Exception.Expires.Format("2006-01-02"))
+		}
+	}
+
 	return output
 }
 
@@ -28,3 +43,12 @@ func NewVulnerabilityError(vuls []Vulnerability) (err error) {
 	}
 	return
 }
+
+// NewWaivedVulnerabilityError creates a new VulnerabilityError with the
+// passed blocking Vulnerabilities, and the Vulnerabilities which were waived.
+func NewWaivedVulnerabilityError(vuls []Vulnerability, waived []WaivedVulnerability) error {
+	return VulnerabilitiesError{
+		Vulnerabilities: vuls,
+		Waived:          waived,
+	}
+}
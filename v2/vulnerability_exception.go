@@ -0,0 +1,130 @@
+package voucher
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNoExceptionExpiry is returned when a VulnerabilityException does not
+// have an expiry date.
+var ErrNoExceptionExpiry = errors.New("vulnerability exception must have an expiry date")
+
+// ErrNoExceptionJustification is returned when a VulnerabilityException does
+// not have a justification.
+var ErrNoExceptionJustification = errors.New("vulnerability exception must have a justification")
+
+// VulnerabilityException waives a known vulnerability, so that it no longer
+// fails vulnerability checks until the exception expires.
+type VulnerabilityException struct {
+	Vulnerability string    `mapstructure:"vulnerability" json:"vulnerability"` // The name of the waived Vulnerability, or its CVE number.
+	Images        []string  `mapstructure:"images" json:"images,omitempty"`     // [optional] Globs of the image paths the exception applies to.
+	Package       string    `mapstructure:"package" json:"package,omitempty"`   // [optional] The package the exception applies to.
+	Expires       time.Time `mapstructure:"expires" json:"expires"`             // When the exception stops applying.
+	Justification string    `mapstructure:"justification" json:"justification"` // Why the vulnerability is acceptable.
+}
+
+// Validate returns an error if the VulnerabilityException is missing any of
+// its mandatory fields.
+func (e VulnerabilityException) Validate() error {
+	if "" == e.Vulnerability {
+		return errors.New("vulnerability exception must name a vulnerability")
+	}
+
+	if e.Expires.IsZero() {
+		return fmt.Errorf("%s: %w", e.Vulnerability, ErrNoExceptionExpiry)
+	}
+
+	if "" == strings.TrimSpace(e.Justification) {
+		return fmt.Errorf("%s: %w", e.Vulnerability, ErrNoExceptionJustification)
+	}
+
+	for _, pattern := range e.Images {
+		if _, err := path.Match(pattern, ""); nil != err {
+			return fmt.Errorf("%s: invalid image glob %q: %w", e.Vulnerability, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Expired returns true if the exception has expired at the passed time.
+func (e VulnerabilityException) Expired(now time.Time) bool {
+	return !now.Before(e.Expires)
+}
+
+// Matches returns true if the exception covers the passed vulnerability in
+// the passed image, regardless of whether it has expired.
+func (e VulnerabilityException) Matches(i ImageData, vuln Vulnerability) bool {
+	if !strings.EqualFold(e.Vulnerability, vuln.Name) {
+		return false
+	}
+
+	if "" != e.Package && e.Package != vuln.Package {
+		return false
+	}
+
+	if 0 == len(e.Images) {
+		return true
+	}
+
+	for _, pattern := range e.Images {
+		if MatchesImagePath(pattern, i.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WaivedVulnerability is a Vulnerability which was waived by a
+// VulnerabilityException.
+type WaivedVulnerability struct {
+	Vulnerability
+	Exception VulnerabilityException `json:"exception"`
+}
+
+// VulnerabilityExceptions is a list of VulnerabilityExceptions.
+type VulnerabilityExceptions []VulnerabilityException
+
+// Validate returns an error if any of the VulnerabilityExceptions are invalid.
+func (exceptions VulnerabilityExceptions) Validate() error {
+	for _, exception := range exceptions {
+		if err := exception.Validate(); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// Waive splits the passed vulnerabilities into those which are still
+// blocking and those which are waived by an unexpired exception at the
+// passed time.
+func (exceptions VulnerabilityExceptions) Waive(i ImageData, vulns []Vulnerability, now time.Time) (blocking []Vulnerability, waived []WaivedVulnerability) {
+	for _, vuln := range vulns {
+		exception, ok := exceptions.find(i, vuln, now)
+		if !ok {
+			blocking = append(blocking, vuln)
+			continue
+		}
+
+		waived = append(waived, WaivedVulnerability{
+			Vulnerability: vuln,
+			Exception:     exception,
+		})
+	}
+	return
+}
+
+// find returns the first unexpired exception which covers the passed
+// vulnerability.
+func (exceptions VulnerabilityExceptions) find(i ImageData, vuln Vulnerability, now time.Time) (VulnerabilityException, bool) {
+	for _, exception := range exceptions {
+		if exception.Matches(i, vuln) && !exception.Expired(now) {
+			return exception, true
+		}
+	}
+	return VulnerabilityException{}, false
+}
@@ -0,0 +1,101 @@
+package voucher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityExceptionValidate(t *testing.T) {
+	valid := VulnerabilityException{
+		Vulnerability: "CVE-2022-0778",
+		Expires:       time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Justification: "Fixed in the next base image",
+	}
+	assert.NoError(t, valid.Validate())
+
+	noExpiry := valid
+	noExpiry.Expires = time.Time{}
+	assert.ErrorIs(t, noExpiry.Validate(), ErrNoExceptionExpiry)
+
+	noJustification := valid
+	noJustification.Justification = " "
+	assert.ErrorIs(t, noJustification.Validate(), ErrNoExceptionJustification)
+
+	noVulnerability := valid
+	noVulnerability.Vulnerability = ""
+	assert.Error(t, noVulnerability.Validate())
+
+	badGlob := valid
+	badGlob.Images = []string{"gcr.io/[project"}
+	assert.Error(t, badGlob.Validate())
+}
+
+func TestVulnerabilityExceptionsWaive(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	i, err := NewImageData("gcr.io/project/path/to/image@sha256:97db2bc359ccc94d3b2d6f5daa4173e9e91c513b0dcd961408adbb95ec5e5ce5")
+	require.NoError(t, err)
+
+	exceptions := VulnerabilityExceptions{
+		{
+			Vulnerability: "cve-scoped-to-image",
+			Images:        []string{"gcr.io/project"},
+			Expires:       now.Add(time.Hour),
+			Justification: "image",
+		},
+		{
+			Vulnerability: "cve-other-image",
+			Images:        []string{"gcr.io/other-*"},
+			Expires:       now.Add(time.Hour),
+			Justification: "other image",
+		},
+		{
+			Vulnerability: "cve-scoped-to-package",
+			Package:       "openssl",
+			Expires:       now.Add(time.Hour),
+			Justification: "package",
+		},
+		{
+			Vulnerability: "cve-expired",
+			Expires:       now,
+			Justification: "expired",
+		},
+	}
+
+	vulns := []Vulnerability{
+		{Name: "CVE-SCOPED-TO-IMAGE", Severity: HighSeverity},
+		{Name: "cve-other-image", Severity: HighSeverity},
+		{Name: "cve-scoped-to-package", Severity: HighSeverity, Package: "openssl"},
+		{Name: "cve-scoped-to-package", Severity: HighSeverity, Package: "libc"},
+		{Name: "cve-expired", Severity: CriticalSeverity},
+	}
+
+	blocking, waived := exceptions.Waive(i, vulns, now)
+
+	assert.Equal(t, []Vulnerability{vulns[1], vulns[3], vulns[4]}, blocking)
+	assert.Equal(t, []WaivedVulnerability{
+		{Vulnerability: vulns[0], Exception: exceptions[0]},
+		{Vulnerability: vulns[2], Exception: exceptions[2]},
+	}, waived)
+}
+
+func TestWaivedVulnerabilityError(t *testing.T) {
+	vulns := makeTestVulns()
+
+	err := NewWaivedVulnerabilityError(vulns[:1], []WaivedVulnerability{
+		{
+			Vulnerability: vulns[2],
+			Exception: VulnerabilityException{
+				Vulnerability: vulns[2].Name,
+				Expires:       time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				Justification: "rare",
+			},
+		},
+	})
+
+	expected := "vulnernable to 1 vulnerabilities: Bad One (high); waived 1 vulnerabilities: The Rare One (critical, expires 2030-01-01)"
+	assert.Equal(t, expected, err.Error())
+}
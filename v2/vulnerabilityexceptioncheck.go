@@ -0,0 +1,8 @@
+package voucher
+
+// VulnerabilityExceptionCheck represents a Voucher check which accepts
+// vulnerabilities covered by VulnerabilityExceptions.
+type VulnerabilityExceptionCheck interface {
+	Check
+	SetVulnerabilityExceptions(VulnerabilityExceptions)
+}